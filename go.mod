@@ -3,6 +3,8 @@ module github.com/devicelab-dev/maestro-runner
 go 1.22.0
 
 require (
+	github.com/danielpaulus/go-ios v1.0.131
+	github.com/dop251/goja v0.0.0-20251201205617-2bb4c724c0f9
 	github.com/urfave/cli/v2 v2.27.7
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -11,10 +13,8 @@ require (
 	github.com/Masterminds/semver v1.5.0 // indirect
 	github.com/cenkalti/backoff v2.2.1+incompatible // indirect
 	github.com/cpuguy83/go-md2man/v2 v2.0.7 // indirect
-	github.com/danielpaulus/go-ios v1.0.131 // indirect
 	github.com/dlclark/regexp2 v1.11.4 // indirect
 	github.com/docopt/docopt-go v0.0.0-20180111231733-ee0de3bc6815 // indirect
-	github.com/dop251/goja v0.0.0-20251201205617-2bb4c724c0f9 // indirect
 	github.com/go-sourcemap/sourcemap v2.1.3+incompatible // indirect
 	github.com/go-task/slim-sprig v0.0.0-20230315185526-52ccab3ef572 // indirect
 	github.com/google/btree v1.1.2 // indirect
@@ -10,6 +10,7 @@ import (
 	"github.com/devicelab-dev/maestro-runner/pkg/core"
 	"github.com/devicelab-dev/maestro-runner/pkg/device"
 	uia2driver "github.com/devicelab-dev/maestro-runner/pkg/driver/uiautomator2"
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
 	"github.com/devicelab-dev/maestro-runner/pkg/logger"
 	"github.com/devicelab-dev/maestro-runner/pkg/uiautomator2"
 )
@@ -133,6 +134,8 @@ func createUIAutomator2Driver(cfg *RunConfig, dev *device.AndroidDevice, info de
 	} else {
 		client = uiautomator2.NewClientTCP(dev.LocalPort())
 	}
+	client.SetDeviceID(info.Serial)
+	client.SetRecorder(cfg.recorder)
 
 	// Set log path to report folder
 	if cfg.OutputDir != "" {
@@ -177,7 +180,10 @@ func createUIAutomator2Driver(cfg *RunConfig, dev *device.AndroidDevice, info de
 		DeviceID:    info.Serial,
 		DeviceName:  fmt.Sprintf("%s %s", info.Brand, info.Model),
 		OSVersion:   info.SDK,
+		OSRelease:   info.Release,
 		IsSimulator: info.IsEmulator,
+		FormFactor:  androidFormFactor(info),
+		Locale:      info.Locale,
 		AppID:       cfg.AppID,
 		AppVersion:  appVersion,
 	}
@@ -194,6 +200,14 @@ func createUIAutomator2Driver(cfg *RunConfig, dev *device.AndroidDevice, info de
 	return driver, cleanup, nil
 }
 
+// androidFormFactor returns the form factor of an Android device.
+func androidFormFactor(info device.DeviceInfo) string {
+	if info.IsTablet {
+		return flow.FormFactorTablet
+	}
+	return flow.FormFactorPhone
+}
+
 // autoDetectAndroidDevices finds N available Android devices.
 func autoDetectAndroidDevices(count int) ([]string, error) {
 	// Use adb devices to list all connected devices
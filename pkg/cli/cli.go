@@ -135,6 +135,14 @@ Examples:
 		// Keep test command for backward compatibility
 		Commands: []*cli.Command{
 			testCommand,
+			inspectCommand,
+			recordCommand,
+			validateCommand,
+			listCommand,
+			envCommand,
+			fmtCommand,
+			lintCommand,
+			schemaCommand,
 			wdaCommand,
 		},
 	}
@@ -3,7 +3,9 @@ package cli
 import (
 	"net"
 	"os"
+	"path/filepath"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -330,7 +332,7 @@ func TestTestCommand_WithFlowFile(t *testing.T) {
 	os.Stdout, _ = os.Open(os.DevNull)
 	defer func() { os.Stdout = oldStdout }()
 
-	err := app.Run([]string{"test-app", "-p", "mock", "test", flowFile})
+	err := app.Run([]string{"test-app", "-p", "mock", "test", "--output", dir + "/reports", flowFile})
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -359,6 +361,9 @@ func TestTestCommand_WithAllFlags(t *testing.T) {
 	os.Stdout, _ = os.Open(os.DevNull)
 	defer func() { os.Stdout = oldStdout }()
 
+	// --continuous watches until interrupted: stop it after the first run
+	go interruptAfterReport(t, dir+"/reports")
+
 	// Note: global flags before command, command flags before positional args
 	err := app.Run([]string{
 		"test-app",
@@ -380,6 +385,21 @@ func TestTestCommand_WithAllFlags(t *testing.T) {
 	}
 }
 
+// interruptAfterReport sends SIGINT to the test process once a run has
+// written its report under dir.
+func interruptAfterReport(t *testing.T, dir string) {
+	deadline := time.Now().Add(30 * time.Second)
+	for time.Now().Before(deadline) {
+		if matches, _ := filepath.Glob(filepath.Join(dir, "*", "report.json")); len(matches) > 0 {
+			time.Sleep(200 * time.Millisecond)
+			_ = syscall.Kill(os.Getpid(), syscall.SIGINT)
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Error("no report written within 30s")
+}
+
 func TestTestCommand_FlattenWithOutput(t *testing.T) {
 	dir := t.TempDir()
 	flowFile := dir + "/test.yaml"
@@ -669,6 +689,7 @@ func TestTestCommand_WithCapsFlag(t *testing.T) {
 		"-p", "mock",
 		"--caps", capsFile,
 		"test",
+		"--output", dir + "/reports",
 		flowFile,
 	})
 	if err != nil {
@@ -0,0 +1,278 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/config"
+	"github.com/devicelab-dev/maestro-runner/pkg/core"
+	"github.com/devicelab-dev/maestro-runner/pkg/device"
+	"github.com/devicelab-dev/maestro-runner/pkg/executor"
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/devicelab-dev/maestro-runner/pkg/logger"
+	"github.com/devicelab-dev/maestro-runner/pkg/report"
+	"github.com/devicelab-dev/maestro-runner/pkg/watch"
+)
+
+// continuousRun is what the next run needs to know about the previous one.
+type continuousRun struct {
+	flows     []flow.Flow
+	source    []byte // Contents of the flow file when there is a single flow
+	stoppedAt int    // Index of the first failed required step, or the step count
+}
+
+// executeContinuous runs flows, then reruns them whenever a flow, a runFlow
+// or runScript dependency, or the workspace config changes. The driver is
+// created once, so the device session survives between runs. It returns
+// when ctx is cancelled (Ctrl+C).
+func executeContinuous(ctx context.Context, cfg *RunConfig, flows []flow.Flow, files []string) error {
+	var driver core.Driver
+	var cleanup func()
+	var err error
+	if strings.ToLower(cfg.Driver) == "appium" {
+		driver, cleanup, err = createAppiumDriver(cfg)
+	} else {
+		driver, cleanup, err = CreateDriver(cfg)
+	}
+	if err != nil {
+		logger.Error("Failed to create driver: %v", err)
+		var noDevErr *device.NoDevicesError
+		if errors.As(err, &noDevErr) {
+			return noDevErr
+		}
+		return fmt.Errorf("failed to create driver: %w", err)
+	}
+	defer cleanup()
+
+	dbg := newDebugger(cfg)
+	watcher := watch.New(watchPaths(cfg, files), watch.DefaultInterval)
+	prev := newContinuousRun(flows)
+	startStep := 0
+
+	for {
+		if prev.flows != nil {
+			runContinuousOnce(ctx, cfg, driver, dbg, prev, startStep)
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		fmt.Printf("\n  %sWatching%s %d file(s) for changes — Ctrl+C to stop\n",
+			color(colorCyan), color(colorReset), watcher.Len())
+		changed, err := watcher.Wait(ctx)
+		if err != nil {
+			return nil
+		}
+		for _, path := range changed {
+			fmt.Printf("  %s●%s Changed: %s\n", color(colorCyan), color(colorReset), path)
+		}
+		logger.Info("Continuous mode: changed %v", changed)
+
+		if envSourceChanged(cfg, changed) {
+			if err := reloadWorkspaceConfig(cfg); err != nil {
+				fmt.Printf("  %s⚠%s Warning: failed to reload config: %v\n", color(colorYellow), color(colorReset), err)
+			}
+		}
+
+		flows, files, err := loadFlows(cfg)
+		watcher.Set(watchPaths(cfg, files))
+		if err != nil {
+			fmt.Printf("  %s✗%s %v — fix the flow and save to rerun\n", color(colorRed), color(colorReset), err)
+			prev = &continuousRun{}
+			continue
+		}
+
+		next := newContinuousRun(flows)
+		startStep = 0
+		if cfg.FromChanged {
+			startStep = resumeStep(prev, next, changed)
+		}
+		prev = next
+	}
+}
+
+func newContinuousRun(flows []flow.Flow) *continuousRun {
+	run := &continuousRun{flows: flows}
+	if len(flows) == 1 {
+		run.stoppedAt = len(flows[0].Steps)
+		if data, err := os.ReadFile(flows[0].SourcePath); err == nil {
+			run.source = data
+		}
+	}
+	return run
+}
+
+// runContinuousOnce executes one run and records where a single flow stopped.
+func runContinuousOnce(ctx context.Context, cfg *RunConfig, driver core.Driver, dbg executor.Debugger, run *continuousRun, startStep int) {
+	if startStep > 0 {
+		fmt.Printf("\n  %sResuming%s at step %d\n", color(colorCyan), color(colorReset), startStep+1)
+	}
+
+	stepComplete := onStepComplete
+	if len(run.flows) == 1 {
+		steps := run.flows[0].Steps
+		stepComplete = func(idx int, desc string, passed bool, durationMs int64, errMsg string) {
+			onStepComplete(idx, desc, passed, durationMs, errMsg)
+			if !passed && idx < run.stoppedAt && !steps[idx].IsOptional() {
+				run.stoppedAt = idx
+			}
+		}
+	}
+
+	deviceInfo := buildDeviceReport(driver)
+	runner := executor.New(driver, executor.RunnerConfig{
+		OutputDir:          cfg.OutputDir,
+		Parallelism:        0,
+		Retries:            cfg.Retries,
+		Artifacts:          cfg.Artifacts,
+		Device:             deviceInfo,
+		App:                buildAppReport(driver),
+		RunnerVersion:      Version,
+		DriverName:         resolveDriverName(cfg, cfg.Platform),
+		Env:                cfg.Env,
+		Secrets:            cfg.Secrets,
+		WaitForIdleTimeout: cfg.WaitForIdleTimeout,
+		CommandTimeout:     cfg.CommandTimeout,
+		ModulePaths:        cfg.ModulePaths,
+		ScriptTimeout:      cfg.ScriptTimeout,
+		Seed:               cfg.Seed,
+		FlowRoot:           cfg.flowRoot(),
+		OrderedFlows:       cfg.OrderedFlows,
+		StopOrderOnFailure: cfg.StopOrderOnFailure,
+		BeforeSuite:        cfg.BeforeSuite,
+		AfterSuite:         cfg.AfterSuite,
+		DeviceGroups:       cfg.DeviceGroups,
+		DeviceInfo:         &deviceInfo,
+		OnFlowStart:        onFlowStart,
+		OnStepComplete:     stepComplete,
+		OnNestedStep:       onNestedStep,
+		OnNestedFlowStart:  onNestedFlowStart,
+		OnFlowEnd:          onFlowEnd,
+		OnSuiteHookStart:   onSuiteHookStart,
+		Debugger:           dbg,
+		StartStep:          startStep,
+	})
+
+	start := time.Now()
+	result, err := runner.Run(ctx, run.flows)
+	if err != nil {
+		fmt.Printf("  %s✗%s Run failed: %v\n", color(colorRed), color(colorReset), err)
+		return
+	}
+	elapsed := time.Since(start).Round(100 * time.Millisecond)
+	if result.Status == report.StatusPassed {
+		fmt.Printf("\n  %s✓ %d/%d flow(s) passed%s in %s\n", color(colorGreen), result.PassedFlows, result.TotalFlows, color(colorReset), elapsed)
+	} else {
+		fmt.Printf("\n  %s✗ %d/%d flow(s) failed%s in %s\n", color(colorRed), result.FailedFlows, result.TotalFlows, color(colorReset), elapsed)
+	}
+}
+
+// resumeStep picks where to rerun a single flow after an edit: the first
+// changed step, or the step the previous run stopped at if that is earlier
+// (the device is still at that point). Anything else reruns from the start.
+func resumeStep(prev, next *continuousRun, changed []string) int {
+	if len(prev.flows) != 1 || len(next.flows) != 1 || prev.source == nil || next.source == nil {
+		return 0
+	}
+	path := next.flows[0].SourcePath
+	if prev.flows[0].SourcePath != path || len(changed) != 1 || !samePath(changed[0], path) {
+		return 0
+	}
+
+	first, err := watch.FirstChangedStep(prev.source, next.source)
+	if err != nil {
+		return 0
+	}
+	start := first
+	if prev.stoppedAt < start {
+		start = prev.stoppedAt
+	}
+	if start >= len(next.flows[0].Steps) {
+		return 0
+	}
+	return start
+}
+
+// envSourceChanged reports whether --config or an --env-file is among the
+// changed files.
+func envSourceChanged(cfg *RunConfig, changed []string) bool {
+	if cfg.ConfigPath != "" && containsPath(changed, cfg.ConfigPath) {
+		return true
+	}
+	for _, path := range cfg.EnvFiles {
+		if containsPath(changed, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// reloadWorkspaceConfig re-reads the workspace config (if any) and the env
+// files: the env of the config and its profile, re-merged with env files, -e
+// and --secret-env values, and the config's secrets, appId and run settings.
+func reloadWorkspaceConfig(cfg *RunConfig) error {
+	var workspaceConfig *config.Config
+	if cfg.ConfigPath != "" {
+		var err error
+		if workspaceConfig, err = config.Load(cfg.ConfigPath); err != nil {
+			return err
+		}
+		workspaceConfig = workspaceConfig.ForPlatform(cfg.Platform)
+	}
+	layers, err := config.EnvLayers(workspaceConfig, cfg.Profile, cfg.EnvFiles, cfg.cliEnv)
+	if err != nil {
+		return err
+	}
+	cfg.Env = config.MergeEnv(layers)
+	if workspaceConfig == nil {
+		return nil
+	}
+	cfg.Secrets = append(append([]string(nil), workspaceConfig.Secrets...), cfg.cliSecrets...)
+	if appID := workspaceAppID(workspaceConfig, cfg.Profile); appID != "" {
+		cfg.AppID = appID
+	}
+	return applyWorkspaceConfig(cfg, workspaceConfig)
+}
+
+// watchPaths returns the files to watch: flow dependencies, the flow paths
+// themselves (directories pick up added or removed flows), --config and the
+// env files.
+func watchPaths(cfg *RunConfig, files []string) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	add := func(p string) {
+		if p != "" && !seen[filepath.Clean(p)] {
+			seen[filepath.Clean(p)] = true
+			paths = append(paths, p)
+		}
+	}
+	for _, f := range files {
+		add(f)
+	}
+	for _, p := range cfg.FlowPaths {
+		add(p)
+	}
+	add(cfg.ConfigPath)
+	for _, p := range cfg.EnvFiles {
+		add(p)
+	}
+	return paths
+}
+
+func containsPath(paths []string, path string) bool {
+	for _, p := range paths {
+		if samePath(p, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func samePath(a, b string) bool {
+	return filepath.Clean(a) == filepath.Clean(b)
+}
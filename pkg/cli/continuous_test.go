@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+)
+
+func writeFlow(t *testing.T, path, content string) *continuousRun {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := flow.ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newContinuousRun([]flow.Flow{*f})
+}
+
+func TestResumeStep(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "login.yaml")
+	base := "- launchApp\n- tapOn: Login\n- inputText: hello\n- back\n"
+
+	prev := writeFlow(t, path, base)
+	if prev.stoppedAt != 4 {
+		t.Fatalf("stoppedAt = %d, want step count", prev.stoppedAt)
+	}
+
+	// Edit step 3 after a passing run
+	next := writeFlow(t, path, "- launchApp\n- tapOn: Login\n- inputText: world\n- back\n")
+	if got := resumeStep(prev, next, []string{path}); got != 2 {
+		t.Errorf("resumeStep = %d, want 2", got)
+	}
+
+	// The previous run stopped earlier than the edit: resume there
+	prev.stoppedAt = 1
+	if got := resumeStep(prev, next, []string{path}); got != 1 {
+		t.Errorf("resumeStep = %d, want 1 (failed step)", got)
+	}
+
+	// A dependency changed too: start over
+	if got := resumeStep(prev, next, []string{path, filepath.Join(dir, "sub.yaml")}); got != 0 {
+		t.Errorf("resumeStep = %d, want 0", got)
+	}
+
+	// Nothing left to run: start over
+	prev = writeFlow(t, path, base)
+	next = writeFlow(t, path, base+"# comment\n")
+	if got := resumeStep(prev, next, []string{path}); got != 0 {
+		t.Errorf("resumeStep = %d, want 0 for a no-op edit", got)
+	}
+}
+
+func TestReloadWorkspaceConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("appId: com.example\nenv:\n  USER: ws\n  PASS: ws\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &RunConfig{ConfigPath: path, Env: map[string]string{"STALE": "x"}, cliEnv: map[string]string{"PASS": "cli"}}
+
+	if err := reloadWorkspaceConfig(cfg); err != nil {
+		t.Fatalf("reloadWorkspaceConfig failed: %v", err)
+	}
+	if cfg.Env["USER"] != "ws" || cfg.Env["PASS"] != "cli" || cfg.Env["STALE"] != "" {
+		t.Errorf("Env = %v", cfg.Env)
+	}
+	if cfg.AppID != "com.example" {
+		t.Errorf("AppID = %q", cfg.AppID)
+	}
+}
+
+func TestReloadWorkspaceConfig_ProfileAndEnvFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	content := "appId: com.example\nenv:\n  USER: ws\nprofiles:\n  staging:\n    appId: com.example.staging\n    env:\n      USER: staging\n      URL: staging\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	envFile := filepath.Join(dir, ".env")
+	if err := os.WriteFile(envFile, []byte("URL=file\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &RunConfig{ConfigPath: path, Profile: "staging", EnvFiles: []string{envFile}}
+
+	if err := reloadWorkspaceConfig(cfg); err != nil {
+		t.Fatalf("reloadWorkspaceConfig failed: %v", err)
+	}
+	if cfg.Env["USER"] != "staging" || cfg.Env["URL"] != "file" {
+		t.Errorf("Env = %v", cfg.Env)
+	}
+	if cfg.AppID != "com.example.staging" {
+		t.Errorf("AppID = %q", cfg.AppID)
+	}
+	if !envSourceChanged(cfg, []string{envFile}) || envSourceChanged(cfg, []string{filepath.Join(dir, "flow.yaml")}) {
+		t.Error("envSourceChanged")
+	}
+}
+
+func TestWatchPaths(t *testing.T) {
+	cfg := &RunConfig{FlowPaths: []string{"flows", "flows/a.yaml"}, ConfigPath: "config.yaml"}
+	got := watchPaths(cfg, []string{"flows/a.yaml", "flows/./b.yaml", "flows/b.yaml"})
+	want := []string{"flows/a.yaml", "flows/./b.yaml", "flows", "config.yaml"}
+	if len(got) != len(want) {
+		t.Fatalf("watchPaths = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("watchPaths = %v, want %v", got, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/config"
+	"github.com/devicelab-dev/maestro-runner/pkg/executor"
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/devicelab-dev/maestro-runner/pkg/secrets"
+	"github.com/urfave/cli/v2"
+)
+
+var envCommand = &cli.Command{
+	Name:      "env",
+	Usage:     "Print the variables flows run with and where each comes from",
+	ArgsUsage: "[flow file]",
+	Description: `Resolve variables the same way 'test' does and print each one with its
+source. With a flow file, the flow's appId and env are applied too (each
+dataset row separately). Secret values are shown as ***.
+
+Sources, lowest precedence first:
+  1. system environment (ALL_CAPS names; shown with --system)
+  2. env in the workspace config (--config, or config.yaml next to the
+     flow), with the overrides of --platform or the config's platform
+  3. env of the workspace config profile (--profile)
+  4. env files (--env-file), later files overriding earlier ones
+  5. -e and --secret-env
+  6. the flow's appId (as APP_ID) and env
+
+Examples:
+  maestro-runner env --config config.yaml --profile staging
+  maestro-runner env --platform ios flows/login.yaml
+  maestro-runner env --env-file .env.staging -e USER=alice login.yaml
+  maestro-runner env --format json login.yaml`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "config",
+			Usage: "Path to workspace config.yaml",
+		},
+		&cli.StringFlag{
+			Name:    "platform",
+			Usage:   "Platform whose workspace config overrides apply (android, ios)",
+			EnvVars: []string{"MAESTRO_PLATFORM"},
+		},
+		&cli.StringFlag{
+			Name:    "profile",
+			Usage:   "Workspace config profile to apply",
+			EnvVars: []string{"MAESTRO_PROFILE"},
+		},
+		&cli.StringSliceFlag{
+			Name:  "env-file",
+			Usage: "Read environment variables from a dotenv file (KEY=VALUE lines)",
+		},
+		&cli.StringSliceFlag{
+			Name:    "env",
+			Aliases: []string{"e"},
+			Usage:   "Environment variables (KEY=VALUE)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "secret-env",
+			Usage: "Secret environment variables (KEY=VALUE)",
+		},
+		&cli.BoolFlag{
+			Name:  "system",
+			Usage: "Include system environment variables",
+		},
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "Output format: human or json",
+			Value: "human",
+		},
+	},
+	Action: runEnv,
+}
+
+// envVar is one variable in env output.
+type envVar struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	Secret bool   `json:"secret,omitempty"`
+}
+
+// envListing is the variables of one flow instance, or of the run when no
+// flow is given.
+type envListing struct {
+	Flow      string   `json:"flow,omitempty"`
+	Path      string   `json:"path,omitempty"`
+	Variables []envVar `json:"variables"`
+}
+
+func runEnv(c *cli.Context) error {
+	format, err := outputFormat(c)
+	if err != nil {
+		return err
+	}
+	if c.NArg() > 1 {
+		return cli.Exit("at most one flow file is allowed", exitUsage)
+	}
+
+	// The workspace config is found and resolved for the platform as in 'test'
+	platform := c.String("platform")
+	if !c.IsSet("platform") && c.Lineage()[1] != nil {
+		platform = c.Lineage()[1].String("platform") // Global --platform
+	}
+	workspaceConfig, _, _, err := loadWorkspaceConfig(c.String("config"), c.Args().Slice(), platform)
+	if err != nil {
+		return err
+	}
+	var secretNames []string
+	if workspaceConfig != nil {
+		secretNames = append(secretNames, workspaceConfig.Secrets...)
+	}
+
+	cliEnv := parseEnvVars(c.StringSlice("env"))
+	for k, v := range parseEnvVars(c.StringSlice("secret-env")) {
+		cliEnv[k] = v
+		secretNames = append(secretNames, k)
+	}
+
+	layers, err := config.EnvLayers(workspaceConfig, c.String("profile"), c.StringSlice("env-file"), cliEnv)
+	if err != nil {
+		return err
+	}
+	if c.Bool("system") {
+		layers = append([]config.EnvLayer{{Source: "system", Env: executor.SystemEnv()}}, layers...)
+	}
+
+	var listings []envListing
+	if c.NArg() == 0 {
+		listings = append(listings, envListing{Variables: envVars(layers, secretNames)})
+	} else {
+		path := c.Args().First()
+		f, err := flow.ParseFile(path)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+		instances, err := flow.Expand(f)
+		if err != nil {
+			return err
+		}
+		for _, inst := range instances {
+			flowEnv := make(map[string]string, len(inst.Config.Env)+1)
+			if inst.Config.AppID != "" {
+				flowEnv["APP_ID"] = inst.Config.AppID
+			}
+			for k, v := range inst.Config.Env {
+				flowEnv[k] = v
+			}
+			flowLayers := append(slices.Clone(layers), config.EnvLayer{Source: "flow", Env: flowEnv})
+			listings = append(listings, envListing{
+				Flow:      flowName(inst, path),
+				Path:      displayPath(path),
+				Variables: envVars(flowLayers, slices.Concat(secretNames, inst.Config.Secrets)),
+			})
+		}
+	}
+
+	if format == "json" {
+		return printJSON(listings)
+	}
+	printEnvListings(listings)
+	return nil
+}
+
+// envVars merges layers into variables sorted by name, each with the layer
+// it came from and secret values redacted.
+func envVars(layers []config.EnvLayer, secretNames []string) []envVar {
+	matcher := secrets.NewMatcher(slices.Concat(secretNames, secrets.DefaultPatterns)...)
+	byName := make(map[string]envVar)
+	for _, l := range layers {
+		for k, v := range l.Env {
+			byName[k] = envVar{Name: k, Value: v, Source: l.Source}
+		}
+	}
+
+	vars := make([]envVar, 0, len(byName))
+	for _, v := range byName {
+		if matcher.IsSecret(v.Name) {
+			v.Value, v.Secret = secrets.Placeholder, true
+		}
+		vars = append(vars, v)
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+	return vars
+}
+
+func printEnvListings(listings []envListing) {
+	for i, l := range listings {
+		if l.Flow != "" {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("%s%s%s  %s%s%s\n", color(colorBold), l.Flow, color(colorReset), color(colorGray), l.Path, color(colorReset))
+		}
+		if len(l.Variables) == 0 {
+			fmt.Println("  (no variables)")
+			continue
+		}
+		width := 0
+		for _, v := range l.Variables {
+			width = max(width, len(v.Name))
+		}
+		for _, v := range l.Variables {
+			fmt.Printf("  %-*s = %s  %s(%s)%s\n", width, v.Name, v.Value, color(colorGray), v.Source, color(colorReset))
+		}
+	}
+}
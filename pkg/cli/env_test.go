@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEnvCommand_FlowJSON(t *testing.T) {
+	dir := writeFlows(t, map[string]string{
+		"config.yaml": `env:
+  USER: ws
+  BASE_URL: https://example.com
+profiles:
+  staging:
+    env:
+      BASE_URL: https://staging.example.com
+`,
+		".env.staging": "USER=file\nDB_PASSWORD=hunter2\n",
+		"login.yaml": `appId: com.example
+env:
+  GREETING: hi
+secrets: [GREETING]
+---
+- launchApp
+`,
+	})
+
+	out, code := runSubcommand(t, envCommand,
+		"--config", filepath.Join(dir, "config.yaml"), "--profile", "staging",
+		"--env-file", filepath.Join(dir, ".env.staging"), "-e", "DEBUG=1", "--format", "json",
+		filepath.Join(dir, "login.yaml"))
+	if code != 0 {
+		t.Fatalf("exit code = %d, output:\n%s", code, out)
+	}
+	var listings []envListing
+	if err := json.Unmarshal([]byte(out), &listings); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(listings) != 1 || listings[0].Flow != "login" {
+		t.Fatalf("listings = %+v", listings)
+	}
+
+	envFile := "env file " + filepath.Join(dir, ".env.staging")
+	want := []envVar{
+		{Name: "APP_ID", Value: "com.example", Source: "flow"},
+		{Name: "BASE_URL", Value: "https://staging.example.com", Source: "profile staging"},
+		{Name: "DB_PASSWORD", Value: "***", Source: envFile, Secret: true},
+		{Name: "DEBUG", Value: "1", Source: "command line"},
+		{Name: "GREETING", Value: "***", Source: "flow", Secret: true},
+		{Name: "USER", Value: "file", Source: envFile},
+	}
+	if !reflect.DeepEqual(listings[0].Variables, want) {
+		t.Errorf("variables = %+v\nwant %+v", listings[0].Variables, want)
+	}
+}
+
+func TestEnvCommand_UnknownProfile(t *testing.T) {
+	dir := writeFlows(t, map[string]string{"config.yaml": "profiles:\n  staging: {}\n"})
+
+	out, code := runSubcommand(t, envCommand, "--config", filepath.Join(dir, "config.yaml"), "--profile", "prod")
+	if code == 0 || strings.Contains(out, "variables") {
+		t.Errorf("exit code = %d, output:\n%s", code, out)
+	}
+}
+
+func TestEnvCommand_FindsWorkspaceConfigForPlatform(t *testing.T) {
+	dir := writeFlows(t, map[string]string{
+		"config.yaml": `env:
+  USER: ws
+  HOST: default
+platforms:
+  ios:
+    env:
+      HOST: ios
+`,
+		"login.yaml": "appId: com.example\n---\n- launchApp\n",
+	})
+
+	out, code := runSubcommand(t, envCommand, "--platform", "ios", "--format", "json", filepath.Join(dir, "login.yaml"))
+	if code != 0 {
+		t.Fatalf("exit code = %d, output:\n%s", code, out)
+	}
+	var listings []envListing
+	if err := json.Unmarshal([]byte(out), &listings); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	got := map[string]string{}
+	for _, v := range listings[0].Variables {
+		got[v.Name] = v.Value
+	}
+	if got["USER"] != "ws" || got["HOST"] != "ios" {
+		t.Errorf("variables = %+v, want the workspace env with ios overrides", listings[0].Variables)
+	}
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/urfave/cli/v2"
+)
+
+var fmtCommand = &cli.Command{
+	Name:      "fmt",
+	Usage:     "Format flow files",
+	ArgsUsage: "<flow files or directories>...",
+	Description: `Rewrite flow files in canonical form: keys in a fixed order, shorthand
+forms where they mean the same thing (tapOn: Login instead of
+tapOn: {text: Login}), quotes only where needed and two-space indentation.
+Comments are kept. Directories are searched recursively for .yaml and .yml
+files; config.yaml is skipped.
+
+With --check, files are not changed; the command lists files that need
+formatting and exits 1 if there are any.
+
+Examples:
+  maestro-runner fmt flows/
+  maestro-runner fmt --check flows/`,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "check",
+			Usage: "List files that need formatting instead of rewriting them",
+		},
+	},
+	Action: runFmt,
+}
+
+func runFmt(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return cli.Exit("at least one flow file or directory is required", exitUsage)
+	}
+	check := c.Bool("check")
+
+	files, err := collectFlowFiles(c.Args().Slice())
+	if err != nil {
+		return err
+	}
+
+	changed, failed := 0, 0
+	for _, path := range files {
+		data, err := os.ReadFile(path) //#nosec G304 -- user-provided flow file
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+		formatted, err := flow.Format(data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  %s✗%s %s: %v\n", color(colorRed), color(colorReset), displayPath(path), err)
+			failed++
+			continue
+		}
+		if bytes.Equal(data, formatted) {
+			continue
+		}
+		changed++
+		if check {
+			fmt.Printf("  %s\n", displayPath(path))
+			continue
+		}
+		if err := os.WriteFile(path, formatted, 0o644); err != nil { //#nosec G306 -- flow files are not secret
+			return fmt.Errorf("write %s: %w", path, err)
+		}
+		fmt.Printf("  %s✓%s Formatted %s\n", color(colorGreen), color(colorReset), displayPath(path))
+	}
+
+	if check && changed > 0 {
+		fmt.Printf("\n  %d of %d file(s) need formatting; run 'maestro-runner fmt' to fix\n", changed, len(files))
+	} else if !check && changed == 0 && failed == 0 {
+		fmt.Printf("  %s✓%s %d file(s) already formatted\n", color(colorGreen), color(colorReset), len(files))
+	}
+	if failed > 0 || (check && changed > 0) {
+		return cli.Exit("", exitInvalid)
+	}
+	return nil
+}
+
+// collectFlowFiles expands directories to the .yaml and .yml files below
+// them, skipping hidden directories and workspace config files.
+func collectFlowFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, root)
+			continue
+		}
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != root && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if isLintable(path) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/inspector"
+	"github.com/devicelab-dev/maestro-runner/pkg/logger"
+	"github.com/urfave/cli/v2"
+)
+
+var inspectCommand = &cli.Command{
+	Name:  "inspect",
+	Usage: "Open an interactive element inspector for the connected device",
+	Description: `Connect to a device and serve a local web page showing a live screenshot
+with the element hierarchy overlaid.
+
+Click an element to get the most stable selector for it (id, then text,
+then relative to a nearby element). Type a selector to highlight the
+elements it matches, resolved with the same logic flow steps use.
+
+Examples:
+  maestro-runner inspect
+  maestro-runner --platform ios inspect --port 9000
+  maestro-runner --device emulator-5554 inspect`,
+	Flags: []cli.Flag{
+		&cli.IntFlag{
+			Name:  "port",
+			Value: 9999,
+			Usage: "Port to serve the inspector on",
+		},
+		&cli.StringFlag{
+			Name:  "host",
+			Value: "127.0.0.1",
+			Usage: "Interface to bind the inspector to",
+		},
+	},
+	Action: runInspect,
+}
+
+func runInspect(c *cli.Context) error {
+	// Global flags live in the parent context when run as a subcommand
+	getString := func(name string) string {
+		if c.IsSet(name) {
+			return c.String(name)
+		}
+		if c.Lineage()[1] != nil {
+			return c.Lineage()[1].String(name)
+		}
+		return c.String(name)
+	}
+
+	capsFile := getString("caps")
+	var caps map[string]interface{}
+	if capsFile != "" {
+		var err error
+		caps, err = loadCapabilities(capsFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	cfg := &RunConfig{
+		Platform:     getString("platform"),
+		Devices:      parseDevices(getString("device")),
+		Driver:       getString("driver"),
+		AppiumURL:    getString("appium-url"),
+		CapsFile:     capsFile,
+		Capabilities: caps,
+		TeamID:       getString("team-id"),
+	}
+
+	fmt.Println()
+	create := CreateDriver
+	if strings.ToLower(cfg.Driver) == "appium" {
+		create = createAppiumDriver
+	}
+	driver, cleanup, err := create(cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	insp, err := inspector.New(driver)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(c.String("host"), strconv.Itoa(c.Int("port")))
+	srv, err := inspector.Serve(insp, addr)
+	if err != nil {
+		return fmt.Errorf("failed to start inspector: %w", err)
+	}
+	defer srv.Close()
+
+	fmt.Printf("\n  %sInspector:%s http://%s\n", color(colorBold), color(colorReset), srv.Addr())
+	fmt.Println("  Press Ctrl+C to stop")
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+	sig := <-sigCh
+	logger.Info("Received signal %v, stopping inspector", sig)
+	fmt.Println()
+	return nil
+}
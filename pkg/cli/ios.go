@@ -12,6 +12,7 @@ import (
 	"github.com/danielpaulus/go-ios/ios/zipconduit"
 	"github.com/devicelab-dev/maestro-runner/pkg/core"
 	wdadriver "github.com/devicelab-dev/maestro-runner/pkg/driver/wda"
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
 	"github.com/devicelab-dev/maestro-runner/pkg/logger"
 )
 
@@ -118,6 +119,8 @@ func CreateIOSDriver(cfg *RunConfig) (core.Driver, func(), error) {
 	// 5. Create WDA client
 	printSetupSuccess(fmt.Sprintf("WDA port: %d", runner.Port()))
 	client := wdadriver.NewClient(runner.Port())
+	client.SetDeviceID(udid)
+	client.SetRecorder(cfg.recorder)
 
 	// 6. Get device info
 	deviceInfo, err := getIOSDeviceInfo(udid)
@@ -132,12 +135,19 @@ func CreateIOSDriver(cfg *RunConfig) (core.Driver, func(), error) {
 		appVersion = getIOSAppVersion(udid, cfg.AppID)
 	}
 
+	locale := ""
+	if isSimulator {
+		locale = getSimulatorLocale(udid)
+	}
+
 	platformInfo := &core.PlatformInfo{
 		Platform:    "ios",
 		OSVersion:   deviceInfo.OSVersion,
 		DeviceName:  deviceInfo.Name,
 		DeviceID:    udid,
 		IsSimulator: deviceInfo.IsSimulator,
+		FormFactor:  iosFormFactor(deviceInfo.Name),
+		Locale:      locale,
 		AppID:       cfg.AppID,
 		AppVersion:  appVersion,
 	}
@@ -445,3 +455,21 @@ func autoDetectIOSDevices(count int) ([]string, error) {
 
 	return devices, nil
 }
+
+// getSimulatorLocale returns the locale of a simulator, e.g. "en_US" ("" if
+// it can't be read).
+func getSimulatorLocale(udid string) string {
+	out, err := runCommand("xcrun", "simctl", "spawn", udid, "defaults", "read", "-g", "AppleLocale")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// iosFormFactor returns the form factor of an iOS device from its name.
+func iosFormFactor(name string) string {
+	if strings.Contains(name, "iPad") {
+		return flow.FormFactorTablet
+	}
+	return flow.FormFactorPhone
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/config"
+	"github.com/devicelab-dev/maestro-runner/pkg/lint"
+	"github.com/urfave/cli/v2"
+)
+
+var lintCommand = &cli.Command{
+	Name:      "lint",
+	Usage:     "Check flows against style rules",
+	ArgsUsage: "<flow files or directories>...",
+	Description: `Check flows and the subflows they use against style rules. Flows must
+be valid first; validation errors are reported and nothing is linted.
+
+Rules:
+` + ruleList() + `
+Turn rules off with --disable or in the workspace config.yaml:
+  lint:
+    disable: [require-tags]
+    longFlowSteps: 25
+
+Exit codes: 0 when there are no findings, 1 when there are findings or
+validation errors, 2 for bad arguments.
+
+Examples:
+  maestro-runner lint flows/
+  maestro-runner lint flows/ --disable no-tap-on-point --format json`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "config",
+			Usage: "Path to workspace config.yaml (default: config.yaml in the flow directory)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "disable",
+			Usage: "Rules to turn off, in addition to the config's lint.disable",
+		},
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "Output format: human or json",
+			Value: "human",
+		},
+	},
+	Action: runLint,
+}
+
+func ruleList() string {
+	var sb strings.Builder
+	for _, r := range lint.Rules {
+		fmt.Fprintf(&sb, "  %-22s %s\n", r.Name, r.Description)
+	}
+	return sb.String()
+}
+
+func runLint(c *cli.Context) error {
+	format, err := outputFormat(c)
+	if err != nil {
+		return err
+	}
+	if c.NArg() == 0 {
+		return cli.Exit("at least one flow file or directory is required", exitUsage)
+	}
+	paths := c.Args().Slice()
+
+	lintCfg, err := loadLintConfig(c.String("config"), paths[0])
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	lintCfg.Disable = append(lintCfg.Disable, c.StringSlice("disable")...)
+	linter, err := lint.New(lintCfg)
+	if err != nil {
+		return cli.Exit(err.Error(), exitUsage)
+	}
+
+	result := validatePaths(paths, nil, nil, false)
+	if !result.IsValid() {
+		fmt.Fprintln(os.Stderr, "Validation errors:")
+		for _, e := range result.Errors {
+			fmt.Fprintf(os.Stderr, "  - %s\n", displayError(e))
+		}
+		return cli.Exit("", exitInvalid)
+	}
+
+	testCases := make(map[string]bool, len(result.TestCases))
+	for _, path := range result.TestCases {
+		testCases[path] = true
+	}
+	findings := []lint.Finding{}
+	linted := 0
+	for _, path := range result.Files {
+		if !isLintable(path) {
+			continue
+		}
+		fileFindings, err := linter.LintFile(path, !testCases[path])
+		if err != nil {
+			return fmt.Errorf("lint %s: %w", path, err)
+		}
+		for i := range fileFindings {
+			fileFindings[i].File = displayPath(fileFindings[i].File)
+		}
+		findings = append(findings, fileFindings...)
+		linted++
+	}
+
+	if format == "json" {
+		if err := printJSON(findings); err != nil {
+			return err
+		}
+	} else {
+		for _, f := range findings {
+			fmt.Printf("  %s⚠%s %s\n", color(colorYellow), color(colorReset), f)
+		}
+		if len(findings) == 0 {
+			fmt.Printf("  %s✓%s No findings in %d file(s)\n", color(colorGreen), color(colorReset), linted)
+		} else {
+			fmt.Printf("\n  %s%d finding(s)%s in %d file(s)\n", color(colorYellow), len(findings), color(colorReset), linted)
+		}
+	}
+
+	if len(findings) > 0 {
+		return cli.Exit("", exitInvalid)
+	}
+	return nil
+}
+
+// loadLintConfig reads lint settings from configPath, or from config.yaml
+// in the directory of the first flow path.
+func loadLintConfig(configPath, firstPath string) (lint.Config, error) {
+	var cfg *config.Config
+	var err error
+	if configPath != "" {
+		cfg, err = config.Load(configPath)
+	} else {
+		dir := firstPath
+		if info, statErr := os.Stat(firstPath); statErr != nil || !info.IsDir() {
+			dir = filepath.Dir(firstPath)
+		}
+		cfg, err = config.LoadFromDir(dir)
+	}
+	if err != nil {
+		return lint.Config{}, err
+	}
+	return lint.Config{
+		Disable:       append([]string(nil), cfg.Lint.Disable...),
+		LongFlowSteps: cfg.Lint.LongFlowSteps,
+	}, nil
+}
+
+// isLintable reports whether path is a flow file rather than a script or
+// the workspace config.
+func isLintable(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	if name == "config.yaml" || name == "config.yml" {
+		return false
+	}
+	ext := filepath.Ext(name)
+	return ext == ".yaml" || ext == ".yml"
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/lint"
+)
+
+func TestLintCommand(t *testing.T) {
+	dir := writeFlows(t, map[string]string{
+		"flows/main.yaml":    "appId: com.example\n---\n- tapOnPoint: {x: 1, y: 2}\n- runFlow: sub/sub.yaml\n",
+		"flows/sub/sub.yaml": "appId: com.example\n---\n- assertVisible: {text: Hi, optional: true}\n",
+		"flows/config.yaml":  "lint:\n  disable: [no-tap-on-point]\n",
+	})
+
+	out, code := runSubcommand(t, lintCommand, "--format", "json", filepath.Join(dir, "flows"))
+	if code != exitInvalid {
+		t.Errorf("exit code = %d, want %d", code, exitInvalid)
+	}
+	var findings []lint.Finding
+	if err := json.Unmarshal([]byte(out), &findings); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	// Config disables no-tap-on-point; sub.yaml is a subflow, so it needs no tags
+	if len(findings) != 2 {
+		t.Fatalf("unexpected findings: %+v", findings)
+	}
+	if findings[0].Rule != "require-tags" || !strings.HasSuffix(findings[0].File, "main.yaml") {
+		t.Errorf("finding 0 = %+v", findings[0])
+	}
+	if findings[1].Rule != "no-optional-assertion" || !strings.HasSuffix(findings[1].File, "sub.yaml") || findings[1].Line != 3 {
+		t.Errorf("finding 1 = %+v", findings[1])
+	}
+
+	_, code = runSubcommand(t, lintCommand, "--disable", "require-tags", "--disable", "no-optional-assertion", filepath.Join(dir, "flows"))
+	if code != 0 {
+		t.Errorf("exit code with rules disabled = %d, want 0", code)
+	}
+
+	if _, code := runSubcommand(t, lintCommand, "--disable", "bogus", filepath.Join(dir, "flows")); code != exitUsage {
+		t.Errorf("unknown rule: exit code = %d, want %d", code, exitUsage)
+	}
+}
+
+func TestFmtCommand(t *testing.T) {
+	dir := writeFlows(t, map[string]string{
+		"flows/a.yaml":        "- tapOn: {text: \"Login\"}\n",
+		"flows/b.yaml":        "- back\n",
+		"flows/config.yaml":   "flows: ['*']\n",
+		"flows/.hidden/c.yml": "- tapOn: {text: x}\n",
+	})
+	flows := filepath.Join(dir, "flows")
+
+	out, code := runSubcommand(t, fmtCommand, "--check", flows)
+	if code != exitInvalid {
+		t.Errorf("--check exit code = %d, want %d", code, exitInvalid)
+	}
+	if !strings.Contains(out, "a.yaml") || strings.Contains(out, "b.yaml") || strings.Contains(out, "c.yml") {
+		t.Errorf("--check output:\n%s", out)
+	}
+
+	if _, code := runSubcommand(t, fmtCommand, flows); code != 0 {
+		t.Errorf("fmt exit code = %d", code)
+	}
+	data, err := os.ReadFile(filepath.Join(flows, "a.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "- tapOn: Login\n" {
+		t.Errorf("a.yaml = %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(flows, "config.yaml")); string(data) != "flows: ['*']\n" {
+		t.Errorf("config.yaml was changed: %q", data)
+	}
+
+	if _, code := runSubcommand(t, fmtCommand, "--check", flows); code != 0 {
+		t.Errorf("--check after fmt exit code = %d, want 0", code)
+	}
+}
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/urfave/cli/v2"
+)
+
+var listCommand = &cli.Command{
+	Name:      "list",
+	Usage:     "List the flows that would run, with their runFlow dependencies",
+	ArgsUsage: "<flow files or directories>...",
+	Description: `Resolve flows the same way 'test' does and print each one with its name,
+appId, tags, step count and the tree of runFlow/retry files it uses.
+Tag filters are applied and datasets expanded, so this shows exactly what
+a filter selects. No device is needed.
+
+Examples:
+  maestro-runner list flows/
+  maestro-runner list flows/ --include-tags smoke --exclude-tags slow
+  maestro-runner list flows/ --format json`,
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "include-tags",
+			Usage: "Only include flows with these tags",
+		},
+		&cli.StringSliceFlag{
+			Name:  "exclude-tags",
+			Usage: "Exclude flows with these tags",
+		},
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "Output format: human or json",
+			Value: "human",
+		},
+	},
+	Action: runList,
+}
+
+// listedFlow is one flow in list output.
+type listedFlow struct {
+	Name         string          `json:"name"`
+	Path         string          `json:"path"`
+	AppID        string          `json:"appId,omitempty"`
+	Tags         []string        `json:"tags"`
+	Steps        int             `json:"steps"`
+	Dependencies []*listedDepend `json:"dependencies"`
+}
+
+// listedDepend is a node in a flow's runFlow dependency tree.
+type listedDepend struct {
+	Path         string          `json:"path"`
+	Cycle        bool            `json:"cycle,omitempty"`
+	Dependencies []*listedDepend `json:"dependencies,omitempty"`
+}
+
+func runList(c *cli.Context) error {
+	format, err := outputFormat(c)
+	if err != nil {
+		return err
+	}
+	if c.NArg() == 0 {
+		return cli.Exit("at least one flow file or directory is required", exitUsage)
+	}
+
+	result := validatePaths(c.Args().Slice(), c.StringSlice("include-tags"), c.StringSlice("exclude-tags"), false)
+	if !result.IsValid() {
+		fmt.Fprintln(os.Stderr, "Validation errors:")
+		for _, e := range result.Errors {
+			fmt.Fprintf(os.Stderr, "  - %s\n", displayError(e))
+		}
+		return cli.Exit("", exitInvalid)
+	}
+
+	flows := make([]listedFlow, 0, len(result.TestCases))
+	for _, path := range result.TestCases {
+		f, err := flow.ParseFile(path)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+		instances, err := flow.Expand(f)
+		if err != nil {
+			return err
+		}
+		for _, inst := range instances {
+			flows = append(flows, listedFlow{
+				Name:         flowName(inst, path),
+				Path:         displayPath(path),
+				AppID:        inst.Config.AppID,
+				Tags:         nonNil(inst.Config.Tags),
+				Steps:        len(inst.Steps),
+				Dependencies: dependencyTree(result.Dependencies, path, []string{path}),
+			})
+		}
+	}
+
+	if format == "json" {
+		return printJSON(flows)
+	}
+	printFlowList(flows)
+	return nil
+}
+
+// flowName returns the flow's configured name, or its file name without
+// extension.
+func flowName(f *flow.Flow, path string) string {
+	if f.Config.Name != "" {
+		return f.Config.Name
+	}
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// dependencyTree builds the runFlow tree below path. chain holds the files
+// on the current branch so circular references stop instead of recursing.
+func dependencyTree(deps map[string][]string, path string, chain []string) []*listedDepend {
+	var nodes []*listedDepend
+	for _, dep := range deps[path] {
+		node := &listedDepend{Path: displayPath(dep)}
+		if containsString(chain, dep) {
+			node.Cycle = true
+		} else {
+			node.Dependencies = dependencyTree(deps, dep, append(chain, dep))
+		}
+		nodes = append(nodes, node)
+	}
+	if nodes == nil {
+		return []*listedDepend{}
+	}
+	return nodes
+}
+
+func printFlowList(flows []listedFlow) {
+	for _, f := range flows {
+		fmt.Printf("%s%s%s  %s%s%s\n", color(colorBold), f.Name, color(colorReset), color(colorGray), f.Path, color(colorReset))
+		if f.AppID != "" {
+			fmt.Printf("  appId: %s\n", f.AppID)
+		}
+		if len(f.Tags) > 0 {
+			fmt.Printf("  tags:  %s\n", strings.Join(f.Tags, ", "))
+		}
+		fmt.Printf("  steps: %d\n", f.Steps)
+		printDependencies(f.Dependencies, "  ")
+		fmt.Println()
+	}
+	fmt.Printf("%d flow(s)\n", len(flows))
+}
+
+func printDependencies(nodes []*listedDepend, indent string) {
+	for i, node := range nodes {
+		branch, next := "├─ ", "│  "
+		if i == len(nodes)-1 {
+			branch, next = "└─ ", "   "
+		}
+		suffix := ""
+		if node.Cycle {
+			suffix = fmt.Sprintf(" %s(cycle)%s", color(colorYellow), color(colorReset))
+		}
+		fmt.Printf("%s%s%s%s\n", indent, branch, node.Path, suffix)
+		printDependencies(node.Dependencies, indent+next)
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
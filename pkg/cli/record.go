@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/core"
+	"github.com/devicelab-dev/maestro-runner/pkg/device"
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/devicelab-dev/maestro-runner/pkg/logger"
+	"github.com/devicelab-dev/maestro-runner/pkg/recorder"
+	"github.com/urfave/cli/v2"
+)
+
+var recordCommand = &cli.Command{
+	Name:  "record",
+	Usage: "Record interactions on an Android device as a flow",
+	Description: `Capture taps, long presses, swipes, text entry and the back key on a
+connected Android device or emulator and write them as a flow file.
+
+Touches are read with getevent and mapped back to elements through the UI
+hierarchy; each step gets the most stable selector that resolves to the
+element (id, then text, then relative), or a screen point if none does.
+Text typed on the soft keyboard is recorded as inputText.
+
+Interact with the device normally, then press Ctrl+C to stop and save.
+The device should stay in portrait orientation while recording.
+
+Examples:
+  maestro-runner record -o flow.yaml
+  maestro-runner --device emulator-5554 record -o login.yaml --app-id com.example.app`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "Flow file to write (default: print to stdout)",
+		},
+		&cli.StringFlag{
+			Name:  "app-id",
+			Usage: "appId for the flow header (default: the app in the foreground)",
+		},
+	},
+	Action: runRecord,
+}
+
+func runRecord(c *cli.Context) error {
+	// Global flags live in the parent context when run as a subcommand
+	getString := func(name string) string {
+		if c.IsSet(name) {
+			return c.String(name)
+		}
+		if c.Lineage()[1] != nil {
+			return c.Lineage()[1].String(name)
+		}
+		return c.String(name)
+	}
+
+	if platform := strings.ToLower(getString("platform")); platform != "" && platform != "android" {
+		return fmt.Errorf("record supports Android only (got --platform %s)", platform)
+	}
+
+	cfg := &RunConfig{
+		Platform: "android",
+		Devices:  parseDevices(getString("device")),
+		Driver:   "uiautomator2",
+	}
+
+	fmt.Println()
+	driver, cleanup, err := CreateAndroidDriver(cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	insp, ok := driver.(core.Inspector)
+	if !ok {
+		return fmt.Errorf("driver %T does not support inspect", driver)
+	}
+	dev, err := device.New(driver.GetPlatformInfo().DeviceID)
+	if err != nil {
+		return fmt.Errorf("connect to device: %w", err)
+	}
+
+	out, err := dev.Shell("wm size")
+	if err != nil {
+		return fmt.Errorf("get screen size: %w", err)
+	}
+	width, height, err := recorder.ParseScreenSize(out)
+	if err != nil {
+		return err
+	}
+
+	out, err = dev.Shell("getevent -lp")
+	if err != nil {
+		return fmt.Errorf("list input devices: %w", err)
+	}
+	inputs := recorder.ParseInputDevices(out)
+	if len(inputs) == 0 {
+		return fmt.Errorf("no touchscreen input device found on %s", dev.Serial())
+	}
+
+	appID := c.String("app-id")
+	if appID == "" {
+		if out, err := dev.Shell("dumpsys window"); err == nil {
+			appID = recorder.ParseFocusedPackage(out)
+		}
+	}
+
+	rec := recorder.New(recorder.NewAndroidDevice(insp, dev.Shell, width, height), recorder.Options{
+		ScreenWidth:  width,
+		ScreenHeight: height,
+		OnStep: func(step flow.Step) {
+			fmt.Printf("  %s●%s %s\n", color(colorCyan), color(colorReset), step.Describe())
+		},
+	})
+	if err := rec.Start(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := dev.ShellStream(ctx, "getevent -lt")
+	if err != nil {
+		return fmt.Errorf("read input events: %w", err)
+	}
+	defer stream.Close()
+
+	events := make(chan recorder.Event, 256)
+	go func() {
+		defer close(events)
+		parser := recorder.NewEventParser(inputs, width, height)
+		scanner := bufio.NewScanner(stream)
+		for scanner.Scan() {
+			if ev, ok := parser.Feed(scanner.Text()); ok {
+				events <- ev
+			}
+		}
+	}()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	fmt.Printf("\n  %sRecording%s %s — press Ctrl+C to stop\n\n", color(colorBold), color(colorReset), appOrDevice(appID, dev.Serial()))
+
+recording:
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				fmt.Printf("  %s⚠%s Warning: input event stream ended\n", color(colorYellow), color(colorReset))
+				break recording
+			}
+			if err := rec.Handle(ev); err != nil {
+				logger.Warn("record: %v", err)
+				fmt.Printf("  %s⚠%s Warning: %v\n", color(colorYellow), color(colorReset), err)
+			}
+		case <-sigCh:
+			break recording
+		}
+	}
+
+	steps := rec.Finish()
+	data, err := recorder.Encode(appID, steps)
+	if err != nil {
+		return fmt.Errorf("encode flow: %w", err)
+	}
+
+	output := c.String("output")
+	if output == "" {
+		fmt.Println()
+		fmt.Print(string(data))
+		return nil
+	}
+	if err := os.WriteFile(output, data, 0o644); err != nil {
+		return fmt.Errorf("write flow: %w", err)
+	}
+	fmt.Printf("\n  %s✓%s Recorded %d steps to %s\n", color(colorGreen), color(colorReset), len(steps), output)
+	return nil
+}
+
+func appOrDevice(appID, serial string) string {
+	if appID != "" {
+		return appID
+	}
+	return serial
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/urfave/cli/v2"
+)
+
+var schemaCommand = &cli.Command{
+	Name:  "schema",
+	Usage: "Print a JSON Schema for flow files",
+	Description: `Print a JSON Schema describing flow files, generated from the step
+definitions this runner parses. Point your editor at it for autocomplete
+and inline errors.
+
+With the VS Code YAML extension, add to settings.json:
+  "yaml.schemas": { "./flow-schema.json": ["flows/**/*.yaml"] }
+
+Examples:
+  maestro-runner schema -o flow-schema.json`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "File to write (default: print to stdout)",
+		},
+	},
+	Action: runSchema,
+}
+
+func runSchema(c *cli.Context) error {
+	data, err := flow.JSONSchema()
+	if err != nil {
+		return fmt.Errorf("generate schema: %w", err)
+	}
+	data = append(data, '\n')
+
+	output := c.String("output")
+	if output == "" {
+		fmt.Print(string(data))
+		return nil
+	}
+	if err := os.WriteFile(output, data, 0o644); err != nil {
+		return fmt.Errorf("write schema: %w", err)
+	}
+	fmt.Printf("  %s✓%s Wrote flow schema to %s\n", color(colorGreen), color(colorReset), output)
+	return nil
+}
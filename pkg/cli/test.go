@@ -10,22 +10,27 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/devicelab-dev/maestro-runner/pkg/config"
 	"github.com/devicelab-dev/maestro-runner/pkg/core"
+	"github.com/devicelab-dev/maestro-runner/pkg/debugger"
 	"github.com/devicelab-dev/maestro-runner/pkg/device"
 	appiumdriver "github.com/devicelab-dev/maestro-runner/pkg/driver/appium"
 	"github.com/devicelab-dev/maestro-runner/pkg/driver/mock"
 	wdadriver "github.com/devicelab-dev/maestro-runner/pkg/driver/wda"
 	"github.com/devicelab-dev/maestro-runner/pkg/emulator"
 	"github.com/devicelab-dev/maestro-runner/pkg/executor"
+	"github.com/devicelab-dev/maestro-runner/pkg/faker"
 	"github.com/devicelab-dev/maestro-runner/pkg/flow"
 	"github.com/devicelab-dev/maestro-runner/pkg/logger"
+	"github.com/devicelab-dev/maestro-runner/pkg/metrics"
 	"github.com/devicelab-dev/maestro-runner/pkg/report"
 	"github.com/devicelab-dev/maestro-runner/pkg/simulator"
+	"github.com/devicelab-dev/maestro-runner/pkg/telemetry"
 	"github.com/devicelab-dev/maestro-runner/pkg/validator"
 	"github.com/urfave/cli/v2"
 )
@@ -50,6 +55,9 @@ Examples:
   # With environment variables
   maestro-runner test flows/ -e USER=test -e PASS=secret
 
+  # With an env file and a workspace config profile
+  maestro-runner test --config config.yaml --profile staging --env-file .env.staging flows/
+
   # With tag filtering
   maestro-runner test flows/ --include-tags smoke
 
@@ -60,7 +68,16 @@ Examples:
   maestro-runner --driver appium --appium-url "https://hub.provider.com/wd/hub" --caps cloud.json test flow.yaml
 
   # Custom output directory
-  maestro-runner test flows/ --output ./my-reports --flatten`,
+  maestro-runner test flows/ --output ./my-reports --flatten
+
+  # Rerun on save, resuming at the first edited step
+  maestro-runner test login.yaml --continuous --from-changed
+
+  # Step through a flow interactively
+  maestro-runner test login.yaml --debug
+
+  # Fail on typos such as unknown keys before touching a device
+  maestro-runner test flows/ --strict`,
 	Flags: []cli.Flag{
 		// Configuration
 		&cli.StringFlag{
@@ -74,6 +91,19 @@ Examples:
 			Aliases: []string{"e"},
 			Usage:   "Environment variables (KEY=VALUE)",
 		},
+		&cli.StringSliceFlag{
+			Name:  "secret-env",
+			Usage: "Secret environment variables (KEY=VALUE), redacted from reports, logs and console output",
+		},
+		&cli.StringSliceFlag{
+			Name:  "env-file",
+			Usage: "Read environment variables from a dotenv file (KEY=VALUE lines); later files override earlier ones",
+		},
+		&cli.StringFlag{
+			Name:    "profile",
+			Usage:   "Workspace config profile to apply (its env and appId)",
+			EnvVars: []string{"MAESTRO_PROFILE"},
+		},
 
 		// Tag filtering
 		&cli.StringSliceFlag{
@@ -84,6 +114,10 @@ Examples:
 			Name:  "exclude-tags",
 			Usage: "Exclude flows with these tags",
 		},
+		&cli.BoolFlag{
+			Name:  "strict",
+			Usage: "Reject flows with unknown keys, wrong value types or conflicting options",
+		},
 
 		// Output directory
 		&cli.StringFlag{
@@ -105,7 +139,20 @@ Examples:
 		&cli.BoolFlag{
 			Name:    "continuous",
 			Aliases: []string{"c"},
-			Usage:   "Enable continuous mode for single flow",
+			Usage:   "Watch flows, their dependencies and the workspace config, and rerun on save",
+		},
+		&cli.BoolFlag{
+			Name:  "from-changed",
+			Usage: "With --continuous, rerun from the first changed step instead of the start",
+		},
+		&cli.BoolFlag{
+			Name:  "debug",
+			Usage: "Pause before each step and on failure for interactive debugging (single device)",
+		},
+		&cli.Int64Flag{
+			Name:    "seed",
+			Usage:   "Seed for generated data (inputRandom, faker.* in scripts); a run's seed is in report.json (default: random)",
+			EnvVars: []string{"MAESTRO_SEED"},
 		},
 
 		// Web options
@@ -122,6 +169,27 @@ Examples:
 			EnvVars: []string{"MAESTRO_WAIT_FOR_IDLE_TIMEOUT"},
 		},
 
+		// Telemetry
+		&cli.StringFlag{
+			Name:    "otlp-endpoint",
+			Usage:   "Export run trace to an OTLP/HTTP collector (e.g. http://localhost:4318)",
+			EnvVars: []string{"OTEL_EXPORTER_OTLP_ENDPOINT"},
+		},
+		&cli.StringFlag{
+			Name:    "otlp-headers",
+			Usage:   "Headers for the OTLP collector (key=value,key2=value2)",
+			EnvVars: []string{"OTEL_EXPORTER_OTLP_HEADERS"},
+		},
+		&cli.StringFlag{
+			Name:  "otlp-file",
+			Usage: "Write run trace as OTLP-JSON to this file",
+		},
+		&cli.StringFlag{
+			Name:    "metrics-addr",
+			Usage:   "Serve Prometheus metrics on this address while tests run (e.g. :9464)",
+			EnvVars: []string{"MAESTRO_METRICS_ADDR"},
+		},
+
 		// Emulator management flags (start-emulator, auto-start-emulator,
 		// shutdown-after, boot-timeout) are global flags defined in cli.go.
 
@@ -389,12 +457,18 @@ type RunConfig struct {
 	ConfigPath string
 
 	// Environment
-	Env map[string]string
+	Env      map[string]string
+	Secrets  []string // Variable names or patterns whose values are redacted from output
+	Profile  string   // Workspace config profile (--profile)
+	EnvFiles []string // Dotenv files (--env-file), lowest precedence first
 
 	// Filtering
 	IncludeTags []string
 	ExcludeTags []string
 
+	// Validation
+	Strict bool // Schema-check flows before running (see flow.Check)
+
 	// Output
 	OutputDir string // Final resolved output directory
 
@@ -402,8 +476,11 @@ type RunConfig struct {
 	Parallel int // Number of devices to use (0 = single device mode)
 
 	// Execution
-	Continuous bool
-	Headless   bool
+	Continuous  bool
+	FromChanged bool // Continuous mode: resume at the first changed step
+	Headless    bool
+	Debug       bool  // Step through flows interactively (sequential, single device)
+	Seed        int64 // Seed for generated data, recorded in report.json
 
 	// Device
 	Platform string
@@ -422,12 +499,44 @@ type RunConfig struct {
 	WaitForIdleTimeout int    // Wait for device idle in ms (0 = disabled, default 200)
 	TeamID             string // Apple Development Team ID for WDA code signing
 
+	// Scripts
+	ModulePaths   []string // Directories require() searches for bare module names
+	ScriptTimeout int      // Time limit for each script in ms (0 = default)
+
+	// Workspace config run settings (see config.Config)
+	CommandTimeout     int                   // Default command timeout in ms (0 = driver default)
+	Retries            int                   // Times a failed flow is rerun
+	Artifacts          executor.ArtifactMode // When to capture screenshots and hierarchy
+	OrderedFlows       int                   // Leading flows that are in executionOrder
+	StopOrderOnFailure bool                  // executionOrder.continueOnFailure: false
+	BeforeSuite        *flow.Flow            // Runs once per device before its flows
+	AfterSuite         *flow.Flow            // Runs once per device after its flows
+	DeviceGroups       map[string][]string   // Device groups for flows that require one
+
 	// Emulator/Simulator management
 	StartEmulator     string // AVD name to start (e.g., Pixel_7_API_33)
 	StartSimulator    string // iOS simulator name/UDID to start (e.g., "iPhone 15 Pro")
 	AutoStartEmulator bool   // Auto-start an emulator/simulator if no devices found
 	ShutdownAfter     bool   // Shutdown emulators/simulators started by maestro-runner after tests
 	BootTimeout       int    // Device boot timeout in seconds
+
+	// Telemetry
+	Telemetry   telemetry.Config // OTLP trace export (disabled if no destination)
+	MetricsAddr string           // Prometheus metrics listen address (empty = disabled)
+
+	cliEnv     map[string]string   // -e and --secret-env values, re-merged when the workspace config changes
+	cliSecrets []string            // --secret-env names, re-merged likewise
+	workspace  *config.Config      // Workspace config, with platform overrides applied (nil if none)
+	recorder   *telemetry.Recorder // Driver HTTP calls of the current run, for trace export (nil if not exporting)
+}
+
+// flowRoot is the directory flows are identified relative to when seeding
+// their generated data: the workspace config's, or the working directory.
+func (cfg *RunConfig) flowRoot() string {
+	if cfg.ConfigPath == "" {
+		return ""
+	}
+	return filepath.Dir(cfg.ConfigPath)
 }
 
 func printBanner() {
@@ -521,6 +630,12 @@ func runTest(c *cli.Context) error {
 
 	// Parse environment variables
 	env := parseEnvVars(getStringSlice("env"))
+	secretEnv := parseEnvVars(getStringSlice("secret-env"))
+	cliSecrets := make([]string, 0, len(secretEnv))
+	for k, v := range secretEnv {
+		env[k] = v
+		cliSecrets = append(cliSecrets, k)
+	}
 
 	// Resolve output directory
 	outputDir, err := resolveOutputDir(getString("output"), getBool("flatten"))
@@ -539,46 +654,52 @@ func runTest(c *cli.Context) error {
 		}
 	}
 
-	// Load workspace config if provided
-	var workspaceConfig *config.Config
-	configPath := getString("config")
-	if configPath != "" {
-		var err error
-		workspaceConfig, err = config.Load(configPath)
-		if err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
-		}
+	// Load workspace config: --config, or config.yaml next to the flows.
+	// Platform: --platform, else the workspace config's. Its overrides apply
+	// when it is known here (not when it is detected from the device).
+	workspaceConfig, configPath, platform, err := loadWorkspaceConfig(getString("config"), c.Args().Slice(), getString("platform"))
+	if err != nil {
+		return err
 	}
 
-	// Merge env variables: workspace config env + CLI env (CLI takes precedence)
-	mergedEnv := make(map[string]string)
-	if workspaceConfig != nil {
-		for k, v := range workspaceConfig.Env {
-			mergedEnv[k] = v
-		}
+	// Resolve env: workspace config < profile < env files < CLI
+	profile := getString("profile")
+	envFiles := getStringSlice("env-file")
+	envLayers, err := config.EnvLayers(workspaceConfig, profile, envFiles, env)
+	if err != nil {
+		return err
 	}
-	for k, v := range env {
-		mergedEnv[k] = v // CLI overrides workspace config
+	mergedEnv := config.MergeEnv(envLayers)
+
+	// Secret names: workspace config + --secret-env
+	var secretNames []string
+	if workspaceConfig != nil {
+		secretNames = append(secretNames, workspaceConfig.Secrets...)
 	}
+	secretNames = append(secretNames, cliSecrets...)
 
 	// Get appId from workspace config or will be extracted from flows later
-	appID := ""
-	if workspaceConfig != nil && workspaceConfig.AppID != "" {
-		appID = workspaceConfig.AppID
-	}
+	appID := workspaceAppID(workspaceConfig, profile)
 
 	// Build run configuration
 	cfg := &RunConfig{
 		FlowPaths:          c.Args().Slice(),
 		ConfigPath:         configPath,
 		Env:                mergedEnv,
+		Secrets:            secretNames,
+		Profile:            profile,
+		EnvFiles:           envFiles,
 		IncludeTags:        getStringSlice("include-tags"),
 		ExcludeTags:        getStringSlice("exclude-tags"),
+		Strict:             getBool("strict"),
 		OutputDir:          outputDir,
 		Parallel:           getInt("parallel"),
 		Continuous:         getBool("continuous"),
+		FromChanged:        getBool("from-changed"),
+		Debug:              getBool("debug"),
+		Seed:               faker.NewSeed(),
 		Headless:           getBool("headless"),
-		Platform:           getString("platform"),
+		Platform:           platform,
 		Devices:            parseDevices(getString("device")),
 		Verbose:            getBool("verbose"),
 		AppFile:            getString("app-file"),
@@ -594,6 +715,31 @@ func runTest(c *cli.Context) error {
 		AutoStartEmulator:  getBool("auto-start-emulator"),
 		ShutdownAfter:      getBool("shutdown-after"),
 		BootTimeout:        getInt("boot-timeout"),
+		Telemetry: telemetry.Config{
+			Endpoint: getString("otlp-endpoint"),
+			Headers:  telemetry.ParseHeaders(getString("otlp-headers")),
+			File:     getString("otlp-file"),
+		},
+		MetricsAddr: getString("metrics-addr"),
+		cliEnv:      env,
+		cliSecrets:  cliSecrets,
+	}
+
+	if c.IsSet("seed") {
+		cfg.Seed = c.Int64("seed")
+	}
+
+	if workspaceConfig != nil {
+		if err := applyWorkspaceConfig(cfg, workspaceConfig); err != nil {
+			return fmt.Errorf("invalid config %s: %w", configPath, err)
+		}
+	}
+
+	if cfg.FromChanged && !cfg.Continuous {
+		return fmt.Errorf("--from-changed requires --continuous")
+	}
+	if cfg.Debug && (cfg.Parallel > 0 || len(cfg.Devices) > 1) {
+		return fmt.Errorf("--debug runs on a single device and cannot be combined with --parallel or multiple devices")
 	}
 
 	// Apply waitForIdleTimeout with priority:
@@ -679,8 +825,16 @@ func executeTest(cfg *RunConfig) error {
 	// Handle SIGINT/SIGTERM to clean up on Ctrl+C or kill
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	// In continuous mode the first signal ends the watch loop; a second one exits
+	runCtx, stopRun := context.WithCancel(context.Background())
+	defer stopRun()
 	go func() {
 		sig := <-sigCh
+		if cfg.Continuous {
+			logger.Info("Received signal %v, stopping continuous mode", sig)
+			stopRun()
+			sig = <-sigCh
+		}
 		logger.Info("Received signal %v, cleaning up...", sig)
 		fmt.Fprintf(os.Stderr, "\nReceived %v, shutting down devices...\n", sig)
 		if cfg.ShutdownAfter {
@@ -695,8 +849,26 @@ func executeTest(cfg *RunConfig) error {
 	}()
 	defer signal.Stop(sigCh)
 
+	// Serve live metrics for the duration of the run
+	if cfg.MetricsAddr != "" {
+		srv, err := metrics.Serve(cfg.MetricsAddr)
+		if err != nil {
+			return fmt.Errorf("failed to start metrics server: %w", err)
+		}
+		defer srv.Close()
+		logger.Info("Serving metrics on http://%s/metrics", srv.Addr())
+		fmt.Printf("  Metrics: http://%s/metrics\n\n", srv.Addr())
+	}
+
+	// Record the run's driver HTTP calls so they appear as spans in the
+	// exported trace
+	cfg.recorder = nil
+	if cfg.Telemetry.Enabled() {
+		cfg.recorder = telemetry.NewRecorder()
+	}
+
 	// 3. Validate and parse flows
-	flows, err := validateAndParseFlows(cfg)
+	flows, files, err := validateAndParseFlows(cfg)
 	if err != nil {
 		logger.Error("Flow validation failed: %v", err)
 		return err
@@ -727,6 +899,13 @@ func executeTest(cfg *RunConfig) error {
 		logger.Info("Single device execution mode")
 	}
 
+	if cfg.Continuous {
+		if needsParallel {
+			return fmt.Errorf("--continuous runs on a single device and cannot be combined with --parallel or multiple devices")
+		}
+		return executeContinuous(runCtx, cfg, flows, files)
+	}
+
 	// 5. Execute flows
 	logger.Info("Starting flow execution (parallel: %v, devices: %v)", needsParallel, deviceIDs)
 	result, err := executeFlowsWithMode(cfg, flows, needsParallel, deviceIDs)
@@ -776,6 +955,17 @@ func executeTest(cfg *RunConfig) error {
 		fmt.Printf("  %s⚠%s Warning: failed to generate Allure report: %v\n", color(colorYellow), color(colorReset), err)
 	}
 
+	traceExported := false
+	if cfg.Telemetry.Enabled() {
+		if err := telemetry.ExportTraces(cfg.OutputDir, cfg.Telemetry, cfg.recorder.Calls()); err != nil {
+			fmt.Printf("  %s⚠%s Warning: failed to export trace: %v\n", color(colorYellow), color(colorReset), err)
+		} else {
+			traceExported = true
+		}
+	}
+
+	fmt.Printf("  %sSeed:%s %d (rerun with --seed %d to generate the same data)\n", color(colorBold), color(colorReset), cfg.Seed, cfg.Seed)
+
 	// Display reports section as a directory tree
 	fmt.Printf("  %sReports:%s %s\n", color(colorBold), color(colorReset), cfg.OutputDir)
 	fmt.Printf("    ├── report.json\n")
@@ -800,6 +990,14 @@ func executeTest(cfg *RunConfig) error {
 	if allureGenerated {
 		fmt.Printf("    Allure: %s\n", allurePath)
 	}
+	if traceExported {
+		if cfg.Telemetry.File != "" {
+			fmt.Printf("    Trace:  %s\n", cfg.Telemetry.File)
+		}
+		if cfg.Telemetry.Endpoint != "" {
+			fmt.Printf("    Trace:  sent to %s\n", cfg.Telemetry.Endpoint)
+		}
+	}
 
 	// 7. Print update notice if available
 	printUpdateNotice()
@@ -816,14 +1014,47 @@ func executeTest(cfg *RunConfig) error {
 }
 
 // validateAndParseFlows validates and parses all flow files.
-func validateAndParseFlows(cfg *RunConfig) ([]flow.Flow, error) {
+// It also returns the files the flows depend on (see loadFlows).
+func validateAndParseFlows(cfg *RunConfig) ([]flow.Flow, []string, error) {
+	flows, files, err := loadFlows(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fmt.Printf("\n%sSetup%s\n", color(colorBold), color(colorReset))
+	fmt.Println(strings.Repeat("─", 40))
+	printSetupSuccess(fmt.Sprintf("Found %d test flow(s)", len(flows)))
+
+	return flows, files, nil
+}
+
+// loadFlows validates and parses all flow files. It also returns every file
+// the flows depend on (flows, runFlow/runScript dependencies, config.yaml).
+func loadFlows(cfg *RunConfig) ([]flow.Flow, []string, error) {
 	v := validator.New(cfg.IncludeTags, cfg.ExcludeTags)
+	v.SetStrict(cfg.Strict)
+	v.SetConfig(cfg.workspace)
 	var allTestCases []string
+	var allFiles []string
 	var allErrors []error
 
 	for _, path := range cfg.FlowPaths {
 		result := v.Validate(path)
 		allTestCases = append(allTestCases, result.TestCases...)
+		allFiles = append(allFiles, result.Files...)
+		allErrors = append(allErrors, result.Errors...)
+	}
+
+	// Suite hooks are validated like flows but are not test cases
+	beforeSuite, afterSuite := suiteHookPaths(cfg)
+	for _, path := range []string{beforeSuite, afterSuite} {
+		if path == "" {
+			continue
+		}
+		hv := validator.New(nil, nil)
+		hv.SetStrict(cfg.Strict)
+		result := hv.Validate(path)
+		allFiles = append(allFiles, result.Files...)
 		allErrors = append(allErrors, result.Errors...)
 	}
 
@@ -832,27 +1063,56 @@ func validateAndParseFlows(cfg *RunConfig) ([]flow.Flow, error) {
 		for _, err := range allErrors {
 			fmt.Fprintf(os.Stderr, "  - %v\n", err)
 		}
-		return nil, fmt.Errorf("validation failed with %d error(s)", len(allErrors))
+		return nil, allFiles, fmt.Errorf("validation failed with %d error(s)", len(allErrors))
 	}
 
+	// A hook next to the flows is matched by the flow patterns but is not a test case
+	allTestCases = slices.DeleteFunc(allTestCases, func(path string) bool {
+		return samePath(path, beforeSuite) || samePath(path, afterSuite)
+	})
+
 	if len(allTestCases) == 0 {
-		return nil, fmt.Errorf("no test flows found")
+		return nil, allFiles, fmt.Errorf("no test flows found")
 	}
 
-	fmt.Printf("\n%sSetup%s\n", color(colorBold), color(colorReset))
-	fmt.Println(strings.Repeat("─", 40))
-	printSetupSuccess(fmt.Sprintf("Found %d test flow(s)", len(allTestCases)))
-
 	var flows []flow.Flow
 	for _, path := range allTestCases {
 		f, err := flow.ParseFile(path)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+			return nil, allFiles, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		// A flow with a dataset runs once per row
+		instances, err := flow.Expand(f)
+		if err != nil {
+			return nil, allFiles, err
+		}
+		for _, inst := range instances {
+			flows = append(flows, *inst)
 		}
-		flows = append(flows, *f)
 	}
 
-	return flows, nil
+	if err := checkDeviceGroups(flows, cfg.workspace); err != nil {
+		return nil, allFiles, err
+	}
+
+	if cfg.workspace != nil {
+		var missing []string
+		flows, cfg.OrderedFlows, missing = orderFlows(flows, cfg.workspace.ExecutionOrder)
+		for _, name := range missing {
+			fmt.Printf("  %s⚠%s Warning: executionOrder names %q, which matches no flow\n", color(colorYellow), color(colorReset), name)
+		}
+		cfg.StopOrderOnFailure = cfg.workspace.ExecutionOrder.StopOnFailure()
+	}
+
+	var err error
+	if cfg.BeforeSuite, err = parseSuiteHook(beforeSuite); err != nil {
+		return nil, allFiles, err
+	}
+	if cfg.AfterSuite, err = parseSuiteHook(afterSuite); err != nil {
+		return nil, allFiles, err
+	}
+
+	return flows, allFiles, nil
 }
 
 // determineExecutionMode decides whether to run in parallel and which devices to use.
@@ -1021,24 +1281,48 @@ func executeSingleDevice(cfg *RunConfig, flows []flow.Flow) (*executor.RunResult
 	runner := executor.New(driver, executor.RunnerConfig{
 		OutputDir:          cfg.OutputDir,
 		Parallelism:        0,
-		Artifacts:          executor.ArtifactOnFailure,
+		Retries:            cfg.Retries,
+		Artifacts:          cfg.Artifacts,
 		Device:             deviceInfo,
 		App:                buildAppReport(driver),
 		RunnerVersion:      Version,
 		DriverName:         driverName,
 		Env:                cfg.Env,
+		Secrets:            cfg.Secrets,
 		WaitForIdleTimeout: cfg.WaitForIdleTimeout,
+		CommandTimeout:     cfg.CommandTimeout,
+		ModulePaths:        cfg.ModulePaths,
+		ScriptTimeout:      cfg.ScriptTimeout,
+		Seed:               cfg.Seed,
+		FlowRoot:           cfg.flowRoot(),
+		OrderedFlows:       cfg.OrderedFlows,
+		StopOrderOnFailure: cfg.StopOrderOnFailure,
+		BeforeSuite:        cfg.BeforeSuite,
+		AfterSuite:         cfg.AfterSuite,
+		DeviceGroups:       cfg.DeviceGroups,
 		DeviceInfo:         &deviceInfo,
 		OnFlowStart:        onFlowStart,
 		OnStepComplete:     onStepComplete,
 		OnNestedStep:       onNestedStep,
 		OnNestedFlowStart:  onNestedFlowStart,
 		OnFlowEnd:          onFlowEnd,
+		OnSuiteHookStart:   onSuiteHookStart,
+		Debugger:           newDebugger(cfg),
 	})
 
 	return runner.Run(context.Background(), flows)
 }
 
+// newDebugger returns the interactive debugger for --debug, or nil.
+func newDebugger(cfg *RunConfig) executor.Debugger {
+	if !cfg.Debug {
+		return nil
+	}
+	fmt.Printf("  %sDebugger:%s pausing before each step — type %shelp%s for commands\n",
+		color(colorCyan), color(colorReset), color(colorBold), color(colorReset))
+	return debugger.New(os.Stdin, os.Stdout)
+}
+
 // ExecuteFlowWithDriver runs a single flow using an existing driver.
 // Use this for library usage when you want to reuse the same session across multiple flows.
 // Provides full output: console progress, JSON report, and screenshots on failure.
@@ -1060,19 +1344,33 @@ func ExecuteFlowWithDriver(driver core.Driver, cfg *RunConfig, f flow.Flow) (*ex
 	runner := executor.New(driver, executor.RunnerConfig{
 		OutputDir:          cfg.OutputDir,
 		Parallelism:        0,
-		Artifacts:          executor.ArtifactOnFailure,
+		Retries:            cfg.Retries,
+		Artifacts:          cfg.Artifacts,
 		Device:             deviceInfo,
 		App:                buildAppReport(driver),
 		RunnerVersion:      Version,
 		DriverName:         driverName,
 		Env:                cfg.Env,
+		Secrets:            cfg.Secrets,
 		WaitForIdleTimeout: cfg.WaitForIdleTimeout,
+		CommandTimeout:     cfg.CommandTimeout,
+		ModulePaths:        cfg.ModulePaths,
+		ScriptTimeout:      cfg.ScriptTimeout,
+		Seed:               cfg.Seed,
+		FlowRoot:           cfg.flowRoot(),
+		OrderedFlows:       cfg.OrderedFlows,
+		StopOrderOnFailure: cfg.StopOrderOnFailure,
+		BeforeSuite:        cfg.BeforeSuite,
+		AfterSuite:         cfg.AfterSuite,
+		DeviceGroups:       cfg.DeviceGroups,
 		DeviceInfo:         &deviceInfo,
 		OnFlowStart:        onFlowStart,
 		OnStepComplete:     onStepComplete,
 		OnNestedStep:       onNestedStep,
 		OnNestedFlowStart:  onNestedFlowStart,
 		OnFlowEnd:          onFlowEnd,
+		OnSuiteHookStart:   onSuiteHookStart,
+		Debugger:           newDebugger(cfg),
 	})
 
 	return runner.Run(context.Background(), []flow.Flow{f})
@@ -1196,6 +1494,11 @@ func onFlowEnd(name string, passed bool, durationMs int64, errMsg string) {
 	}
 }
 
+func onSuiteHookStart(hook, file string) {
+	fmt.Printf("\n  %s%s%s (%s)\n", color(colorCyan), hook, color(colorReset), file)
+	fmt.Println(strings.Repeat("─", 60))
+}
+
 func printSummary(result *executor.RunResult) {
 	// Calculate totals
 	totalSteps := 0
@@ -1268,6 +1571,20 @@ func printSummary(result *executor.RunResult) {
 		totalSteps, passedSteps, failedSteps, skippedSteps,
 		formatDuration(result.Duration))
 	fmt.Println(strings.Repeat("═", tableWidth))
+	for _, d := range result.FailedDevices {
+		fmt.Printf("  %s✗ beforeSuite failed on %s%s: %s\n", color(colorRed), deviceLabel(d), color(colorReset), d.Error)
+	}
+	for _, u := range result.Unschedulable {
+		fmt.Printf("  %s⚠ %s (%s) skipped%s: no device meets its requirements (%s)\n", color(colorYellow), u.Name, u.File, color(colorReset), u.Requirements)
+	}
+}
+
+// deviceLabel names a device in output: its name, or its ID.
+func deviceLabel(d executor.DeviceFailure) string {
+	if d.Name != "" {
+		return d.Name
+	}
+	return d.DeviceID
 }
 
 // formatDuration formats milliseconds to a human-readable string.
@@ -1284,6 +1601,18 @@ func formatDuration(ms int64) string {
 	return fmt.Sprintf("%dm %ds", mins, secs)
 }
 
+// workspaceAppID returns the appId of the workspace config (nil if none),
+// or of its profile when that sets one.
+func workspaceAppID(cfg *config.Config, profile string) string {
+	if cfg == nil {
+		return ""
+	}
+	if p, err := cfg.Profile(profile); err == nil && p != nil && p.AppID != "" {
+		return p.AppID
+	}
+	return cfg.AppID
+}
+
 func parseEnvVars(envs []string) map[string]string {
 	result := make(map[string]string)
 	for _, e := range envs {
@@ -1323,19 +1652,33 @@ func executeAppiumSingleSession(cfg *RunConfig, flows []flow.Flow) (*executor.Ru
 	runner := executor.New(driver, executor.RunnerConfig{
 		OutputDir:          cfg.OutputDir,
 		Parallelism:        0,
-		Artifacts:          executor.ArtifactOnFailure,
+		Retries:            cfg.Retries,
+		Artifacts:          cfg.Artifacts,
 		Device:             deviceInfo,
 		App:                buildAppReport(driver),
 		RunnerVersion:      Version,
 		DriverName:         "appium",
 		Env:                cfg.Env,
+		Secrets:            cfg.Secrets,
 		WaitForIdleTimeout: cfg.WaitForIdleTimeout,
+		CommandTimeout:     cfg.CommandTimeout,
+		ModulePaths:        cfg.ModulePaths,
+		ScriptTimeout:      cfg.ScriptTimeout,
+		Seed:               cfg.Seed,
+		FlowRoot:           cfg.flowRoot(),
+		OrderedFlows:       cfg.OrderedFlows,
+		StopOrderOnFailure: cfg.StopOrderOnFailure,
+		BeforeSuite:        cfg.BeforeSuite,
+		AfterSuite:         cfg.AfterSuite,
+		DeviceGroups:       cfg.DeviceGroups,
 		DeviceInfo:         &deviceInfo,
 		OnFlowStart:        onFlowStart,
 		OnStepComplete:     onStepComplete,
 		OnNestedStep:       onNestedStep,
 		OnNestedFlowStart:  onNestedFlowStart,
 		OnFlowEnd:          onFlowEnd,
+		OnSuiteHookStart:   onSuiteHookStart,
+		Debugger:           newDebugger(cfg),
 	})
 
 	return runner.Run(context.Background(), flows)
@@ -1432,6 +1775,7 @@ func createAppiumDriver(cfg *RunConfig) (core.Driver, func(), error) {
 		logger.Error("Failed to create Appium session: %v", err)
 		return nil, nil, fmt.Errorf("create Appium session: %w", err)
 	}
+	driver.SetRecorder(cfg.recorder)
 	logger.Info("Appium session created successfully: %s", driver.GetPlatformInfo().DeviceID)
 	printSetupSuccess("Appium session created")
 
@@ -1818,13 +2162,26 @@ func createParallelRunner(cfg *RunConfig, workers []executor.DeviceWorker, platf
 	runnerConfig := executor.RunnerConfig{
 		OutputDir:          cfg.OutputDir,
 		Parallelism:        0,
-		Artifacts:          executor.ArtifactOnFailure,
+		Retries:            cfg.Retries,
+		Artifacts:          cfg.Artifacts,
 		Device:             deviceInfo,
 		App:                buildAppReport(firstDriver),
 		RunnerVersion:      Version,
 		DriverName:         driverName,
 		Env:                cfg.Env,
+		Secrets:            cfg.Secrets,
 		WaitForIdleTimeout: cfg.WaitForIdleTimeout,
+		CommandTimeout:     cfg.CommandTimeout,
+		ModulePaths:        cfg.ModulePaths,
+		ScriptTimeout:      cfg.ScriptTimeout,
+		Seed:               cfg.Seed,
+		FlowRoot:           cfg.flowRoot(),
+		OrderedFlows:       cfg.OrderedFlows,
+		StopOrderOnFailure: cfg.StopOrderOnFailure,
+		BeforeSuite:        cfg.BeforeSuite,
+		AfterSuite:         cfg.AfterSuite,
+		DeviceGroups:       cfg.DeviceGroups,
+		ExpectedDurations:  flowHistory(cfg.OutputDir),
 		// Callbacks will be set per-worker in parallel.go with device info
 	}
 
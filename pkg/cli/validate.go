@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/validator"
+	"github.com/urfave/cli/v2"
+)
+
+// Exit codes for validate and list.
+const (
+	exitInvalid = 1 // Validation errors found
+	exitUsage   = 2 // Bad arguments
+)
+
+var validateCommand = &cli.Command{
+	Name:      "validate",
+	Usage:     "Check flows for errors without a device",
+	ArgsUsage: "<flow files or directories>...",
+	Description: `Parse every flow and its runFlow/runScript dependencies and report
+errors such as invalid YAML, unknown commands, missing files and circular
+runFlow references. No device or driver is needed, so it is suited to CI
+linting.
+
+Exit codes: 0 when all flows are valid, 1 when errors were found, 2 for
+bad arguments.
+
+Examples:
+  maestro-runner validate flows/
+  maestro-runner validate flows/ --strict --format json`,
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "include-tags",
+			Usage: "Only include flows with these tags",
+		},
+		&cli.StringSliceFlag{
+			Name:  "exclude-tags",
+			Usage: "Exclude flows with these tags",
+		},
+		&cli.BoolFlag{
+			Name:  "strict",
+			Usage: "Also report unknown keys, wrong value types and conflicting options",
+		},
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "Output format: human or json",
+			Value: "human",
+		},
+	},
+	Action: runValidate,
+}
+
+// validateOutput is the JSON output of validate.
+type validateOutput struct {
+	Valid  bool            `json:"valid"`
+	Flows  []string        `json:"flows"`
+	Files  []string        `json:"files"`
+	Errors []validateIssue `json:"errors"`
+}
+
+type validateIssue struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+func runValidate(c *cli.Context) error {
+	format, err := outputFormat(c)
+	if err != nil {
+		return err
+	}
+	if c.NArg() == 0 {
+		return cli.Exit("at least one flow file or directory is required", exitUsage)
+	}
+
+	result := validatePaths(c.Args().Slice(), c.StringSlice("include-tags"), c.StringSlice("exclude-tags"), c.Bool("strict"))
+
+	if format == "json" {
+		out := validateOutput{
+			Valid:  result.IsValid(),
+			Flows:  nonNil(result.TestCases),
+			Files:  nonNil(result.Files),
+			Errors: []validateIssue{},
+		}
+		for _, e := range result.Errors {
+			out.Errors = append(out.Errors, toIssue(e))
+		}
+		if err := printJSON(out); err != nil {
+			return err
+		}
+	} else {
+		printValidateResult(result)
+	}
+
+	if !result.IsValid() {
+		return cli.Exit("", exitInvalid)
+	}
+	return nil
+}
+
+// validatePaths validates each path and merges the results.
+func validatePaths(paths, includeTags, excludeTags []string, strict bool) *validator.Result {
+	v := validator.New(includeTags, excludeTags)
+	v.SetStrict(strict)
+
+	merged := &validator.Result{Dependencies: make(map[string][]string)}
+	for _, path := range paths {
+		result := v.Validate(path)
+		merged.TestCases = append(merged.TestCases, result.TestCases...)
+		merged.Files = append(merged.Files, result.Files...)
+		merged.Errors = append(merged.Errors, result.Errors...)
+		for parent, deps := range result.Dependencies {
+			merged.Dependencies[parent] = deps
+		}
+	}
+	return merged
+}
+
+func printValidateResult(result *validator.Result) {
+	if result.IsValid() {
+		for _, path := range result.TestCases {
+			fmt.Printf("  %s✓%s %s\n", color(colorGreen), color(colorReset), displayPath(path))
+		}
+		fmt.Printf("\n  %s%d flow(s) valid%s, %d file(s) checked\n",
+			color(colorGreen), len(result.TestCases), color(colorReset), len(result.Files))
+		return
+	}
+
+	for _, e := range result.Errors {
+		fmt.Printf("  %s✗%s %s\n", color(colorRed), color(colorReset), displayError(e))
+	}
+	fmt.Printf("\n  %s%d error(s)%s in %d file(s) checked\n",
+		color(colorRed), len(result.Errors), color(colorReset), len(result.Files))
+}
+
+func toIssue(err error) validateIssue {
+	var ve *validator.ValidationError
+	if errors.As(err, &ve) {
+		return validateIssue{File: ve.File, Line: ve.Line, Column: ve.Column, Message: ve.Message}
+	}
+	return validateIssue{Message: err.Error()}
+}
+
+// displayError formats a validation error with its path relative to the
+// working directory.
+func displayError(err error) string {
+	var ve *validator.ValidationError
+	if !errors.As(err, &ve) {
+		return err.Error()
+	}
+	short := *ve
+	short.File = displayPath(ve.File)
+	return short.Error()
+}
+
+// outputFormat returns the --format value, rejecting unknown formats.
+func outputFormat(c *cli.Context) (string, error) {
+	format := strings.ToLower(c.String("format"))
+	if format != "human" && format != "json" {
+		return "", cli.Exit(fmt.Sprintf("unknown format %q (use human or json)", c.String("format")), exitUsage)
+	}
+	return format, nil
+}
+
+// displayPath returns path relative to the working directory when it is
+// inside it.
+func displayPath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
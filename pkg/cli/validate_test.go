@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+// writeFlows writes files under a temp dir and returns the dir.
+func writeFlows(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// runSubcommand runs cmd with args and returns its stdout and exit code.
+func runSubcommand(t *testing.T, cmd *cli.Command, args ...string) (string, int) {
+	t.Helper()
+	code := 0
+	app := &cli.App{
+		Name:     "test-app",
+		Flags:    GlobalFlags,
+		Commands: []*cli.Command{cmd},
+		ExitErrHandler: func(_ *cli.Context, err error) {
+			if coder, ok := err.(cli.ExitCoder); ok {
+				code = coder.ExitCode()
+			}
+		},
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	os.Stdout = w
+	os.Stderr, _ = os.Open(os.DevNull)
+	defer func() { os.Stdout, os.Stderr = oldStdout, oldStderr }()
+
+	done := make(chan string)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- string(out)
+	}()
+
+	if err := app.Run(append([]string{"test-app", cmd.Name}, args...)); err != nil && code == 0 {
+		code = 1
+	}
+	w.Close()
+	return <-done, code
+}
+
+var listFlows = map[string]string{
+	"flows/login.yaml":      "appId: com.example\nname: Login\ntags: [smoke]\n---\n- launchApp\n- runFlow: sub/common.yaml\n- tapOn: OK\n",
+	"flows/checkout.yaml":   "appId: com.example\ntags: [slow]\n---\n- runFlow: sub/common.yaml\n",
+	"flows/sub/common.yaml": "appId: com.example\n---\n- runFlow: leaf.yaml\n",
+	"flows/sub/leaf.yaml":   "appId: com.example\n---\n- back\n",
+}
+
+func TestValidateCommand_Valid(t *testing.T) {
+	dir := writeFlows(t, listFlows)
+
+	out, code := runSubcommand(t, validateCommand, filepath.Join(dir, "flows"))
+	if code != 0 {
+		t.Fatalf("exit code = %d, output:\n%s", code, out)
+	}
+	if !strings.Contains(out, "2 flow(s) valid, 4 file(s) checked") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestValidateCommand_ErrorsJSON(t *testing.T) {
+	dir := writeFlows(t, map[string]string{
+		"bad.yaml": "appId: com.example\n---\n- back\n- runFlow: missing.yaml\n",
+	})
+
+	out, code := runSubcommand(t, validateCommand, "--format", "json", filepath.Join(dir, "bad.yaml"))
+	if code != exitInvalid {
+		t.Errorf("exit code = %d, want %d", code, exitInvalid)
+	}
+	var got validateOutput
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if got.Valid || len(got.Errors) != 1 {
+		t.Fatalf("unexpected result: %+v", got)
+	}
+	e := got.Errors[0]
+	if e.Line != 4 || e.Column != 3 || e.Message != "runFlow: file not found: missing.yaml" {
+		t.Errorf("unexpected error: %+v", e)
+	}
+}
+
+func TestValidateCommand_UsageErrors(t *testing.T) {
+	if _, code := runSubcommand(t, validateCommand); code != exitUsage {
+		t.Errorf("no paths: exit code = %d, want %d", code, exitUsage)
+	}
+	if _, code := runSubcommand(t, validateCommand, "--format", "xml", "."); code != exitUsage {
+		t.Errorf("bad format: exit code = %d, want %d", code, exitUsage)
+	}
+}
+
+func TestListCommand_TagsAndDependencies(t *testing.T) {
+	dir := writeFlows(t, listFlows)
+
+	out, code := runSubcommand(t, listCommand, "--exclude-tags", "slow", "--format", "json", filepath.Join(dir, "flows"))
+	if code != 0 {
+		t.Fatalf("exit code = %d, output:\n%s", code, out)
+	}
+	var flows []listedFlow
+	if err := json.Unmarshal([]byte(out), &flows); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(flows) != 1 {
+		t.Fatalf("expected 1 flow after tag filter, got %d", len(flows))
+	}
+	f := flows[0]
+	if f.Name != "Login" || f.AppID != "com.example" || f.Steps != 3 || len(f.Tags) != 1 {
+		t.Errorf("unexpected flow: %+v", f)
+	}
+	if len(f.Dependencies) != 1 || !strings.HasSuffix(f.Dependencies[0].Path, "common.yaml") {
+		t.Fatalf("unexpected dependencies: %+v", f.Dependencies)
+	}
+	nested := f.Dependencies[0].Dependencies
+	if len(nested) != 1 || !strings.HasSuffix(nested[0].Path, "leaf.yaml") {
+		t.Errorf("unexpected nested dependencies: %+v", nested)
+	}
+}
+
+func TestListCommand_Human(t *testing.T) {
+	dir := writeFlows(t, listFlows)
+
+	out, code := runSubcommand(t, listCommand, filepath.Join(dir, "flows"))
+	if code != 0 {
+		t.Fatalf("exit code = %d, output:\n%s", code, out)
+	}
+	for _, want := range []string{"Login", "checkout", "tags:  smoke", "steps: 3", "└─ ", "2 flow(s)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestListCommand_InvalidFlow(t *testing.T) {
+	dir := writeFlows(t, map[string]string{"bad.yaml": "- notAStep: x\n"})
+
+	if _, code := runSubcommand(t, listCommand, dir); code != exitInvalid {
+		t.Errorf("exit code = %d, want %d", code, exitInvalid)
+	}
+}
+
+func TestDependencyTree_Cycle(t *testing.T) {
+	deps := map[string][]string{"a": {"b"}, "b": {"a"}}
+	tree := dependencyTree(deps, "a", []string{"a"})
+	if len(tree) != 1 || len(tree[0].Dependencies) != 1 || !tree[0].Dependencies[0].Cycle {
+		t.Errorf("cycle not marked: %+v", tree)
+	}
+}
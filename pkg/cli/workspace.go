@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/config"
+	"github.com/devicelab-dev/maestro-runner/pkg/executor"
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/devicelab-dev/maestro-runner/pkg/report"
+)
+
+// findWorkspaceConfig returns the config.yaml (or config.yml) of the first
+// flow directory, or directory of a flow file, that has one, or "".
+func findWorkspaceConfig(flowPaths []string) string {
+	for _, p := range flowPaths {
+		info, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		dir := p
+		if !info.IsDir() {
+			dir = filepath.Dir(p)
+		}
+		if path := config.Find(dir); path != "" {
+			return path
+		}
+	}
+	return ""
+}
+
+// loadWorkspaceConfig loads the workspace config: configPath (--config), or
+// the one found next to the flows. The overrides of platform apply, or of the
+// config's own platform when platform is "". It returns the config (nil if
+// there is none), its path and the resulting platform.
+func loadWorkspaceConfig(configPath string, flowPaths []string, platform string) (*config.Config, string, string, error) {
+	if configPath == "" {
+		configPath = findWorkspaceConfig(flowPaths)
+	}
+	if configPath == "" {
+		return nil, "", platform, nil
+	}
+	ws, err := config.Load(configPath)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to load config: %w", err)
+	}
+	if platform == "" {
+		platform = ws.Platform
+	}
+	return ws.ForPlatform(platform), configPath, platform, nil
+}
+
+// applyWorkspaceConfig applies the run settings of the workspace config (with
+// platform overrides already applied) to cfg: script settings, the default
+// command timeout, retries, artifacts, device groups and the suite hook paths. Env and
+// appId are resolved separately, with profiles and env files.
+func applyWorkspaceConfig(cfg *RunConfig, ws *config.Config) error {
+	artifacts, err := executor.ParseArtifactMode(ws.Artifacts)
+	if err != nil {
+		return err
+	}
+	cfg.workspace = ws
+	cfg.ModulePaths = ws.ModulePaths(cfg.ConfigPath)
+	cfg.ScriptTimeout = ws.Scripts.Timeout
+	cfg.CommandTimeout = ws.CommandTimeout
+	cfg.Retries = ws.Retries
+	cfg.Artifacts = artifacts
+	cfg.DeviceGroups = ws.DeviceGroups
+	return nil
+}
+
+// suiteHookPaths returns the beforeSuite and afterSuite flow files of the
+// workspace config, resolved against the config file ("" if not set).
+func suiteHookPaths(cfg *RunConfig) (beforeSuite, afterSuite string) {
+	if cfg.workspace == nil {
+		return "", ""
+	}
+	return config.ResolvePath(cfg.ConfigPath, cfg.workspace.BeforeSuite),
+		config.ResolvePath(cfg.ConfigPath, cfg.workspace.AfterSuite)
+}
+
+// parseSuiteHook parses a suite hook flow file ("" = no hook).
+func parseSuiteHook(path string) (*flow.Flow, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := flow.ParseFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// orderFlows moves the flows named in executionOrder to the front, in that
+// order, and returns the reordered flows and how many are ordered. A name
+// matches a flow's name or its file name without extension; every dataset
+// instance of a matching flow is ordered. Names that match no flow are
+// returned as missing.
+func orderFlows(flows []flow.Flow, order *config.ExecutionOrder) (ordered []flow.Flow, count int, missing []string) {
+	if order == nil || len(order.FlowsOrder) == 0 {
+		return flows, 0, nil
+	}
+
+	taken := make([]bool, len(flows))
+	for _, name := range order.FlowsOrder {
+		found := false
+		for i, f := range flows {
+			if !taken[i] && flowMatchesName(f, name) {
+				taken[i] = true
+				ordered = append(ordered, f)
+				found = true
+			}
+		}
+		if !found {
+			missing = append(missing, name)
+		}
+	}
+	count = len(ordered)
+	for i, f := range flows {
+		if !taken[i] {
+			ordered = append(ordered, f)
+		}
+	}
+	return ordered, count, missing
+}
+
+// flowMatchesName reports whether an executionOrder entry names the flow.
+func flowMatchesName(f flow.Flow, name string) bool {
+	base := filepath.Base(f.SourcePath)
+	return f.Config.Name == name || strings.TrimSuffix(base, filepath.Ext(base)) == name
+}
+
+// checkDeviceGroups returns an error if a flow requires a device group that
+// the workspace config doesn't define.
+func checkDeviceGroups(flows []flow.Flow, ws *config.Config) error {
+	for _, f := range flows {
+		if f.Config.Device == nil || f.Config.Device.Group == "" {
+			continue
+		}
+		if ws == nil || len(ws.DeviceGroups[f.Config.Device.Group]) == 0 {
+			return fmt.Errorf("%s requires device group %q, which is not in the workspace config's deviceGroups",
+				f.SourcePath, f.Config.Device.Group)
+		}
+	}
+	return nil
+}
+
+// flowHistory returns the average flow durations of past runs. A --flatten
+// output directory holds the previous run's report; otherwise the reports
+// are in the timestamped folders next to outputDir.
+func flowHistory(outputDir string) map[string]int64 {
+	if _, err := os.Stat(filepath.Join(outputDir, "report.json")); err == nil {
+		return report.ExpectedDurations(outputDir, report.HistoryRuns)
+	}
+	return report.ExpectedDurations(filepath.Dir(outputDir), report.HistoryRuns)
+}
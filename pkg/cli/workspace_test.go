@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/config"
+	"github.com/devicelab-dev/maestro-runner/pkg/executor"
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/devicelab-dev/maestro-runner/pkg/report"
+)
+
+func TestOrderFlows(t *testing.T) {
+	flows := []flow.Flow{
+		{SourcePath: "flows/a.yaml"},
+		{SourcePath: "flows/login.yaml", Config: flow.Config{Name: "Login [alice]"}},
+		{SourcePath: "flows/login.yaml", Config: flow.Config{Name: "Login [bob]"}},
+		{SourcePath: "flows/c.yml", Config: flow.Config{Name: "Checkout"}},
+	}
+	order := &config.ExecutionOrder{FlowsOrder: []string{"Checkout", "login", "missing"}}
+
+	ordered, count, missing := orderFlows(flows, order)
+
+	var names []string
+	for _, f := range ordered {
+		names = append(names, f.Config.Name)
+	}
+	if want := []string{"Checkout", "Login [alice]", "Login [bob]", ""}; !reflect.DeepEqual(names, want) {
+		t.Errorf("order = %q, want %q", names, want)
+	}
+	if count != 3 {
+		t.Errorf("count = %d, want 3", count)
+	}
+	if !reflect.DeepEqual(missing, []string{"missing"}) {
+		t.Errorf("missing = %v", missing)
+	}
+}
+
+func TestLoadFlows_WorkspaceConfig(t *testing.T) {
+	dir := writeFlows(t, map[string]string{
+		"config.yaml": `flows:
+  - "suite/**"
+  - "*"
+executionOrder:
+  continueOnFailure: false
+  flowsOrder: [second]
+beforeSuite: hooks/setup.yaml
+afterSuite: teardown.yaml
+commandTimeout: 7000
+artifacts: never
+retries: 1
+`,
+		"hooks/setup.yaml":         "- launchApp\n",
+		"suite/first.yaml":         "- launchApp\n",
+		"suite/nested/second.yaml": "- launchApp\n",
+		"top.yaml":                 "- launchApp\n",
+		"teardown.yaml":            "- stopApp\n",
+	})
+	configPath := findWorkspaceConfig([]string{filepath.Join(dir, "top.yaml"), dir})
+	if configPath != filepath.Join(dir, "config.yaml") {
+		t.Fatalf("findWorkspaceConfig() = %q", configPath)
+	}
+	ws, err := config.Load(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &RunConfig{FlowPaths: []string{dir}, ConfigPath: configPath}
+	if err := applyWorkspaceConfig(cfg, ws); err != nil {
+		t.Fatal(err)
+	}
+
+	flows, files, err := loadFlows(cfg)
+	if err != nil {
+		t.Fatalf("loadFlows() error = %v", err)
+	}
+	var got []string
+	for _, f := range flows {
+		got = append(got, filepath.Base(f.SourcePath))
+	}
+	if want := []string{"second.yaml", "first.yaml", "top.yaml"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("flows = %v, want %v", got, want)
+	}
+	if cfg.OrderedFlows != 1 || !cfg.StopOrderOnFailure {
+		t.Errorf("OrderedFlows = %d, StopOrderOnFailure = %v", cfg.OrderedFlows, cfg.StopOrderOnFailure)
+	}
+	if cfg.BeforeSuite == nil || cfg.BeforeSuite.SourcePath != filepath.Join(dir, "hooks", "setup.yaml") || cfg.AfterSuite == nil {
+		t.Errorf("BeforeSuite = %+v, AfterSuite = %+v", cfg.BeforeSuite, cfg.AfterSuite)
+	}
+	if cfg.CommandTimeout != 7000 || cfg.Artifacts != executor.ArtifactNever || cfg.Retries != 1 {
+		t.Errorf("CommandTimeout = %d, Artifacts = %v, Retries = %d", cfg.CommandTimeout, cfg.Artifacts, cfg.Retries)
+	}
+	if !containsPath(files, cfg.BeforeSuite.SourcePath) {
+		t.Errorf("files %v don't include the beforeSuite flow", files)
+	}
+}
+
+func TestLoadWorkspaceConfig(t *testing.T) {
+	dir := writeFlows(t, map[string]string{
+		"config.yaml": "platform: ios\nappId: com.example\nplatforms:\n  ios:\n    appId: com.example.ios\n",
+		"login.yaml":  "- launchApp\n",
+	})
+
+	// Found from a flow file's directory, with the config's own platform
+	ws, path, platform, err := loadWorkspaceConfig("", []string{filepath.Join(dir, "login.yaml")}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(dir, "config.yaml") || platform != "ios" || ws.AppID != "com.example.ios" {
+		t.Errorf("path = %q, platform = %q, appId = %q", path, platform, ws.AppID)
+	}
+
+	// --platform wins over the config's platform
+	ws, _, platform, err = loadWorkspaceConfig(path, nil, "android")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if platform != "android" || ws.AppID != "com.example" {
+		t.Errorf("platform = %q, appId = %q", platform, ws.AppID)
+	}
+
+	if ws, _, _, err := loadWorkspaceConfig("", []string{t.TempDir()}, ""); ws != nil || err != nil {
+		t.Errorf("expected no config, got %+v, %v", ws, err)
+	}
+}
+
+func TestApplyWorkspaceConfig_InvalidArtifacts(t *testing.T) {
+	if err := applyWorkspaceConfig(&RunConfig{}, &config.Config{Artifacts: "sometimes"}); err == nil {
+		t.Error("expected error for unknown artifacts mode")
+	}
+}
+
+func TestCheckDeviceGroups(t *testing.T) {
+	flows := []flow.Flow{
+		{SourcePath: "any.yaml"},
+		{SourcePath: "lab.yaml", Config: flow.Config{Device: &flow.DeviceRequirements{Group: "lab"}}},
+	}
+	if err := checkDeviceGroups(flows, &config.Config{DeviceGroups: map[string][]string{"lab": {"emulator-5554"}}}); err != nil {
+		t.Errorf("checkDeviceGroups() error = %v", err)
+	}
+	if err := checkDeviceGroups(flows, &config.Config{}); err == nil {
+		t.Error("expected error for undefined group")
+	}
+	if err := checkDeviceGroups(flows, nil); err == nil {
+		t.Error("expected error for a group without a workspace config")
+	}
+}
+
+func TestFlowHistory(t *testing.T) {
+	base := t.TempDir()
+	previous := filepath.Join(base, "2026-01-01_10-00-00")
+	writeReport := func(dir string, ms int64) {
+		t.Helper()
+		index := &report.Index{Flows: []report.FlowEntry{
+			{Name: "Login", SourceFile: "login.yaml", Status: report.StatusPassed, Duration: &ms},
+		}}
+		data, err := json.Marshal(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "report.json"), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeReport(previous, 4000)
+	key := report.FlowKey("login.yaml", "Login")
+
+	// A new timestamped run reads the runs next to it
+	if got := flowHistory(filepath.Join(base, "2026-01-02_10-00-00"))[key]; got != 4000 {
+		t.Errorf("timestamped history = %d, want 4000", got)
+	}
+	// A --flatten directory reads its own previous report
+	flat := filepath.Join(base, "flat")
+	writeReport(flat, 2000)
+	if got := flowHistory(flat)[key]; got != 2000 {
+		t.Errorf("flattened history = %d, want 2000", got)
+	}
+}
@@ -2,8 +2,10 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -14,19 +16,135 @@ type Config struct {
 	AppID string `yaml:"appId"` // App bundle ID or package name
 
 	// Flow selection
-	Flows       []string `yaml:"flows"`       // Glob patterns for flows
+	Flows       []string `yaml:"flows"`       // Glob patterns for flows, relative to the flow directory ("**" matches any depth)
 	IncludeTags []string `yaml:"includeTags"` // Tags to include
 	ExcludeTags []string `yaml:"excludeTags"` // Tags to exclude
 
+	// Flows that run first, in this order
+	ExecutionOrder *ExecutionOrder `yaml:"executionOrder"`
+
+	// Suite hooks: flow files, relative to the config file, run once per
+	// device before its first flow and after its last. Output variables of
+	// beforeSuite carry into the device's flows.
+	BeforeSuite string `yaml:"beforeSuite"`
+	AfterSuite  string `yaml:"afterSuite"`
+	BeforeAll   string `yaml:"beforeAll"` // Alias of beforeSuite
+	AfterAll    string `yaml:"afterAll"`  // Alias of afterSuite
+
 	// Execution settings
-	Env map[string]string `yaml:"env"` // Environment variables
+	Env     map[string]string `yaml:"env"`     // Environment variables
+	Secrets []string          `yaml:"secrets"` // Variable names or patterns (e.g. "*_PASSWORD") whose values are redacted from output
+
+	// Named overrides selected with --profile
+	Profiles map[string]Profile `yaml:"profiles"`
 
 	// Device settings
 	Platform string `yaml:"platform"` // Target platform
 	Device   string `yaml:"device"`   // Target device
 
+	// Named device groups for flows that require one (device: {group: ...}):
+	// group name to device IDs or names
+	DeviceGroups map[string][]string `yaml:"deviceGroups"`
+
 	// Driver settings
 	WaitForIdleTimeout int `yaml:"waitForIdleTimeout"` // Wait for device idle in ms (0 = disabled, default 200)
+	CommandTimeout     int `yaml:"commandTimeout"`     // Default command timeout in ms for flows without one (0 = driver default)
+
+	// Artifacts and retries
+	Artifacts string `yaml:"artifacts"` // When to capture screenshots and hierarchy: onFailure (default), always or never
+	Retries   int    `yaml:"retries"`   // Times a failed flow is rerun
+
+	// Overrides for one platform ("android", "ios")
+	Platforms map[string]PlatformConfig `yaml:"platforms"`
+
+	// Script settings
+	Scripts ScriptsConfig `yaml:"scripts"`
+
+	// Lint settings
+	Lint LintConfig `yaml:"lint"`
+}
+
+// ExecutionOrder lists flows that run before the others, one after another.
+type ExecutionOrder struct {
+	ContinueOnFailure *bool    `yaml:"continueOnFailure"` // Keep running the ordered flows after one fails (default true)
+	FlowsOrder        []string `yaml:"flowsOrder"`        // Flow names, or file names without extension
+}
+
+// StopOnFailure reports whether a failed ordered flow skips the ones after it.
+func (o *ExecutionOrder) StopOnFailure() bool {
+	return o != nil && o.ContinueOnFailure != nil && !*o.ContinueOnFailure
+}
+
+// PlatformConfig overrides workspace settings on one platform.
+type PlatformConfig struct {
+	AppID              string            `yaml:"appId"`              // Replaces the config's appId
+	Env                map[string]string `yaml:"env"`                // Merged over the config's env
+	CommandTimeout     int               `yaml:"commandTimeout"`     // Replaces the config's commandTimeout
+	WaitForIdleTimeout int               `yaml:"waitForIdleTimeout"` // Replaces the config's waitForIdleTimeout
+	ScriptTimeout      int               `yaml:"scriptTimeout"`      // Replaces scripts.timeout
+}
+
+// ForPlatform returns the config with the overrides of platform applied.
+// The config itself is not modified.
+func (c *Config) ForPlatform(platform string) *Config {
+	p, ok := c.Platforms[strings.ToLower(platform)]
+	if !ok {
+		return c
+	}
+	cfg := *c
+	if p.AppID != "" {
+		cfg.AppID = p.AppID
+	}
+	if len(p.Env) > 0 {
+		cfg.Env = make(map[string]string, len(c.Env)+len(p.Env))
+		for k, v := range c.Env {
+			cfg.Env[k] = v
+		}
+		for k, v := range p.Env {
+			cfg.Env[k] = v
+		}
+	}
+	if p.CommandTimeout != 0 {
+		cfg.CommandTimeout = p.CommandTimeout
+	}
+	if p.WaitForIdleTimeout != 0 {
+		cfg.WaitForIdleTimeout = p.WaitForIdleTimeout
+	}
+	if p.ScriptTimeout != 0 {
+		cfg.Scripts.Timeout = p.ScriptTimeout
+	}
+	return &cfg
+}
+
+// ResolvePath resolves a path from the config file at configPath against
+// the file's directory.
+func ResolvePath(configPath, p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(filepath.Dir(configPath), p)
+}
+
+// ScriptsConfig configures the JavaScript engine.
+type ScriptsConfig struct {
+	ModulePaths []string `yaml:"modulePaths"` // Directories require() searches for bare module names, relative to the config file
+	Timeout     int      `yaml:"timeout"`     // Time limit for each script in ms, including its timers (0 = default 30s)
+}
+
+// ModulePaths returns the scripts module paths resolved against the
+// directory of the config file at configPath.
+func (c *Config) ModulePaths(configPath string) []string {
+	paths := make([]string, 0, len(c.Scripts.ModulePaths))
+	for _, p := range c.Scripts.ModulePaths {
+		paths = append(paths, ResolvePath(configPath, p))
+	}
+	return paths
+}
+
+// LintConfig configures the lint command.
+type LintConfig struct {
+	Disable       []string `yaml:"disable"`       // Rule names to turn off
+	LongFlowSteps int      `yaml:"longFlowSteps"` // Step count above which steps need labels (0 = default)
 }
 
 // Load loads configuration from a file.
@@ -40,24 +158,48 @@ func Load(path string) (*Config, error) {
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, err
 	}
+	if cfg.Retries < 0 {
+		return nil, fmt.Errorf("retries must not be negative, got %d", cfg.Retries)
+	}
+	if cfg.BeforeSuite, err = alias("beforeSuite", cfg.BeforeSuite, "beforeAll", cfg.BeforeAll); err != nil {
+		return nil, err
+	}
+	if cfg.AfterSuite, err = alias("afterSuite", cfg.AfterSuite, "afterAll", cfg.AfterAll); err != nil {
+		return nil, err
+	}
 
 	return &cfg, nil
 }
 
-// LoadFromDir looks for config.yaml or config.yml in the directory.
-func LoadFromDir(dir string) (*Config, error) {
-	// Try config.yaml first
-	configPath := filepath.Join(dir, "config.yaml")
-	if _, err := os.Stat(configPath); err == nil {
-		return Load(configPath)
+// alias returns the value of a key or of its alias; setting both is an error.
+func alias(key, value, aliasKey, aliasValue string) (string, error) {
+	if value != "" && aliasValue != "" {
+		return "", fmt.Errorf("%s and %s are the same setting; use %s", key, aliasKey, key)
+	}
+	if value == "" {
+		return aliasValue, nil
 	}
+	return value, nil
+}
 
-	// Try config.yml
-	configPath = filepath.Join(dir, "config.yml")
-	if _, err := os.Stat(configPath); err == nil {
-		return Load(configPath)
+// Find returns the path of config.yaml or config.yml in the directory, or ""
+// if there is neither.
+func Find(dir string) string {
+	for _, name := range []string{"config.yaml", "config.yml"} {
+		configPath := filepath.Join(dir, name)
+		if info, err := os.Stat(configPath); err == nil && !info.IsDir() {
+			return configPath
+		}
 	}
+	return ""
+}
 
-	// No config file found, return empty config
-	return &Config{}, nil
+// LoadFromDir looks for config.yaml or config.yml in the directory.
+func LoadFromDir(dir string) (*Config, error) {
+	configPath := Find(dir)
+	if configPath == "" {
+		// No config file found, return empty config
+		return &Config{}, nil
+	}
+	return Load(configPath)
 }
@@ -22,6 +22,9 @@ env:
   PASS: secret
 platform: ios
 device: iPhone-15
+lint:
+  disable: [require-tags]
+  longFlowSteps: 25
 `
 	if err := os.WriteFile(configPath, []byte(content), 0o644); err != nil {
 		t.Fatal(err)
@@ -50,6 +53,19 @@ device: iPhone-15
 	if cfg.Device != "iPhone-15" {
 		t.Errorf("expected device iPhone-15, got %s", cfg.Device)
 	}
+	if len(cfg.Lint.Disable) != 1 || cfg.Lint.Disable[0] != "require-tags" || cfg.Lint.LongFlowSteps != 25 {
+		t.Errorf("expected lint {disable:[require-tags], longFlowSteps:25}, got %+v", cfg.Lint)
+	}
+}
+
+func TestConfig_ModulePaths(t *testing.T) {
+	cfg := &Config{Scripts: ScriptsConfig{ModulePaths: []string{"scripts/lib", "/opt/js"}}}
+
+	got := cfg.ModulePaths(filepath.Join("workspace", "config.yaml"))
+	want := []string{filepath.Join("workspace", "scripts", "lib"), "/opt/js"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("ModulePaths() = %v, want %v", got, want)
+	}
 }
 
 func TestLoad_NonExistentFile(t *testing.T) {
@@ -172,3 +188,110 @@ func TestLoadFromDir_PrefersYamlOverYml(t *testing.T) {
 		t.Errorf("expected platform ios (from config.yaml), got %s", cfg.Platform)
 	}
 }
+
+func TestLoad_SuiteSettings(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+
+	content := `
+appId: com.example
+env:
+  USER: test
+executionOrder:
+  continueOnFailure: false
+  flowsOrder: [login, checkout]
+beforeAll: hooks/setup.yaml
+afterAll: hooks/teardown.yaml
+commandTimeout: 10000
+artifacts: always
+retries: 2
+deviceGroups:
+  tablets: [emulator-5556, "Pixel Tablet"]
+platforms:
+  ios:
+    appId: com.example.ios
+    env:
+      USER: ios
+    commandTimeout: 20000
+    scriptTimeout: 5000
+`
+	if err := os.WriteFile(configPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.ExecutionOrder.StopOnFailure() || len(cfg.ExecutionOrder.FlowsOrder) != 2 {
+		t.Errorf("executionOrder = %+v", cfg.ExecutionOrder)
+	}
+	if cfg.CommandTimeout != 10000 || cfg.Artifacts != "always" || cfg.Retries != 2 {
+		t.Errorf("commandTimeout = %d, artifacts = %q, retries = %d", cfg.CommandTimeout, cfg.Artifacts, cfg.Retries)
+	}
+	if got := cfg.DeviceGroups["tablets"]; len(got) != 2 || got[1] != "Pixel Tablet" {
+		t.Errorf("deviceGroups = %v", cfg.DeviceGroups)
+	}
+	// beforeAll and afterAll are aliases of beforeSuite and afterSuite
+	if got := ResolvePath(configPath, cfg.BeforeSuite); got != filepath.Join(dir, "hooks", "setup.yaml") {
+		t.Errorf("beforeSuite resolves to %s", got)
+	}
+	if cfg.AfterSuite != "hooks/teardown.yaml" {
+		t.Errorf("afterSuite = %q", cfg.AfterSuite)
+	}
+
+	ios := cfg.ForPlatform("iOS")
+	if ios.AppID != "com.example.ios" || ios.Env["USER"] != "ios" || ios.CommandTimeout != 20000 || ios.Scripts.Timeout != 5000 {
+		t.Errorf("ios config = %+v", ios)
+	}
+	if cfg.AppID != "com.example" || cfg.Env["USER"] != "test" {
+		t.Errorf("ForPlatform modified the config: %+v", cfg)
+	}
+	if android := cfg.ForPlatform("android"); android != cfg {
+		t.Error("ForPlatform without overrides should return the config")
+	}
+}
+
+func TestExecutionOrder_StopOnFailure(t *testing.T) {
+	f := false
+	if (*ExecutionOrder)(nil).StopOnFailure() || (&ExecutionOrder{}).StopOnFailure() {
+		t.Error("continueOnFailure defaults to true")
+	}
+	if !(&ExecutionOrder{ContinueOnFailure: &f}).StopOnFailure() {
+		t.Error("continueOnFailure: false should stop")
+	}
+}
+
+func TestLoad_NegativeRetries(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("retries: -1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(configPath); err == nil {
+		t.Error("expected error for negative retries")
+	}
+}
+
+func TestLoad_SuiteHookAlias(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("beforeSuite: a.yaml\nbeforeAll: b.yaml\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(configPath); err == nil {
+		t.Error("expected error for beforeSuite with beforeAll")
+	}
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	if got := Find(dir); got != "" {
+		t.Errorf("Find() = %q, want none", got)
+	}
+	configPath := filepath.Join(dir, "config.yml")
+	if err := os.WriteFile(configPath, []byte("appId: x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := Find(dir); got != configPath {
+		t.Errorf("Find() = %q, want %q", got, configPath)
+	}
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Variables of a run come from these sources, lowest precedence first:
+//
+//  1. the system environment (ALL_CAPS names, imported by the runner)
+//  2. env in the workspace config
+//  3. env of the profile selected with --profile
+//  4. env files (--env-file), in the order given
+//  5. -e and --secret-env flags
+//  6. the flow's appId (as APP_ID) and env, then runFlow/step env while
+//     those run
+//
+// EnvLayers resolves sources 2-5; the runner applies 1 and 6 per flow.
+
+// Profile overrides parts of the workspace config for one environment, such
+// as staging. It is selected with --profile.
+type Profile struct {
+	AppID string            `yaml:"appId"` // Replaces the config's appId
+	Env   map[string]string `yaml:"env"`   // Merged over the config's env
+}
+
+// Profile returns the named profile. An empty name selects no profile.
+func (c *Config) Profile(name string) (*Profile, error) {
+	if name == "" {
+		return nil, nil
+	}
+	if p, ok := c.Profiles[name]; ok {
+		return &p, nil
+	}
+	names := make([]string, 0, len(c.Profiles))
+	for n := range c.Profiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("unknown profile %q: the config defines no profiles", name)
+	}
+	return nil, fmt.Errorf("unknown profile %q (defined: %s)", name, strings.Join(names, ", "))
+}
+
+// EnvLayer is one source of variables.
+type EnvLayer struct {
+	Source string // e.g. "config", "profile staging", "env file .env.staging"
+	Env    map[string]string
+}
+
+// EnvLayers returns the variables of a run from the workspace config (nil if
+// none), its profile, env files and command-line values, lowest precedence
+// first. Empty layers are left out.
+func EnvLayers(cfg *Config, profile string, envFiles []string, cliEnv map[string]string) ([]EnvLayer, error) {
+	var layers []EnvLayer
+	add := func(source string, env map[string]string) {
+		if len(env) > 0 {
+			layers = append(layers, EnvLayer{Source: source, Env: env})
+		}
+	}
+
+	if cfg != nil {
+		add("config", cfg.Env)
+		p, err := cfg.Profile(profile)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			add("profile "+profile, p.Env)
+		}
+	} else if profile != "" {
+		return nil, fmt.Errorf("profile %q needs a workspace config (--config)", profile)
+	}
+
+	for _, path := range envFiles {
+		env, err := LoadEnvFile(path)
+		if err != nil {
+			return nil, err
+		}
+		add("env file "+path, env)
+	}
+
+	add("command line", cliEnv)
+	return layers, nil
+}
+
+// MergeEnv merges layers into one map, later layers overriding earlier ones.
+func MergeEnv(layers []EnvLayer) map[string]string {
+	env := make(map[string]string)
+	for _, l := range layers {
+		for k, v := range l.Env {
+			env[k] = v
+		}
+	}
+	return env
+}
+
+// envFileKey matches a valid variable name in an env file.
+var envFileKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// LoadEnvFile reads variables from a dotenv-style file: KEY=VALUE lines,
+// optionally prefixed with "export", with blank lines and # comments
+// ignored. Values may be single-quoted (taken literally) or double-quoted
+// (\n, \t, \" and \\ are unescaped); unquoted values end at " #".
+func LoadEnvFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path) //#nosec G304 -- user-provided env file
+	if err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+
+	env := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !envFileKey.MatchString(key) {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, n)
+		}
+		value, err := envFileValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		env[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+	return env, nil
+}
+
+// envFileValue unquotes the value part of an env file line.
+func envFileValue(v string) (string, error) {
+	if v == "" {
+		return "", nil
+	}
+	switch quote := v[0]; quote {
+	case '\'', '"':
+		end := closingQuote(v, quote)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated %c quote", quote)
+		}
+		if rest := strings.TrimSpace(v[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected %q after quoted value", rest)
+		}
+		if quote == '\'' {
+			return v[1:end], nil
+		}
+		return strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`, `\\`, `\`).Replace(v[1:end]), nil
+	}
+	if i := strings.Index(v, " #"); i >= 0 {
+		v = strings.TrimSpace(v[:i])
+	}
+	return v, nil
+}
+
+// closingQuote returns the index of the quote ending the value that starts
+// with it, skipping backslash escapes inside double quotes, or -1.
+func closingQuote(v string, quote byte) int {
+	for i := 1; i < len(v); i++ {
+		switch {
+		case v[i] == '\\' && quote == '"':
+			i++
+		case v[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
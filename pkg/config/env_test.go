@@ -0,0 +1,140 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeEnvFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	path := writeEnvFile(t, `
+# staging
+BASE_URL=https://staging.example.com
+export USER = alice
+PASSWORD='p@ss #1'
+GREETING="hello\n\"world\"" # comment
+EMPTY=
+URL_WITH_HASH=https://example.com/#anchor
+TRAILING=value # comment
+`)
+
+	env, err := LoadEnvFile(path)
+	if err != nil {
+		t.Fatalf("LoadEnvFile() error = %v", err)
+	}
+	want := map[string]string{
+		"BASE_URL":      "https://staging.example.com",
+		"USER":          "alice",
+		"PASSWORD":      "p@ss #1",
+		"GREETING":      "hello\n\"world\"",
+		"EMPTY":         "",
+		"URL_WITH_HASH": "https://example.com/#anchor",
+		"TRAILING":      "value",
+	}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("LoadEnvFile() = %q, want %q", env, want)
+	}
+}
+
+func TestLoadEnvFile_Errors(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"USER=alice\nnot a variable\n", ":2: expected KEY=VALUE"},
+		{"1USER=alice\n", ":1: expected KEY=VALUE"},
+		{`TOKEN="abc`, `:1: unterminated " quote`},
+		{`TOKEN='abc' def`, `:1: unexpected "def" after quoted value`},
+	}
+	for _, tt := range tests {
+		_, err := LoadEnvFile(writeEnvFile(t, tt.content))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("LoadEnvFile(%q) error = %v, want %q", tt.content, err, tt.want)
+		}
+	}
+
+	if _, err := LoadEnvFile(filepath.Join(t.TempDir(), "missing.env")); err == nil {
+		t.Error("LoadEnvFile() of a missing file should fail")
+	}
+}
+
+func TestEnvLayers_Precedence(t *testing.T) {
+	cfg := &Config{
+		Env: map[string]string{"USER": "config", "BASE_URL": "config", "REGION": "config"},
+		Profiles: map[string]Profile{
+			"staging": {AppID: "com.example.staging", Env: map[string]string{"BASE_URL": "profile", "USER": "profile"}},
+			"prod":    {},
+		},
+	}
+	envFile := writeEnvFile(t, "USER=file\n")
+
+	layers, err := EnvLayers(cfg, "staging", []string{envFile}, map[string]string{"DEBUG": "cli"})
+	if err != nil {
+		t.Fatalf("EnvLayers() error = %v", err)
+	}
+	var sources []string
+	for _, l := range layers {
+		sources = append(sources, l.Source)
+	}
+	if want := []string{"config", "profile staging", "env file " + envFile, "command line"}; !reflect.DeepEqual(sources, want) {
+		t.Errorf("sources = %q, want %q", sources, want)
+	}
+
+	want := map[string]string{"USER": "file", "BASE_URL": "profile", "REGION": "config", "DEBUG": "cli"}
+	if env := MergeEnv(layers); !reflect.DeepEqual(env, want) {
+		t.Errorf("MergeEnv() = %v, want %v", env, want)
+	}
+}
+
+func TestEnvLayers_Profiles(t *testing.T) {
+	cfg := &Config{Profiles: map[string]Profile{"staging": {}, "prod": {}}}
+	if _, err := EnvLayers(cfg, "qa", nil, nil); err == nil || !strings.Contains(err.Error(), "defined: prod, staging") {
+		t.Errorf("unknown profile error = %v", err)
+	}
+	if _, err := EnvLayers(&Config{}, "qa", nil, nil); err == nil || !strings.Contains(err.Error(), "no profiles") {
+		t.Errorf("no profiles error = %v", err)
+	}
+	if _, err := EnvLayers(nil, "qa", nil, nil); err == nil || !strings.Contains(err.Error(), "--config") {
+		t.Errorf("profile without config error = %v", err)
+	}
+	if layers, err := EnvLayers(nil, "", nil, nil); err != nil || len(layers) != 0 {
+		t.Errorf("EnvLayers() = %v, %v, want no layers", layers, err)
+	}
+}
+
+func TestLoad_Profiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `
+appId: com.example
+profiles:
+  staging:
+    appId: com.example.staging
+    env:
+      BASE_URL: https://staging.example.com
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := cfg.Profile("staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.AppID != "com.example.staging" || p.Env["BASE_URL"] != "https://staging.example.com" {
+		t.Errorf("profile = %+v", p)
+	}
+}
@@ -124,6 +124,9 @@ type PlatformInfo struct {
 	DeviceName   string `json:"deviceName"`             // e.g., "iPhone 15 Pro", "Pixel 8"
 	DeviceID     string `json:"deviceId"`               // Unique device identifier
 	IsSimulator  bool   `json:"isSimulator"`            // Simulator/emulator vs real device
+	OSRelease    string `json:"osRelease,omitempty"`    // User-facing OS version when OSVersion is an API level (Android "14" for 34)
+	FormFactor   string `json:"formFactor,omitempty"`   // phone or tablet ("" = unknown)
+	Locale       string `json:"locale,omitempty"`       // e.g. "en_US" ("" = unknown)
 	ScreenWidth  int    `json:"screenWidth,omitempty"`  // Screen width in pixels
 	ScreenHeight int    `json:"screenHeight,omitempty"` // Screen height in pixels
 	AppID        string `json:"appId,omitempty"`        // Bundle ID / Package name
@@ -0,0 +1,50 @@
+package core
+
+import "github.com/devicelab-dev/maestro-runner/pkg/flow"
+
+// Inspector is implemented by drivers that support the interactive element
+// inspector (maestro-runner inspect). It is optional: callers should
+// type-assert a Driver to Inspector.
+type Inspector interface {
+	// Inspect captures the current UI hierarchy.
+	Inspect() (InspectSnapshot, error)
+}
+
+// InspectSnapshot is a UI hierarchy captured at one point in time.
+// Selectors are resolved against the captured elements with the driver's own
+// matching logic, so results agree with what a flow step would do.
+type InspectSnapshot interface {
+	// Elements returns all elements in document order (parents before children).
+	Elements() []InspectElement
+
+	// Match resolves a selector against the snapshot.
+	Match(sel flow.Selector) (*InspectMatch, error)
+}
+
+// InspectElement is a driver-neutral view of one element in the hierarchy.
+type InspectElement struct {
+	Index              int    `json:"index"`
+	Parent             int    `json:"parent"` // Index of the parent element, -1 for roots
+	Depth              int    `json:"depth"`
+	ID                 string `json:"id,omitempty"`   // resource-id (Android) or accessibility identifier (iOS)
+	Text               string `json:"text,omitempty"` // Visible text or label
+	AccessibilityLabel string `json:"accessibilityLabel,omitempty"`
+	HintText           string `json:"hintText,omitempty"`
+	Class              string `json:"class,omitempty"`
+	Bounds             Bounds `json:"bounds"`
+	Clickable          bool   `json:"clickable"`
+	Enabled            bool   `json:"enabled"`
+	Selected           bool   `json:"selected,omitempty"`
+	Focused            bool   `json:"focused,omitempty"`
+	Visible            bool   `json:"visible"`
+}
+
+// InspectMatch is the result of resolving a selector against a snapshot.
+type InspectMatch struct {
+	// Matches holds the indices of all elements the selector matches.
+	Matches []int `json:"matches"`
+	// Target is the index of the element a step would act on, -1 if none.
+	Target int `json:"target"`
+	// TapBounds is where a tap would land (the clickable ancestor of Target, if any).
+	TapBounds *Bounds `json:"tapBounds,omitempty"`
+}
@@ -0,0 +1,318 @@
+// Package debugger implements the interactive step debugger used by
+// `maestro-runner test --debug`.
+package debugger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/core"
+	"github.com/devicelab-dev/maestro-runner/pkg/executor"
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/devicelab-dev/maestro-runner/pkg/secrets"
+)
+
+const helpText = `Commands:
+  n, next              run this step and pause before the next one
+  c, continue          run until a breakpoint or a failure
+  s, skip              skip this step (after a failure: accept it and go on)
+  r, retry             run the failed step again
+  e, eval <js>         evaluate JavaScript in the flow's engine
+  run <step>           run an ad hoc step, e.g. run tapOn: "Login"
+  screenshot [file]    save a screenshot (default screenshot.png)
+  hierarchy            print the UI hierarchy
+  b, break <where>     add a breakpoint: <line>, <file>:<line> or <label>
+  delete <where|all>   remove a breakpoint
+  breakpoints          list breakpoints
+  q, quit              stop the flow
+  h, help              show this help
+`
+
+// REPL is an executor.Debugger that prompts for commands on a reader.
+type REPL struct {
+	in  *bufio.Scanner
+	out io.Writer
+
+	breakpoints map[string]bool
+	running     bool // true after "continue": only stop at breakpoints and failures
+}
+
+// New creates a debugger that reads commands from in and writes to out,
+// with secret values redacted. It pauses before the first step.
+func New(in io.Reader, out io.Writer) *REPL {
+	return &REPL{
+		in:          bufio.NewScanner(in),
+		out:         secrets.Writer(out),
+		breakpoints: make(map[string]bool),
+	}
+}
+
+// Break adds a breakpoint: a line number, file:line, or a step label.
+func (r *REPL) Break(where string) {
+	r.breakpoints[strings.TrimSpace(where)] = true
+}
+
+// BeforeStep implements executor.Debugger.
+func (r *REPL) BeforeStep(s *executor.DebugSession, idx int, step flow.Step) executor.DebugAction {
+	if r.running && !r.atBreakpoint(s.Flow(), idx, step) {
+		return executor.DebugRun
+	}
+	r.running = false
+
+	fmt.Fprintf(r.out, "\n  ▶ %s\n", r.location(s.Flow(), idx))
+	fmt.Fprintf(r.out, "    %s\n", step.Describe())
+
+	for {
+		cmd, arg, ok := r.prompt()
+		if !ok {
+			return executor.DebugRun
+		}
+		switch cmd {
+		case "n", "next":
+			return executor.DebugRun
+		case "c", "continue":
+			r.running = true
+			return executor.DebugRun
+		case "s", "skip":
+			return executor.DebugSkip
+		case "q", "quit":
+			return executor.DebugAbort
+		case "r", "retry":
+			fmt.Fprintln(r.out, "  nothing to retry: the step has not run yet (use next)")
+		default:
+			r.command(s, cmd, arg)
+		}
+	}
+}
+
+// OnFailure implements executor.Debugger.
+func (r *REPL) OnFailure(s *executor.DebugSession, idx int, step flow.Step, errMsg string) executor.DebugAction {
+	r.running = false
+
+	fmt.Fprintf(r.out, "\n  ✗ %s failed\n", r.location(s.Flow(), idx))
+	fmt.Fprintf(r.out, "    %s\n", step.Describe())
+	if errMsg != "" {
+		fmt.Fprintf(r.out, "    %s\n", errMsg)
+	}
+
+	for {
+		cmd, arg, ok := r.prompt()
+		if !ok {
+			return executor.DebugAbort
+		}
+		switch cmd {
+		case "r", "retry":
+			return executor.DebugRun
+		case "n", "next", "s", "skip":
+			return executor.DebugSkip
+		case "c", "continue":
+			r.running = true
+			return executor.DebugSkip
+		case "q", "quit":
+			return executor.DebugAbort
+		default:
+			r.command(s, cmd, arg)
+		}
+	}
+}
+
+// prompt reads the next non-empty command. It returns false when input
+// is exhausted; the debugger then stops pausing.
+func (r *REPL) prompt() (cmd, arg string, ok bool) {
+	for {
+		fmt.Fprint(r.out, "(debug) ")
+		if !r.in.Scan() {
+			fmt.Fprintln(r.out)
+			r.running = true
+			r.breakpoints = map[string]bool{}
+			return "", "", false
+		}
+		line := strings.TrimSpace(r.in.Text())
+		if line == "" {
+			continue
+		}
+		cmd, arg, _ = strings.Cut(line, " ")
+		return cmd, strings.TrimSpace(arg), true
+	}
+}
+
+// command runs a command that inspects or changes state without
+// resuming the flow.
+func (r *REPL) command(s *executor.DebugSession, cmd, arg string) {
+	switch cmd {
+	case "e", "eval":
+		if arg == "" {
+			fmt.Fprintln(r.out, "  usage: eval <js>")
+			return
+		}
+		result, err := s.Eval(arg)
+		if err != nil {
+			fmt.Fprintf(r.out, "  error: %v\n", err)
+			return
+		}
+		fmt.Fprintf(r.out, "  %s\n", result)
+	case "run":
+		r.runStep(s, arg)
+	case "screenshot":
+		r.screenshot(s.Driver(), arg)
+	case "hierarchy":
+		r.hierarchy(s.Driver())
+	case "b", "break":
+		if arg == "" {
+			fmt.Fprintln(r.out, "  usage: break <line|file:line|label>")
+			return
+		}
+		r.Break(arg)
+		fmt.Fprintf(r.out, "  breakpoint at %s\n", arg)
+	case "delete":
+		if arg == "all" {
+			r.breakpoints = map[string]bool{}
+			return
+		}
+		if !r.breakpoints[arg] {
+			fmt.Fprintf(r.out, "  no breakpoint at %s\n", arg)
+			return
+		}
+		delete(r.breakpoints, arg)
+	case "breakpoints":
+		if len(r.breakpoints) == 0 {
+			fmt.Fprintln(r.out, "  no breakpoints")
+			return
+		}
+		keys := make([]string, 0, len(r.breakpoints))
+		for k := range r.breakpoints {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(r.out, "  %s\n", k)
+		}
+	case "h", "help":
+		fmt.Fprint(r.out, helpText)
+	default:
+		fmt.Fprintf(r.out, "  unknown command %q (type help)\n", cmd)
+	}
+}
+
+// runStep parses a single YAML step and runs it in the flow's context.
+func (r *REPL) runStep(s *executor.DebugSession, text string) {
+	if text == "" {
+		fmt.Fprintln(r.out, "  usage: run <step>")
+		return
+	}
+	f, err := flow.Parse([]byte("- "+text+"\n"), s.Flow().SourcePath)
+	if err != nil {
+		fmt.Fprintf(r.out, "  error: %v\n", err)
+		return
+	}
+	for _, step := range f.Steps {
+		result := s.RunStep(step)
+		if result.Success {
+			fmt.Fprintf(r.out, "  ✓ %s\n", step.Describe())
+			continue
+		}
+		fmt.Fprintf(r.out, "  ✗ %s: %v\n", step.Describe(), result.Error)
+		return
+	}
+}
+
+func (r *REPL) screenshot(driver core.Driver, path string) {
+	if path == "" {
+		path = "screenshot.png"
+	}
+	data, err := driver.Screenshot()
+	if err != nil {
+		fmt.Fprintf(r.out, "  error: %v\n", err)
+		return
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		fmt.Fprintf(r.out, "  error: %v\n", err)
+		return
+	}
+	fmt.Fprintf(r.out, "  saved %s\n", path)
+}
+
+// hierarchy prints visible elements one per line when the driver supports
+// inspection, and the raw hierarchy otherwise.
+func (r *REPL) hierarchy(driver core.Driver) {
+	insp, ok := driver.(core.Inspector)
+	if !ok {
+		data, err := driver.Hierarchy()
+		if err != nil {
+			fmt.Fprintf(r.out, "  error: %v\n", err)
+			return
+		}
+		fmt.Fprintln(r.out, string(data))
+		return
+	}
+
+	snap, err := insp.Inspect()
+	if err != nil {
+		fmt.Fprintf(r.out, "  error: %v\n", err)
+		return
+	}
+	for _, e := range snap.Elements() {
+		if !e.Visible {
+			continue
+		}
+		line := fmt.Sprintf("%s%s", strings.Repeat("  ", e.Depth+1), shortClass(e.Class))
+		if e.ID != "" {
+			line += fmt.Sprintf(" id=%q", e.ID)
+		}
+		if e.Text != "" {
+			line += fmt.Sprintf(" text=%q", e.Text)
+		}
+		line += fmt.Sprintf(" [%d,%d %dx%d]", e.Bounds.X, e.Bounds.Y, e.Bounds.Width, e.Bounds.Height)
+		fmt.Fprintln(r.out, line)
+	}
+}
+
+func shortClass(class string) string {
+	if i := strings.LastIndex(class, "."); i >= 0 {
+		return class[i+1:]
+	}
+	return class
+}
+
+// atBreakpoint reports whether a step matches a line, file:line or label
+// breakpoint.
+func (r *REPL) atBreakpoint(f flow.Flow, idx int, step flow.Step) bool {
+	if len(r.breakpoints) == 0 {
+		return false
+	}
+	if label := step.Label(); label != "" && r.breakpoints[label] {
+		return true
+	}
+	line := r.stepLine(f, idx)
+	if line == 0 {
+		return false
+	}
+	return r.breakpoints[strconv.Itoa(line)] ||
+		r.breakpoints[fmt.Sprintf("%s:%d", filepath.Base(f.SourcePath), line)] ||
+		r.breakpoints[fmt.Sprintf("%s:%d", f.SourcePath, line)]
+}
+
+// stepLine returns the source line of a top-level step, 0 if unknown.
+func (r *REPL) stepLine(f flow.Flow, idx int) int {
+	if idx < 0 || idx >= len(f.Steps) {
+		return 0
+	}
+	return f.Steps[idx].Position().Line
+}
+
+func (r *REPL) location(f flow.Flow, idx int) string {
+	loc := fmt.Sprintf("step %d/%d", idx+1, len(f.Steps))
+	if f.SourcePath == "" {
+		return loc
+	}
+	if line := r.stepLine(f, idx); line > 0 {
+		return fmt.Sprintf("%s  %s:%d", loc, filepath.Base(f.SourcePath), line)
+	}
+	return fmt.Sprintf("%s  %s", loc, filepath.Base(f.SourcePath))
+}
@@ -0,0 +1,119 @@
+package debugger
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/driver/mock"
+	"github.com/devicelab-dev/maestro-runner/pkg/executor"
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/devicelab-dev/maestro-runner/pkg/report"
+)
+
+const testFlow = `appId: com.example
+---
+- launchApp
+- tapOn: "Login"
+- inputText:
+    text: "hello"
+    label: Type greeting
+- back
+`
+
+// run executes testFlow with the REPL reading commands from input.
+func run(t *testing.T, driver *mock.Driver, input string, breaks ...string) (executor.FlowResult, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "login.yaml")
+	if err := os.WriteFile(path, []byte(testFlow), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := flow.ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	repl := New(strings.NewReader(input), &out)
+	for _, b := range breaks {
+		repl.Break(b)
+	}
+	runner := executor.New(driver, executor.RunnerConfig{
+		OutputDir: t.TempDir(),
+		Artifacts: executor.ArtifactNever,
+		Debugger:  repl,
+	})
+	result, err := runner.Run(context.Background(), []flow.Flow{*f})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	return result.FlowResults[0], out.String()
+}
+
+func TestREPL_StepSkipAndContinue(t *testing.T) {
+	driver := mock.New(mock.Config{})
+	result, out := run(t, driver, "next\nskip\neval 1 + 2\nhelp\ncontinue\n")
+
+	if result.Status != report.StatusPassed {
+		t.Errorf("Status = %v, want passed", result.Status)
+	}
+	if result.StepsPassed != 3 || result.StepsSkipped != 1 {
+		t.Errorf("passed=%d skipped=%d, want 3/1", result.StepsPassed, result.StepsSkipped)
+	}
+	if !strings.Contains(out, "step 1/4  login.yaml:3") || !strings.Contains(out, "step 2/4  login.yaml:4") {
+		t.Errorf("missing step locations:\n%s", out)
+	}
+	if !strings.Contains(out, "  3\n") {
+		t.Errorf("eval result missing:\n%s", out)
+	}
+	if strings.Contains(out, "step 4/4") {
+		t.Errorf("should not pause after continue:\n%s", out)
+	}
+}
+
+func TestREPL_Breakpoints(t *testing.T) {
+	driver := mock.New(mock.Config{})
+	_, out := run(t, driver, "continue\ncontinue\ncontinue\n", "login.yaml:4", "Type greeting")
+
+	if strings.Count(out, "(debug) ") != 3 {
+		t.Errorf("expected 3 prompts (start, line 4, label):\n%s", out)
+	}
+	if !strings.Contains(out, "login.yaml:4") || !strings.Contains(out, "login.yaml:5") {
+		t.Errorf("did not stop at breakpoints:\n%s", out)
+	}
+	if strings.Contains(out, "login.yaml:8") {
+		t.Errorf("stopped without a breakpoint:\n%s", out)
+	}
+}
+
+func TestREPL_FailureRetryAndAdHocStep(t *testing.T) {
+	// Second driver call fails: the tapOn. The ad hoc step and the retry pass.
+	driver := mock.New(mock.Config{FailOnStep: 2})
+	result, out := run(t, driver, "continue\nrun back\nretry\n")
+
+	if result.Status != report.StatusPassed {
+		t.Errorf("Status = %v, want passed after retry:\n%s", result.Status, out)
+	}
+	if !strings.Contains(out, "✗ step 2/4  login.yaml:4 failed") {
+		t.Errorf("missing failure prompt:\n%s", out)
+	}
+	if !strings.Contains(out, "✓ back") {
+		t.Errorf("ad hoc step not run:\n%s", out)
+	}
+}
+
+func TestREPL_QuitAndEOF(t *testing.T) {
+	result, _ := run(t, mock.New(mock.Config{}), "quit\n")
+	if result.Status != report.StatusFailed || result.StepsSkipped != 4 {
+		t.Errorf("quit: status=%v skipped=%d", result.Status, result.StepsSkipped)
+	}
+
+	// Input closed: run to the end without pausing
+	result, _ = run(t, mock.New(mock.Config{}), "")
+	if result.Status != report.StatusPassed || result.StepsPassed != 4 {
+		t.Errorf("EOF: status=%v passed=%d", result.Status, result.StepsPassed)
+	}
+}
@@ -3,7 +3,9 @@ package device
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -24,8 +26,11 @@ type DeviceInfo struct {
 	Serial     string
 	Model      string
 	SDK        string
+	Release    string // Android version, e.g. "14"
 	Brand      string
 	IsEmulator bool
+	IsTablet   bool
+	Locale     string // e.g. "en_US"
 }
 
 // New creates an AndroidDevice for the given serial.
@@ -214,6 +219,41 @@ func (d *AndroidDevice) Shell(cmd string) (string, error) {
 	return d.adb("shell", cmd)
 }
 
+// ShellStream starts a long-running shell command on the device and returns
+// its output as a stream. The command stops when ctx is cancelled or the
+// stream is closed.
+func (d *AndroidDevice) ShellStream(ctx context.Context, cmd string) (io.ReadCloser, error) {
+	args := make([]string, 0, 4)
+	if d.serial != "" {
+		args = append(args, "-s", d.serial)
+	}
+	args = append(args, "shell", cmd)
+
+	c := exec.CommandContext(ctx, d.adbPath, args...)
+	stdout, err := c.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("adb shell %s: %w", cmd, err)
+	}
+	if err := c.Start(); err != nil {
+		return nil, fmt.Errorf("adb shell %s: %w", cmd, err)
+	}
+	return &shellStream{ReadCloser: stdout, cmd: c}, nil
+}
+
+// shellStream stops the adb process when closed.
+type shellStream struct {
+	io.ReadCloser
+	cmd *exec.Cmd
+}
+
+func (s *shellStream) Close() error {
+	if s.cmd.Process != nil {
+		_ = s.cmd.Process.Kill() // best-effort, the process may have exited already
+	}
+	_ = s.cmd.Wait() // reaps the process; a kill error is expected here
+	return nil
+}
+
 // Install installs an APK on the device.
 func (d *AndroidDevice) Install(apkPath string) error {
 	_, err := d.adb("install", "-r", "-g", apkPath)
@@ -318,6 +358,18 @@ func (d *AndroidDevice) Info() (DeviceInfo, error) {
 	if brand, err := d.Shell("getprop ro.product.brand"); err == nil {
 		info.Brand = strings.TrimSpace(brand)
 	}
+	if release, err := d.Shell("getprop ro.build.version.release"); err == nil {
+		info.Release = strings.TrimSpace(release)
+	}
+	if chars, err := d.Shell("getprop ro.build.characteristics"); err == nil {
+		info.IsTablet = isTabletCharacteristics(chars)
+	}
+	for _, prop := range []string{"persist.sys.locale", "ro.product.locale"} {
+		if locale, err := d.Shell("getprop " + prop); err == nil && strings.TrimSpace(locale) != "" {
+			info.Locale = normalizeLocale(locale)
+			break
+		}
+	}
 
 	// Check if emulator
 	chars, _ := d.Shell("getprop ro.kernel.qemu")
@@ -326,6 +378,22 @@ func (d *AndroidDevice) Info() (DeviceInfo, error) {
 	return info, nil
 }
 
+// isTabletCharacteristics reports whether ro.build.characteristics (e.g.
+// "tablet,nosdcard") marks a tablet.
+func isTabletCharacteristics(chars string) bool {
+	for _, c := range strings.Split(strings.TrimSpace(chars), ",") {
+		if c == "tablet" {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeLocale turns a locale property such as "en-US" into "en_US".
+func normalizeLocale(locale string) string {
+	return strings.ReplaceAll(strings.TrimSpace(locale), "-", "_")
+}
+
 // adb executes an ADB command.
 func (d *AndroidDevice) adb(args ...string) (string, error) {
 	cmdArgs := make([]string, 0, len(args)+2)
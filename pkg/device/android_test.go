@@ -126,6 +126,24 @@ func TestAndroidDevice_Info(t *testing.T) {
 	t.Logf("Device: %s %s (SDK %s)", info.Brand, info.Model, info.SDK)
 }
 
+func TestIsTabletCharacteristics(t *testing.T) {
+	tests := map[string]bool{
+		"tablet\n":        true,
+		"tablet,nosdcard": true,
+		"default":         false,
+		"emulator,phone":  false,
+		"":                false,
+	}
+	for chars, want := range tests {
+		if got := isTabletCharacteristics(chars); got != want {
+			t.Errorf("isTabletCharacteristics(%q) = %v, want %v", chars, got, want)
+		}
+	}
+	if got := normalizeLocale("de-DE\n"); got != "de_DE" {
+		t.Errorf("normalizeLocale() = %q", got)
+	}
+}
+
 func TestAndroidDevice_IsInstalled(t *testing.T) {
 	skipIfNoDevice(t)
 
@@ -12,6 +12,8 @@ import (
 	"time"
 
 	"github.com/devicelab-dev/maestro-runner/pkg/logger"
+	"github.com/devicelab-dev/maestro-runner/pkg/secrets"
+	"github.com/devicelab-dev/maestro-runner/pkg/telemetry"
 )
 
 // W3C WebDriver element identifier key (standard constant)
@@ -25,6 +27,8 @@ type Client struct {
 	platform  string // ios, android
 	screenW   int
 	screenH   int
+	deviceID  string              // UDID of the session's device, recorded with telemetry HTTP calls
+	recorder  *telemetry.Recorder // Records HTTP calls for trace export (nil = off)
 }
 
 // NewClient creates a new Appium client.
@@ -79,6 +83,10 @@ func (c *Client) Connect(capabilities map[string]interface{}) error {
 		if platform, ok := caps["platformName"].(string); ok {
 			c.platform = strings.ToLower(platform)
 		}
+		c.deviceID = sessionDeviceID(caps)
+	}
+	if c.deviceID == "" {
+		c.deviceID, _ = capabilities["appium:udid"].(string)
 	}
 
 	// Get screen size
@@ -158,6 +166,28 @@ func (c *Client) Platform() string {
 	return c.platform
 }
 
+// DeviceID returns the UDID of the session's device, or "" if unknown.
+func (c *Client) DeviceID() string {
+	return c.deviceID
+}
+
+// SetRecorder records the client's HTTP calls in r, the run's call log for
+// trace export.
+func (c *Client) SetRecorder(r *telemetry.Recorder) {
+	c.recorder = r
+}
+
+// sessionDeviceID returns the device UDID from the capabilities of a new
+// session: UiAutomator2 reports it as deviceUDID, XCUITest as udid.
+func sessionDeviceID(caps map[string]interface{}) string {
+	for _, key := range []string{"deviceUDID", "udid", "appium:udid"} {
+		if id, ok := caps[key].(string); ok && id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
 // ScreenSize returns the screen dimensions.
 func (c *Client) ScreenSize() (int, int) {
 	return c.screenW, c.screenH
@@ -652,7 +682,7 @@ func (c *Client) request(method, path string, body interface{}) (map[string]inte
 			return nil, err
 		}
 		bodyReader = bytes.NewReader(jsonBody)
-		bodyStr = string(jsonBody)
+		bodyStr = secrets.Redact(string(jsonBody)) // Before truncating, which could cut a secret short
 		if len(bodyStr) > 100 {
 			bodyStr = bodyStr[:100] + "..."
 		}
@@ -669,6 +699,7 @@ func (c *Client) request(method, path string, body interface{}) (map[string]inte
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := c.client.Do(req)
+	c.recorder.RecordResponse(c.deviceID, c.serverURL, method, path, start, resp, err)
 	duration := time.Since(start).Milliseconds()
 
 	if err != nil {
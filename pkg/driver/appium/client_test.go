@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/telemetry"
 )
 
 // writeJSON encodes data as JSON to the response writer.
@@ -65,6 +67,54 @@ func TestClient_Connect(t *testing.T) {
 	}
 }
 
+func TestClient_ConnectRecordsDeviceID(t *testing.T) {
+	tests := []struct {
+		name        string
+		sessionCaps map[string]interface{}
+		requested   map[string]interface{}
+	}{
+		{"reported by UiAutomator2", map[string]interface{}{"platformName": "Android", "deviceUDID": "emulator-5554"}, map[string]interface{}{}},
+		{"reported by XCUITest", map[string]interface{}{"platformName": "iOS", "udid": "emulator-5554"}, map[string]interface{}{}},
+		{"requested only", map[string]interface{}{"platformName": "Android"}, map[string]interface{}{"appium:udid": "emulator-5554"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/session" {
+					writeJSON(w, map[string]interface{}{
+						"value": map[string]interface{}{"sessionId": "s1", "capabilities": tc.sessionCaps},
+					})
+					return
+				}
+				writeJSON(w, map[string]interface{}{"value": nil})
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL)
+			recorder := telemetry.NewRecorder()
+			client.SetRecorder(recorder)
+			if err := client.Connect(tc.requested); err != nil {
+				t.Fatalf("Connect failed: %v", err)
+			}
+			if client.DeviceID() != "emulator-5554" {
+				t.Errorf("DeviceID() = %q", client.DeviceID())
+			}
+
+			if _, err := client.get(client.sessionPath() + "/source"); err != nil {
+				t.Fatalf("get failed: %v", err)
+			}
+			calls := recorder.Calls()
+			if len(calls) == 0 || calls[0].Device != "" {
+				t.Fatalf("session creation should be recorded without a device: %+v", calls)
+			}
+			if last := calls[len(calls)-1]; last.Device != "emulator-5554" || last.Path != "/session/s1/source" {
+				t.Errorf("last call = %+v", last)
+			}
+		})
+	}
+}
+
 func TestClient_Disconnect(t *testing.T) {
 	deleteCalled := false
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -631,33 +631,6 @@ func (d *Driver) killApp(step *flow.KillAppStep) *core.CommandResult {
 	return successResult(fmt.Sprintf("Killed app: %s", appID), nil)
 }
 
-func (d *Driver) inputRandom(step *flow.InputRandomStep) *core.CommandResult {
-	length := step.Length
-	if length <= 0 {
-		length = 10
-	}
-
-	var text string
-	switch strings.ToUpper(step.DataType) {
-	case "EMAIL":
-		text = randomEmail()
-	case "NUMBER":
-		text = randomNumber(length)
-	case "PERSON_NAME":
-		text = randomPersonName()
-	default:
-		text = randomString(length)
-	}
-
-	if err := d.client.SendKeys(text); err != nil {
-		return errorResult(err, "Failed to input random text")
-	}
-
-	result := successResult(fmt.Sprintf("Input random %s: %s", step.DataType, text), nil)
-	result.Data = text
-	return result
-}
-
 func (d *Driver) takeScreenshot(step *flow.TakeScreenshotStep) *core.CommandResult {
 	data, err := d.client.Screenshot()
 	if err != nil {
@@ -671,38 +644,6 @@ func (d *Driver) takeScreenshot(step *flow.TakeScreenshotStep) *core.CommandResu
 	}
 }
 
-// Random data generators
-
-func randomString(length int) string {
-	const chars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, length)
-	for i := range b {
-		b[i] = chars[time.Now().UnixNano()%int64(len(chars))]
-		time.Sleep(time.Nanosecond)
-	}
-	return string(b)
-}
-
-func randomEmail() string {
-	return randomString(8) + "@example.com"
-}
-
-func randomNumber(length int) string {
-	const digits = "0123456789"
-	b := make([]byte, length)
-	for i := range b {
-		b[i] = digits[time.Now().UnixNano()%10]
-		time.Sleep(time.Nanosecond)
-	}
-	return string(b)
-}
-
-func randomPersonName() string {
-	firstNames := []string{"John", "Jane", "Michael", "Emily", "David"}
-	lastNames := []string{"Smith", "Johnson", "Williams", "Brown", "Jones"}
-	return firstNames[time.Now().UnixNano()%int64(len(firstNames))] + " " + lastNames[time.Now().UnixNano()%int64(len(lastNames))]
-}
-
 // Helpers
 
 func parsePercentageCoords(coord string) (float64, float64, error) {
@@ -2,13 +2,14 @@ package appium
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
-	"unicode"
 
+	"github.com/devicelab-dev/maestro-runner/pkg/faker"
 	"github.com/devicelab-dev/maestro-runner/pkg/flow"
 )
 
@@ -16,101 +17,6 @@ import (
 // Pure function tests
 // =============================================================================
 
-func TestRandomString(t *testing.T) {
-	tests := []struct {
-		name   string
-		length int
-	}{
-		{"length 1", 1},
-		{"length 5", 5},
-		{"length 10", 10},
-		{"length 20", 20},
-	}
-
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			result := randomString(tc.length)
-			if len(result) != tc.length {
-				t.Fatalf("expected length %d, got %d", tc.length, len(result))
-			}
-			for _, c := range result {
-				if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
-					t.Fatalf("unexpected character %q in random string", c)
-				}
-			}
-		})
-	}
-}
-
-func TestRandomStringUniqueness(t *testing.T) {
-	// Generate several strings and verify they are not all identical
-	results := make(map[string]bool)
-	for i := 0; i < 5; i++ {
-		results[randomString(8)] = true
-	}
-	if len(results) < 2 {
-		t.Fatalf("expected at least 2 unique strings out of 5, got %d", len(results))
-	}
-}
-
-func TestRandomEmail(t *testing.T) {
-	email := randomEmail()
-	if !strings.HasSuffix(email, "@example.com") {
-		t.Fatalf("expected email to end with @example.com, got %q", email)
-	}
-	localPart := strings.TrimSuffix(email, "@example.com")
-	if len(localPart) != 8 {
-		t.Fatalf("expected local part length 8, got %d", len(localPart))
-	}
-}
-
-func TestRandomNumber(t *testing.T) {
-	tests := []struct {
-		name   string
-		length int
-	}{
-		{"length 1", 1},
-		{"length 5", 5},
-		{"length 10", 10},
-	}
-
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			result := randomNumber(tc.length)
-			if len(result) != tc.length {
-				t.Fatalf("expected length %d, got %d", tc.length, len(result))
-			}
-			for _, c := range result {
-				if !unicode.IsDigit(c) {
-					t.Fatalf("expected only digits, got %q", c)
-				}
-			}
-		})
-	}
-}
-
-func TestRandomPersonName(t *testing.T) {
-	name := randomPersonName()
-	parts := strings.SplitN(name, " ", 2)
-	if len(parts) != 2 {
-		t.Fatalf("expected first and last name separated by space, got %q", name)
-	}
-
-	validFirstNames := map[string]bool{
-		"John": true, "Jane": true, "Michael": true, "Emily": true, "David": true,
-	}
-	validLastNames := map[string]bool{
-		"Smith": true, "Johnson": true, "Williams": true, "Brown": true, "Jones": true,
-	}
-
-	if !validFirstNames[parts[0]] {
-		t.Fatalf("unexpected first name %q", parts[0])
-	}
-	if !validLastNames[parts[1]] {
-		t.Fatalf("unexpected last name %q", parts[1])
-	}
-}
-
 func TestEscapeIOSPredicateString(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -539,84 +445,68 @@ func TestKillAppError(t *testing.T) {
 	}
 }
 
-func TestInputRandom(t *testing.T) {
-	server := mockAppiumServerForDriver()
+func TestInputTextGeneratedData(t *testing.T) {
+	var typed strings.Builder
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/actions") {
+			var body struct {
+				Actions []struct {
+					Actions []struct {
+						Type  string `json:"type"`
+						Value string `json:"value"`
+					} `json:"actions"`
+				} `json:"actions"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decode actions: %v", err)
+			}
+			for _, source := range body.Actions {
+				for _, a := range source.Actions {
+					if a.Type == "keyDown" {
+						typed.WriteString(a.Value)
+					}
+				}
+			}
+		}
+		writeJSON(w, map[string]interface{}{"value": nil})
+	}))
 	defer server.Close()
 	driver := createTestAppiumDriver(server)
 
+	gen := faker.New(42)
 	tests := []struct {
 		name     string
 		dataType string
 		length   int
+		locale   string
 	}{
-		{"email", "EMAIL", 0},
-		{"number", "NUMBER", 5},
-		{"person_name", "PERSON_NAME", 0},
-		{"default text", "", 8},
-		{"text with length", "TEXT", 12},
+		{"email", "EMAIL", 0, ""},
+		{"number", "NUMBER", 5, ""},
+		{"person_name", "PERSON_NAME", 0, "fr_FR"},
+		{"unicode address", "ADDRESS", 0, "de_DE"},
+		{"text with length", "TEXT", 12, ""},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			step := &flow.InputRandomStep{
-				DataType: tc.dataType,
-				Length:   tc.length,
+			text, err := gen.Generate(tc.dataType, tc.length, tc.locale)
+			if err != nil {
+				t.Fatalf("Generate: %v", err)
 			}
-			result := driver.inputRandom(step)
+			typed.Reset()
+			result := driver.Execute(&flow.InputTextStep{Text: text})
 
 			if !result.Success {
 				t.Fatalf("expected success, got error: %v", result.Error)
 			}
-			if result.Data == nil {
-				t.Fatalf("expected Data to be set with generated text")
+			if typed.String() != text {
+				t.Errorf("typed %q, want %q", typed.String(), text)
 			}
 		})
 	}
 }
 
-func TestInputRandomDefaultLength(t *testing.T) {
-	server := mockAppiumServerForDriver()
-	defer server.Close()
-	driver := createTestAppiumDriver(server)
-
-	// Length <= 0 should default to 10
-	step := &flow.InputRandomStep{
-		DataType: "TEXT",
-		Length:   0,
-	}
-	result := driver.inputRandom(step)
-
-	if !result.Success {
-		t.Fatalf("expected success, got error: %v", result.Error)
-	}
-}
-
-func TestInputRandomError(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		// SendKeys tries /actions first, then /appium/element/active/value
-		if strings.Contains(r.URL.Path, "/actions") || strings.Contains(r.URL.Path, "/active/value") {
-			writeJSON(w, map[string]interface{}{
-				"value": map[string]interface{}{
-					"error":   "input failed",
-					"message": "Failed to send keys",
-				},
-			})
-			return
-		}
-		writeJSON(w, map[string]interface{}{"value": nil})
-	}))
-	defer server.Close()
-	driver := createTestAppiumDriver(server)
-
-	step := &flow.InputRandomStep{DataType: "EMAIL"}
-	result := driver.inputRandom(step)
-
-	if result.Success {
-		t.Fatalf("expected failure when SendKeys fails")
-	}
-}
-
 func TestTakeScreenshot(t *testing.T) {
 	server := mockAppiumServerForDriver()
 	defer server.Close()
@@ -793,19 +683,6 @@ func TestExecuteKillApp(t *testing.T) {
 	}
 }
 
-func TestExecuteInputRandom(t *testing.T) {
-	server := mockAppiumServerForDriver()
-	defer server.Close()
-	driver := createTestAppiumDriver(server)
-
-	step := &flow.InputRandomStep{DataType: "EMAIL"}
-	result := driver.Execute(step)
-
-	if !result.Success {
-		t.Fatalf("expected success, got error: %v", result.Error)
-	}
-}
-
 func TestExecuteTakeScreenshot(t *testing.T) {
 	server := mockAppiumServerForDriver()
 	defer server.Close()
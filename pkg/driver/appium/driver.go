@@ -9,6 +9,8 @@ import (
 
 	"github.com/devicelab-dev/maestro-runner/pkg/core"
 	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/devicelab-dev/maestro-runner/pkg/metrics"
+	"github.com/devicelab-dev/maestro-runner/pkg/telemetry"
 )
 
 // DefaultFindTimeout is the default timeout for element operations.
@@ -58,6 +60,12 @@ func NewDriver(serverURL string, capabilities map[string]interface{}) (*Driver,
 	return d, nil
 }
 
+// SetRecorder records the driver's HTTP calls in r, the run's call log for
+// trace export. Calls made creating the session are not included.
+func (d *Driver) SetRecorder(r *telemetry.Recorder) {
+	d.client.SetRecorder(r)
+}
+
 // Close disconnects from Appium server.
 func (d *Driver) Close() error {
 	return d.client.Disconnect()
@@ -125,8 +133,6 @@ func (d *Driver) executeStep(step flow.Step) *core.CommandResult {
 		return d.waitUntil(s)
 	case *flow.KillAppStep:
 		return d.killApp(s)
-	case *flow.InputRandomStep:
-		return d.inputRandom(s)
 	case *flow.TakeScreenshotStep:
 		return d.takeScreenshot(s)
 	default:
@@ -164,6 +170,7 @@ func (d *Driver) GetPlatformInfo() *core.PlatformInfo {
 	w, h := d.client.ScreenSize()
 	return &core.PlatformInfo{
 		Platform:     d.platform,
+		DeviceID:     d.client.DeviceID(),
 		ScreenWidth:  w,
 		ScreenHeight: h,
 		AppID:        d.appID,
@@ -229,6 +236,7 @@ func (d *Driver) findElement(sel flow.Selector, timeout time.Duration) (*core.El
 			}
 			return nil, fmt.Errorf("element not found: %s", sel.Describe())
 		}
+		recordFindRetry()
 		time.Sleep(200 * time.Millisecond)
 	}
 }
@@ -407,6 +415,7 @@ func (d *Driver) findElementForTap(sel flow.Selector, timeout time.Duration) (*c
 		if time.Now().After(deadline) {
 			return nil, fmt.Errorf("element not found: %s", sel.Describe())
 		}
+		recordFindRetry()
 		time.Sleep(200 * time.Millisecond)
 	}
 }
@@ -492,6 +501,7 @@ func (d *Driver) findElementRelativeWithContext(ctx context.Context, sel flow.Se
 			if err == nil && info != nil {
 				return info, nil
 			}
+			recordFindRetry()
 			// HTTP round-trip is natural rate limit, no sleep needed
 		}
 	}
@@ -514,6 +524,26 @@ func (d *Driver) findElementRelativeOnce(sel flow.Selector) (*core.ElementInfo,
 }
 
 func (d *Driver) findElementRelativeWithElements(sel flow.Selector, allElements []*ParsedElement, platform string) (*core.ElementInfo, error) {
+	candidates, err := d.relativeCandidates(sel, allElements, platform)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := selectCandidate(candidates, sel.Index)
+	if selected == nil {
+		return nil, fmt.Errorf("no element selected")
+	}
+
+	// If element isn't clickable, try to find a clickable parent
+	// This handles React Native pattern where text nodes aren't clickable but containers are
+	clickableElem := GetClickableElement(selected)
+
+	return elementToInfoWithClickable(selected, clickableElem, platform), nil
+}
+
+// relativeCandidates returns all elements matching sel, including relative and
+// containsDescendants filters, with clickable elements first.
+func (d *Driver) relativeCandidates(sel flow.Selector, allElements []*ParsedElement, platform string) ([]*ParsedElement, error) {
 	// Build base selector (without relative parts)
 	baseSel := flow.Selector{
 		Text:      sel.Text,
@@ -582,37 +612,26 @@ func (d *Driver) findElementRelativeWithElements(sel flow.Selector, allElements
 		return nil, fmt.Errorf("no elements match relative criteria")
 	}
 
-	// Prioritize and select
-	candidates = SortClickableFirst(candidates)
-	if len(candidates) == 0 {
-		return nil, fmt.Errorf("no candidates after sorting")
-	}
+	// Prioritize clickable elements
+	return SortClickableFirst(candidates), nil
+}
 
-	var selected *ParsedElement
-	if sel.Index != "" {
-		idx := 0
-		if i, err := strconv.Atoi(sel.Index); err == nil {
-			if i < 0 {
-				i = len(candidates) + i
-			}
-			if i >= 0 && i < len(candidates) {
-				idx = i
-			}
-		}
-		selected = candidates[idx]
-	} else {
-		selected = DeepestMatchingElement(candidates)
+// selectCandidate picks the element a command acts on: the candidate at index
+// if specified, otherwise the deepest matching element.
+func selectCandidate(candidates []*ParsedElement, index string) *ParsedElement {
+	if index == "" {
+		return DeepestMatchingElement(candidates)
 	}
-
-	if selected == nil {
-		return nil, fmt.Errorf("no element selected")
+	idx := 0
+	if i, err := strconv.Atoi(index); err == nil {
+		if i < 0 {
+			i = len(candidates) + i
+		}
+		if i >= 0 && i < len(candidates) {
+			idx = i
+		}
 	}
-
-	// If element isn't clickable, try to find a clickable parent
-	// This handles React Native pattern where text nodes aren't clickable but containers are
-	clickableElem := GetClickableElement(selected)
-
-	return elementToInfoWithClickable(selected, clickableElem, platform), nil
+	return candidates[idx]
 }
 
 // Filter types
@@ -773,3 +792,8 @@ func errorResult(err error, msg string) *core.CommandResult {
 		Message: msg,
 	}
 }
+
+// recordFindRetry counts an element lookup attempt that missed inside a polling loop.
+func recordFindRetry() {
+	metrics.RecordFindRetry("appium")
+}
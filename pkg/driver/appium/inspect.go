@@ -0,0 +1,106 @@
+package appium
+
+import (
+	"fmt"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/core"
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+)
+
+// Inspect implements core.Inspector.
+func (d *Driver) Inspect() (core.InspectSnapshot, error) {
+	source, err := d.client.Source()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page source: %w", err)
+	}
+
+	elements, platform, err := ParsePageSource(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse page source: %w", err)
+	}
+
+	return newInspectSnapshot(d, elements, platform), nil
+}
+
+// inspectSnapshot resolves selectors against a fixed page source using the
+// same candidate filtering and selection as relative/page-source lookups.
+type inspectSnapshot struct {
+	driver   *Driver
+	elements []*ParsedElement
+	platform string
+	indices  map[*ParsedElement]int
+}
+
+func newInspectSnapshot(d *Driver, elements []*ParsedElement, platform string) *inspectSnapshot {
+	indices := make(map[*ParsedElement]int, len(elements))
+	for i, elem := range elements {
+		indices[elem] = i
+	}
+	return &inspectSnapshot{driver: d, elements: elements, platform: platform, indices: indices}
+}
+
+// Elements implements core.InspectSnapshot.
+func (s *inspectSnapshot) Elements() []core.InspectElement {
+	result := make([]core.InspectElement, len(s.elements))
+	for i, elem := range s.elements {
+		parent := -1
+		if elem.Parent != nil {
+			if p, ok := s.indices[elem.Parent]; ok {
+				parent = p
+			}
+		}
+		ie := core.InspectElement{
+			Index:     i,
+			Parent:    parent,
+			Depth:     elem.Depth,
+			Bounds:    elem.Bounds,
+			Clickable: elem.Clickable,
+			Enabled:   elem.Enabled,
+			Selected:  elem.Selected,
+			Focused:   elem.Focused,
+			Visible:   elem.Displayed,
+		}
+		if s.platform == "ios" {
+			ie.ID = elem.Name
+			ie.Text = elem.Label
+			if ie.Text == "" {
+				ie.Text = elem.Value
+			}
+			ie.AccessibilityLabel = elem.Label
+			ie.HintText = elem.PlaceholderValue
+			ie.Class = elem.Type
+		} else {
+			ie.ID = elem.ResourceID
+			ie.Text = elem.Text
+			ie.AccessibilityLabel = elem.ContentDesc
+			ie.HintText = elem.HintText
+			ie.Class = elem.ClassName
+		}
+		result[i] = ie
+	}
+	return result
+}
+
+// Match implements core.InspectSnapshot.
+func (s *inspectSnapshot) Match(sel flow.Selector) (*core.InspectMatch, error) {
+	match := &core.InspectMatch{Matches: []int{}, Target: -1}
+
+	candidates, err := s.driver.relativeCandidates(sel, s.elements, s.platform)
+	if err != nil {
+		return match, nil // No match is a valid answer, not an error
+	}
+
+	for _, elem := range candidates {
+		if i, ok := s.indices[elem]; ok {
+			match.Matches = append(match.Matches, i)
+		}
+	}
+
+	selected := selectCandidate(candidates, sel.Index)
+	if i, ok := s.indices[selected]; ok {
+		match.Target = i
+		tap := GetClickableElement(selected).Bounds
+		match.TapBounds = &tap
+	}
+	return match, nil
+}
@@ -3,7 +3,6 @@ package uiautomator2
 import (
 	"context"
 	"fmt"
-	"math/rand"
 	"strconv"
 	"strings"
 	"time"
@@ -334,42 +333,6 @@ func (d *Driver) hideKeyboard(_ *flow.HideKeyboardStep) *core.CommandResult {
 	return successResult("Keyboard hidden", nil)
 }
 
-func (d *Driver) inputRandom(step *flow.InputRandomStep) *core.CommandResult {
-	length := step.Length
-	if length <= 0 {
-		length = 10 // default
-	}
-
-	// Generate random data based on DataType
-	var text string
-	dataType := strings.ToUpper(step.DataType)
-	switch dataType {
-	case "EMAIL":
-		text = randomEmail()
-	case "NUMBER":
-		text = randomNumber(length)
-	case "PERSON_NAME":
-		text = randomPersonName()
-	default: // "TEXT" or empty
-		text = randomString(length)
-	}
-
-	// Type into focused element
-	active, err := d.client.ActiveElement()
-	if err != nil {
-		return errorResult(err, "No focused element to type into")
-	}
-	if err := active.SendKeys(text); err != nil {
-		return errorResult(err, fmt.Sprintf("Failed to input text: %v", err))
-	}
-
-	return &core.CommandResult{
-		Success: true,
-		Message: fmt.Sprintf("Entered random %s: %s", dataType, text),
-		Data:    text,
-	}
-}
-
 // ============================================================================
 // Scroll/Swipe Commands
 // ============================================================================
@@ -1593,34 +1556,3 @@ func mapKeyCode(key string) int {
 		return 0
 	}
 }
-
-func randomString(length int) string {
-	const chars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, length)
-	for i := range b {
-		b[i] = chars[rand.Intn(len(chars))]
-	}
-	return string(b)
-}
-
-func randomEmail() string {
-	user := randomString(8)
-	domains := []string{"example.com", "test.com", "mail.com"}
-	domain := domains[rand.Intn(len(domains))]
-	return user + "@" + domain
-}
-
-func randomNumber(length int) string {
-	const digits = "0123456789"
-	b := make([]byte, length)
-	for i := range b {
-		b[i] = digits[rand.Intn(len(digits))]
-	}
-	return string(b)
-}
-
-func randomPersonName() string {
-	firstNames := []string{"John", "Jane", "Michael", "Emily", "David", "Sarah", "James", "Emma", "Robert", "Olivia"}
-	lastNames := []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez"}
-	return firstNames[rand.Intn(len(firstNames))] + " " + lastNames[rand.Intn(len(lastNames))]
-}
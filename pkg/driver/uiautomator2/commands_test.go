@@ -1,6 +1,7 @@
 package uiautomator2
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -8,6 +9,7 @@ import (
 	"sync/atomic"
 	"testing"
 
+	"github.com/devicelab-dev/maestro-runner/pkg/faker"
 	"github.com/devicelab-dev/maestro-runner/pkg/flow"
 	"github.com/devicelab-dev/maestro-runner/pkg/uiautomator2"
 )
@@ -70,32 +72,6 @@ func TestMapKeyCode(t *testing.T) {
 	}
 }
 
-func TestRandomString(t *testing.T) {
-	// Test various lengths
-	lengths := []int{0, 1, 5, 10, 50}
-	for _, length := range lengths {
-		result := randomString(length)
-		if len(result) != length {
-			t.Errorf("randomString(%d) returned length %d", length, len(result))
-		}
-	}
-
-	// Test randomness (two calls should produce different results for sufficient length)
-	r1 := randomString(20)
-	r2 := randomString(20)
-	if r1 == r2 {
-		t.Error("randomString should produce different results")
-	}
-
-	// Test character set
-	result := randomString(100)
-	for _, c := range result {
-		if !((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')) {
-			t.Errorf("randomString contains invalid character: %c", c)
-		}
-	}
-}
-
 func TestLaunchAppNoDevice(t *testing.T) {
 	driver := &Driver{device: nil}
 	step := &flow.LaunchAppStep{AppID: "com.example.app"}
@@ -1537,6 +1513,68 @@ func TestInputTextNoSelectorActiveElementSuccess(t *testing.T) {
 	}
 }
 
+// generatedInputs are inputRandom values the executor hands to inputText:
+// ASCII, digits, non-ASCII (German addresses always contain "ß") and JSON
+// metacharacters.
+func generatedInputs(t *testing.T) []string {
+	t.Helper()
+	gen := faker.New(42)
+	var inputs []string
+	for _, tc := range []struct {
+		dataType string
+		length   int
+		locale   string
+	}{
+		{"EMAIL", 0, ""},
+		{"NUMBER", 5, ""},
+		{"PERSON_NAME", 0, "fr_FR"},
+		{"ADDRESS", 0, "de_DE"},
+		{"TEXT", 12, ""},
+	} {
+		text, err := gen.Generate(tc.dataType, tc.length, tc.locale)
+		if err != nil {
+			t.Fatalf("Generate(%s): %v", tc.dataType, err)
+		}
+		inputs = append(inputs, text)
+	}
+	return append(inputs, `say "hi" \ bye`)
+}
+
+func TestInputTextGeneratedData(t *testing.T) {
+	var typed string
+	server := setupMockServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /element/active": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, map[string]interface{}{
+				"value": map[string]string{"ELEMENT": "active-elem"},
+			})
+		},
+		"POST /element/active-elem/value": func(w http.ResponseWriter, r *http.Request) {
+			var req uiautomator2.InputTextRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("decode request: %v", err)
+			}
+			typed = req.Text
+			writeJSON(w, map[string]interface{}{"value": nil})
+		},
+	})
+	defer server.Close()
+
+	client := newMockHTTPClient(server.URL)
+	driver := New(client.Client, nil, nil)
+
+	for _, text := range generatedInputs(t) {
+		typed = ""
+		result := driver.Execute(&flow.InputTextStep{Text: text})
+
+		if !result.Success {
+			t.Fatalf("inputText %q failed: %v", text, result.Error)
+		}
+		if typed != text {
+			t.Errorf("typed %q, want %q", typed, text)
+		}
+	}
+}
+
 // ============================================================================
 // SetClipboard Tests
 // ============================================================================
@@ -1677,145 +1715,6 @@ func TestToggleAirplaneModeShellError(t *testing.T) {
 	}
 }
 
-// ============================================================================
-// InputRandom DataType Tests (HTTP Mock)
-// ============================================================================
-
-func TestInputRandomEmail(t *testing.T) {
-	server := setupMockServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
-		"GET /element/active": func(w http.ResponseWriter, r *http.Request) {
-			writeJSON(w, map[string]interface{}{
-				"value": map[string]string{"ELEMENT": "active-elem"},
-			})
-		},
-		"POST /element/active-elem/value": func(w http.ResponseWriter, r *http.Request) {
-			writeJSON(w, map[string]interface{}{"value": nil})
-		},
-	})
-	defer server.Close()
-
-	client := newMockHTTPClient(server.URL)
-	driver := New(client.Client, nil, nil)
-
-	step := &flow.InputRandomStep{DataType: "EMAIL", Length: 8}
-	result := driver.Execute(step)
-
-	if !result.Success {
-		t.Errorf("expected success, got error: %v", result.Error)
-	}
-	if text, ok := result.Data.(string); ok {
-		if !strings.Contains(text, "@") {
-			t.Errorf("expected email format with @, got: %s", text)
-		}
-	} else {
-		t.Error("expected string data")
-	}
-}
-
-func TestInputRandomNumber(t *testing.T) {
-	server := setupMockServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
-		"GET /element/active": func(w http.ResponseWriter, r *http.Request) {
-			writeJSON(w, map[string]interface{}{
-				"value": map[string]string{"ELEMENT": "active-elem"},
-			})
-		},
-		"POST /element/active-elem/value": func(w http.ResponseWriter, r *http.Request) {
-			writeJSON(w, map[string]interface{}{"value": nil})
-		},
-	})
-	defer server.Close()
-
-	client := newMockHTTPClient(server.URL)
-	driver := New(client.Client, nil, nil)
-
-	step := &flow.InputRandomStep{DataType: "NUMBER", Length: 6}
-	result := driver.Execute(step)
-
-	if !result.Success {
-		t.Errorf("expected success, got error: %v", result.Error)
-	}
-	if text, ok := result.Data.(string); ok {
-		if len(text) != 6 {
-			t.Errorf("expected 6 digit number, got %d chars: %s", len(text), text)
-		}
-		for _, c := range text {
-			if c < '0' || c > '9' {
-				t.Errorf("expected digits only, got: %s", text)
-				break
-			}
-		}
-	} else {
-		t.Error("expected string data")
-	}
-}
-
-func TestInputRandomPersonName(t *testing.T) {
-	server := setupMockServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
-		"GET /element/active": func(w http.ResponseWriter, r *http.Request) {
-			writeJSON(w, map[string]interface{}{
-				"value": map[string]string{"ELEMENT": "active-elem"},
-			})
-		},
-		"POST /element/active-elem/value": func(w http.ResponseWriter, r *http.Request) {
-			writeJSON(w, map[string]interface{}{"value": nil})
-		},
-	})
-	defer server.Close()
-
-	client := newMockHTTPClient(server.URL)
-	driver := New(client.Client, nil, nil)
-
-	step := &flow.InputRandomStep{DataType: "PERSON_NAME"}
-	result := driver.Execute(step)
-
-	if !result.Success {
-		t.Errorf("expected success, got error: %v", result.Error)
-	}
-	if text, ok := result.Data.(string); ok {
-		if !strings.Contains(text, " ") {
-			t.Errorf("expected person name with space (first last), got: %s", text)
-		}
-	} else {
-		t.Error("expected string data")
-	}
-}
-
-// ============================================================================
-// RandomHelpers Tests
-// ============================================================================
-
-func TestRandomEmail(t *testing.T) {
-	email := randomEmail()
-	if !strings.Contains(email, "@") {
-		t.Errorf("expected @ in email, got: %s", email)
-	}
-	parts := strings.Split(email, "@")
-	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
-		t.Errorf("invalid email format: %s", email)
-	}
-}
-
-func TestRandomNumber(t *testing.T) {
-	num := randomNumber(5)
-	if len(num) != 5 {
-		t.Errorf("expected 5 digits, got %d: %s", len(num), num)
-	}
-	for _, c := range num {
-		if c < '0' || c > '9' {
-			t.Errorf("expected digits only, got: %s", num)
-			break
-		}
-	}
-}
-
-func TestRandomPersonName(t *testing.T) {
-	name := randomPersonName()
-	parts := strings.Split(name, " ")
-	if len(parts) != 2 {
-		t.Errorf("expected 'first last' format, got: %s", name)
-	}
-}
-
 // ============================================================================
 // SetOrientation Shell Error Test
 // ============================================================================
@@ -10,6 +10,7 @@ import (
 
 	"github.com/devicelab-dev/maestro-runner/pkg/core"
 	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/devicelab-dev/maestro-runner/pkg/metrics"
 	"github.com/devicelab-dev/maestro-runner/pkg/uiautomator2"
 )
 
@@ -124,8 +125,6 @@ func (d *Driver) Execute(step flow.Step) *core.CommandResult {
 		result = d.eraseText(s)
 	case *flow.HideKeyboardStep:
 		result = d.hideKeyboard(s)
-	case *flow.InputRandomStep:
-		result = d.inputRandom(s)
 
 	// Scroll/Swipe commands
 	case *flow.ScrollStep:
@@ -327,6 +326,7 @@ func (d *Driver) findElementForTapWithContext(ctx context.Context, sel flow.Sele
 				if err == nil {
 					return nil, info, nil
 				}
+				recordFindRetry()
 				// Still not found - keep polling
 				lastErr = existsErr
 				continue
@@ -338,6 +338,7 @@ func (d *Driver) findElementForTapWithContext(ctx context.Context, sel flow.Sele
 				return nil, info, nil
 			}
 			lastErr = err
+			recordFindRetry()
 		}
 	}
 }
@@ -444,6 +445,7 @@ func (d *Driver) findElementWithContext(ctx context.Context, sel flow.Selector,
 				return elem, info, nil
 			}
 			lastErr = err
+			recordFindRetry()
 			// HTTP round-trip (~100ms) is natural rate limit, no sleep needed
 		}
 	}
@@ -639,6 +641,7 @@ func (d *Driver) findElementRelativeWithContext(ctx context.Context, sel flow.Se
 				return nil, info, nil
 			}
 			lastErr = err
+			recordFindRetry()
 			// HTTP round-trip is natural rate limit, no sleep needed
 		}
 	}
@@ -774,6 +777,29 @@ func (d *Driver) resolveRelativeSelector(sel flow.Selector) (*core.ElementInfo,
 // findElementRelativeWithElements resolves a relative selector using pre-parsed elements.
 // Used for recursive resolution of nested relative selectors without refetching page source.
 func (d *Driver) findElementRelativeWithElements(sel flow.Selector, allElements []*ParsedElement) (*uiautomator2.Element, *core.ElementInfo, error) {
+	candidates, err := d.relativeCandidates(sel, allElements)
+	if err != nil {
+		return nil, nil, err
+	}
+	selected := selectCandidate(candidates, sel.Index)
+
+	// If element isn't clickable, try to find a clickable parent
+	// This handles React Native pattern where text nodes aren't clickable but containers are
+	clickableElem := GetClickableElement(selected)
+
+	info := &core.ElementInfo{
+		Text:    selected.Text,
+		Bounds:  clickableElem.Bounds,
+		Enabled: selected.Enabled,
+		Visible: selected.Displayed,
+	}
+
+	return nil, info, nil
+}
+
+// relativeCandidates returns all elements matching sel, including relative and
+// containsDescendants filters, with clickable elements first.
+func (d *Driver) relativeCandidates(sel flow.Selector, allElements []*ParsedElement) ([]*ParsedElement, error) {
 	// Get anchor selector and filter type
 	anchorSelector, filterType := getRelativeFilter(sel)
 
@@ -831,7 +857,7 @@ func (d *Driver) findElementRelativeWithElements(sel flow.Selector, allElements
 		}
 		candidates = matchingCandidates
 	} else if anchorSelector != nil {
-		return nil, nil, fmt.Errorf("anchor element not found")
+		return nil, fmt.Errorf("anchor element not found")
 	}
 
 	// Apply containsDescendants filter
@@ -840,41 +866,29 @@ func (d *Driver) findElementRelativeWithElements(sel flow.Selector, allElements
 	}
 
 	if len(candidates) == 0 {
-		return nil, nil, fmt.Errorf("no elements match relative criteria")
+		return nil, fmt.Errorf("no elements match relative criteria")
 	}
 
 	// Prioritize clickable elements
-	candidates = SortClickableFirst(candidates)
+	return SortClickableFirst(candidates), nil
+}
 
-	// Apply index if specified, otherwise use deepest matching element
-	var selected *ParsedElement
-	if sel.Index != "" {
-		idx := 0
-		if i, err := strconv.Atoi(sel.Index); err == nil {
-			if i < 0 {
-				i = len(candidates) + i
-			}
-			if i >= 0 && i < len(candidates) {
-				idx = i
-			}
-		}
-		selected = candidates[idx]
-	} else {
-		selected = DeepestMatchingElement(candidates)
+// selectCandidate picks the element a command acts on: the candidate at index
+// if specified, otherwise the deepest matching element.
+func selectCandidate(candidates []*ParsedElement, index string) *ParsedElement {
+	if index == "" {
+		return DeepestMatchingElement(candidates)
 	}
-
-	// If element isn't clickable, try to find a clickable parent
-	// This handles React Native pattern where text nodes aren't clickable but containers are
-	clickableElem := GetClickableElement(selected)
-
-	info := &core.ElementInfo{
-		Text:    selected.Text,
-		Bounds:  clickableElem.Bounds,
-		Enabled: selected.Enabled,
-		Visible: selected.Displayed,
+	idx := 0
+	if i, err := strconv.Atoi(index); err == nil {
+		if i < 0 {
+			i = len(candidates) + i
+		}
+		if i >= 0 && i < len(candidates) {
+			idx = i
+		}
 	}
-
-	return nil, info, nil
+	return candidates[idx]
 }
 
 // findElementByPageSourceOnce performs a single page source search without polling.
@@ -937,6 +951,7 @@ func (d *Driver) findElementByPageSourceWithContext(ctx context.Context, sel flo
 				return nil, info, nil
 			}
 			lastErr = err
+			recordFindRetry()
 			// HTTP round-trip is natural rate limit, no sleep needed
 		}
 	}
@@ -1207,3 +1222,8 @@ func errorResult(err error, msg string) *core.CommandResult {
 		Message: msg,
 	}
 }
+
+// recordFindRetry counts an element lookup attempt that missed inside a polling loop.
+func recordFindRetry() {
+	metrics.RecordFindRetry("uiautomator2")
+}
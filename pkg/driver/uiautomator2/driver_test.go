@@ -865,18 +865,6 @@ func TestInputTextEmpty(t *testing.T) {
 	}
 }
 
-func TestInputRandomNoActiveElement(t *testing.T) {
-	client := &MockUIA2Client{}
-	driver := New(client, nil, nil)
-
-	step := &flow.InputRandomStep{Length: 10}
-	result := driver.Execute(step)
-
-	if result.Success {
-		t.Error("expected failure when no active element")
-	}
-}
-
 // ============================================================================
 // Execute Switch Coverage
 // ============================================================================
@@ -904,7 +892,6 @@ func TestExecuteAllStepTypes(t *testing.T) {
 		{"InputTextStep_empty", &flow.InputTextStep{Text: ""}, true},
 		{"EraseTextStep", &flow.EraseTextStep{Characters: 1}, false},
 		{"HideKeyboardStep", &flow.HideKeyboardStep{}, false},
-		{"InputRandomStep", &flow.InputRandomStep{Length: 5}, true},
 		{"ScrollStep", &flow.ScrollStep{Direction: "down"}, false},
 		{"ScrollUntilVisibleStep", &flow.ScrollUntilVisibleStep{Element: flow.Selector{Text: "btn"}}, true},
 		{"SwipeStep", &flow.SwipeStep{Direction: "up"}, false},
@@ -1543,88 +1530,6 @@ func TestInputTextNoSelectorError(t *testing.T) {
 	}
 }
 
-func TestInputRandomWithActiveElement(t *testing.T) {
-	server := setupMockServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
-		"GET /element/active": func(w http.ResponseWriter, r *http.Request) {
-			writeJSON(w,map[string]interface{}{
-				"value": map[string]string{"ELEMENT": "active-elem"},
-			})
-		},
-		"POST /element/active-elem/value": func(w http.ResponseWriter, r *http.Request) {
-			writeJSON(w,map[string]interface{}{"value": nil})
-		},
-	})
-	defer server.Close()
-
-	client := newMockHTTPClient(server.URL)
-	driver := New(client.Client, nil, nil)
-
-	step := &flow.InputRandomStep{Length: 8}
-	result := driver.Execute(step)
-
-	if !result.Success {
-		t.Errorf("expected success, got error: %v", result.Error)
-	}
-	if result.Data == nil {
-		t.Error("expected random text in Data")
-	}
-}
-
-func TestInputRandomDefaultLength(t *testing.T) {
-	server := setupMockServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
-		"GET /element/active": func(w http.ResponseWriter, r *http.Request) {
-			writeJSON(w,map[string]interface{}{
-				"value": map[string]string{"ELEMENT": "active-elem"},
-			})
-		},
-		"POST /element/active-elem/value": func(w http.ResponseWriter, r *http.Request) {
-			writeJSON(w,map[string]interface{}{"value": nil})
-		},
-	})
-	defer server.Close()
-
-	client := newMockHTTPClient(server.URL)
-	driver := New(client.Client, nil, nil)
-
-	step := &flow.InputRandomStep{Length: 0} // Default 10
-	result := driver.Execute(step)
-
-	if !result.Success {
-		t.Errorf("expected success, got error: %v", result.Error)
-	}
-	// Data should be 10 chars
-	if text, ok := result.Data.(string); ok {
-		if len(text) != 10 {
-			t.Errorf("expected 10 char string, got %d", len(text))
-		}
-	}
-}
-
-func TestInputRandomSendKeysError(t *testing.T) {
-	server := setupMockServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
-		"GET /element/active": func(w http.ResponseWriter, r *http.Request) {
-			writeJSON(w,map[string]interface{}{
-				"value": map[string]string{"ELEMENT": "active-elem"},
-			})
-		},
-		"POST /element/active-elem/value": func(w http.ResponseWriter, r *http.Request) {
-			w.WriteHeader(http.StatusInternalServerError)
-			writeJSON(w,map[string]interface{}{"value": "error"})
-		},
-	})
-	defer server.Close()
-
-	client := newMockHTTPClient(server.URL)
-	driver := New(client.Client, nil, nil)
-
-	step := &flow.InputRandomStep{Length: 5}
-	result := driver.Execute(step)
-
-	if result.Success {
-		t.Error("expected failure on SendKeys error")
-	}
-}
-
 func TestCopyTextFromWithElement(t *testing.T) {
 	server := setupMockServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
 		"POST /element": func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,100 @@
+package uiautomator2
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/core"
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+)
+
+const inspectPageSource = `<?xml version="1.0" encoding="UTF-8"?>
+<hierarchy>
+  <node class="android.widget.FrameLayout" bounds="[0,0][1080,1920]" displayed="true" enabled="true">
+    <node text="Header" resource-id="com.app:id/title" class="android.widget.TextView" bounds="[0,0][1080,100]" displayed="true" enabled="true" />
+    <node class="android.widget.LinearLayout" clickable="true" bounds="[0,200][1080,300]" displayed="true" enabled="true">
+      <node text="Save" class="android.widget.TextView" bounds="[40,220][240,280]" displayed="true" enabled="true" />
+    </node>
+    <node text="Save" class="android.widget.Button" clickable="true" bounds="[0,400][1080,500]" displayed="true" enabled="true" />
+  </node>
+</hierarchy>`
+
+func newInspectDriver(t *testing.T) (*Driver, func()) {
+	server := setupMockServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /source": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, map[string]interface{}{"value": inspectPageSource})
+		},
+	})
+	client := newMockHTTPClient(server.URL)
+	return New(client.Client, nil, nil), server.Close
+}
+
+func TestInspectElements(t *testing.T) {
+	driver, done := newInspectDriver(t)
+	defer done()
+
+	var _ core.Inspector = driver
+
+	snap, err := driver.Inspect()
+	if err != nil {
+		t.Fatalf("Inspect failed: %v", err)
+	}
+	elements := snap.Elements()
+	if len(elements) != 5 {
+		t.Fatalf("expected 5 elements, got %d", len(elements))
+	}
+
+	root, header, row, label := elements[0], elements[1], elements[2], elements[3]
+	if root.Parent != -1 {
+		t.Errorf("root parent = %d, want -1", root.Parent)
+	}
+	if header.ID != "com.app:id/title" || header.Text != "Header" || header.Parent != 0 {
+		t.Errorf("unexpected header element: %+v", header)
+	}
+	if !row.Clickable || label.Parent != row.Index || label.Depth != row.Depth+1 {
+		t.Errorf("unexpected row/label: %+v %+v", row, label)
+	}
+}
+
+func TestInspectMatch(t *testing.T) {
+	driver, done := newInspectDriver(t)
+	defer done()
+
+	snap, err := driver.Inspect()
+	if err != nil {
+		t.Fatalf("Inspect failed: %v", err)
+	}
+
+	// Ambiguous text: the deepest match is selected, the tap lands on its clickable parent
+	m, err := snap.Match(flow.Selector{Text: "Save"})
+	if err != nil {
+		t.Fatalf("Match failed: %v", err)
+	}
+	if len(m.Matches) != 2 {
+		t.Errorf("expected 2 matches, got %v", m.Matches)
+	}
+	if m.Target != 3 || m.TapBounds == nil || *m.TapBounds != snap.Elements()[2].Bounds {
+		t.Errorf("unexpected target %d / tap bounds %+v", m.Target, m.TapBounds)
+	}
+
+	// Index picks among candidates, clickable ones first
+	m, _ = snap.Match(flow.Selector{Text: "Save", Index: "0"})
+	if m.Target != 4 || *m.TapBounds != snap.Elements()[4].Bounds {
+		t.Errorf("index 0: target %d, tap bounds %+v", m.Target, m.TapBounds)
+	}
+
+	// Relative selector
+	m, _ = snap.Match(flow.Selector{Text: "Save", Below: &flow.Selector{ID: "title"}})
+	if len(m.Matches) != 2 {
+		t.Errorf("expected 2 relative matches, got %v", m.Matches)
+	}
+
+	// No match is not an error
+	m, err = snap.Match(flow.Selector{Text: "Missing"})
+	if err != nil {
+		t.Fatalf("Match failed: %v", err)
+	}
+	if len(m.Matches) != 0 || m.Target != -1 || m.TapBounds != nil {
+		t.Errorf("expected no match, got %+v", m)
+	}
+}
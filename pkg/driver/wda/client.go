@@ -12,6 +12,9 @@ import (
 	"time"
 
 	"github.com/devicelab-dev/maestro-runner/pkg/logger"
+	"github.com/devicelab-dev/maestro-runner/pkg/metrics"
+	"github.com/devicelab-dev/maestro-runner/pkg/secrets"
+	"github.com/devicelab-dev/maestro-runner/pkg/telemetry"
 )
 
 // Client is an HTTP client for WebDriverAgent.
@@ -19,6 +22,9 @@ type Client struct {
 	baseURL    string
 	sessionID  string
 	httpClient *http.Client
+	hadSession bool                // A session was created before (next one is a reconnect)
+	deviceID   string              // Recorded with telemetry HTTP calls
+	recorder   *telemetry.Recorder // Records HTTP calls for trace export (nil = off)
 }
 
 // NewClient creates a new WDA client.
@@ -31,6 +37,18 @@ func NewClient(port uint16) *Client {
 	}
 }
 
+// SetDeviceID sets the ID of the device WDA runs on, so its HTTP calls can be
+// told apart from those of other devices in a parallel run.
+func (c *Client) SetDeviceID(id string) {
+	c.deviceID = id
+}
+
+// SetRecorder records the client's HTTP calls in r, the run's call log for
+// trace export.
+func (c *Client) SetRecorder(r *telemetry.Recorder) {
+	c.recorder = r
+}
+
 // Session management
 
 // CreateSession creates a new WDA session.
@@ -63,6 +81,11 @@ func (c *Client) CreateSession(bundleID string) error {
 		}
 	}
 
+	if c.hadSession {
+		metrics.RecordReconnect("wda")
+	}
+	c.hadSession = true
+
 	return nil
 }
 
@@ -468,6 +491,7 @@ func (c *Client) get(path string) (map[string]interface{}, error) {
 	logger.Debug("WDA GET %s", path)
 
 	resp, err := c.httpClient.Get(c.baseURL + path)
+	c.recorder.RecordResponse(c.deviceID, c.baseURL, http.MethodGet, path, start, resp, err)
 	duration := time.Since(start).Milliseconds()
 
 	if err != nil {
@@ -490,7 +514,7 @@ func (c *Client) post(path string, body interface{}) (map[string]interface{}, er
 			return nil, err
 		}
 		reqBody = bytes.NewReader(data)
-		bodyStr = string(data)
+		bodyStr = secrets.Redact(string(data)) // Before truncating, which could cut a secret short
 		if len(bodyStr) > 100 {
 			bodyStr = bodyStr[:100] + "..."
 		}
@@ -499,6 +523,7 @@ func (c *Client) post(path string, body interface{}) (map[string]interface{}, er
 	logger.Debug("WDA POST %s body=%s", path, bodyStr)
 
 	resp, err := c.httpClient.Post(c.baseURL+path, "application/json", reqBody)
+	c.recorder.RecordResponse(c.deviceID, c.baseURL, http.MethodPost, path, start, resp, err)
 	duration := time.Since(start).Milliseconds()
 
 	if err != nil {
@@ -521,6 +546,7 @@ func (c *Client) delete(path string) (map[string]interface{}, error) {
 	}
 
 	resp, err := c.httpClient.Do(req)
+	c.recorder.RecordResponse(c.deviceID, c.baseURL, http.MethodDelete, path, start, resp, err)
 	duration := time.Since(start).Milliseconds()
 
 	if err != nil {
@@ -3,7 +3,6 @@ package wda
 import (
 	"context"
 	"fmt"
-	"math/rand"
 	"os/exec"
 	"strconv"
 	"strings"
@@ -313,37 +312,6 @@ func (d *Driver) hideKeyboard(step *flow.HideKeyboardStep) *core.CommandResult {
 	return successResult("Attempted to hide keyboard", nil)
 }
 
-func (d *Driver) inputRandom(step *flow.InputRandomStep) *core.CommandResult {
-	length := step.Length
-	if length <= 0 {
-		length = 10 // default
-	}
-
-	// Generate random data based on DataType
-	var text string
-	dataType := strings.ToUpper(step.DataType)
-	switch dataType {
-	case "EMAIL":
-		text = randomEmail()
-	case "NUMBER":
-		text = randomNumber(length)
-	case "PERSON_NAME":
-		text = randomPersonName()
-	default: // "TEXT" or empty
-		text = randomString(length)
-	}
-
-	if err := d.client.SendKeys(text); err != nil {
-		return errorResult(err, "Input random text failed")
-	}
-
-	return &core.CommandResult{
-		Success: true,
-		Message: fmt.Sprintf("Entered random %s: %s", dataType, text),
-		Data:    text,
-	}
-}
-
 // Scroll/Swipe commands
 
 func (d *Driver) scroll(step *flow.ScrollStep) *core.CommandResult {
@@ -849,37 +817,6 @@ func selectorDesc(sel flow.Selector) string {
 	return "selector"
 }
 
-func randomString(length int) string {
-	const chars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, length)
-	for i := range b {
-		b[i] = chars[rand.Intn(len(chars))]
-	}
-	return string(b)
-}
-
-func randomEmail() string {
-	user := randomString(8)
-	domains := []string{"example.com", "test.com", "mail.com"}
-	domain := domains[rand.Intn(len(domains))]
-	return user + "@" + domain
-}
-
-func randomNumber(length int) string {
-	const digits = "0123456789"
-	b := make([]byte, length)
-	for i := range b {
-		b[i] = digits[rand.Intn(len(digits))]
-	}
-	return string(b)
-}
-
-func randomPersonName() string {
-	firstNames := []string{"John", "Jane", "Michael", "Emily", "David", "Sarah", "James", "Emma", "Robert", "Olivia"}
-	lastNames := []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez"}
-	return firstNames[rand.Intn(len(firstNames))] + " " + lastNames[rand.Intn(len(lastNames))]
-}
-
 func parsePercentageCoords(coord string) (float64, float64, error) {
 	// Parse "50%, 50%" format
 	coord = strings.ReplaceAll(coord, " ", "")
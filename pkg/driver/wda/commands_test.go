@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/devicelab-dev/maestro-runner/pkg/core"
+	"github.com/devicelab-dev/maestro-runner/pkg/faker"
 	"github.com/devicelab-dev/maestro-runner/pkg/flow"
 )
 
@@ -2450,113 +2451,64 @@ func TestInputTextNonASCII(t *testing.T) {
 	}
 }
 
-// =============================================================================
-// inputRandom additional tests
-// =============================================================================
-
-// TestInputRandomEmail tests inputRandom with EMAIL type.
-func TestInputRandomEmail(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		jsonResponse(w, map[string]interface{}{"status": 0})
-	}))
-	defer server.Close()
-	driver := createTestDriver(server)
-
-	step := &flow.InputRandomStep{DataType: "EMAIL"}
-	result := driver.inputRandom(step)
-
-	if !result.Success {
-		t.Fatalf("Expected success, got: %s", result.Message)
-	}
-	if !strings.Contains(result.Message, "EMAIL") {
-		t.Errorf("Expected 'EMAIL' in message, got: %s", result.Message)
-	}
-	data, ok := result.Data.(string)
-	if !ok {
-		t.Fatalf("Expected Data to be string, got: %T", result.Data)
-	}
-	if !strings.Contains(data, "@") {
-		t.Errorf("Expected email with '@', got: %s", data)
-	}
-}
-
-// TestInputRandomNumberDigits tests inputRandom with NUMBER type returns only digits.
-func TestInputRandomNumberDigits(t *testing.T) {
+// TestInputTextGeneratedData tests that inputRandom values, which the executor
+// types with inputText, reach WDA character for character.
+func TestInputTextGeneratedData(t *testing.T) {
+	var typed []string
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		jsonResponse(w, map[string]interface{}{"status": 0})
-	}))
-	defer server.Close()
-	driver := createTestDriver(server)
-
-	step := &flow.InputRandomStep{DataType: "NUMBER", Length: 6}
-	result := driver.inputRandom(step)
+		path := r.URL.Path
 
-	if !result.Success {
-		t.Fatalf("Expected success, got: %s", result.Message)
-	}
-	data, ok := result.Data.(string)
-	if !ok {
-		t.Fatalf("Expected Data to be string, got: %T", result.Data)
-	}
-	if len(data) != 6 {
-		t.Errorf("Expected 6 digit number, got length %d: %s", len(data), data)
-	}
-	for _, c := range data {
-		if c < '0' || c > '9' {
-			t.Errorf("Expected only digits, got char: %c in %s", c, data)
-			break
+		if strings.Contains(path, "/element/active") {
+			jsonResponse(w, map[string]interface{}{
+				"value": map[string]interface{}{"ELEMENT": "active-elem"},
+			})
+			return
+		}
+		if strings.HasSuffix(path, "/wda/keys") {
+			var body struct {
+				Value []string `json:"value"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("Failed to decode keys request: %v", err)
+			}
+			typed = body.Value
 		}
-	}
-}
-
-// TestInputRandomPersonNameFormat tests inputRandom with PERSON_NAME type returns first and last name.
-func TestInputRandomPersonNameFormat(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
 		jsonResponse(w, map[string]interface{}{"status": 0})
 	}))
 	defer server.Close()
 	driver := createTestDriver(server)
 
-	step := &flow.InputRandomStep{DataType: "PERSON_NAME"}
-	result := driver.inputRandom(step)
-
-	if !result.Success {
-		t.Fatalf("Expected success, got: %s", result.Message)
-	}
-	data, ok := result.Data.(string)
-	if !ok {
-		t.Fatalf("Expected Data to be string, got: %T", result.Data)
-	}
-	// Person name should have a space between first and last name
-	if !strings.Contains(data, " ") {
-		t.Errorf("Expected person name with space, got: %s", data)
+	gen := faker.New(42)
+	testCases := []struct {
+		dataType string
+		length   int
+		locale   string
+	}{
+		{"EMAIL", 0, ""},
+		{"NUMBER", 5, ""},
+		{"PERSON_NAME", 0, "fr_FR"},
+		{"ADDRESS", 0, "de_DE"}, // Street names contain "ß"
+		{"TEXT", 12, ""},
 	}
-}
-
-// TestInputRandomDefaultText tests inputRandom with empty DataType (defaults to TEXT).
-func TestInputRandomDefaultText(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		jsonResponse(w, map[string]interface{}{"status": 0})
-	}))
-	defer server.Close()
-	driver := createTestDriver(server)
 
-	step := &flow.InputRandomStep{DataType: "", Length: 12}
-	result := driver.inputRandom(step)
+	for _, tc := range testCases {
+		text, err := gen.Generate(tc.dataType, tc.length, tc.locale)
+		if err != nil {
+			t.Fatalf("Generate(%s) failed: %v", tc.dataType, err)
+		}
+		typed = nil
+		result := driver.Execute(&flow.InputTextStep{Text: text})
 
-	if !result.Success {
-		t.Fatalf("Expected success, got: %s", result.Message)
-	}
-	data, ok := result.Data.(string)
-	if !ok {
-		t.Fatalf("Expected Data to be string, got: %T", result.Data)
-	}
-	if len(data) != 12 {
-		t.Errorf("Expected 12 char text, got length %d: %s", len(data), data)
+		if !result.Success {
+			t.Fatalf("inputText %q failed: %s", text, result.Message)
+		}
+		if got := strings.Join(typed, ""); got != text {
+			t.Errorf("%s: typed %q, want %q", tc.dataType, got, text)
+		}
+		if len(typed) != len([]rune(text)) {
+			t.Errorf("%s: sent %d keys for %d characters", tc.dataType, len(typed), len([]rune(text)))
+		}
 	}
 }
 
@@ -3054,57 +3006,3 @@ func TestSelectorDescTableDriven(t *testing.T) {
 		})
 	}
 }
-
-// =============================================================================
-// randomString / randomEmail / randomNumber / randomPersonName tests
-// =============================================================================
-
-// TestRandomStringLength tests that randomString returns the correct length.
-func TestRandomStringLength(t *testing.T) {
-	for _, length := range []int{0, 1, 5, 20, 100} {
-		result := randomString(length)
-		if len(result) != length {
-			t.Errorf("randomString(%d) returned length %d", length, len(result))
-		}
-	}
-}
-
-// TestRandomEmailFormat tests that randomEmail returns a valid email format.
-func TestRandomEmailFormat(t *testing.T) {
-	email := randomEmail()
-	if !strings.Contains(email, "@") {
-		t.Errorf("Expected email with '@', got: %s", email)
-	}
-	parts := strings.Split(email, "@")
-	if len(parts) != 2 {
-		t.Errorf("Expected exactly one '@' in email, got: %s", email)
-	}
-	if len(parts[0]) == 0 || len(parts[1]) == 0 {
-		t.Errorf("Expected non-empty user and domain, got: %s", email)
-	}
-}
-
-// TestRandomNumberDigitsOnly tests that randomNumber returns only digits.
-func TestRandomNumberDigitsOnly(t *testing.T) {
-	result := randomNumber(10)
-	if len(result) != 10 {
-		t.Errorf("Expected length 10, got %d", len(result))
-	}
-	for _, c := range result {
-		if c < '0' || c > '9' {
-			t.Errorf("Expected only digits, found '%c' in %s", c, result)
-		}
-	}
-}
-
-// TestRandomPersonNameHasSpace tests that randomPersonName returns first and last name.
-func TestRandomPersonNameHasSpace(t *testing.T) {
-	name := randomPersonName()
-	if !strings.Contains(name, " ") {
-		t.Errorf("Expected name with space, got: %s", name)
-	}
-	parts := strings.Split(name, " ")
-	if len(parts) < 2 {
-		t.Errorf("Expected at least first and last name, got: %s", name)
-	}
-}
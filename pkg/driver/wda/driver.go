@@ -9,6 +9,7 @@ import (
 
 	"github.com/devicelab-dev/maestro-runner/pkg/core"
 	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/devicelab-dev/maestro-runner/pkg/metrics"
 )
 
 // Driver implements core.Driver using WebDriverAgent for iOS.
@@ -84,8 +85,6 @@ func (d *Driver) Execute(step flow.Step) *core.CommandResult {
 		result = d.eraseText(s)
 	case *flow.HideKeyboardStep:
 		result = d.hideKeyboard(s)
-	case *flow.InputRandomStep:
-		result = d.inputRandom(s)
 
 	// Scroll/Swipe commands
 	case *flow.ScrollStep:
@@ -221,6 +220,7 @@ func (d *Driver) findElementWithContext(ctx context.Context, sel flow.Selector)
 			} else {
 				lastErr = err
 			}
+			recordFindRetry()
 			// HTTP round-trip is natural rate limit, no sleep needed
 		}
 	}
@@ -298,6 +298,7 @@ func (d *Driver) findElementForTapWithContext(ctx context.Context, sel flow.Sele
 				if info, err := d.findElementByPageSourceOnce(sel); err == nil {
 					return info, nil
 				}
+				recordFindRetry()
 				// Still not found - keep polling
 				lastErr = textExistsErr
 				continue
@@ -317,6 +318,7 @@ func (d *Driver) findElementForTapWithContext(ctx context.Context, sel flow.Sele
 				}
 				lastErr = err
 			}
+			recordFindRetry()
 		}
 	}
 }
@@ -517,6 +519,7 @@ func (d *Driver) findElementRelativeWithContext(ctx context.Context, sel flow.Se
 				return info, nil
 			}
 			lastErr = err
+			recordFindRetry()
 			// HTTP round-trip is natural rate limit, no sleep needed
 		}
 	}
@@ -539,6 +542,23 @@ func (d *Driver) findElementRelativeOnce(sel flow.Selector) (*core.ElementInfo,
 
 // resolveRelativeSelector resolves a relative selector against parsed elements.
 func (d *Driver) resolveRelativeSelector(sel flow.Selector, allElements []*ParsedElement) (*core.ElementInfo, error) {
+	candidates, err := d.relativeCandidates(sel, allElements)
+	if err != nil {
+		return nil, err
+	}
+	selected := selectCandidate(candidates, sel.Index)
+
+	return &core.ElementInfo{
+		Text:    selected.Label,
+		Bounds:  selected.Bounds,
+		Enabled: selected.Enabled,
+		Visible: selected.Displayed,
+	}, nil
+}
+
+// relativeCandidates returns all elements matching sel, including relative and
+// containsDescendants filters, with interactive elements first.
+func (d *Driver) relativeCandidates(sel flow.Selector, allElements []*ParsedElement) ([]*ParsedElement, error) {
 	// Build base selector
 	baseSel := flow.Selector{
 		Text:      sel.Text,
@@ -589,31 +609,25 @@ func (d *Driver) resolveRelativeSelector(sel flow.Selector, allElements []*Parse
 	}
 
 	// Prioritize clickable/interactive elements
-	candidates = SortClickableFirst(candidates)
+	return SortClickableFirst(candidates), nil
+}
 
-	// Select element
-	var selected *ParsedElement
-	if sel.Index != "" {
-		idx := 0
-		if i, err := strconv.Atoi(sel.Index); err == nil {
-			if i < 0 {
-				i = len(candidates) + i
-			}
-			if i >= 0 && i < len(candidates) {
-				idx = i
-			}
+// selectCandidate picks the element a command acts on: the candidate at index
+// if specified, otherwise the deepest matching element.
+func selectCandidate(candidates []*ParsedElement, index string) *ParsedElement {
+	if index == "" {
+		return DeepestMatchingElement(candidates)
+	}
+	idx := 0
+	if i, err := strconv.Atoi(index); err == nil {
+		if i < 0 {
+			i = len(candidates) + i
+		}
+		if i >= 0 && i < len(candidates) {
+			idx = i
 		}
-		selected = candidates[idx]
-	} else {
-		selected = DeepestMatchingElement(candidates)
 	}
-
-	return &core.ElementInfo{
-		Text:    selected.Label,
-		Bounds:  selected.Bounds,
-		Enabled: selected.Enabled,
-		Visible: selected.Displayed,
-	}, nil
+	return candidates[idx]
 }
 
 // findElementByPageSourceOnce performs a single page source search.
@@ -728,3 +742,8 @@ func errorResult(err error, msg string) *core.CommandResult {
 		Message: msg,
 	}
 }
+
+// recordFindRetry counts an element lookup attempt that missed inside a polling loop.
+func recordFindRetry() {
+	metrics.RecordFindRetry("wda")
+}
@@ -390,34 +390,6 @@ func TestExecuteHideKeyboard(t *testing.T) {
 	}
 }
 
-// TestExecuteInputRandom tests random input
-func TestExecuteInputRandom(t *testing.T) {
-	server := mockWDAServerForDriver()
-	defer server.Close()
-	driver := createTestDriver(server)
-
-	testCases := []struct {
-		dataType string
-	}{
-		{"TEXT"},
-		{"EMAIL"},
-		{"NUMBER"},
-		{"PERSON_NAME"},
-	}
-
-	for _, tc := range testCases {
-		step := &flow.InputRandomStep{
-			DataType: tc.dataType,
-			Length:   10,
-		}
-		result := driver.Execute(step)
-
-		if !result.Success {
-			t.Errorf("InputRandom %s failed: %v", tc.dataType, result.Error)
-		}
-	}
-}
-
 // TestExecuteScroll tests scroll command
 func TestExecuteScroll(t *testing.T) {
 	server := mockWDAServerForDriver()
@@ -960,38 +932,6 @@ func TestParsePercentageCoords(t *testing.T) {
 	}
 }
 
-// TestRandomHelpers tests random generation helpers
-func TestRandomHelpers(t *testing.T) {
-	// Test randomString
-	s := randomString(10)
-	if len(s) != 10 {
-		t.Errorf("Expected length 10, got %d", len(s))
-	}
-
-	// Test randomEmail
-	email := randomEmail()
-	if !strings.Contains(email, "@") {
-		t.Errorf("Expected email format, got '%s'", email)
-	}
-
-	// Test randomNumber
-	num := randomNumber(5)
-	if len(num) != 5 {
-		t.Errorf("Expected length 5, got %d", len(num))
-	}
-	for _, c := range num {
-		if c < '0' || c > '9' {
-			t.Errorf("Expected digit, got '%c'", c)
-		}
-	}
-
-	// Test randomPersonName
-	name := randomPersonName()
-	if !strings.Contains(name, " ") {
-		t.Errorf("Expected first and last name, got '%s'", name)
-	}
-}
-
 // TestSuccessResult tests success result creation
 func TestSuccessResult(t *testing.T) {
 	elem := &core.ElementInfo{Text: "Test"}
@@ -2881,73 +2821,6 @@ func TestAssertVisibleNotFound(t *testing.T) {
 	}
 }
 
-// TestInputRandomName tests inputRandom with Name type
-func TestInputRandomName(t *testing.T) {
-	server := mockWDAServerForDriver()
-	defer server.Close()
-	driver := createTestDriver(server)
-
-	step := &flow.InputRandomStep{
-		DataType: "Name",
-	}
-	result := driver.inputRandom(step)
-
-	if !result.Success {
-		t.Errorf("Expected success, got: %s", result.Message)
-	}
-}
-
-// TestInputRandomNumber tests inputRandom with Number type
-func TestInputRandomNumber(t *testing.T) {
-	server := mockWDAServerForDriver()
-	defer server.Close()
-	driver := createTestDriver(server)
-
-	step := &flow.InputRandomStep{
-		DataType: "Number",
-		Length:   5,
-	}
-	result := driver.inputRandom(step)
-
-	if !result.Success {
-		t.Errorf("Expected success, got: %s", result.Message)
-	}
-}
-
-// TestInputRandomPersonName tests inputRandom with Person_Name type
-func TestInputRandomPersonName(t *testing.T) {
-	server := mockWDAServerForDriver()
-	defer server.Close()
-	driver := createTestDriver(server)
-
-	step := &flow.InputRandomStep{
-		DataType: "Person_Name",
-	}
-	result := driver.inputRandom(step)
-
-	if !result.Success {
-		t.Errorf("Expected success, got: %s", result.Message)
-	}
-}
-
-// TestInputRandomUnknownTypeDefaultsToText tests inputRandom with unknown type defaults to text
-func TestInputRandomUnknownTypeDefaultsToText(t *testing.T) {
-	server := mockWDAServerForDriver()
-	defer server.Close()
-	driver := createTestDriver(server)
-
-	step := &flow.InputRandomStep{
-		DataType: "Unknown",
-		Length:   8,
-	}
-	result := driver.inputRandom(step)
-
-	// Unknown types default to TEXT, so it should succeed
-	if !result.Success {
-		t.Errorf("Expected success (defaults to TEXT), got: %s", result.Message)
-	}
-}
-
 // TestClientFindElements tests FindElements client method
 func TestClientFindElements(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -4931,49 +4804,6 @@ func TestInputTextSendKeysError(t *testing.T) {
 	}
 }
 
-// TestInputRandomDefaultLength tests inputRandom with default length
-func TestInputRandomDefaultLength(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		jsonResponse(w, map[string]interface{}{"status": 0})
-	}))
-	defer server.Close()
-	driver := createTestDriver(server)
-
-	// Length is 0, should default to 10
-	step := &flow.InputRandomStep{DataType: "TEXT", Length: 0}
-	result := driver.inputRandom(step)
-
-	if !result.Success {
-		t.Errorf("Expected success, got error: %v", result.Error)
-	}
-	// Verify the result data contains 10 characters (default length)
-	if resultStr, ok := result.Data.(string); ok {
-		if len(resultStr) != 10 {
-			t.Errorf("Expected 10 characters (default), got %d", len(resultStr))
-		}
-	}
-}
-
-// TestInputRandomError tests inputRandom when sendKeys fails
-func TestInputRandomError(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		jsonResponse(w, map[string]interface{}{
-			"value": map[string]interface{}{"error": "send keys failed"},
-		})
-	}))
-	defer server.Close()
-	driver := createTestDriver(server)
-
-	step := &flow.InputRandomStep{DataType: "TEXT", Length: 5}
-	result := driver.inputRandom(step)
-
-	if result.Success {
-		t.Error("Expected error when sendKeys fails")
-	}
-}
-
 // TestScrollSwipeError tests scroll when swipe fails
 func TestScrollSwipeError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
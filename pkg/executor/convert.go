@@ -1,6 +1,8 @@
 package executor
 
 import (
+	"errors"
+
 	"github.com/devicelab-dev/maestro-runner/pkg/core"
 	"github.com/devicelab-dev/maestro-runner/pkg/report"
 )
@@ -39,6 +41,11 @@ func commandResultToError(r *core.CommandResult) *report.Error {
 	errType := "unknown"
 	message := r.Error.Error()
 
+	var execErr *core.ExecutionError
+	if errors.As(r.Error, &execErr) {
+		errType = execErr.Category.String()
+	}
+
 	// Use message from result if available
 	if r.Message != "" {
 		message = r.Message
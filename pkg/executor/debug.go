@@ -0,0 +1,62 @@
+package executor
+
+import (
+	"github.com/devicelab-dev/maestro-runner/pkg/core"
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+)
+
+// DebugAction tells the flow runner how to proceed after a debugger hook.
+type DebugAction int
+
+const (
+	// DebugRun executes the step (or retries it after a failure).
+	DebugRun DebugAction = iota
+	// DebugSkip skips the step. After a failure, it moves on to the next
+	// step; the flow is still reported as failed.
+	DebugSkip
+	// DebugAbort stops the flow. After a failure, this is the normal
+	// behavior of skipping the remaining steps.
+	DebugAbort
+)
+
+// Debugger is consulted by the flow runner around top-level steps.
+// Steps are passed after variable expansion. Steps inside runFlow,
+// repeat and retry run without interruption.
+type Debugger interface {
+	// BeforeStep is called before each top-level step runs.
+	BeforeStep(s *DebugSession, idx int, step flow.Step) DebugAction
+	// OnFailure is called when a required top-level step fails.
+	OnFailure(s *DebugSession, idx int, step flow.Step, errMsg string) DebugAction
+}
+
+// DebugSession gives a debugger access to the running flow.
+type DebugSession struct {
+	fr *FlowRunner
+}
+
+// Flow returns the flow being executed.
+func (s *DebugSession) Flow() flow.Flow {
+	return s.fr.flow
+}
+
+// Driver returns the driver the flow runs on.
+func (s *DebugSession) Driver() core.Driver {
+	return s.fr.driver
+}
+
+// Eval evaluates a JavaScript expression in the flow's script engine.
+func (s *DebugSession) Eval(script string) (string, error) {
+	return s.fr.script.Eval(script)
+}
+
+// RunStep executes an ad hoc step in the flow's context. The step is not
+// added to the report and does not affect the flow's step counts.
+func (s *DebugSession) RunStep(step flow.Step) *core.CommandResult {
+	fr := s.fr
+	passed, failed, subCommands := fr.stepsPassed, fr.stepsFailed, fr.subCommands
+	defer func() {
+		fr.stepsPassed, fr.stepsFailed, fr.subCommands = passed, failed, subCommands
+	}()
+	fr.subCommands = nil
+	return fr.executeNestedStep(step)
+}
@@ -0,0 +1,185 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/core"
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/devicelab-dev/maestro-runner/pkg/report"
+)
+
+// scriptedDebugger returns canned actions and records what it saw.
+type scriptedDebugger struct {
+	before    []DebugAction
+	onFailure []DebugAction
+	seen      []string
+	failures  []string
+	hook      func(s *DebugSession)
+}
+
+func (d *scriptedDebugger) BeforeStep(s *DebugSession, idx int, step flow.Step) DebugAction {
+	d.seen = append(d.seen, step.Describe())
+	if d.hook != nil {
+		d.hook(s)
+	}
+	if len(d.before) == 0 {
+		return DebugRun
+	}
+	action := d.before[0]
+	d.before = d.before[1:]
+	return action
+}
+
+func (d *scriptedDebugger) OnFailure(s *DebugSession, idx int, step flow.Step, errMsg string) DebugAction {
+	d.failures = append(d.failures, errMsg)
+	if len(d.onFailure) == 0 {
+		return DebugAbort
+	}
+	action := d.onFailure[0]
+	d.onFailure = d.onFailure[1:]
+	return action
+}
+
+func runWithDebugger(t *testing.T, driver core.Driver, dbg Debugger, f flow.Flow) FlowResult {
+	t.Helper()
+	runner := New(driver, RunnerConfig{
+		OutputDir: t.TempDir(),
+		Artifacts: ArtifactNever,
+		Debugger:  dbg,
+	})
+	result, err := runner.Run(context.Background(), []flow.Flow{f})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	return result.FlowResults[0]
+}
+
+func tapText(text string) *flow.TapOnStep {
+	return &flow.TapOnStep{BaseStep: flow.BaseStep{StepType: flow.StepTapOn}, Selector: flow.Selector{Text: text}}
+}
+
+func TestDebugger_SeesExpandedSteps(t *testing.T) {
+	dbg := &scriptedDebugger{}
+	f := flow.Flow{
+		Config: flow.Config{Env: map[string]string{"USER": "alice"}},
+		Steps:  []flow.Step{tapText("${USER}")},
+	}
+	fr := runWithDebugger(t, &mockDriver{}, dbg, f)
+
+	if fr.Status != report.StatusPassed {
+		t.Errorf("Status = %v, want passed", fr.Status)
+	}
+	if len(dbg.seen) != 1 || dbg.seen[0] != `tapOn: text="alice"` {
+		t.Errorf("debugger saw %q", dbg.seen)
+	}
+}
+
+func TestDebugger_SkipAndAbort(t *testing.T) {
+	var executed []string
+	driver := &mockDriver{executeFunc: func(step flow.Step) *core.CommandResult {
+		executed = append(executed, step.Describe())
+		return &core.CommandResult{Success: true}
+	}}
+	dbg := &scriptedDebugger{before: []DebugAction{DebugSkip, DebugRun, DebugAbort}}
+	f := flow.Flow{Steps: []flow.Step{tapText("A"), tapText("B"), tapText("C"), tapText("D")}}
+
+	fr := runWithDebugger(t, driver, dbg, f)
+
+	if len(executed) != 1 || executed[0] != `tapOn: text="B"` {
+		t.Errorf("executed = %q, want only B", executed)
+	}
+	if fr.Status != report.StatusFailed || fr.Error != "aborted from debugger" {
+		t.Errorf("Status = %v (%q), want failed/aborted", fr.Status, fr.Error)
+	}
+	if fr.StepsPassed != 1 || fr.StepsSkipped != 3 {
+		t.Errorf("passed=%d skipped=%d, want 1/3", fr.StepsPassed, fr.StepsSkipped)
+	}
+}
+
+func TestDebugger_RetryAfterFailure(t *testing.T) {
+	attempts := 0
+	driver := &mockDriver{executeFunc: func(step flow.Step) *core.CommandResult {
+		attempts++
+		if attempts == 1 {
+			return &core.CommandResult{Success: false, Error: &testError{msg: "not found"}}
+		}
+		return &core.CommandResult{Success: true}
+	}}
+	dbg := &scriptedDebugger{onFailure: []DebugAction{DebugRun}}
+	f := flow.Flow{Steps: []flow.Step{tapText("A"), tapText("B")}}
+
+	fr := runWithDebugger(t, driver, dbg, f)
+
+	if fr.Status != report.StatusPassed {
+		t.Errorf("Status = %v, want passed after retry", fr.Status)
+	}
+	if fr.StepsPassed != 2 || fr.StepsFailed != 0 {
+		t.Errorf("passed=%d failed=%d, want 2/0", fr.StepsPassed, fr.StepsFailed)
+	}
+	if len(dbg.failures) != 1 || len(dbg.seen) != 2 {
+		t.Errorf("failures=%q seen=%q", dbg.failures, dbg.seen)
+	}
+}
+
+func TestDebugger_SkipFailureContinuesButFailsFlow(t *testing.T) {
+	var executed int
+	driver := &mockDriver{executeFunc: func(step flow.Step) *core.CommandResult {
+		executed++
+		if executed == 1 {
+			return &core.CommandResult{Success: false, Error: &testError{msg: "not found"}}
+		}
+		return &core.CommandResult{Success: true}
+	}}
+	dbg := &scriptedDebugger{onFailure: []DebugAction{DebugSkip}}
+	f := flow.Flow{Steps: []flow.Step{tapText("A"), tapText("B")}}
+
+	fr := runWithDebugger(t, driver, dbg, f)
+
+	if executed != 2 {
+		t.Errorf("executed %d steps, want 2", executed)
+	}
+	if fr.Status != report.StatusFailed || fr.Error != "not found" {
+		t.Errorf("Status = %v (%q), want failed with first error", fr.Status, fr.Error)
+	}
+	if fr.StepsPassed != 1 || fr.StepsFailed != 1 {
+		t.Errorf("passed=%d failed=%d, want 1/1", fr.StepsPassed, fr.StepsFailed)
+	}
+}
+
+func TestDebugSession_EvalAndRunStep(t *testing.T) {
+	var evalResult string
+	var evalErr error
+	var adHoc *core.CommandResult
+	dbg := &scriptedDebugger{hook: func(s *DebugSession) {
+		if adHoc != nil {
+			return
+		}
+		evalResult, evalErr = s.Eval("output.greeting = 'hi ' + NAME")
+		adHoc = s.RunStep(tapText("${greeting}"))
+	}}
+	var executed []string
+	driver := &mockDriver{executeFunc: func(step flow.Step) *core.CommandResult {
+		executed = append(executed, step.Describe())
+		return &core.CommandResult{Success: true}
+	}}
+	f := flow.Flow{
+		Config: flow.Config{Env: map[string]string{"NAME": "bob"}},
+		Steps:  []flow.Step{tapText("A")},
+	}
+
+	fr := runWithDebugger(t, driver, dbg, f)
+
+	if evalErr != nil || evalResult != "hi bob" {
+		t.Errorf("Eval = %q, %v", evalResult, evalErr)
+	}
+	if adHoc == nil || !adHoc.Success {
+		t.Errorf("RunStep = %+v", adHoc)
+	}
+	if len(executed) != 2 || executed[0] != `tapOn: text="hi bob"` {
+		t.Errorf("executed = %q", executed)
+	}
+	if fr.StepsTotal != 1 || fr.StepsPassed != 1 {
+		t.Errorf("ad hoc step was counted: total=%d passed=%d", fr.StepsTotal, fr.StepsPassed)
+	}
+}
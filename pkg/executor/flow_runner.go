@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/devicelab-dev/maestro-runner/pkg/core"
+	"github.com/devicelab-dev/maestro-runner/pkg/faker"
 	"github.com/devicelab-dev/maestro-runner/pkg/flow"
 	"github.com/devicelab-dev/maestro-runner/pkg/logger"
+	"github.com/devicelab-dev/maestro-runner/pkg/metrics"
 	"github.com/devicelab-dev/maestro-runner/pkg/report"
 )
 
@@ -31,6 +34,12 @@ type FlowRunner struct {
 	stepsSkipped int
 	// Sub-command tracking for compound steps (runFlow, repeat, retry)
 	subCommands []report.Command
+	// Position of the last failed nested step, to locate compound step failures
+	nestedFailure flow.Position
+	// Output variables the flow starts with (from the suite setup flow) and
+	// those it ends with
+	suiteOutput map[string]interface{}
+	output      map[string]interface{}
 }
 
 // Run executes the flow and returns the result.
@@ -47,6 +56,11 @@ func (fr *FlowRunner) Run() FlowResult {
 	// Initialize script engine
 	fr.script = NewScriptEngine()
 	defer fr.script.Close()
+	defer func() { fr.output = fr.script.GetOutput() }()
+
+	// Mark secret variables before any are set, so their values are redacted
+	fr.script.AddSecretPatterns(fr.config.Secrets...)
+	fr.script.AddSecretPatterns(fr.flow.Config.Secrets...)
 
 	// Import system environment variables
 	fr.script.ImportSystemEnv()
@@ -55,25 +69,51 @@ func (fr *FlowRunner) Run() FlowResult {
 	// These take precedence over system env, but flow-level env takes precedence over these
 	fr.script.SetVariables(fr.config.Env)
 
+	// Output of the suite setup flow on this device
+	if len(fr.suiteOutput) > 0 {
+		fr.script.SetOutput(fr.suiteOutput)
+	}
+
 	// Set flow directory for relative path resolution
 	if fr.flow.SourcePath != "" {
 		fr.script.SetFlowDir(filepath.Dir(fr.flow.SourcePath))
 	}
+	fr.script.SetModulePaths(fr.config.ModulePaths)
+
+	// Script timeout priority: step timeout > flow config > workspace config > default
+	scriptTimeout := fr.config.ScriptTimeout
+	if fr.flow.Config.ScriptTimeout > 0 {
+		scriptTimeout = fr.flow.Config.ScriptTimeout
+	}
+	if scriptTimeout > 0 {
+		fr.script.SetTimeout(time.Duration(scriptTimeout) * time.Millisecond)
+	}
 
 	// Set platform in JS engine
 	if info := fr.driver.GetPlatformInfo(); info != nil {
 		fr.script.SetPlatform(info.Platform)
 	}
 
+	// Seed generated data per flow, independent of the other flows in the run
+	fr.script.SetSeed(faker.Derive(fr.config.Seed, fr.seedKey()))
+
+	// Give scripts access to the device through maestro.*
+	fr.script.SetDevice(&scriptDevice{fr: fr})
+
 	// Apply flow config variables (takes precedence over CLI env)
 	if fr.flow.Config.AppID != "" {
 		fr.script.SetVariable("APP_ID", fr.flow.Config.AppID)
 	}
 	fr.script.SetVariables(fr.flow.Config.Env)
 
-	// Apply commandTimeout if specified - overrides driver's default find timeout
+	// Apply commandTimeout - overrides driver's default find timeout
+	// Priority: flow config > workspace config > driver default
+	commandTimeout := fr.config.CommandTimeout
 	if fr.flow.Config.CommandTimeout > 0 {
-		fr.driver.SetFindTimeout(fr.flow.Config.CommandTimeout)
+		commandTimeout = fr.flow.Config.CommandTimeout
+	}
+	if commandTimeout > 0 {
+		fr.driver.SetFindTimeout(commandTimeout)
 	}
 
 	// Apply waitForIdleTimeout with priority:
@@ -103,23 +143,16 @@ func (fr *FlowRunner) Run() FlowResult {
 	flowStatus := report.StatusPassed
 	var flowError string
 
-	// Execute onFlowComplete in defer (runs even on failure)
-	defer func() {
-		if len(fr.flow.Config.OnFlowComplete) > 0 {
-			for _, step := range fr.flow.Config.OnFlowComplete {
-				fr.executeNestedStep(step) // Ignore failures in cleanup
-			}
-		}
-	}()
-
-	// Execute onFlowStart hooks
-	if len(fr.flow.Config.OnFlowStart) > 0 {
+	// Execute onFlowStart hooks (not when resuming mid-flow)
+	if len(fr.flow.Config.OnFlowStart) > 0 && fr.config.StartStep == 0 {
 		for _, step := range fr.flow.Config.OnFlowStart {
+			fr.nestedFailure = flow.Position{}
 			result := fr.executeNestedStep(step)
 			if !result.Success && !step.IsOptional() {
 				// onFlowStart failed - fail the flow
+				errMsg := "onFlowStart failed: " + fr.locateError(step, fmt.Sprint(result.Error))
+				fr.runEndHooks(report.StatusFailed, errMsg)
 				fr.flowWriter.End(report.StatusFailed)
-				errMsg := fmt.Sprintf("onFlowStart failed: %v", result.Error)
 				if fr.config.OnFlowEnd != nil {
 					fr.config.OnFlowEnd(flowName, false, time.Since(flowStart).Milliseconds(), errMsg)
 				}
@@ -138,6 +171,12 @@ func (fr *FlowRunner) Run() FlowResult {
 		}
 	}
 
+	var debug *DebugSession
+	if fr.config.Debugger != nil {
+		debug = &DebugSession{fr: fr}
+	}
+
+steps:
 	for i, step := range fr.flow.Steps {
 		// Check context cancellation
 		if fr.ctx.Err() != nil {
@@ -147,28 +186,68 @@ func (fr *FlowRunner) Run() FlowResult {
 			break
 		}
 
-		// Execute step
-		stepStatus, stepError, stepDuration := fr.executeStep(i, step)
+		if i < fr.config.StartStep {
+			fr.skipStep(i, step)
+			continue
+		}
+
+		// Expand variables in step before execution
+		original := retryCopy(step)
+		expandCalls := fr.scriptCalls(func() { fr.script.ExpandStep(step) })
+
+		if debug != nil {
+			switch fr.config.Debugger.BeforeStep(debug, i, step) {
+			case DebugSkip:
+				fr.skipStep(i, step)
+				continue
+			case DebugAbort:
+				fr.skipRemaining(i)
+				flowStatus = report.StatusFailed
+				flowError = "aborted from debugger"
+				break steps
+			}
+		}
+
+		// Execute step; the debugger may retry it after a failure
+		var stepStatus report.Status
+		var stepError string
+		action := DebugAbort
+		for {
+			var stepDuration int64
+			fr.nestedFailure = flow.Position{}
+			stepStatus, stepError, stepDuration = fr.executeStep(i, step, original, expandCalls)
+
+			// Notify step complete
+			if fr.config.OnStepComplete != nil {
+				errMsg := stepError
+				if stepStatus == report.StatusFailed {
+					errMsg = fr.locateError(step, stepError)
+				}
+				desc := step.Describe()
+				if stepStatus == report.StatusConditionSkipped {
+					desc += " (skipped: condition not met)"
+				}
+				fr.config.OnStepComplete(i, desc, stepStatus != report.StatusFailed, stepDuration, errMsg)
+			}
 
-		// Notify step complete
-		if fr.config.OnStepComplete != nil {
-			fr.config.OnStepComplete(i, step.Describe(), stepStatus == report.StatusPassed, stepDuration, stepError)
+			if stepStatus != report.StatusFailed || step.IsOptional() || debug == nil {
+				break
+			}
+			action = fr.config.Debugger.OnFailure(debug, i, step, stepError)
+			if action != DebugRun {
+				break
+			}
 		}
 
 		// Track step counts (compound steps like runFlow/repeat/retry don't count themselves,
 		// their sub-steps are counted individually in executeNestedStep)
-		isCompoundStep := false
-		switch step.(type) {
-		case *flow.RepeatStep, *flow.RetryStep, *flow.RunFlowStep:
-			isCompoundStep = true
-		}
-		if !isCompoundStep {
+		if !isCompound(step) {
 			switch stepStatus {
 			case report.StatusPassed:
 				fr.stepsPassed++
 			case report.StatusFailed:
 				fr.stepsFailed++
-			case report.StatusSkipped:
+			case report.StatusSkipped, report.StatusConditionSkipped:
 				fr.stepsSkipped++
 			}
 		}
@@ -179,24 +258,22 @@ func (fr *FlowRunner) Run() FlowResult {
 				// Optional step failure doesn't fail flow
 				continue
 			}
-			// Required step failed - skip remaining and fail flow
-			fr.flowWriter.SkipRemainingCommands(i + 1)
-			// Count remaining non-compound steps as skipped
-			for j := i + 1; j < len(fr.flow.Steps); j++ {
-				switch fr.flow.Steps[j].(type) {
-				case *flow.RepeatStep, *flow.RetryStep, *flow.RunFlowStep:
-					// Compound steps don't count themselves
-				default:
-					fr.stepsSkipped++
-				}
-			}
 			flowStatus = report.StatusFailed
-			flowError = stepError
+			if flowError == "" {
+				flowError = fr.locateError(step, stepError)
+			}
+			if action == DebugSkip {
+				// Failure accepted in the debugger - keep going
+				continue
+			}
+			// Required step failed - skip remaining and fail flow
+			fr.skipRemaining(i + 1)
 			break
 		}
 	}
 
-	// Mark flow as complete
+	// Run onFlowFailure and onFlowComplete, then mark flow as complete
+	fr.runEndHooks(flowStatus, flowError)
 	fr.flowWriter.End(flowStatus)
 
 	// Calculate duration
@@ -223,15 +300,114 @@ func (fr *FlowRunner) Run() FlowResult {
 	}
 }
 
+// runEndHooks runs the onFlowFailure hook if the flow failed, with the
+// failure available as ${FLOW_ERROR}, then the onFlowComplete hook. Hook
+// failures are logged and recorded in the report but don't change the
+// flow's result.
+func (fr *FlowRunner) runEndHooks(status report.Status, flowError string) {
+	var onFailure []report.Command
+	if status == report.StatusFailed {
+		fr.script.SetVariable("FLOW_ERROR", flowError)
+		onFailure = fr.runHook("onFlowFailure", fr.flow.Config.OnFlowFailure)
+	}
+	onComplete := fr.runHook("onFlowComplete", fr.flow.Config.OnFlowComplete)
+	fr.flowWriter.SetHookCommands(onFailure, onComplete)
+}
+
+// runHook runs every step of a lifecycle hook, even after one fails, and
+// returns their report entries. Hook steps don't count towards the flow's
+// step totals.
+func (fr *FlowRunner) runHook(name string, steps []flow.Step) []report.Command {
+	if len(steps) == 0 {
+		return nil
+	}
+	passed, failed, skipped := fr.stepsPassed, fr.stepsFailed, fr.stepsSkipped
+	parentSubCommands := fr.subCommands
+	fr.subCommands = nil
+
+	for _, step := range steps {
+		fr.nestedFailure = flow.Position{}
+		result := fr.executeNestedStep(step)
+		if !result.Success && !step.IsOptional() {
+			logger.Warn("%s failed: %s", name, fr.locateError(step, fmt.Sprint(result.Error)))
+		}
+	}
+
+	commands := fr.subCommands
+	fr.subCommands = parentSubCommands
+	fr.stepsPassed, fr.stepsFailed, fr.stepsSkipped = passed, failed, skipped
+	return commands
+}
+
+// skipStep marks a single step as skipped without running it.
+func (fr *FlowRunner) skipStep(idx int, step flow.Step) {
+	fr.flowWriter.CommandEnd(idx, report.StatusSkipped, nil, nil, report.CommandArtifacts{})
+	if !isCompound(step) {
+		fr.stepsSkipped++
+	}
+}
+
+// skipRemaining marks steps from index onwards as skipped.
+func (fr *FlowRunner) skipRemaining(from int) {
+	fr.flowWriter.SkipRemainingCommands(from)
+	// Count remaining non-compound steps as skipped
+	for _, step := range fr.flow.Steps[from:] {
+		if !isCompound(step) {
+			fr.stepsSkipped++
+		}
+	}
+}
+
+// isCompound reports whether a step only runs other steps. Compound steps
+// (runFlow, repeat, forEach, retry, if, custom commands) don't count themselves;
+// their sub-steps do.
+func isCompound(step flow.Step) bool {
+	switch step.(type) {
+	case *flow.RepeatStep, *flow.ForEachStep, *flow.RetryStep, *flow.RunFlowStep, *flow.IfStep, *flow.CustomCommandStep:
+		return true
+	}
+	return false
+}
+
+// locateError prefixes a step's error with where it failed in the flow
+// file: the innermost failed step for runFlow/repeat/retry.
+func (fr *FlowRunner) locateError(step flow.Step, errMsg string) string {
+	pos := step.Position()
+	if isCompound(step) && !fr.nestedFailure.IsZero() {
+		pos = fr.nestedFailure
+	}
+	if pos.IsZero() {
+		return errMsg
+	}
+	return pos.String() + ": " + errMsg
+}
+
 // executeStep executes a single step and updates the report.
 // Returns status, error message, and duration in milliseconds.
-func (fr *FlowRunner) executeStep(idx int, step flow.Step) (report.Status, string, int64) {
+// original is the step before its variables were expanded, for the retry
+// after onFailure (nil if it has none). expandCalls are the device calls
+// scripts made while the step was expanded, reported as its first
+// sub-commands.
+func (fr *FlowRunner) executeStep(idx int, step, original flow.Step, expandCalls []report.Command) (report.Status, string, int64) {
 	stepStart := time.Now()
 
 	logger.Debug("Executing step %d: %s", idx, step.Describe())
 
 	// Mark step as started
 	fr.flowWriter.CommandStart(idx)
+	fr.subCommands = append([]report.Command(nil), expandCalls...)
+
+	// Steps whose when condition is not met don't run
+	if !fr.conditionMet(step) {
+		logger.Info("Step %d skipped, when condition not met: %s", idx, step.Describe())
+		if len(fr.subCommands) > 0 {
+			fr.flowWriter.CommandEndWithSubs(idx, report.StatusConditionSkipped, nil, nil, report.CommandArtifacts{}, fr.subCommands)
+			fr.subCommands = nil
+		} else {
+			fr.flowWriter.CommandEnd(idx, report.StatusConditionSkipped, nil, nil, report.CommandArtifacts{})
+		}
+		return report.StatusConditionSkipped, "", time.Since(stepStart).Milliseconds()
+	}
 
 	// Determine what artifacts to capture
 	captureAlways := fr.config.Artifacts == ArtifactAlways
@@ -243,68 +419,124 @@ func (fr *FlowRunner) executeStep(idx int, step flow.Step) (report.Status, strin
 		artifacts = fr.captureArtifacts(idx, "before")
 	}
 
-	// Expand variables in step before execution
-	fr.script.ExpandStep(step)
+	// Execute step; scripts, compound steps and onFailure recovery add
+	// sub-commands
+	result := fr.runStep(step)
+	if !result.Success && len(step.Recovery()) > 0 {
+		result = fr.recoverStep(step, original, result, func(retry flow.Step) *core.CommandResult {
+			fr.script.ExpandStep(retry)
+			return fr.runStep(retry)
+		})
+	}
 
-	// Execute step - route to appropriate handler
-	var result *core.CommandResult
+	stepDuration := time.Since(stepStart).Milliseconds()
+
+	// Determine status and error
+	var status report.Status
+	var errorInfo *report.Error
+	var errorMsg string
+
+	if result.Success {
+		status = report.StatusPassed
+		logger.Debug("Step %d completed successfully (%dms): %s", idx, stepDuration, step.Describe())
+	} else {
+		status = report.StatusFailed
+		errorInfo = commandResultToError(result)
+		if errorInfo != nil {
+			errorMsg = errorInfo.Message
+		}
+		logger.Error("Step %d failed (%dms): %s - Error: %s", idx, stepDuration, step.Describe(), errorMsg)
+	}
+	metrics.RecordCommand(string(step.Type()), fr.config.DriverName, string(status), time.Since(stepStart))
+
+	// Capture after screenshot (on failure or always)
+	shouldCaptureAfter := captureAlways || (captureOnFailure && !result.Success)
+	if shouldCaptureAfter {
+		afterArtifacts := fr.captureArtifacts(idx, "after")
+		artifacts.ScreenshotAfter = afterArtifacts.ScreenshotAfter
+		artifacts.ViewHierarchy = afterArtifacts.ViewHierarchy
+	}
+
+	// Convert element info
+	var element *report.Element
+	if result.Element != nil {
+		element = commandResultToElement(result)
+	}
+
+	// Update report - use CommandEndWithSubs for compound steps and recovered steps
+	if isCompound(step) || len(fr.subCommands) > 0 {
+		fr.flowWriter.CommandEndWithSubs(idx, status, element, errorInfo, artifacts, fr.subCommands)
+		fr.subCommands = nil // Clear after use
+	} else {
+		fr.flowWriter.CommandEnd(idx, status, element, errorInfo, artifacts)
+	}
+
+	return status, errorMsg, stepDuration
+}
 
+// runStep routes a top-level step to its handler.
+func (fr *FlowRunner) runStep(step flow.Step) *core.CommandResult {
 	switch s := step.(type) {
 	// JS/Scripting steps - handled by ScriptEngine
 	case *flow.DefineVariablesStep:
-		result = fr.script.ExecuteDefineVariables(s)
+		return fr.script.ExecuteDefineVariables(s)
 	case *flow.RunScriptStep:
-		result = fr.script.ExecuteRunScript(s)
+		return fr.script.ExecuteRunScript(s)
 	case *flow.EvalScriptStep:
-		result = fr.script.ExecuteEvalScript(s)
+		return fr.script.ExecuteEvalScript(s)
 	case *flow.AssertTrueStep:
-		result = fr.script.ExecuteAssertTrue(s)
+		return fr.script.ExecuteAssertTrue(s)
 	case *flow.AssertConditionStep:
-		result = fr.script.ExecuteAssertCondition(fr.ctx, s, fr.driver)
+		return fr.script.ExecuteAssertCondition(fr.ctx, s, fr.driver)
+	case *flow.InputRandomStep:
+		return fr.script.ExecuteInputRandom(s, fr.driver)
 
 	// Flow control steps - handled by FlowRunner
-	// Clear sub-commands before compound step execution
 	case *flow.RepeatStep:
-		fr.subCommands = nil
-		result = fr.executeRepeat(s)
+		return fr.executeRepeat(s)
+	case *flow.ForEachStep:
+		return fr.executeForEach(s)
 	case *flow.RetryStep:
-		fr.subCommands = nil
-		result = fr.executeRetry(s)
+		return fr.executeRetry(s)
 	case *flow.RunFlowStep:
-		fr.subCommands = nil
-		result = fr.executeRunFlow(s)
+		return fr.executeRunFlow(s)
+	case *flow.IfStep:
+		return fr.executeIf(s)
+	case *flow.CustomCommandStep:
+		return fr.executeCustomCommand(s)
 
 	// App lifecycle steps - inject flow's appId if not specified
 	case *flow.LaunchAppStep:
 		if s.AppID == "" && fr.flow.Config.AppID != "" {
 			s.AppID = fr.flow.Config.AppID
 		}
-		result = fr.driver.Execute(step)
+		return fr.driver.Execute(step)
 	case *flow.StopAppStep:
 		if s.AppID == "" && fr.flow.Config.AppID != "" {
 			s.AppID = fr.flow.Config.AppID
 		}
-		result = fr.driver.Execute(step)
+		return fr.driver.Execute(step)
 	case *flow.KillAppStep:
 		if s.AppID == "" && fr.flow.Config.AppID != "" {
 			s.AppID = fr.flow.Config.AppID
 		}
-		result = fr.driver.Execute(step)
+		return fr.driver.Execute(step)
 	case *flow.ClearStateStep:
 		if s.AppID == "" && fr.flow.Config.AppID != "" {
 			s.AppID = fr.flow.Config.AppID
 		}
-		result = fr.driver.Execute(step)
+		return fr.driver.Execute(step)
 
 	// CopyTextFrom - delegate to driver and sync copied text to script engine
 	case *flow.CopyTextFromStep:
 		fr.script.ExpandStep(step) // Expand variables in selector
-		result = fr.driver.Execute(step)
+		result := fr.driver.Execute(step)
 		if result.Success && result.Data != nil {
 			if text, ok := result.Data.(string); ok {
 				fr.script.SetCopiedText(text)
 			}
 		}
+		return result
 
 	// PasteText - use in-memory copiedText first, clipboard as fallback
 	case *flow.PasteTextStep:
@@ -312,63 +544,47 @@ func (fr *FlowRunner) executeStep(idx int, step flow.Step) (report.Status, strin
 		if text != "" {
 			// Use stored copiedText (like Maestro does)
 			inputStep := &flow.InputTextStep{Text: text}
-			result = fr.driver.Execute(inputStep)
+			result := fr.driver.Execute(inputStep)
 			if result.Success {
 				result.Message = fmt.Sprintf("Pasted text: %s", text)
 			}
-		} else {
-			// Fallback to clipboard
-			result = fr.driver.Execute(step)
+			return result
 		}
+		// Fallback to clipboard
+		return fr.driver.Execute(step)
 
 	// All other steps - delegate to driver
 	default:
-		result = fr.driver.Execute(step)
-	}
-
-	stepDuration := time.Since(stepStart).Milliseconds()
-
-	// Determine status and error
-	var status report.Status
-	var errorInfo *report.Error
-	var errorMsg string
-
-	if result.Success {
-		status = report.StatusPassed
-		logger.Debug("Step %d completed successfully (%dms): %s", idx, stepDuration, step.Describe())
-	} else {
-		status = report.StatusFailed
-		errorInfo = commandResultToError(result)
-		if errorInfo != nil {
-			errorMsg = errorInfo.Message
-		}
-		logger.Error("Step %d failed (%dms): %s - Error: %s", idx, stepDuration, step.Describe(), errorMsg)
+		return fr.driver.Execute(step)
 	}
 
-	// Capture after screenshot (on failure or always)
-	shouldCaptureAfter := captureAlways || (captureOnFailure && !result.Success)
-	if shouldCaptureAfter {
-		afterArtifacts := fr.captureArtifacts(idx, "after")
-		artifacts.ScreenshotAfter = afterArtifacts.ScreenshotAfter
-		artifacts.ViewHierarchy = afterArtifacts.ViewHierarchy
-	}
+}
 
-	// Convert element info
-	var element *report.Element
-	if result.Element != nil {
-		element = commandResultToElement(result)
+// retryCopy returns an unexpanded copy of a step with onFailure steps, to
+// retry after them, or nil for a step without.
+func retryCopy(step flow.Step) flow.Step {
+	if len(step.Recovery()) == 0 {
+		return nil
 	}
+	return flow.Clone(step)
+}
 
-	// Update report - use CommandEndWithSubs for compound steps
-	switch step.(type) {
-	case *flow.RepeatStep, *flow.RetryStep, *flow.RunFlowStep:
-		fr.flowWriter.CommandEndWithSubs(idx, status, element, errorInfo, artifacts, fr.subCommands)
-		fr.subCommands = nil // Clear after use
-	default:
-		fr.flowWriter.CommandEnd(idx, status, element, errorInfo, artifacts)
+// recoverStep runs a failed step's onFailure steps and then retries the step
+// once with run. The retry is a fresh copy of original, the step before its
+// variables were expanded, so it sees variables the recovery steps changed.
+// If a required recovery step fails, the step is not retried and its original
+// failure stands.
+func (fr *FlowRunner) recoverStep(step, original flow.Step, failed *core.CommandResult, run func(flow.Step) *core.CommandResult) *core.CommandResult {
+	logger.Info("Step failed, running onFailure: %s", step.Describe())
+	for _, recovery := range step.Recovery() {
+		result := fr.executeNestedStep(recovery)
+		if !result.Success && !recovery.IsOptional() {
+			logger.Warn("onFailure of %s failed, not retrying: %v", step.Describe(), result.Error)
+			return failed
+		}
 	}
-
-	return status, errorMsg, stepDuration
+	logger.Info("Retrying step after onFailure: %s", step.Describe())
+	return run(flow.Clone(original))
 }
 
 // executeRepeat handles repeat step execution.
@@ -412,6 +628,61 @@ func (fr *FlowRunner) executeRepeat(step *flow.RepeatStep) *core.CommandResult {
 	}
 }
 
+// executeForEach runs a forEach step's commands once per item, with the
+// item and its index bound as variables. Each iteration runs fresh copies of
+// the commands, as steps are expanded in place.
+func (fr *FlowRunner) executeForEach(step *flow.ForEachStep) *core.CommandResult {
+	items, err := fr.script.ForEachItems(step.Items)
+	if err != nil {
+		return &core.CommandResult{
+			Success: false,
+			Error:   err,
+			Message: fmt.Sprintf("forEach items: %v", err),
+		}
+	}
+
+	// Restore the loop variables afterwards
+	itemVar, indexVar := step.ItemVar(), step.IndexVar()
+	defer fr.script.withEnvVars(map[string]string{
+		itemVar:  fr.script.GetVariable(itemVar),
+		indexVar: fr.script.GetVariable(indexVar),
+	})()
+
+	ran := 0
+	for i, item := range items {
+		if fr.ctx.Err() != nil {
+			return &core.CommandResult{
+				Success: false,
+				Error:   fr.ctx.Err(),
+				Message: "forEach cancelled",
+			}
+		}
+
+		fr.script.SetItemVariable(itemVar, item)
+		fr.script.SetVariable(indexVar, strconv.Itoa(i))
+
+		if step.BreakIf != nil && fr.script.CheckCondition(fr.ctx, *step.BreakIf, fr.driver) {
+			break
+		}
+		if step.ContinueIf != nil && fr.script.CheckCondition(fr.ctx, *step.ContinueIf, fr.driver) {
+			continue
+		}
+
+		ran++
+		for _, nestedStep := range flow.CloneSteps(step.Steps) {
+			result := fr.executeNestedStep(nestedStep)
+			if !result.Success && !nestedStep.IsOptional() {
+				return result
+			}
+		}
+	}
+
+	return &core.CommandResult{
+		Success: true,
+		Message: fmt.Sprintf("forEach completed (%d of %d items)", ran, len(items)),
+	}
+}
+
 // executeRetry handles retry step execution.
 func (fr *FlowRunner) executeRetry(step *flow.RetryStep) *core.CommandResult {
 	maxRetries := fr.script.ParseInt(step.MaxRetries, 3)
@@ -471,16 +742,6 @@ func (fr *FlowRunner) executeRetry(step *flow.RetryStep) *core.CommandResult {
 
 // executeRunFlow handles runFlow step execution.
 func (fr *FlowRunner) executeRunFlow(step *flow.RunFlowStep) *core.CommandResult {
-	// Check when condition
-	if step.When != nil {
-		if !fr.script.CheckCondition(fr.ctx, *step.When, fr.driver) {
-			return &core.CommandResult{
-				Success: true,
-				Message: "Skipped (when condition not met)",
-			}
-		}
-	}
-
 	// Report nested flow start
 	if fr.config.OnNestedFlowStart != nil && step.File != "" {
 		fr.config.OnNestedFlowStart(fr.depth+1, "Run "+step.File)
@@ -529,59 +790,107 @@ func (fr *FlowRunner) executeRunFlow(step *flow.RunFlowStep) *core.CommandResult
 	return fr.executeSubFlow(*subFlow)
 }
 
+// executeIf runs the then branch of an if step when its condition holds,
+// and the else branch otherwise.
+func (fr *FlowRunner) executeIf(step *flow.IfStep) *core.CommandResult {
+	branch, name := step.Then, "then"
+	if !fr.script.CheckCondition(fr.ctx, step.Condition, fr.driver) {
+		branch, name = step.Else, "else"
+	}
+
+	for _, nestedStep := range branch {
+		result := fr.executeNestedStep(nestedStep)
+		if !result.Success && !nestedStep.IsOptional() {
+			return result
+		}
+	}
+
+	return &core.CommandResult{
+		Success: true,
+		Message: fmt.Sprintf("If completed (%s branch)", name),
+	}
+}
+
+// conditionMet reports whether a step's when condition holds. Steps without
+// one always run.
+func (fr *FlowRunner) conditionMet(step flow.Step) bool {
+	cond := step.WhenCondition()
+	return cond == nil || fr.script.CheckCondition(fr.ctx, *cond, fr.driver)
+}
+
+// scriptCalls runs fn and returns the device calls scripts made meanwhile,
+// keeping them out of the current step's sub-commands.
+func (fr *FlowRunner) scriptCalls(fn func()) []report.Command {
+	saved := fr.subCommands
+	fr.subCommands = nil
+	fn()
+	calls := fr.subCommands
+	fr.subCommands = saved
+	return calls
+}
+
+// executeCustomCommand runs a custom command's flow with its arguments as
+// env. Arguments are expanded in the caller's scope and override the
+// command's own env.
+func (fr *FlowRunner) executeCustomCommand(step *flow.CustomCommandStep) *core.CommandResult {
+	subFlow, err := flow.ParseFile(step.File)
+	if err != nil {
+		return &core.CommandResult{
+			Success: false,
+			Error:   err,
+			Message: fmt.Sprintf("Failed to parse command file: %s", step.File),
+		}
+	}
+
+	env := make(map[string]string, len(subFlow.Config.Env)+len(step.Params))
+	for k, v := range subFlow.Config.Env {
+		env[k] = v
+	}
+	for k, v := range step.Params {
+		env[k] = fr.script.ExpandVariables(v)
+	}
+	subFlow.Config.Env = env
+
+	if fr.config.OnNestedFlowStart != nil {
+		fr.config.OnNestedFlowStart(fr.depth+1, step.Describe())
+	}
+	fr.depth++
+	defer func() { fr.depth-- }()
+
+	result := fr.executeSubFlow(*subFlow)
+	if result.Success {
+		result.Message = fmt.Sprintf("Command '%s' completed", step.Name)
+	}
+	return result
+}
+
 // executeNestedStep executes a step without report tracking (for nested execution).
 func (fr *FlowRunner) executeNestedStep(step flow.Step) *core.CommandResult {
 	start := time.Now()
 	var result *core.CommandResult
 
-	// For nested compound steps, we need to track their sub-commands separately
-	var nestedSubCommands []report.Command
-	isCompoundStep := false
-	switch step.(type) {
-	case *flow.RepeatStep, *flow.RetryStep, *flow.RunFlowStep:
-		isCompoundStep = true
-		// Save parent's subCommands and start fresh for this nested compound step
-		parentSubCommands := fr.subCommands
-		fr.subCommands = nil
-		defer func() {
-			nestedSubCommands = fr.subCommands
-			fr.subCommands = parentSubCommands
-		}()
+	// Nested steps track their sub-commands (device calls from scripts,
+	// including the when condition, and the steps of compound steps and
+	// onFailure recovery) separately: save the parent's and start fresh
+	isCompoundStep := isCompound(step)
+	parentSubCommands := fr.subCommands
+	fr.subCommands = nil
+
+	if !fr.conditionMet(step) {
+		conditionCalls := fr.subCommands
+		fr.subCommands = parentSubCommands
+		return fr.skipNestedStep(step, start, conditionCalls)
 	}
 
-	switch s := step.(type) {
-	case *flow.DefineVariablesStep:
-		result = fr.script.ExecuteDefineVariables(s)
-	case *flow.RunScriptStep:
-		result = fr.script.ExecuteRunScript(s)
-	case *flow.EvalScriptStep:
-		result = fr.script.ExecuteEvalScript(s)
-	case *flow.AssertTrueStep:
-		result = fr.script.ExecuteAssertTrue(s)
-	case *flow.AssertConditionStep:
-		result = fr.script.ExecuteAssertCondition(fr.ctx, s, fr.driver)
-	case *flow.RepeatStep:
-		result = fr.executeRepeat(s)
-	case *flow.RetryStep:
-		result = fr.executeRetry(s)
-	case *flow.RunFlowStep:
-		result = fr.executeRunFlow(s)
-	case *flow.CopyTextFromStep:
-		// Expand variables before driver execution
-		fr.script.ExpandStep(step)
-		result = fr.driver.Execute(step)
-		// Sync copied text to script engine
-		if result.Success && result.Data != nil {
-			if text, ok := result.Data.(string); ok {
-				fr.script.SetCopiedText(text)
-			}
-		}
-	default:
-		// Expand variables before driver execution
-		fr.script.ExpandStep(step)
-		result = fr.driver.Execute(step)
+	original := retryCopy(step)
+	result = fr.runNestedStep(step)
+	if !result.Success && len(step.Recovery()) > 0 {
+		result = fr.recoverStep(step, original, result, fr.runNestedStep)
 	}
 
+	nestedSubCommands := fr.subCommands
+	fr.subCommands = parentSubCommands
+
 	duration := time.Since(start).Milliseconds()
 
 	// Track nested step counts (compound steps like runFlow/repeat/retry don't count themselves)
@@ -590,6 +899,7 @@ func (fr *FlowRunner) executeNestedStep(step flow.Step) *core.CommandResult {
 			fr.stepsPassed++
 		} else {
 			fr.stepsFailed++
+			fr.nestedFailure = step.Position()
 		}
 	}
 
@@ -609,12 +919,14 @@ func (fr *FlowRunner) executeNestedStep(step flow.Step) *core.CommandResult {
 	}
 
 	now := time.Now()
+	metrics.RecordCommand(string(step.Type()), fr.config.DriverName, string(status), now.Sub(start))
 	cmd := report.Command{
 		ID:        fmt.Sprintf("sub-%d", len(fr.subCommands)),
 		Index:     len(fr.subCommands),
 		Type:      string(step.Type()),
 		Label:     step.Label(),
-		YAML:      step.Describe(),
+		YAML:      report.StepYAML(step),
+		Location:  step.Position().String(),
 		Status:    status,
 		StartTime: &start,
 		EndTime:   &now,
@@ -629,16 +941,109 @@ func (fr *FlowRunner) executeNestedStep(step flow.Step) *core.CommandResult {
 		}
 	}
 
-	// Add nested sub-commands for compound steps
-	if isCompoundStep {
-		cmd.SubCommands = nestedSubCommands
-	}
+	// Add nested sub-commands for compound and recovered steps
+	cmd.SubCommands = nestedSubCommands
 
 	fr.subCommands = append(fr.subCommands, cmd)
 
 	return result
 }
 
+// runNestedStep routes a nested step to its handler.
+func (fr *FlowRunner) runNestedStep(step flow.Step) *core.CommandResult {
+	switch s := step.(type) {
+	case *flow.DefineVariablesStep:
+		return fr.script.ExecuteDefineVariables(s)
+	case *flow.RunScriptStep:
+		return fr.script.ExecuteRunScript(s)
+	case *flow.EvalScriptStep:
+		return fr.script.ExecuteEvalScript(s)
+	case *flow.AssertTrueStep:
+		return fr.script.ExecuteAssertTrue(s)
+	case *flow.AssertConditionStep:
+		return fr.script.ExecuteAssertCondition(fr.ctx, s, fr.driver)
+	case *flow.InputRandomStep:
+		return fr.script.ExecuteInputRandom(s, fr.driver)
+	case *flow.RepeatStep:
+		return fr.executeRepeat(s)
+	case *flow.ForEachStep:
+		return fr.executeForEach(s)
+	case *flow.RetryStep:
+		return fr.executeRetry(s)
+	case *flow.RunFlowStep:
+		return fr.executeRunFlow(s)
+	case *flow.IfStep:
+		return fr.executeIf(s)
+	case *flow.CustomCommandStep:
+		return fr.executeCustomCommand(s)
+	case *flow.CopyTextFromStep:
+		// Expand variables before driver execution
+		fr.script.ExpandStep(step)
+		result := fr.driver.Execute(step)
+		// Sync copied text to script engine
+		if result.Success && result.Data != nil {
+			if text, ok := result.Data.(string); ok {
+				fr.script.SetCopiedText(text)
+			}
+		}
+		return result
+	default:
+		// Expand variables before driver execution
+		fr.script.ExpandStep(step)
+		return fr.driver.Execute(step)
+	}
+}
+
+// skipNestedStep records a nested step whose when condition is not met,
+// with the device calls its condition made.
+func (fr *FlowRunner) skipNestedStep(step flow.Step, start time.Time, conditionCalls []report.Command) *core.CommandResult {
+	if !isCompound(step) {
+		fr.stepsSkipped++
+	}
+	if fr.config.OnNestedStep != nil && fr.depth > 0 {
+		fr.config.OnNestedStep(fr.depth, step.Describe()+" (skipped: condition not met)", true, 0, "")
+	}
+
+	now := time.Now()
+	duration := now.Sub(start).Milliseconds()
+	fr.subCommands = append(fr.subCommands, report.Command{
+		ID:          fmt.Sprintf("sub-%d", len(fr.subCommands)),
+		Index:       len(fr.subCommands),
+		Type:        string(step.Type()),
+		Label:       step.Label(),
+		YAML:        report.StepYAML(step),
+		Location:    step.Position().String(),
+		Status:      report.StatusConditionSkipped,
+		StartTime:   &start,
+		EndTime:     &now,
+		Duration:    &duration,
+		SubCommands: conditionCalls,
+	})
+
+	return &core.CommandResult{
+		Success: true,
+		Message: "Skipped (when condition not met)",
+	}
+}
+
+// seedKey identifies the flow for seeding its generated data: its path
+// relative to FlowRoot, plus its dataset row. Unlike the flow's name it is
+// unique in the run, and unlike the path as typed it is the same on a rerun.
+func (fr *FlowRunner) seedKey() string {
+	path := fr.flow.SourcePath
+	if path == "" {
+		return fr.detail.Name + "#" + fr.flow.DataKey
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		if root, err := filepath.Abs(fr.config.FlowRoot); err == nil {
+			if rel, err := filepath.Rel(root, abs); err == nil {
+				path = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path)) + "#" + fr.flow.DataKey
+}
+
 // executeSubFlow executes a sub-flow without separate report tracking.
 func (fr *FlowRunner) executeSubFlow(subFlow flow.Flow) *core.CommandResult {
 	// Save current flow dir
@@ -646,9 +1051,10 @@ func (fr *FlowRunner) executeSubFlow(subFlow flow.Flow) *core.CommandResult {
 	if subFlow.SourcePath != "" {
 		fr.script.SetFlowDir(filepath.Dir(subFlow.SourcePath))
 	}
-	defer func() { fr.script.flowDir = prevDir }()
+	defer fr.script.SetFlowDir(prevDir) // Also resets require()'s base directory
 
-	// Apply sub-flow env
+	// Apply sub-flow env, marking its secrets first
+	fr.script.AddSecretPatterns(subFlow.Config.Secrets...)
 	defer fr.script.withEnvVars(subFlow.Config.Env)()
 
 	// Execute steps
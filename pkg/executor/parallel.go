@@ -8,6 +8,7 @@ import (
 
 	"github.com/devicelab-dev/maestro-runner/pkg/core"
 	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/devicelab-dev/maestro-runner/pkg/metrics"
 	"github.com/devicelab-dev/maestro-runner/pkg/report"
 )
 
@@ -20,9 +21,13 @@ type DeviceWorker struct {
 }
 
 // workItem represents a flow and its index in the original flow list.
+// Flows in executionOrder are queued as one item, the rest of the order in
+// next, so a single worker runs them in sequence (unless no device meets
+// the requirements of all of them).
 type workItem struct {
 	flow  flow.Flow
 	index int
+	next  []workItem
 }
 
 // ParallelRunner coordinates parallel test execution across multiple devices.
@@ -77,7 +82,9 @@ func NewParallelRunner(workers []DeviceWorker, config RunnerConfig) *ParallelRun
 }
 
 // Run executes flows in parallel using a work queue pattern.
-// All workers pull from the same queue until all flows are complete.
+// All workers pull from the same queue until all flows are complete; each
+// only takes flows whose device requirements its device meets, longest
+// expected first. Flows no device meets the requirements of are skipped.
 func (pr *ParallelRunner) Run(ctx context.Context, flows []flow.Flow) (*RunResult, error) {
 	if len(pr.workers) == 0 {
 		return nil, fmt.Errorf("no workers available")
@@ -91,6 +98,7 @@ func (pr *ParallelRunner) Run(ctx context.Context, flows []flow.Flow) (*RunResul
 		CI:            pr.config.CI,
 		RunnerVersion: pr.config.RunnerVersion,
 		DriverName:    pr.config.DriverName,
+		Seed:          pr.config.Seed,
 	}
 
 	index, flowDetails, err := report.BuildSkeleton(flows, builderCfg)
@@ -111,15 +119,24 @@ func (pr *ParallelRunner) Run(ctx context.Context, flows []flow.Flow) (*RunResul
 	indexWriter.Start()
 	startTime := time.Now()
 
-	// Create work queue with flow indices
-	workQueue := make(chan workItem, len(flows))
-	for i, f := range flows {
-		workQueue <- workItem{flow: f, index: i}
+	// Queue the flows for the devices that can run them
+	profiles := make([]deviceProfile, len(pr.workers))
+	for i, w := range pr.workers {
+		profiles[i] = newDeviceProfile(w.Driver.GetPlatformInfo(), pr.config.DeviceGroups)
 	}
-	close(workQueue)
+	ordered := min(pr.config.OrderedFlows, len(flows))
+	items, unplaced := planWork(flows, ordered, profiles)
+	queue := newScheduler(items, ordered, expectedDurations(flowDetails, pr.config.ExpectedDurations))
 
 	// Results collection
 	results := make([]FlowResult, len(flows))
+	var unschedulableFlows []UnschedulableFlow
+	for _, i := range unplaced {
+		var u UnschedulableFlow
+		results[i], u = markUnschedulable(indexWriter, &flowDetails[i], flows[i])
+		unschedulableFlows = append(unschedulableFlows, u)
+	}
+	var failedDevices []DeviceFailure
 	var resultsMu sync.Mutex
 	var wg sync.WaitGroup
 
@@ -129,6 +146,7 @@ func (pr *ParallelRunner) Run(ctx context.Context, flows []flow.Flow) (*RunResul
 	for i := range pr.workers {
 		wg.Add(1)
 		worker := pr.workers[i]
+		profile := profiles[i]
 
 		go func(w DeviceWorker) {
 			defer wg.Done()
@@ -187,6 +205,12 @@ func (pr *ParallelRunner) Run(ctx context.Context, flows []flow.Flow) (*RunResul
 				}
 			}
 
+			workerConfig.OnSuiteHookStart = func(hook, file string) {
+				pr.outputMutex.Lock()
+				defer pr.outputMutex.Unlock()
+				fmt.Printf("%s (%s) - %s⚡ Started%s on %s\n", hook, file, color(colorCyan), color(colorReset), deviceLabel)
+			}
+
 			// Suppress detailed command output during parallel execution
 			workerConfig.OnStepComplete = func(idx int, desc string, passed bool, durationMs int64, errMsg string) {}
 			workerConfig.OnNestedStep = func(depth int, desc string, passed bool, durationMs int64, errMsg string) {}
@@ -198,18 +222,51 @@ func (pr *ParallelRunner) Run(ctx context.Context, flows []flow.Flow) (*RunResul
 				driver: w.Driver,
 			}
 
-			// Process flows from queue
-			for item := range workQueue {
-				// Update flow detail with actual device
-				flowDetails[item.index].Device = deviceInfo
-
-				// Execute flow
-				result := runner.executeFlow(ctx, item.flow, &flowDetails[item.index], indexWriter, item.index, totalFlows)
+			// Track busy/idle time for utilization metrics
+			metrics.WorkerStarted(deviceInfo.ID)
+			defer metrics.WorkerStopped(deviceInfo.ID)
 
-				// Store result
+			defer runner.runSuiteHook(ctx, hookAfterSuite, pr.config.AfterSuite, fmt.Sprintf("%s-%d", hookAfterSuite, w.ID), indexWriter)
+			if msg := runner.runSuiteHook(ctx, hookBeforeSuite, pr.config.BeforeSuite, fmt.Sprintf("%s-%d", hookBeforeSuite, w.ID), indexWriter); msg != "" {
+				// Mark the worker failed; the others take its share of the queue
 				resultsMu.Lock()
-				results[item.index] = result
+				failedDevices = append(failedDevices, *runner.setupFailure)
 				resultsMu.Unlock()
+
+				pr.outputMutex.Lock()
+				fmt.Printf("%s✗ %s failed%s on %s, its flows go to the other devices: %s\n",
+					color(colorRed), hookBeforeSuite, color(colorReset), deviceLabel, msg)
+				pr.outputMutex.Unlock()
+				return
+			}
+
+			// Process the queued flows this device can run
+			for {
+				item, ok := queue.next(profile)
+				if !ok {
+					break
+				}
+				failed := false
+				for _, it := range append([]workItem{item}, item.next...) {
+					var result FlowResult
+					if failed {
+						result = skipFlow(indexWriter, &flowDetails[it.index], skipOrderFailed)
+					} else {
+						// Update flow detail with actual device
+						flowDetails[it.index].Device = deviceInfo
+
+						// Execute flow
+						metrics.WorkerFlowStarted(deviceInfo.ID)
+						result = runner.executeFlow(ctx, it.flow, &flowDetails[it.index], indexWriter, it.index, totalFlows)
+						metrics.WorkerFlowFinished(deviceInfo.ID, string(result.Status), time.Duration(result.Duration)*time.Millisecond)
+						failed = pr.config.StopOrderOnFailure && result.Status == report.StatusFailed
+					}
+
+					// Store result
+					resultsMu.Lock()
+					results[it.index] = result
+					resultsMu.Unlock()
+				}
 			}
 		}(worker)
 	}
@@ -217,6 +274,14 @@ func (pr *ParallelRunner) Run(ctx context.Context, flows []flow.Flow) (*RunResul
 	// Wait for all workers to complete
 	wg.Wait()
 
+	// Flows left in the queue had no device to run on: beforeSuite failed on
+	// every device that meets their requirements
+	for _, item := range queue.drain() {
+		for _, it := range append([]workItem{item}, item.next...) {
+			results[it.index] = skipFlow(indexWriter, &flowDetails[it.index], skipHookFailed)
+		}
+	}
+
 	// Cleanup all workers after tests complete
 	// This ensures cleanup happens synchronously after all work is done
 	for i := range pr.workers {
@@ -232,7 +297,13 @@ func (pr *ParallelRunner) Run(ctx context.Context, flows []flow.Flow) (*RunResul
 	indexWriter.End()
 
 	// Build result using the same logic as single-device runner
-	return pr.buildRunResult(results, wallClockDuration), nil
+	result := pr.buildRunResult(results, wallClockDuration)
+	result.FailedDevices = failedDevices
+	result.Unschedulable = unschedulableFlows
+	if len(failedDevices) == len(pr.workers) {
+		result.Status = report.StatusFailed // No device could run the flows
+	}
+	return result, nil
 }
 
 // buildRunResult aggregates flow results into a run result.
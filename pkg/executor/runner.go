@@ -3,11 +3,16 @@ package executor
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"sync"
 
 	"github.com/devicelab-dev/maestro-runner/pkg/core"
 	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/devicelab-dev/maestro-runner/pkg/logger"
+	"github.com/devicelab-dev/maestro-runner/pkg/metrics"
 	"github.com/devicelab-dev/maestro-runner/pkg/report"
+	"github.com/devicelab-dev/maestro-runner/pkg/secrets"
 )
 
 // ArtifactMode determines when to capture screenshots/hierarchy.
@@ -22,6 +27,20 @@ const (
 	ArtifactNever
 )
 
+// ParseArtifactMode parses an artifact mode as written in the workspace
+// config: "onFailure", "always" or "never". Empty means onFailure.
+func ParseArtifactMode(s string) (ArtifactMode, error) {
+	switch s {
+	case "", "onFailure":
+		return ArtifactOnFailure, nil
+	case "always":
+		return ArtifactAlways, nil
+	case "never":
+		return ArtifactNever, nil
+	}
+	return ArtifactOnFailure, fmt.Errorf("unknown artifacts mode %q (want onFailure, always or never)", s)
+}
+
 // RunnerConfig configures the test runner.
 type RunnerConfig struct {
 	OutputDir   string       // Report output directory
@@ -42,8 +61,46 @@ type RunnerConfig struct {
 	// Environment variables from CLI (-e KEY=VALUE)
 	Env map[string]string
 
+	// Variable names or patterns (e.g. "*_PASSWORD") whose values are redacted
+	// from reports, logs and console output, on top of secrets.DefaultPatterns
+	Secrets []string
+
 	// Driver settings
 	WaitForIdleTimeout int // Global wait for idle timeout in ms
+	CommandTimeout     int // Default command timeout in ms for flows without commandTimeout (0 = driver default)
+
+	// Script settings
+	ModulePaths   []string // Directories require() searches for bare module names
+	ScriptTimeout int      // Time limit for each script in ms (0 = jsengine default)
+
+	// Seed for generated data (inputRandom, faker.* in scripts). Each flow
+	// derives its own seed from it, so a rerun with the same seed generates
+	// the same values.
+	Seed int64
+
+	// FlowRoot is the directory a flow's path is taken relative to when
+	// deriving its seed (the workspace directory), so a flow gets the same
+	// values however its path was given. Empty means the working directory.
+	FlowRoot string
+
+	// Execution order: the first OrderedFlows flows run one after another, in
+	// order, before the others. With StopOrderOnFailure, a failure among them
+	// skips the ordered flows after it.
+	OrderedFlows       int
+	StopOrderOnFailure bool
+
+	// Suite hooks: flows run once per device, before its first flow and after
+	// its last. A device whose BeforeSuite fails runs no flows.
+	BeforeSuite *flow.Flow
+	AfterSuite  *flow.Flow
+
+	// Device groups from the workspace config (group name to device IDs or
+	// names), for flows that require a group.
+	DeviceGroups map[string][]string
+
+	// Expected flow durations in milliseconds from past runs, keyed by
+	// report.FlowKey. The parallel runner starts the longest flows first.
+	ExpectedDurations map[string]int64
 
 	// Device information (set by executor)
 	DeviceInfo *report.Device
@@ -54,6 +111,16 @@ type RunnerConfig struct {
 	OnNestedStep      func(depth int, desc string, passed bool, durationMs int64, err string)
 	OnNestedFlowStart func(depth int, desc string)
 	OnFlowEnd         func(name string, passed bool, durationMs int64, errMsg string)
+	OnSuiteHookStart  func(hook, file string) // Before a BeforeSuite/AfterSuite flow; its steps use the step callbacks
+
+	// Debugger pauses execution around steps (nil = run straight through).
+	// Only meaningful with sequential execution.
+	Debugger Debugger
+
+	// StartStep resumes flows at this top-level step index: earlier steps are
+	// reported as skipped and onFlowStart hooks don't run. Used by continuous
+	// mode, where the device is already in the state the earlier steps reach.
+	StartStep int
 }
 
 // RunResult contains the outcome of a test run.
@@ -65,6 +132,12 @@ type RunResult struct {
 	SkippedFlows int
 	Duration     int64 // Total duration in milliseconds
 	FlowResults  []FlowResult
+
+	// Devices whose beforeSuite flow failed
+	FailedDevices []DeviceFailure
+
+	// Flows skipped because no device meets their device requirements
+	Unschedulable []UnschedulableFlow
 }
 
 // FlowResult contains the outcome of a single flow execution.
@@ -82,8 +155,12 @@ type FlowResult struct {
 
 // Runner orchestrates flow execution.
 type Runner struct {
-	config RunnerConfig
-	driver core.Driver
+	config       RunnerConfig
+	driver       core.Driver
+	suiteOutput  map[string]interface{} // Output of BeforeSuite, passed to every flow
+	setupFailure *DeviceFailure         // Set when BeforeSuite failed
+
+	unschedulable []UnschedulableFlow // Flows skipped for their device requirements
 }
 
 // New creates a new Runner.
@@ -104,6 +181,7 @@ func (r *Runner) Run(ctx context.Context, flows []flow.Flow) (*RunResult, error)
 		CI:            r.config.CI,
 		RunnerVersion: r.config.RunnerVersion,
 		DriverName:    r.config.DriverName,
+		Seed:          r.config.Seed,
 	}
 
 	index, flowDetails, err := report.BuildSkeleton(flows, builderCfg)
@@ -130,17 +208,62 @@ func (r *Runner) Run(ctx context.Context, flows []flow.Flow) (*RunResult, error)
 	indexWriter.End()
 
 	// Build result
-	return r.buildRunResult(results), nil
+	result := r.buildRunResult(results)
+	result.Unschedulable = r.unschedulable
+	if r.setupFailure != nil {
+		// No flow ran, so the run fails even though none failed
+		result.FailedDevices = append(result.FailedDevices, *r.setupFailure)
+		result.Status = report.StatusFailed
+	}
+	return result, nil
 }
 
-// executeFlows runs flows either sequentially or in parallel.
+// executeFlows runs flows either sequentially or in parallel, between the
+// suite hooks. Flows in executionOrder run first, one after another.
 func (r *Runner) executeFlows(ctx context.Context, flows []flow.Flow, flowDetails []report.FlowDetail, indexWriter *report.IndexWriter) []FlowResult {
 	results := make([]FlowResult, len(flows))
+	unmet := r.skipUnmet(flows, flowDetails, indexWriter, results)
+	defer r.runSuiteHook(ctx, hookAfterSuite, r.config.AfterSuite, hookAfterSuite, indexWriter)
+
+	if msg := r.runSuiteHook(ctx, hookBeforeSuite, r.config.BeforeSuite, hookBeforeSuite, indexWriter); msg != "" {
+		for i := range flows {
+			if !unmet[i] {
+				results[i] = skipFlow(indexWriter, &flowDetails[i], skipHookFailed+": "+msg)
+			}
+		}
+		return results
+	}
 
 	totalFlows := len(flows)
+	ordered := min(r.config.OrderedFlows, totalFlows)
+	orderFailed := false
+	for i := 0; i < ordered; i++ {
+		if unmet[i] {
+			continue
+		}
+		if ctx.Err() != nil {
+			results[i] = FlowResult{
+				ID:     flowDetails[i].ID,
+				Name:   flowDetails[i].Name,
+				Status: report.StatusSkipped,
+				Error:  "run cancelled",
+			}
+			continue
+		}
+		if orderFailed {
+			results[i] = skipFlow(indexWriter, &flowDetails[i], skipOrderFailed)
+			continue
+		}
+		results[i] = r.executeFlow(ctx, flows[i], &flowDetails[i], indexWriter, i, totalFlows)
+		orderFailed = r.config.StopOrderOnFailure && results[i].Status == report.StatusFailed
+	}
+
 	if r.config.Parallelism <= 0 {
 		// Sequential execution
-		for i := range flows {
+		for i := ordered; i < totalFlows; i++ {
+			if unmet[i] {
+				continue
+			}
 			if ctx.Err() != nil {
 				// Context cancelled, skip remaining
 				results[i] = FlowResult{
@@ -160,7 +283,10 @@ func (r *Runner) executeFlows(ctx context.Context, flows []flow.Flow, flowDetail
 		var mu sync.Mutex
 		stopAll := false
 
-		for i := range flows {
+		for i := ordered; i < totalFlows; i++ {
+			if unmet[i] {
+				continue
+			}
 			// Check if we should stop
 			mu.Lock()
 			shouldStop := stopAll
@@ -198,19 +324,105 @@ func (r *Runner) executeFlows(ctx context.Context, flows []flow.Flow, flowDetail
 	return results
 }
 
-// executeFlow runs a single flow.
+// skipUnmet skips the flows whose device requirements the runner's device
+// doesn't meet, recording them as unschedulable. It returns which flows it
+// skipped.
+func (r *Runner) skipUnmet(flows []flow.Flow, flowDetails []report.FlowDetail, indexWriter *report.IndexWriter, results []FlowResult) []bool {
+	unmet := make([]bool, len(flows))
+	var profile *deviceProfile
+	for i, f := range flows {
+		if f.Config.Device == nil {
+			continue
+		}
+		if profile == nil {
+			p := newDeviceProfile(r.driver.GetPlatformInfo(), r.config.DeviceGroups)
+			profile = &p
+		}
+		if !profile.meets(f.Config.Device) {
+			var u UnschedulableFlow
+			results[i], u = markUnschedulable(indexWriter, &flowDetails[i], f)
+			r.unschedulable = append(r.unschedulable, u)
+			unmet[i] = true
+		}
+	}
+	return unmet
+}
+
+// executeFlow runs a single flow, rerunning it up to config.Retries times
+// while it fails. Failed attempts are kept in the report's attempt history.
 func (r *Runner) executeFlow(ctx context.Context, f flow.Flow, detail *report.FlowDetail, indexWriter *report.IndexWriter, flowIdx, totalFlows int) FlowResult {
-	fr := &FlowRunner{
-		ctx:         ctx,
-		flow:        f,
-		detail:      detail,
-		driver:      r.driver,
-		config:      r.config,
-		indexWriter: indexWriter,
-		flowIdx:     flowIdx,
-		totalFlows:  totalFlows,
-	}
-	return fr.Run()
+	pristine := *detail
+	pristine.Commands = slices.Clone(detail.Commands)
+
+	var result FlowResult
+	for attempt := 1; ; attempt++ {
+		fr := &FlowRunner{
+			ctx:         ctx,
+			flow:        withFreshSteps(f), // A retry evaluates variables again
+			detail:      detail,
+			driver:      r.driver,
+			config:      redactCallbacks(r.config),
+			indexWriter: indexWriter,
+			flowIdx:     flowIdx,
+			totalFlows:  totalFlows,
+			suiteOutput: r.suiteOutput,
+		}
+		result = fr.Run()
+		result.Error = secrets.Redact(result.Error)
+		if result.Status != report.StatusFailed || attempt > r.config.Retries || ctx.Err() != nil {
+			break
+		}
+
+		// Keep the failed attempt and run the flow again from a clean detail
+		dataFile, err := report.SaveAttempt(r.config.OutputDir, detail, attempt)
+		if err != nil {
+			logger.Warn("failed to save attempt %d of %s: %v", attempt, detail.Name, err)
+		}
+		indexWriter.RecordAttempt(detail.ID, attempt, result.Status, result.Duration, result.Error, dataFile)
+		logger.Info("Flow %s failed, retrying (%d of %d)", detail.Name, attempt, r.config.Retries)
+
+		*detail = pristine
+		detail.Commands = slices.Clone(pristine.Commands)
+	}
+	metrics.RecordFlow(string(result.Status))
+	return result
+}
+
+// withFreshSteps returns f with copies of its steps and lifecycle hooks.
+// Steps are expanded in place as they run, so each run of a flow needs its
+// own.
+func withFreshSteps(f flow.Flow) flow.Flow {
+	f.Steps = flow.CloneSteps(f.Steps)
+	f.Config.OnFlowStart = flow.CloneSteps(f.Config.OnFlowStart)
+	f.Config.OnFlowComplete = flow.CloneSteps(f.Config.OnFlowComplete)
+	f.Config.OnFlowFailure = flow.CloneSteps(f.Config.OnFlowFailure)
+	return f
+}
+
+// redactCallbacks returns cfg with its progress callbacks wrapped to redact
+// secret values from step descriptions and errors before they are printed.
+func redactCallbacks(cfg RunnerConfig) RunnerConfig {
+	if cb := cfg.OnStepComplete; cb != nil {
+		cfg.OnStepComplete = func(idx int, desc string, passed bool, durationMs int64, err string) {
+			cb(idx, secrets.Redact(desc), passed, durationMs, secrets.Redact(err))
+		}
+	}
+	if cb := cfg.OnNestedStep; cb != nil {
+		cfg.OnNestedStep = func(depth int, desc string, passed bool, durationMs int64, err string) {
+			cb(depth, secrets.Redact(desc), passed, durationMs, secrets.Redact(err))
+		}
+	}
+	if cb := cfg.OnNestedFlowStart; cb != nil {
+		cfg.OnNestedFlowStart = func(depth int, desc string) {
+			cb(depth, secrets.Redact(desc))
+		}
+	}
+	if cb := cfg.OnFlowEnd; cb != nil {
+		cfg.OnFlowEnd = func(name string, passed bool, durationMs int64, errMsg string) {
+			cb(name, passed, durationMs, secrets.Redact(errMsg))
+		}
+	}
+	return cfg
 }
 
 // buildRunResult aggregates flow results into a run result.
@@ -2,8 +2,11 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -11,6 +14,7 @@ import (
 	"github.com/devicelab-dev/maestro-runner/pkg/core"
 	"github.com/devicelab-dev/maestro-runner/pkg/flow"
 	"github.com/devicelab-dev/maestro-runner/pkg/report"
+	"github.com/devicelab-dev/maestro-runner/pkg/secrets"
 )
 
 // mockDriver implements core.Driver for testing.
@@ -880,9 +884,11 @@ func TestRunner_RunFlowStep_WhenCondition(t *testing.T) {
 			Config:     flow.Config{Name: "RunFlow When Test"},
 			Steps: []flow.Step{
 				&flow.RunFlowStep{
-					BaseStep: flow.BaseStep{StepType: flow.StepRunFlow},
-					When: &flow.Condition{
-						Visible: &flow.Selector{Text: "Login"},
+					BaseStep: flow.BaseStep{
+						StepType: flow.StepRunFlow,
+						When: &flow.Condition{
+							Visible: &flow.Selector{Text: "Login"},
+						},
 					},
 					Steps: []flow.Step{
 						&flow.TapOnStep{BaseStep: flow.BaseStep{StepType: flow.StepTapOn}},
@@ -907,6 +913,689 @@ func TestRunner_RunFlowStep_WhenCondition(t *testing.T) {
 	}
 }
 
+func TestRunner_WhenCondition_AnyStep(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	var executed []string
+	driver := &mockDriver{
+		executeFunc: func(step flow.Step) *core.CommandResult {
+			executed = append(executed, step.Describe())
+			return &core.CommandResult{Success: true}
+		},
+	}
+
+	runner := New(driver, RunnerConfig{
+		OutputDir: tmpDir,
+		Artifacts: ArtifactNever,
+	})
+
+	flows := []flow.Flow{
+		{
+			SourcePath: "test.yaml",
+			Config:     flow.Config{Name: "When Test"},
+			Steps: []flow.Step{
+				&flow.TapOnStep{
+					BaseStep: flow.BaseStep{StepType: flow.StepTapOn, When: &flow.Condition{Platform: "ios"}},
+					Selector: flow.Selector{Text: "iOS only"},
+				},
+				&flow.TapOnStep{
+					BaseStep: flow.BaseStep{StepType: flow.StepTapOn, When: &flow.Condition{Platform: "Android"}},
+					Selector: flow.Selector{Text: "Android only"},
+				},
+				&flow.RepeatStep{
+					BaseStep: flow.BaseStep{StepType: flow.StepRepeat},
+					Times:    "1",
+					Steps: []flow.Step{
+						&flow.BackStep{BaseStep: flow.BaseStep{StepType: flow.StepBack, When: &flow.Condition{Script: "${false}"}}},
+					},
+				},
+			},
+		},
+	}
+
+	result, err := runner.Run(context.Background(), flows)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(executed) != 1 || executed[0] != `tapOn: text="Android only"` {
+		t.Errorf("executed = %q, want only the Android step", executed)
+	}
+	fr := result.FlowResults[0]
+	if fr.Status != report.StatusPassed || fr.StepsPassed != 1 || fr.StepsSkipped != 2 {
+		t.Errorf("status=%v passed=%d skipped=%d, want passed/1/2", fr.Status, fr.StepsPassed, fr.StepsSkipped)
+	}
+
+	_, details, err := report.ReadReport(tmpDir)
+	if err != nil {
+		t.Fatalf("ReadReport() error = %v", err)
+	}
+	cmds := details[0].Commands
+	if cmds[0].Status != report.StatusConditionSkipped || cmds[1].Status != report.StatusPassed {
+		t.Errorf("command statuses = %v, %v, want conditionSkipped, passed", cmds[0].Status, cmds[1].Status)
+	}
+	if subs := cmds[2].SubCommands; len(subs) != 1 || subs[0].Status != report.StatusConditionSkipped {
+		t.Errorf("repeat sub-commands = %+v, want one conditionSkipped", subs)
+	}
+}
+
+func TestRunner_IfStep(t *testing.T) {
+	tests := []struct {
+		name    string
+		visible bool
+		want    string
+	}{
+		{"then", true, `tapOn: text="Continue"`},
+		{"else", false, `tapOn: text="Sign up"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var executed []string
+			driver := &mockDriver{
+				executeFunc: func(step flow.Step) *core.CommandResult {
+					if _, ok := step.(*flow.AssertVisibleStep); ok {
+						return &core.CommandResult{Success: tt.visible}
+					}
+					executed = append(executed, step.Describe())
+					return &core.CommandResult{Success: true}
+				},
+			}
+
+			runner := New(driver, RunnerConfig{
+				OutputDir: t.TempDir(),
+				Artifacts: ArtifactNever,
+			})
+
+			flows := []flow.Flow{
+				{
+					SourcePath: "test.yaml",
+					Config:     flow.Config{Name: "If Test"},
+					Steps: []flow.Step{
+						&flow.IfStep{
+							BaseStep:  flow.BaseStep{StepType: flow.StepIf},
+							Condition: flow.Condition{Visible: &flow.Selector{Text: "Welcome back"}},
+							Then: []flow.Step{
+								&flow.TapOnStep{BaseStep: flow.BaseStep{StepType: flow.StepTapOn}, Selector: flow.Selector{Text: "Continue"}},
+							},
+							Else: []flow.Step{
+								&flow.TapOnStep{BaseStep: flow.BaseStep{StepType: flow.StepTapOn}, Selector: flow.Selector{Text: "Sign up"}},
+							},
+						},
+					},
+				},
+			}
+
+			result, err := runner.Run(context.Background(), flows)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if result.Status != report.StatusPassed {
+				t.Errorf("Status = %v, want %v", result.Status, report.StatusPassed)
+			}
+			if len(executed) != 1 || executed[0] != tt.want {
+				t.Errorf("executed = %q, want [%s]", executed, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunner_ForEachStep(t *testing.T) {
+	tests := []struct {
+		name  string
+		items any
+		env   map[string]string
+		want  []string
+	}{
+		{"list", []any{"a", "b"}, nil, []string{"0:a", "1:b"}},
+		{"comma-separated variable", "${IDS}", map[string]string{"IDS": "x, y,z"}, []string{"0:x", "1:y", "2:z"}},
+		{"js array", "${[1, 2].map(function (n) { return n * 10 })}", nil, []string{"0:10", "1:20"}},
+		{"empty", []any{}, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var typed []string
+			driver := &mockDriver{
+				executeFunc: func(step flow.Step) *core.CommandResult {
+					if s, ok := step.(*flow.InputTextStep); ok {
+						typed = append(typed, s.Text)
+					}
+					return &core.CommandResult{Success: true}
+				},
+			}
+
+			runner := New(driver, RunnerConfig{
+				OutputDir: t.TempDir(),
+				Artifacts: ArtifactNever,
+				Env:       tt.env,
+			})
+
+			flows := []flow.Flow{
+				{
+					SourcePath: "test.yaml",
+					Config:     flow.Config{Name: "ForEach Test"},
+					Steps: []flow.Step{
+						&flow.ForEachStep{
+							BaseStep: flow.BaseStep{StepType: flow.StepForEach},
+							Items:    tt.items,
+							Steps: []flow.Step{
+								&flow.InputTextStep{BaseStep: flow.BaseStep{StepType: flow.StepInputText}, Text: "${index}:${item}"},
+							},
+						},
+					},
+				},
+			}
+
+			result, err := runner.Run(context.Background(), flows)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if result.Status != report.StatusPassed {
+				t.Errorf("Status = %v, want %v", result.Status, report.StatusPassed)
+			}
+			if strings.Join(typed, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("typed = %q, want %q", typed, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunner_ForEachStep_BreakContinue(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	var typed []string
+	driver := &mockDriver{
+		executeFunc: func(step flow.Step) *core.CommandResult {
+			if s, ok := step.(*flow.InputTextStep); ok {
+				typed = append(typed, s.Text)
+			}
+			return &core.CommandResult{Success: true}
+		},
+	}
+
+	runner := New(driver, RunnerConfig{
+		OutputDir: tmpDir,
+		Artifacts: ArtifactNever,
+	})
+
+	flows := []flow.Flow{
+		{
+			SourcePath: "test.yaml",
+			Config:     flow.Config{Name: "ForEach Break Test"},
+			Steps: []flow.Step{
+				&flow.ForEachStep{
+					BaseStep:   flow.BaseStep{StepType: flow.StepForEach},
+					Items:      []any{1, 2, 3, 4},
+					As:         "n",
+					ContinueIf: &flow.Condition{Script: "${n == 2}"},
+					BreakIf:    &flow.Condition{Script: "${n == 4}"},
+					Steps: []flow.Step{
+						&flow.InputTextStep{BaseStep: flow.BaseStep{StepType: flow.StepInputText}, Text: "${n}"},
+					},
+				},
+			},
+		},
+	}
+
+	if _, err := runner.Run(context.Background(), flows); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if strings.Join(typed, ",") != "1,3" {
+		t.Errorf("typed = %q, want 1,3", typed)
+	}
+
+	_, details, err := report.ReadReport(tmpDir)
+	if err != nil {
+		t.Fatalf("ReadReport() error = %v", err)
+	}
+	if subs := details[0].Commands[0].SubCommands; len(subs) != 2 {
+		t.Errorf("forEach sub-commands = %d, want 2", len(subs))
+	}
+}
+
+func TestRunner_OnFlowFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		tapFails bool
+		want     []string
+	}{
+		{"flow passes", false, []string{`tapOn: text="Pay"`, "stopApp"}},
+		{"flow fails", true, []string{`tapOn: text="Pay"`, `inputText: "test.yaml:3:1: element not found"`, "stopApp"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var executed []string
+			driver := &mockDriver{
+				executeFunc: func(step flow.Step) *core.CommandResult {
+					executed = append(executed, step.Describe())
+					if _, ok := step.(*flow.TapOnStep); ok && tt.tapFails {
+						return &core.CommandResult{Success: false, Error: &testError{msg: "element not found"}}
+					}
+					return &core.CommandResult{Success: true}
+				},
+			}
+
+			runner := New(driver, RunnerConfig{
+				OutputDir: t.TempDir(),
+				Artifacts: ArtifactNever,
+			})
+
+			flows := []flow.Flow{
+				{
+					SourcePath: "test.yaml",
+					Config: flow.Config{
+						Name: "Hooks Test",
+						OnFlowFailure: []flow.Step{
+							&flow.InputTextStep{BaseStep: flow.BaseStep{StepType: flow.StepInputText}, Text: "${FLOW_ERROR}"},
+						},
+						OnFlowComplete: []flow.Step{
+							&flow.StopAppStep{BaseStep: flow.BaseStep{StepType: flow.StepStopApp}},
+						},
+					},
+					Steps: []flow.Step{
+						&flow.TapOnStep{BaseStep: flow.BaseStep{StepType: flow.StepTapOn, SourcePos: flow.Position{File: "test.yaml", Line: 3, Column: 1}}, Selector: flow.Selector{Text: "Pay"}},
+					},
+				},
+			}
+
+			result, err := runner.Run(context.Background(), flows)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if got := result.FlowResults[0].StepsTotal; got != 1 {
+				t.Errorf("StepsTotal = %d, want 1 (hook steps don't count)", got)
+			}
+			if !reflect.DeepEqual(executed, tt.want) {
+				t.Errorf("executed = %q, want %q", executed, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunner_OnFailureRecovery(t *testing.T) {
+	tests := []struct {
+		name         string
+		backFails    bool
+		wantStatus   report.Status
+		wantExecuted []string
+	}{
+		{"recovered", false, report.StatusPassed, []string{`tapOn: text="Pay"`, "back", `tapOn: text="Pay"`}},
+		{"recovery fails", true, report.StatusFailed, []string{`tapOn: text="Pay"`, "back"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var executed []string
+			taps := 0
+			driver := &mockDriver{
+				executeFunc: func(step flow.Step) *core.CommandResult {
+					executed = append(executed, step.Describe())
+					switch step.(type) {
+					case *flow.TapOnStep:
+						taps++
+						if taps == 1 {
+							return &core.CommandResult{Success: false, Error: &testError{msg: "element not found"}}
+						}
+					case *flow.BackStep:
+						if tt.backFails {
+							return &core.CommandResult{Success: false, Error: &testError{msg: "back failed"}}
+						}
+					}
+					return &core.CommandResult{Success: true}
+				},
+			}
+
+			runner := New(driver, RunnerConfig{
+				OutputDir: t.TempDir(),
+				Artifacts: ArtifactNever,
+			})
+
+			flows := []flow.Flow{
+				{
+					SourcePath: "test.yaml",
+					Config:     flow.Config{Name: "Recovery Test"},
+					Steps: []flow.Step{
+						&flow.TapOnStep{
+							BaseStep: flow.BaseStep{
+								StepType:  flow.StepTapOn,
+								OnFailure: []flow.Step{&flow.BackStep{BaseStep: flow.BaseStep{StepType: flow.StepBack}}},
+							},
+							Selector: flow.Selector{Text: "Pay"},
+						},
+					},
+				},
+			}
+
+			result, err := runner.Run(context.Background(), flows)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("Status = %v, want %v", result.Status, tt.wantStatus)
+			}
+			if !reflect.DeepEqual(executed, tt.wantExecuted) {
+				t.Errorf("executed = %q, want %q", executed, tt.wantExecuted)
+			}
+		})
+	}
+}
+
+func TestRunner_OnFailureRetrySeesRefreshedVariables(t *testing.T) {
+	tokenStep := func() flow.Step {
+		return &flow.InputTextStep{
+			BaseStep: flow.BaseStep{
+				StepType: flow.StepInputText,
+				OnFailure: []flow.Step{
+					&flow.EvalScriptStep{BaseStep: flow.BaseStep{StepType: flow.StepEvalScript}, Script: "output.token = 'fresh'"},
+				},
+			},
+			Text: "${output.token}",
+		}
+	}
+	tests := []struct {
+		name string
+		step flow.Step
+	}{
+		{"top-level step", tokenStep()},
+		{"nested step", &flow.RepeatStep{BaseStep: flow.BaseStep{StepType: flow.StepRepeat}, Times: "1", Steps: []flow.Step{tokenStep()}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var typed []string
+			driver := &mockDriver{
+				executeFunc: func(step flow.Step) *core.CommandResult {
+					text := step.(*flow.InputTextStep).Text
+					typed = append(typed, text)
+					if text != "fresh" {
+						return &core.CommandResult{Success: false, Error: errors.New("expired token")}
+					}
+					return &core.CommandResult{Success: true}
+				},
+			}
+			runner := New(driver, RunnerConfig{OutputDir: t.TempDir(), Artifacts: ArtifactNever})
+			f := flow.Flow{
+				SourcePath: "token.yaml",
+				Steps: []flow.Step{
+					&flow.EvalScriptStep{BaseStep: flow.BaseStep{StepType: flow.StepEvalScript}, Script: "output.token = 'stale'"},
+					tt.step,
+				},
+			}
+
+			result, err := runner.Run(context.Background(), []flow.Flow{f})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if result.Status != report.StatusPassed || !reflect.DeepEqual(typed, []string{"stale", "fresh"}) {
+				t.Errorf("status = %s, typed = %v, want stale then fresh", result.Status, typed)
+			}
+		})
+	}
+}
+
+func TestRunner_ScriptDeviceAccess(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	var tapped []string
+	driver := &mockDriver{
+		executeFunc: func(step flow.Step) *core.CommandResult {
+			switch s := step.(type) {
+			case *flow.AssertVisibleStep:
+				if s.Selector.ID == "price" {
+					return &core.CommandResult{Success: true, Element: &core.ElementInfo{ID: "price", Text: "42", Visible: true}}
+				}
+				return &core.CommandResult{Success: false, Error: &testError{msg: "element not found"}}
+			case *flow.TapOnStep:
+				tapped = append(tapped, s.Selector.Text)
+			}
+			return &core.CommandResult{Success: true}
+		},
+		stateFunc: func() *core.StateSnapshot {
+			return &core.StateSnapshot{AppState: "foreground"}
+		},
+	}
+
+	runner := New(driver, RunnerConfig{
+		OutputDir: tmpDir,
+		Artifacts: ArtifactNever,
+	})
+
+	flows := []flow.Flow{
+		{
+			SourcePath: "test.yaml",
+			Config:     flow.Config{Name: "Script Device Test"},
+			Steps: []flow.Step{
+				&flow.RunScriptStep{
+					BaseStep: flow.BaseStep{StepType: flow.StepRunScript},
+					Script: `if (maestro.getText({id: 'price'}) === '42' && !maestro.isVisible('Sold out', {timeout: 100})) {
+	maestro.tap('Buy');
+}
+output.app = maestro.state().appState;`,
+				},
+			},
+		},
+	}
+
+	result, err := runner.Run(context.Background(), flows)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Status != report.StatusPassed {
+		t.Fatalf("Status = %v, want passed", result.Status)
+	}
+	if strings.Join(tapped, ",") != "Buy" {
+		t.Errorf("tapped = %q, want Buy", tapped)
+	}
+
+	_, details, err := report.ReadReport(tmpDir)
+	if err != nil {
+		t.Fatalf("ReadReport() error = %v", err)
+	}
+	var labels []string
+	for _, sub := range details[0].Commands[0].SubCommands {
+		labels = append(labels, sub.Label)
+	}
+	want := []string{`maestro.getText(id="price")`, `maestro.isVisible(text="Sold out")`, `maestro.tap(text="Buy")`, "maestro.state"}
+	if !reflect.DeepEqual(labels, want) {
+		t.Errorf("sub-commands = %q, want %q", labels, want)
+	}
+}
+
+func TestRunner_ScriptDeviceCallsInConditionsAndExpansion(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	driver := &mockDriver{
+		executeFunc: func(step flow.Step) *core.CommandResult {
+			if s, ok := step.(*flow.AssertVisibleStep); ok {
+				switch {
+				case s.Selector.Text == "Banner":
+					return &core.CommandResult{Success: true}
+				case s.Selector.ID == "price":
+					return &core.CommandResult{Success: true, Element: &core.ElementInfo{ID: "price", Text: "42", Visible: true}}
+				}
+				return &core.CommandResult{Success: false, Error: &testError{msg: "element not found"}}
+			}
+			return &core.CommandResult{Success: true}
+		},
+	}
+
+	runner := New(driver, RunnerConfig{
+		OutputDir: tmpDir,
+		Artifacts: ArtifactNever,
+	})
+
+	popupShown := &flow.Condition{Script: "${maestro.isVisible('Popup', {timeout: 100})}"}
+	flows := []flow.Flow{
+		{
+			SourcePath: "test.yaml",
+			Config:     flow.Config{Name: "Script Calls Test"},
+			Steps: []flow.Step{
+				&flow.TapOnStep{
+					BaseStep: flow.BaseStep{StepType: flow.StepTapOn, When: &flow.Condition{Script: "${maestro.isVisible('Banner')}"}},
+					Selector: flow.Selector{Text: "Close"},
+				},
+				&flow.BackStep{BaseStep: flow.BaseStep{StepType: flow.StepBack, When: popupShown}},
+				&flow.InputTextStep{BaseStep: flow.BaseStep{StepType: flow.StepInputText}, Text: "${maestro.getText({id: 'price'})}"},
+				&flow.RepeatStep{
+					BaseStep: flow.BaseStep{StepType: flow.StepRepeat},
+					Times:    "1",
+					Steps: []flow.Step{
+						&flow.BackStep{BaseStep: flow.BaseStep{StepType: flow.StepBack, When: popupShown}},
+					},
+				},
+				&flow.HideKeyboardStep{BaseStep: flow.BaseStep{StepType: flow.StepHideKeyboard}},
+			},
+		},
+	}
+
+	result, err := runner.Run(context.Background(), flows)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Status != report.StatusPassed {
+		t.Fatalf("Status = %v, want passed", result.Status)
+	}
+
+	_, details, err := report.ReadReport(tmpDir)
+	if err != nil {
+		t.Fatalf("ReadReport() error = %v", err)
+	}
+	labels := func(cmds []report.Command) []string {
+		var labels []string
+		for _, cmd := range cmds {
+			labels = append(labels, cmd.Label)
+		}
+		return labels
+	}
+	commands := details[0].Commands
+	wants := map[int][]string{
+		0: {`maestro.isVisible(text="Banner")`},
+		1: {`maestro.isVisible(text="Popup")`},
+		2: {`maestro.getText(id="price")`},
+		4: nil,
+	}
+	for i, want := range wants {
+		if got := labels(commands[i].SubCommands); !reflect.DeepEqual(got, want) {
+			t.Errorf("command %d sub-commands = %q, want %q", i, got, want)
+		}
+	}
+	if commands[1].Status != report.StatusConditionSkipped {
+		t.Errorf("command 1 status = %s, want condition skipped", commands[1].Status)
+	}
+
+	// The nested step's condition call belongs to the nested step, not
+	// the repeat
+	if len(commands[3].SubCommands) != 1 {
+		t.Fatalf("repeat sub-commands = %q, want the back step only", labels(commands[3].SubCommands))
+	}
+	nested := commands[3].SubCommands[0]
+	if nested.Status != report.StatusConditionSkipped {
+		t.Errorf("nested status = %s, want condition skipped", nested.Status)
+	}
+	if got, want := labels(nested.SubCommands), []string{`maestro.isVisible(text="Popup")`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("nested sub-commands = %q, want %q", got, want)
+	}
+}
+
+func TestRunner_SeededRandomData(t *testing.T) {
+	// run runs one flow per source path, all with the same name, and returns
+	// what they typed, three values per flow
+	run := func(seed int64, root string, sourcePaths ...string) (typed []string, index *report.Index) {
+		tmpDir := t.TempDir()
+		driver := &mockDriver{
+			executeFunc: func(step flow.Step) *core.CommandResult {
+				if s, ok := step.(*flow.InputTextStep); ok {
+					typed = append(typed, s.Text)
+				}
+				return &core.CommandResult{Success: true}
+			},
+		}
+
+		runner := New(driver, RunnerConfig{
+			OutputDir: tmpDir,
+			Artifacts: ArtifactNever,
+			Seed:      seed,
+			FlowRoot:  root,
+		})
+
+		var flows []flow.Flow
+		for _, sourcePath := range sourcePaths {
+			flows = append(flows, flow.Flow{
+				SourcePath: sourcePath,
+				Config:     flow.Config{Name: "Random Data Test"},
+				Steps: []flow.Step{
+					&flow.InputRandomStep{BaseStep: flow.BaseStep{StepType: flow.StepInputRandom}, DataType: "EMAIL"},
+					&flow.InputRandomStep{BaseStep: flow.BaseStep{StepType: flow.StepInputRandom}, DataType: "PERSON_NAME", Locale: "de_DE"},
+					&flow.RunScriptStep{BaseStep: flow.BaseStep{StepType: flow.StepRunScript}, Script: "output.phone = faker.phoneNumber()"},
+					&flow.InputTextStep{BaseStep: flow.BaseStep{StepType: flow.StepInputText}, Text: "${output.phone}"},
+				},
+			})
+		}
+
+		result, err := runner.Run(context.Background(), flows)
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if result.Status != report.StatusPassed {
+			t.Fatalf("Status = %v, want passed", result.Status)
+		}
+		index, _, err = report.ReadReport(tmpDir)
+		if err != nil {
+			t.Fatalf("ReadReport() error = %v", err)
+		}
+		return typed, index
+	}
+
+	first, index := run(7, "", "flows/test.yaml")
+	if len(first) != 3 || !strings.Contains(first[0], "@example.") || strings.Contains(first[2], "$") {
+		t.Fatalf("typed = %q", first)
+	}
+	if index.Seed != 7 {
+		t.Errorf("report seed = %d, want 7", index.Seed)
+	}
+	// The flow's path as given (relative, ./, absolute under the root) doesn't
+	// change the values
+	if again, _ := run(7, "", "./flows/test.yaml"); !reflect.DeepEqual(again, first) {
+		t.Errorf("same seed typed %q, then %q", first, again)
+	}
+	if again, _ := run(7, "/work", "/work/flows/test.yaml"); !reflect.DeepEqual(again, first) {
+		t.Errorf("same seed with an absolute path typed %q, then %q", first, again)
+	}
+	if other, _ := run(8, "", "flows/test.yaml"); reflect.DeepEqual(other, first) {
+		t.Errorf("different seeds typed the same values %q", first)
+	}
+
+	// Flows with the same name and file name in different directories get
+	// different values, the same ones again on a rerun
+	both, _ := run(7, "", "admin/login.yaml", "user/login.yaml")
+	if reflect.DeepEqual(both[:3], both[3:]) {
+		t.Errorf("same-named flows typed the same values %q", both[:3])
+	}
+	if again, _ := run(7, "", "admin/login.yaml", "user/login.yaml"); !reflect.DeepEqual(again, both) {
+		t.Errorf("rerun typed %q, then %q", both, again)
+	}
+}
+
+func TestRunner_InputRandomUnknownType(t *testing.T) {
+	driver := &mockDriver{}
+	runner := New(driver, RunnerConfig{OutputDir: t.TempDir(), Artifacts: ArtifactNever})
+
+	flows := []flow.Flow{
+		{
+			SourcePath: "test.yaml",
+			Config:     flow.Config{Name: "Unknown Type Test"},
+			Steps: []flow.Step{
+				&flow.InputRandomStep{BaseStep: flow.BaseStep{StepType: flow.StepInputRandom}, DataType: "SHOE_SIZE"},
+			},
+		},
+	}
+
+	result, err := runner.Run(context.Background(), flows)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Status != report.StatusFailed || !strings.Contains(result.FlowResults[0].Error, "unknown random data type") {
+		t.Errorf("Status = %v, error = %q", result.Status, result.FlowResults[0].Error)
+	}
+}
+
 func TestRunner_RunFlowStep_NoFileOrSteps(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -936,10 +1625,46 @@ func TestRunner_RunFlowStep_NoFileOrSteps(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Run() error = %v", err)
 	}
-
-	// Should fail - no file or steps
-	if result.Status != report.StatusFailed {
-		t.Errorf("Status = %v, want %v", result.Status, report.StatusFailed)
+
+	// Should fail - no file or steps
+	if result.Status != report.StatusFailed {
+		t.Errorf("Status = %v, want %v", result.Status, report.StatusFailed)
+	}
+}
+
+func TestRunner_RequireAfterRunFlowInSubdirectory(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"sub/child.yaml": "appId: com.example\n---\n- evalScript: ${output.child = 'ran'}\n",
+		"lib/x.js":       "exports.v = 'parent lib';",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runner := New(&mockDriver{}, RunnerConfig{OutputDir: t.TempDir(), Artifacts: ArtifactNever})
+	result, err := runner.Run(context.Background(), []flow.Flow{
+		{
+			SourcePath: filepath.Join(tmpDir, "main.yaml"),
+			Config:     flow.Config{Name: "main"},
+			Steps: []flow.Step{
+				&flow.RunFlowStep{BaseStep: flow.BaseStep{StepType: flow.StepRunFlow}, File: "sub/child.yaml"},
+				// Resolves against main.yaml's directory again, not sub/
+				&flow.EvalScriptStep{BaseStep: flow.BaseStep{StepType: flow.StepEvalScript}, Script: "${output.v = require('./lib/x.js').v}"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Status != report.StatusPassed {
+		t.Errorf("Status = %v, want passed: %s", result.Status, result.FlowResults[0].Error)
 	}
 }
 
@@ -1948,3 +2673,516 @@ func TestRunner_NestedOptionalStepFailure(t *testing.T) {
 		t.Errorf("Status = %v, want %v", result.Status, report.StatusPassed)
 	}
 }
+
+func TestRunner_StartStep(t *testing.T) {
+	var executed []string
+	driver := &mockDriver{
+		executeFunc: func(step flow.Step) *core.CommandResult {
+			executed = append(executed, step.Describe())
+			return &core.CommandResult{Success: true}
+		},
+	}
+
+	runner := New(driver, RunnerConfig{
+		OutputDir: t.TempDir(),
+		Artifacts: ArtifactNever,
+		StartStep: 2,
+	})
+
+	flows := []flow.Flow{
+		{
+			SourcePath: "resume.yaml",
+			Config: flow.Config{
+				OnFlowStart: []flow.Step{&flow.BackStep{BaseStep: flow.BaseStep{StepType: flow.StepBack}}},
+			},
+			Steps: []flow.Step{
+				&flow.LaunchAppStep{BaseStep: flow.BaseStep{StepType: flow.StepLaunchApp}},
+				&flow.TapOnStep{BaseStep: flow.BaseStep{StepType: flow.StepTapOn}, Selector: flow.Selector{Text: "A"}},
+				&flow.TapOnStep{BaseStep: flow.BaseStep{StepType: flow.StepTapOn}, Selector: flow.Selector{Text: "B"}},
+			},
+		},
+	}
+
+	result, err := runner.Run(context.Background(), flows)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(executed) != 1 || executed[0] != `tapOn: text="B"` {
+		t.Errorf("executed = %q, want only the third step (no onFlowStart)", executed)
+	}
+	fr := result.FlowResults[0]
+	if fr.Status != report.StatusPassed || fr.StepsPassed != 1 || fr.StepsSkipped != 2 {
+		t.Errorf("status=%v passed=%d skipped=%d, want passed/1/2", fr.Status, fr.StepsPassed, fr.StepsSkipped)
+	}
+}
+
+func TestRunner_FailureLocation(t *testing.T) {
+	driver := &mockDriver{
+		executeFunc: func(step flow.Step) *core.CommandResult {
+			if tap, ok := step.(*flow.TapOnStep); ok && tap.Selector.Text == "Missing" {
+				return &core.CommandResult{Success: false, Error: &testError{msg: "not found"}}
+			}
+			return &core.CommandResult{Success: true}
+		},
+	}
+	f, err := flow.Parse([]byte(`- launchApp
+- repeat:
+    times: 1
+    commands:
+      - back
+      - tapOn: Missing
+`), "login.yaml")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	var stepErr string
+	runner := New(driver, RunnerConfig{
+		OutputDir: t.TempDir(),
+		Artifacts: ArtifactNever,
+		OnStepComplete: func(idx int, desc string, passed bool, durationMs int64, errMsg string) {
+			if !passed {
+				stepErr = errMsg
+			}
+		},
+	})
+	result, err := runner.Run(context.Background(), []flow.Flow{*f})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// The failure points at the nested step, not the repeat
+	want := "login.yaml:6:9: not found"
+	if fr := result.FlowResults[0]; fr.Error != want {
+		t.Errorf("flow error = %q, want %q", fr.Error, want)
+	}
+	if stepErr != want {
+		t.Errorf("step error = %q, want %q", stepErr, want)
+	}
+}
+
+func TestRunner_CustomCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	command := `params:
+  user: string
+  greeting:
+    type: string
+    default: Hello
+env:
+  user: overridden
+---
+- inputText: ${greeting} ${user}
+- tapOn: Submit
+`
+	if err := os.MkdirAll(filepath.Join(tmpDir, "commands"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "commands", "greet.yaml"), []byte(command), 0o644); err != nil {
+		t.Fatalf("Failed to write command: %v", err)
+	}
+	main, err := flow.Parse([]byte("- greet: {user: '${NAME}'}\n"), filepath.Join(tmpDir, "main.yaml"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	main.Config.Env = map[string]string{"NAME": "Ada"}
+
+	var typed []string
+	execCount := 0
+	driver := &mockDriver{
+		executeFunc: func(step flow.Step) *core.CommandResult {
+			execCount++
+			if s, ok := step.(*flow.InputTextStep); ok {
+				typed = append(typed, s.Text)
+			}
+			return &core.CommandResult{Success: true}
+		},
+	}
+
+	runner := New(driver, RunnerConfig{
+		OutputDir:   tmpDir,
+		Parallelism: 0,
+		Artifacts:   ArtifactNever,
+		Device:      report.Device{ID: "test", Platform: "android"},
+	})
+
+	result, err := runner.Run(context.Background(), []flow.Flow{*main})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Status != report.StatusPassed {
+		t.Errorf("Status = %v, want %v", result.Status, report.StatusPassed)
+	}
+	if execCount != 2 {
+		t.Errorf("execCount = %d, want 2", execCount)
+	}
+	// Arguments are expanded by the caller and override the command's env
+	if len(typed) != 1 || typed[0] != "Hello Ada" {
+		t.Errorf("typed = %v, want [Hello Ada]", typed)
+	}
+	// Like runFlow, the command's steps count instead of the command itself
+	if result.FlowResults[0].StepsTotal != 2 {
+		t.Errorf("StepsTotal = %d, want 2", result.FlowResults[0].StepsTotal)
+	}
+}
+
+func TestRunner_RedactsSecrets(t *testing.T) {
+	defer secrets.Reset()
+
+	tmpDir := t.TempDir()
+	var typed []string
+	driver := &mockDriver{
+		executeFunc: func(step flow.Step) *core.CommandResult {
+			s := step.(*flow.InputTextStep)
+			typed = append(typed, s.Text)
+			if s.Text == "4711-0815" {
+				return &core.CommandResult{Success: false, Error: errors.New("could not type 4711-0815")}
+			}
+			return &core.CommandResult{Success: true}
+		},
+	}
+
+	var printed []string
+	runner := New(driver, RunnerConfig{
+		OutputDir: tmpDir,
+		Artifacts: ArtifactNever,
+		Env:       map[string]string{"ADMIN_PASSWORD": "hunter2", "PIN": "4711-0815"},
+		Secrets:   []string{"PIN"},
+		OnStepComplete: func(idx int, desc string, passed bool, durationMs int64, err string) {
+			printed = append(printed, desc, err)
+		},
+	})
+
+	flows := []flow.Flow{
+		{
+			SourcePath: "login.yaml",
+			Config:     flow.Config{Name: "Login"},
+			Steps: []flow.Step{
+				&flow.InputTextStep{BaseStep: flow.BaseStep{StepType: flow.StepInputText}, Text: "${ADMIN_PASSWORD}"},
+				&flow.InputTextStep{BaseStep: flow.BaseStep{StepType: flow.StepInputText}, Text: "${PIN}"},
+			},
+		},
+	}
+
+	result, err := runner.Run(context.Background(), flows)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// Execution sees the real values
+	if !reflect.DeepEqual(typed, []string{"hunter2", "4711-0815"}) {
+		t.Errorf("typed = %q", typed)
+	}
+
+	leaked := func(where, s string) {
+		if strings.Contains(s, "hunter2") || strings.Contains(s, "4711") {
+			t.Errorf("%s contains a secret: %s", where, s)
+		}
+	}
+	leaked("flow error", result.FlowResults[0].Error)
+	leaked("step output", strings.Join(printed, "\n"))
+	if !strings.Contains(result.FlowResults[0].Error, secrets.Placeholder) {
+		t.Errorf("flow error = %q, want it redacted", result.FlowResults[0].Error)
+	}
+
+	err = filepath.Walk(tmpDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		leaked(path, string(data))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// tapFlow returns a flow with one tapOn step on text, for tests that track
+// which flows ran.
+func tapFlow(name, text string) flow.Flow {
+	return flow.Flow{
+		SourcePath: name + ".yaml",
+		Config:     flow.Config{Name: name},
+		Steps: []flow.Step{
+			&flow.TapOnStep{BaseStep: flow.BaseStep{StepType: flow.StepTapOn}, Selector: flow.Selector{Text: text}},
+		},
+	}
+}
+
+// tapDriver records the text of each tapOn and fails those in fail.
+func tapDriver(tapped *[]string, fail ...string) *mockDriver {
+	var mu sync.Mutex
+	return &mockDriver{
+		executeFunc: func(step flow.Step) *core.CommandResult {
+			text := step.(*flow.TapOnStep).Selector.Text
+			mu.Lock()
+			*tapped = append(*tapped, text)
+			mu.Unlock()
+			for _, f := range fail {
+				if f == text {
+					return &core.CommandResult{Success: false, Error: errors.New(text + " not found")}
+				}
+			}
+			return &core.CommandResult{Success: true}
+		},
+	}
+}
+
+func flowStatuses(result *RunResult) []report.Status {
+	var statuses []report.Status
+	for _, fr := range result.FlowResults {
+		statuses = append(statuses, fr.Status)
+	}
+	return statuses
+}
+
+func TestRunner_ExecutionOrder_StopOnFailure(t *testing.T) {
+	var tapped []string
+	var hooks []string
+	runner := New(tapDriver(&tapped, "B"), RunnerConfig{
+		OutputDir:          t.TempDir(),
+		Artifacts:          ArtifactNever,
+		OrderedFlows:       3,
+		StopOrderOnFailure: true,
+		BeforeSuite:        &flow.Flow{SourcePath: "setup.yaml", Steps: tapFlow("setup", "setup").Steps},
+		AfterSuite:         &flow.Flow{SourcePath: "teardown.yaml", Steps: tapFlow("teardown", "teardown").Steps},
+		OnSuiteHookStart:   func(hook, file string) { hooks = append(hooks, hook+" "+file) },
+	})
+
+	flows := []flow.Flow{tapFlow("a", "A"), tapFlow("b", "B"), tapFlow("c", "C"), tapFlow("d", "D")}
+	result, err := runner.Run(context.Background(), flows)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if want := []string{"setup", "A", "B", "D", "teardown"}; !reflect.DeepEqual(tapped, want) {
+		t.Errorf("tapped = %v, want %v", tapped, want)
+	}
+	if want := []string{"beforeSuite setup.yaml", "afterSuite teardown.yaml"}; !reflect.DeepEqual(hooks, want) {
+		t.Errorf("hooks = %v, want %v", hooks, want)
+	}
+	want := []report.Status{report.StatusPassed, report.StatusFailed, report.StatusSkipped, report.StatusPassed}
+	if got := flowStatuses(result); !reflect.DeepEqual(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+	if result.FlowResults[2].Error != skipOrderFailed {
+		t.Errorf("skipped flow error = %q", result.FlowResults[2].Error)
+	}
+	// Hooks don't count as flows
+	if result.TotalFlows != 4 {
+		t.Errorf("TotalFlows = %d, want 4", result.TotalFlows)
+	}
+}
+
+func TestRunner_BeforeSuiteFailure_SkipsFlows(t *testing.T) {
+	var tapped []string
+	runner := New(tapDriver(&tapped, "setup"), RunnerConfig{
+		OutputDir:   t.TempDir(),
+		Artifacts:   ArtifactNever,
+		BeforeSuite: &flow.Flow{SourcePath: "setup.yaml", Steps: tapFlow("setup", "setup").Steps},
+		AfterSuite:  &flow.Flow{SourcePath: "teardown.yaml", Steps: tapFlow("teardown", "teardown").Steps},
+	})
+
+	result, err := runner.Run(context.Background(), []flow.Flow{tapFlow("a", "A"), tapFlow("b", "B")})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// afterSuite still runs to clean up what beforeSuite did
+	if want := []string{"setup", "teardown"}; !reflect.DeepEqual(tapped, want) {
+		t.Errorf("tapped = %v, want %v", tapped, want)
+	}
+	for _, fr := range result.FlowResults {
+		if fr.Status != report.StatusSkipped || !strings.HasPrefix(fr.Error, skipHookFailed+": ") {
+			t.Errorf("flow %s = %s %q, want skipped because beforeSuite failed", fr.Name, fr.Status, fr.Error)
+		}
+	}
+}
+
+func TestRunner_Retries(t *testing.T) {
+	tmpDir := t.TempDir()
+	var tapped []string
+	attempts := 0
+	driver := tapDriver(&tapped)
+	driver.executeFunc = func(step flow.Step) *core.CommandResult {
+		attempts++
+		if attempts < 3 {
+			return &core.CommandResult{Success: false, Error: errors.New("flaky")}
+		}
+		return &core.CommandResult{Success: true}
+	}
+	runner := New(driver, RunnerConfig{OutputDir: tmpDir, Artifacts: ArtifactNever, Retries: 2})
+
+	result, err := runner.Run(context.Background(), []flow.Flow{tapFlow("a", "A")})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Status != report.StatusPassed || attempts != 3 {
+		t.Fatalf("status = %s after %d attempts, want passed after 3", result.Status, attempts)
+	}
+
+	index, err := report.ReadIndex(filepath.Join(tmpDir, "report.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := index.Flows[0]
+	if entry.Attempts != 2 || len(entry.AttemptHistory) != 2 {
+		t.Fatalf("attempts = %d, history = %+v", entry.Attempts, entry.AttemptHistory)
+	}
+	for i, a := range entry.AttemptHistory {
+		if a.Status != report.StatusFailed || a.Error != "flaky" {
+			t.Errorf("attempt %d = %+v", i+1, a)
+		}
+		if _, err := os.Stat(filepath.Join(tmpDir, a.DataFile)); err != nil {
+			t.Errorf("attempt %d detail: %v", i+1, err)
+		}
+	}
+	// The final detail is the passing attempt, not a mix with failed ones
+	detail, err := report.ReadFlowDetail(filepath.Join(tmpDir, "flows", entry.ID+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail.Commands[0].Status != report.StatusPassed {
+		t.Errorf("final command status = %s", detail.Commands[0].Status)
+	}
+}
+
+func TestRunner_RetryExpandsStepsAgain(t *testing.T) {
+	var typed []string
+	driver := &mockDriver{
+		executeFunc: func(step flow.Step) *core.CommandResult {
+			typed = append(typed, step.(*flow.InputTextStep).Text)
+			if len(typed) == 1 {
+				return &core.CommandResult{Success: false, Error: errors.New("flaky")}
+			}
+			return &core.CommandResult{Success: true}
+		},
+	}
+	f := flow.Flow{
+		SourcePath: "a.yaml",
+		Steps: []flow.Step{
+			&flow.EvalScriptStep{BaseStep: flow.BaseStep{StepType: flow.StepEvalScript}, Script: "output.n = (output.n || 0) + 1"},
+			&flow.InputTextStep{BaseStep: flow.BaseStep{StepType: flow.StepInputText}, Text: "${output.n}"},
+		},
+	}
+	runner := New(driver, RunnerConfig{OutputDir: t.TempDir(), Artifacts: ArtifactNever, Retries: 1})
+
+	result, err := runner.Run(context.Background(), []flow.Flow{f})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Status != report.StatusPassed || !reflect.DeepEqual(typed, []string{"1", "1"}) {
+		t.Errorf("status = %s, typed = %v", result.Status, typed)
+	}
+	// Each attempt expands copies; the flow keeps its variables for the next
+	if text := f.Steps[1].(*flow.InputTextStep).Text; text != "${output.n}" {
+		t.Errorf("flow step expanded in place: %q", text)
+	}
+}
+
+func TestParallelRunner_BeforeSuiteFailure(t *testing.T) {
+	var goodTapped, badTapped []string
+	good := tapDriver(&goodTapped)
+	good.platformFunc = func() *core.PlatformInfo { return &core.PlatformInfo{DeviceID: "good", DeviceName: "good"} }
+	bad := tapDriver(&badTapped, "setup")
+	bad.platformFunc = func() *core.PlatformInfo { return &core.PlatformInfo{DeviceID: "bad", DeviceName: "bad"} }
+	workers := []DeviceWorker{
+		{ID: 0, DeviceID: "bad", Driver: bad, Cleanup: func() {}},
+		{ID: 1, DeviceID: "good", Driver: good, Cleanup: func() {}},
+	}
+	pr := NewParallelRunner(workers, RunnerConfig{
+		OutputDir:   t.TempDir(),
+		Artifacts:   ArtifactNever,
+		BeforeSuite: &flow.Flow{SourcePath: "setup.yaml", Steps: tapFlow("setup", "setup").Steps},
+	})
+
+	result, err := pr.Run(context.Background(), []flow.Flow{tapFlow("a", "A"), tapFlow("b", "B"), tapFlow("c", "C")})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	// The worker whose setup failed takes no flows; the other runs them all
+	if result.PassedFlows != 3 {
+		t.Errorf("PassedFlows = %d, statuses = %v", result.PassedFlows, flowStatuses(result))
+	}
+	if want := []string{"setup"}; !reflect.DeepEqual(badTapped, want) {
+		t.Errorf("bad device tapped %v, want %v", badTapped, want)
+	}
+	if len(goodTapped) != 4 {
+		t.Errorf("good device tapped %v, want setup and 3 flows", goodTapped)
+	}
+	if result.Status != report.StatusPassed || len(result.FailedDevices) != 1 || result.FailedDevices[0].DeviceID != "bad" {
+		t.Errorf("status = %s, FailedDevices = %+v", result.Status, result.FailedDevices)
+	}
+}
+
+func TestRunner_BeforeSuiteOutput(t *testing.T) {
+	var typed []string
+	var mu sync.Mutex
+	driver := &mockDriver{
+		executeFunc: func(step flow.Step) *core.CommandResult {
+			if s, ok := step.(*flow.InputTextStep); ok {
+				mu.Lock()
+				typed = append(typed, s.Text)
+				mu.Unlock()
+			}
+			return &core.CommandResult{Success: true}
+		},
+	}
+	setup := &flow.Flow{
+		SourcePath: "setup.yaml",
+		Steps: []flow.Step{
+			&flow.EvalScriptStep{BaseStep: flow.BaseStep{StepType: flow.StepEvalScript}, Script: "output.token = 'abc123'"},
+		},
+	}
+	useToken := func(name string) flow.Flow {
+		return flow.Flow{
+			SourcePath: name + ".yaml",
+			Config:     flow.Config{Name: name},
+			Steps: []flow.Step{
+				&flow.InputTextStep{BaseStep: flow.BaseStep{StepType: flow.StepInputText}, Text: "${output.token} ${token}"},
+			},
+		}
+	}
+	runner := New(driver, RunnerConfig{
+		OutputDir:   t.TempDir(),
+		Artifacts:   ArtifactNever,
+		BeforeSuite: setup,
+		AfterSuite:  &flow.Flow{SourcePath: "teardown.yaml", Steps: useToken("teardown").Steps},
+	})
+
+	result, err := runner.Run(context.Background(), []flow.Flow{useToken("a"), useToken("b")})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Status != report.StatusPassed {
+		t.Fatalf("status = %s", result.Status)
+	}
+	// Both flows and the teardown see the setup's output
+	if want := []string{"abc123 abc123", "abc123 abc123", "abc123 abc123"}; !reflect.DeepEqual(typed, want) {
+		t.Errorf("typed = %q, want %q", typed, want)
+	}
+}
+
+func TestRunner_BeforeSuiteFailure_FailsRun(t *testing.T) {
+	var tapped []string
+	runner := New(tapDriver(&tapped, "setup"), RunnerConfig{
+		OutputDir:   t.TempDir(),
+		Artifacts:   ArtifactNever,
+		DeviceInfo:  &report.Device{ID: "emulator-5554", Name: "Pixel"},
+		BeforeSuite: &flow.Flow{SourcePath: "setup.yaml", Steps: tapFlow("setup", "setup").Steps},
+	})
+
+	result, err := runner.Run(context.Background(), []flow.Flow{tapFlow("a", "A")})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Status != report.StatusFailed {
+		t.Errorf("status = %s, want failed", result.Status)
+	}
+	want := []DeviceFailure{{DeviceID: "emulator-5554", Name: "Pixel", Error: "setup not found"}}
+	if !reflect.DeepEqual(result.FailedDevices, want) {
+		t.Errorf("FailedDevices = %+v, want %+v", result.FailedDevices, want)
+	}
+}
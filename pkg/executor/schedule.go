@@ -0,0 +1,230 @@
+package executor
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/core"
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/devicelab-dev/maestro-runner/pkg/report"
+)
+
+// skipUnschedulable is the reason a flow no device in the run can run is
+// skipped; the requirements follow in parentheses.
+const skipUnschedulable = "skipped: no device meets requirements"
+
+// UnschedulableFlow is a flow that was skipped because no device in the run
+// meets its device requirements.
+type UnschedulableFlow struct {
+	Name         string
+	File         string
+	Requirements string // e.g. "android, OS >= 14, tablet"
+}
+
+// deviceProfile is what flow device requirements are matched against.
+// Unknown properties don't meet a requirement on them.
+type deviceProfile struct {
+	platform   string
+	osVersion  []int // nil = unknown
+	formFactor string
+	locale     string
+	groups     map[string]bool
+}
+
+// newDeviceProfile builds the profile of a device. groups maps a device group
+// name to the IDs or names of its devices. Android devices are matched on
+// their release ("14"), not their API level, when the driver reports it.
+func newDeviceProfile(info *core.PlatformInfo, groups map[string][]string) deviceProfile {
+	if info == nil {
+		return deviceProfile{}
+	}
+	version := info.OSVersion
+	if info.OSRelease != "" {
+		version = info.OSRelease
+	}
+	p := deviceProfile{
+		platform:   strings.ToLower(info.Platform),
+		formFactor: info.FormFactor,
+		locale:     info.Locale,
+		groups:     make(map[string]bool),
+	}
+	p.osVersion, _ = flow.ParseVersion(version)
+	for group, members := range groups {
+		if slices.Contains(members, info.DeviceID) || (info.DeviceName != "" && slices.Contains(members, info.DeviceName)) {
+			p.groups[group] = true
+		}
+	}
+	return p
+}
+
+// meets reports whether the device meets req (nil = no requirements).
+func (p deviceProfile) meets(req *flow.DeviceRequirements) bool {
+	if req == nil {
+		return true
+	}
+	if req.Platform != "" && !strings.EqualFold(req.Platform, p.platform) {
+		return false
+	}
+	if req.MinOSVersion != "" {
+		minVersion, err := flow.ParseVersion(req.MinOSVersion)
+		if err != nil || p.osVersion == nil || flow.CompareVersions(p.osVersion, minVersion) < 0 {
+			return false
+		}
+	}
+	if req.FormFactor != "" && req.FormFactor != p.formFactor {
+		return false
+	}
+	if req.Locale != "" && !localeMatches(p.locale, req.Locale) {
+		return false
+	}
+	if req.Group != "" && !p.groups[req.Group] {
+		return false
+	}
+	return true
+}
+
+// meetsAll reports whether the device meets the requirements of every flow
+// of a work item.
+func (p deviceProfile) meetsAll(item workItem) bool {
+	if !p.meets(item.flow.Config.Device) {
+		return false
+	}
+	for _, it := range item.next {
+		if !p.meets(it.flow.Config.Device) {
+			return false
+		}
+	}
+	return true
+}
+
+// localeMatches compares locales case-insensitively, with "-" and "_" alike.
+// A language without region ("de") matches any locale of that language.
+func localeMatches(have, want string) bool {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.ReplaceAll(s, "-", "_"))
+	}
+	have, want = normalize(have), normalize(want)
+	return have == want || (!strings.Contains(want, "_") && strings.HasPrefix(have, want+"_"))
+}
+
+// anyMeets reports whether any of the devices meets req.
+func anyMeets(profiles []deviceProfile, req *flow.DeviceRequirements) bool {
+	for _, p := range profiles {
+		if p.meets(req) {
+			return true
+		}
+	}
+	return false
+}
+
+// markUnschedulable skips a flow no device can run and describes it for the
+// run result.
+func markUnschedulable(indexWriter *report.IndexWriter, detail *report.FlowDetail, f flow.Flow) (FlowResult, UnschedulableFlow) {
+	requirements := f.Config.Device.String()
+	result := skipFlow(indexWriter, detail, fmt.Sprintf("%s (%s)", skipUnschedulable, requirements))
+	return result, UnschedulableFlow{Name: detail.Name, File: detail.SourceFile, Requirements: requirements}
+}
+
+// planWork turns the flows into work items for devices with the given
+// profiles. The first ordered flows form one chain, run by a single device;
+// if no device can run the whole chain, its flows are queued separately.
+// Flows that no device can run are returned as unplaced instead.
+func planWork(flows []flow.Flow, ordered int, profiles []deviceProfile) (items []workItem, unplaced []int) {
+	var chain []workItem
+	for i, f := range flows {
+		if !anyMeets(profiles, f.Config.Device) {
+			unplaced = append(unplaced, i)
+			continue
+		}
+		item := workItem{flow: f, index: i}
+		if i < ordered {
+			chain = append(chain, item)
+		} else {
+			items = append(items, item)
+		}
+	}
+	if len(chain) == 0 {
+		return items, unplaced
+	}
+
+	head := chain[0]
+	head.next = chain[1:]
+	for _, p := range profiles {
+		if p.meetsAll(head) {
+			return append([]workItem{head}, items...), unplaced
+		}
+	}
+	return append(chain, items...), unplaced
+}
+
+// expectedDurations returns the expected duration of each flow from past
+// runs. Flows without history get the average of those with it, so they
+// neither all start first nor all start last.
+func expectedDurations(details []report.FlowDetail, history map[string]int64) []int64 {
+	durations := make([]int64, len(details))
+	known := make([]bool, len(details))
+	var total, count int64
+	for i, d := range details {
+		if ms, ok := history[report.FlowKey(d.SourceFile, d.Name)]; ok {
+			durations[i], known[i] = ms, true
+			total += ms
+			count++
+		}
+	}
+	if count == 0 {
+		return durations
+	}
+	for i := range durations {
+		if !known[i] {
+			durations[i] = total / count
+		}
+	}
+	return durations
+}
+
+// scheduler hands out work items to device workers. A worker gets the first
+// pending item whose requirements its device meets. Items of the execution
+// order come first, then the longest expected flows, so short flows fill in
+// at the end of the run.
+type scheduler struct {
+	mu      sync.Mutex
+	pending []workItem
+}
+
+// newScheduler queues items; durations holds the expected duration of each
+// flow by index.
+func newScheduler(items []workItem, ordered int, durations []int64) *scheduler {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].index, items[j].index
+		if a < ordered || b < ordered {
+			return a < b
+		}
+		return durations[a] > durations[b]
+	})
+	return &scheduler{pending: items}
+}
+
+// next removes and returns the first pending item the device can run.
+func (s *scheduler) next(p deviceProfile) (workItem, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, item := range s.pending {
+		if p.meetsAll(item) {
+			s.pending = slices.Delete(s.pending, i, i+1)
+			return item, true
+		}
+	}
+	return workItem{}, false
+}
+
+// drain removes and returns the items no worker took.
+func (s *scheduler) drain() []workItem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := s.pending
+	s.pending = nil
+	return items
+}
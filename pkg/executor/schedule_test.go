@@ -0,0 +1,214 @@
+package executor
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/core"
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/devicelab-dev/maestro-runner/pkg/report"
+)
+
+func requireDevice(f flow.Flow, req flow.DeviceRequirements) flow.Flow {
+	f.Config.Device = &req
+	return f
+}
+
+func TestDeviceProfile_Meets(t *testing.T) {
+	pixel := newDeviceProfile(&core.PlatformInfo{
+		Platform:   "android",
+		OSVersion:  "34",
+		OSRelease:  "14",
+		DeviceID:   "emulator-5554",
+		DeviceName: "Pixel Tablet",
+		FormFactor: flow.FormFactorTablet,
+		Locale:     "de_DE",
+	}, map[string][]string{"lab": {"Pixel Tablet"}, "ci": {"other"}})
+
+	tests := []struct {
+		name string
+		req  *flow.DeviceRequirements
+		want bool
+	}{
+		{"no requirements", nil, true},
+		{"platform", &flow.DeviceRequirements{Platform: "Android"}, true},
+		{"other platform", &flow.DeviceRequirements{Platform: "ios"}, false},
+		{"release, not API level", &flow.DeviceRequirements{MinOSVersion: "14"}, true},
+		{"newer OS", &flow.DeviceRequirements{MinOSVersion: "14.1"}, false},
+		{"form factor", &flow.DeviceRequirements{FormFactor: flow.FormFactorTablet}, true},
+		{"other form factor", &flow.DeviceRequirements{FormFactor: flow.FormFactorPhone}, false},
+		{"locale", &flow.DeviceRequirements{Locale: "de-de"}, true},
+		{"language", &flow.DeviceRequirements{Locale: "de"}, true},
+		{"other locale", &flow.DeviceRequirements{Locale: "de_AT"}, false},
+		{"group by name", &flow.DeviceRequirements{Group: "lab"}, true},
+		{"other group", &flow.DeviceRequirements{Group: "ci"}, false},
+		{"all of them", &flow.DeviceRequirements{Platform: "android", MinOSVersion: "13", Locale: "de", Group: "lab"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pixel.meets(tt.req); got != tt.want {
+				t.Errorf("meets(%+v) = %v, want %v", tt.req, got, tt.want)
+			}
+		})
+	}
+
+	// Properties the driver doesn't report meet no requirement on them
+	unknown := newDeviceProfile(&core.PlatformInfo{Platform: "ios"}, nil)
+	for _, req := range []*flow.DeviceRequirements{{MinOSVersion: "1"}, {FormFactor: flow.FormFactorPhone}, {Locale: "en"}, {Group: "lab"}} {
+		if unknown.meets(req) {
+			t.Errorf("device with unknown properties meets %+v", req)
+		}
+	}
+}
+
+func TestPlanWork(t *testing.T) {
+	phone := newDeviceProfile(&core.PlatformInfo{Platform: "android", FormFactor: flow.FormFactorPhone}, nil)
+	tablet := newDeviceProfile(&core.PlatformInfo{Platform: "android", FormFactor: flow.FormFactorTablet}, nil)
+	flows := []flow.Flow{
+		tapFlow("a", "A"),
+		requireDevice(tapFlow("b", "B"), flow.DeviceRequirements{FormFactor: flow.FormFactorTablet}),
+		requireDevice(tapFlow("c", "C"), flow.DeviceRequirements{Platform: "ios"}),
+		tapFlow("d", "D"),
+	}
+
+	items, unplaced := planWork(flows, 2, []deviceProfile{phone, tablet})
+	if !reflect.DeepEqual(unplaced, []int{2}) {
+		t.Errorf("unplaced = %v, want [2]", unplaced)
+	}
+	// The tablet can run the whole ordered chain
+	if len(items) != 2 || items[0].index != 0 || len(items[0].next) != 1 || items[1].index != 3 {
+		t.Errorf("items = %+v", items)
+	}
+
+	// With devices that each run only part of the chain, it is split
+	flows[0] = requireDevice(flows[0], flow.DeviceRequirements{FormFactor: flow.FormFactorPhone})
+	items, _ = planWork(flows, 2, []deviceProfile{phone, tablet})
+	if len(items) != 3 || len(items[0].next) != 0 || items[1].index != 1 {
+		t.Errorf("items = %+v", items)
+	}
+}
+
+func TestScheduler_LongestFirst(t *testing.T) {
+	var items []workItem
+	for i, name := range []string{"ordered", "short", "unknown", "long"} {
+		items = append(items, workItem{flow: tapFlow(name, name), index: i})
+	}
+	details := []report.FlowDetail{
+		{Name: "ordered", SourceFile: "ordered.yaml"},
+		{Name: "short", SourceFile: "short.yaml"},
+		{Name: "unknown", SourceFile: "unknown.yaml"},
+		{Name: "long", SourceFile: "long.yaml"},
+	}
+	durations := expectedDurations(details, map[string]int64{
+		report.FlowKey("short.yaml", "short"): 1000,
+		report.FlowKey("long.yaml", "long"):   9000,
+	})
+	if durations[2] != 5000 {
+		t.Errorf("unknown duration = %d, want the average 5000", durations[2])
+	}
+
+	s := newScheduler(items, 1, durations)
+	phone := newDeviceProfile(&core.PlatformInfo{Platform: "android"}, nil)
+	var got []string
+	for {
+		item, ok := s.next(phone)
+		if !ok {
+			break
+		}
+		got = append(got, item.flow.Config.Name)
+	}
+	if want := []string{"ordered", "long", "unknown", "short"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
+
+func TestScheduler_SkipsUnmetItems(t *testing.T) {
+	items := []workItem{
+		{flow: requireDevice(tapFlow("tablet", "T"), flow.DeviceRequirements{FormFactor: flow.FormFactorTablet}), index: 0},
+		{flow: tapFlow("any", "A"), index: 1},
+	}
+	s := newScheduler(items, 0, make([]int64, 2))
+	phone := newDeviceProfile(&core.PlatformInfo{Platform: "android", FormFactor: flow.FormFactorPhone}, nil)
+
+	if item, ok := s.next(phone); !ok || item.index != 1 {
+		t.Fatalf("next() = %+v, %v, want the flow without requirements", item, ok)
+	}
+	if _, ok := s.next(phone); ok {
+		t.Error("phone got the tablet flow")
+	}
+	if left := s.drain(); len(left) != 1 || left[0].index != 0 {
+		t.Errorf("drain() = %+v", left)
+	}
+}
+
+func TestParallelRunner_DeviceRequirements(t *testing.T) {
+	var phoneTapped, tabletTapped []string
+	phone := tapDriver(&phoneTapped)
+	phone.platformFunc = func() *core.PlatformInfo {
+		return &core.PlatformInfo{Platform: "android", DeviceID: "phone", DeviceName: "phone", FormFactor: flow.FormFactorPhone}
+	}
+	tablet := tapDriver(&tabletTapped)
+	tablet.platformFunc = func() *core.PlatformInfo {
+		return &core.PlatformInfo{Platform: "android", DeviceID: "tablet", DeviceName: "tablet", FormFactor: flow.FormFactorTablet}
+	}
+	workers := []DeviceWorker{
+		{ID: 0, DeviceID: "phone", Driver: phone, Cleanup: func() {}},
+		{ID: 1, DeviceID: "tablet", Driver: tablet, Cleanup: func() {}},
+	}
+	pr := NewParallelRunner(workers, RunnerConfig{
+		OutputDir:    t.TempDir(),
+		Artifacts:    ArtifactNever,
+		DeviceGroups: map[string][]string{"lab": {"tablet"}},
+	})
+
+	result, err := pr.Run(context.Background(), []flow.Flow{
+		requireDevice(tapFlow("t1", "T1"), flow.DeviceRequirements{FormFactor: flow.FormFactorTablet}),
+		requireDevice(tapFlow("p1", "P1"), flow.DeviceRequirements{FormFactor: flow.FormFactorPhone}),
+		requireDevice(tapFlow("lab", "L"), flow.DeviceRequirements{Group: "lab"}),
+		requireDevice(tapFlow("ios", "I"), flow.DeviceRequirements{Platform: "ios", MinOSVersion: "17"}),
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if want := []string{"P1"}; !reflect.DeepEqual(phoneTapped, want) {
+		t.Errorf("phone tapped %v, want %v", phoneTapped, want)
+	}
+	if len(tabletTapped) != 2 {
+		t.Errorf("tablet tapped %v, want T1 and L", tabletTapped)
+	}
+	if result.PassedFlows != 3 || result.SkippedFlows != 1 {
+		t.Errorf("statuses = %v", flowStatuses(result))
+	}
+	if len(result.Unschedulable) != 1 || result.Unschedulable[0].Name != "ios" || result.Unschedulable[0].Requirements != "ios, OS >= 17" {
+		t.Errorf("Unschedulable = %+v", result.Unschedulable)
+	}
+	if fr := result.FlowResults[3]; !strings.HasPrefix(fr.Error, skipUnschedulable) {
+		t.Errorf("ios flow error = %q", fr.Error)
+	}
+}
+
+func TestRunner_DeviceRequirements(t *testing.T) {
+	var tapped []string
+	driver := tapDriver(&tapped)
+	driver.platformFunc = func() *core.PlatformInfo {
+		return &core.PlatformInfo{Platform: "android", OSVersion: "34", OSRelease: "14", DeviceID: "emulator-5554"}
+	}
+	runner := New(driver, RunnerConfig{OutputDir: t.TempDir(), Artifacts: ArtifactNever})
+
+	result, err := runner.Run(context.Background(), []flow.Flow{
+		requireDevice(tapFlow("new", "N"), flow.DeviceRequirements{MinOSVersion: "15"}),
+		requireDevice(tapFlow("old", "O"), flow.DeviceRequirements{MinOSVersion: "13"}),
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := []string{"O"}; !reflect.DeepEqual(tapped, want) {
+		t.Errorf("tapped = %v, want %v", tapped, want)
+	}
+	if result.Status != report.StatusPassed || len(result.Unschedulable) != 1 || result.Unschedulable[0].Name != "new" {
+		t.Errorf("status = %s, Unschedulable = %+v", result.Status, result.Unschedulable)
+	}
+}
@@ -0,0 +1,165 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/core"
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/devicelab-dev/maestro-runner/pkg/report"
+)
+
+// scriptDevice gives scripts the flow's driver through the maestro.*
+// functions. Each call is recorded as a sub-command of the script's step.
+type scriptDevice struct {
+	fr *FlowRunner
+}
+
+// FindElement returns the visible element matching selector, or nil.
+func (d *scriptDevice) FindElement(sel flow.Selector, timeoutMs int) (*core.ElementInfo, error) {
+	var info *core.ElementInfo
+	err := d.record("findElement", sel.DescribeQuoted(), func() error {
+		info = d.find(sel, timeoutMs)
+		return nil
+	})
+	return info, err
+}
+
+// IsVisible reports whether an element matching selector is visible.
+func (d *scriptDevice) IsVisible(sel flow.Selector, timeoutMs int) (bool, error) {
+	var visible bool
+	err := d.record("isVisible", sel.DescribeQuoted(), func() error {
+		visible = d.find(sel, timeoutMs) != nil
+		return nil
+	})
+	return visible, err
+}
+
+// GetText returns the text of the element matching selector.
+func (d *scriptDevice) GetText(sel flow.Selector, timeoutMs int) (string, error) {
+	var text string
+	err := d.record("getText", sel.DescribeQuoted(), func() error {
+		info := d.find(sel, timeoutMs)
+		if info == nil {
+			return fmt.Errorf("element not found: %s", sel.DescribeQuoted())
+		}
+		text = info.Text
+		return nil
+	})
+	return text, err
+}
+
+// Tap taps the element matching selector.
+func (d *scriptDevice) Tap(sel flow.Selector) error {
+	return d.record("tap", sel.DescribeQuoted(), func() error {
+		step := &flow.TapOnStep{BaseStep: flow.BaseStep{StepType: flow.StepTapOn}, Selector: sel}
+		return resultError(d.fr.driver.Execute(step))
+	})
+}
+
+// Screenshot saves a PNG screenshot. A relative path is resolved against the
+// report directory, and ".png" is added if path has no extension.
+func (d *scriptDevice) Screenshot(path string) (string, error) {
+	if filepath.Ext(path) == "" {
+		path += ".png"
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(d.fr.config.OutputDir, path)
+	}
+
+	err := d.record("screenshot", path, func() error {
+		data, err := d.fr.driver.Screenshot()
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(path, data, 0o644)
+	})
+	return path, err
+}
+
+// Hierarchy returns the UI hierarchy.
+func (d *scriptDevice) Hierarchy() ([]byte, error) {
+	var data []byte
+	err := d.record("hierarchy", "", func() error {
+		var err error
+		data, err = d.fr.driver.Hierarchy()
+		return err
+	})
+	return data, err
+}
+
+// State returns the current device and app state.
+func (d *scriptDevice) State() (*core.StateSnapshot, error) {
+	var state *core.StateSnapshot
+	err := d.record("state", "", func() error {
+		state = d.fr.driver.GetState()
+		return nil
+	})
+	return state, err
+}
+
+// find looks for a visible element matching selector, waiting up to
+// timeoutMs (0 = the driver's find timeout).
+func (d *scriptDevice) find(sel flow.Selector, timeoutMs int) *core.ElementInfo {
+	step := &flow.AssertVisibleStep{
+		BaseStep: flow.BaseStep{StepType: flow.StepAssertVisible, TimeoutMs: timeoutMs},
+		Selector: sel,
+	}
+	result := d.fr.driver.Execute(step)
+	if !result.Success {
+		return nil
+	}
+	if result.Element != nil {
+		return result.Element
+	}
+	return &core.ElementInfo{Text: sel.Text, Visible: true}
+}
+
+// record runs call and adds it to the flow runner's sub-commands as
+// maestro.<name>, labelled with detail.
+func (d *scriptDevice) record(name, detail string, call func() error) error {
+	start := time.Now()
+	err := call()
+	now := time.Now()
+	duration := now.Sub(start).Milliseconds()
+
+	label := "maestro." + name
+	if detail != "" {
+		label += "(" + detail + ")"
+	}
+	cmd := report.Command{
+		ID:        fmt.Sprintf("sub-%d", len(d.fr.subCommands)),
+		Index:     len(d.fr.subCommands),
+		Type:      "maestro." + name,
+		Label:     label,
+		Status:    report.StatusPassed,
+		StartTime: &start,
+		EndTime:   &now,
+		Duration:  &duration,
+	}
+	if err != nil {
+		cmd.Status = report.StatusFailed
+		cmd.Error = &report.Error{Type: "execution", Message: err.Error()}
+	}
+	d.fr.subCommands = append(d.fr.subCommands, cmd)
+	return err
+}
+
+// resultError returns the error of a failed driver result, or nil.
+func resultError(result *core.CommandResult) error {
+	switch {
+	case result.Success:
+		return nil
+	case result.Error != nil:
+		return result.Error
+	case result.Message != "":
+		return fmt.Errorf("%s", result.Message)
+	default:
+		return fmt.Errorf("command failed")
+	}
+}
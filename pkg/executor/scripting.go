@@ -2,17 +2,23 @@ package executor
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/devicelab-dev/maestro-runner/pkg/core"
+	"github.com/devicelab-dev/maestro-runner/pkg/faker"
 	"github.com/devicelab-dev/maestro-runner/pkg/flow"
 	"github.com/devicelab-dev/maestro-runner/pkg/jsengine"
+	"github.com/devicelab-dev/maestro-runner/pkg/logger"
+	"github.com/devicelab-dev/maestro-runner/pkg/secrets"
 )
 
 // envVarPattern matches ALL_CAPS identifiers that look like env variables
@@ -20,17 +26,23 @@ var envVarPattern = regexp.MustCompile(`\b([A-Z][A-Z0-9_]{2,})\b`)
 
 // ScriptEngine handles JavaScript execution and variable management.
 type ScriptEngine struct {
-	js        *jsengine.Engine
-	variables map[string]string
-	flowDir   string // Directory of current flow (for resolving relative paths)
+	js             *jsengine.Engine
+	variables      map[string]string
+	flowDir        string       // Directory of current flow (for resolving relative paths)
+	random         *faker.Faker // Generates inputRandom values and faker.* in scripts
+	secretPatterns []string     // Names of variables whose values are redacted from output
+	secretNames    *secrets.Matcher
 }
 
 // NewScriptEngine creates a new script engine.
 func NewScriptEngine() *ScriptEngine {
-	return &ScriptEngine{
+	se := &ScriptEngine{
 		js:        jsengine.New(),
 		variables: make(map[string]string),
 	}
+	se.SetSeed(faker.NewSeed())
+	se.AddSecretPatterns(secrets.DefaultPatterns...)
+	return se
 }
 
 // Close cleans up the script engine.
@@ -43,14 +55,61 @@ func (se *ScriptEngine) Close() {
 // SetFlowDir sets the current flow directory for relative path resolution.
 func (se *ScriptEngine) SetFlowDir(dir string) {
 	se.flowDir = dir
+	se.js.SetBaseDir(dir)
+}
+
+// SetSeed reseeds the generator behind inputRandom and faker.* in scripts.
+func (se *ScriptEngine) SetSeed(seed int64) {
+	se.random = faker.New(seed)
+	se.js.SetFaker(se.random)
+}
+
+// SetTimeout sets the default time limit for each script, including its timers.
+func (se *ScriptEngine) SetTimeout(d time.Duration) {
+	se.js.SetTimeout(d)
+}
+
+// withStepTimeout applies a step's timeout, if set, to the scripts it runs.
+// It returns a func that restores the default.
+func (se *ScriptEngine) withStepTimeout(timeoutMs int) func() {
+	if timeoutMs <= 0 {
+		return func() {}
+	}
+	prev := se.js.Timeout()
+	se.js.SetTimeout(time.Duration(timeoutMs) * time.Millisecond)
+	return func() { se.js.SetTimeout(prev) }
+}
+
+// SetModulePaths sets the directories require() searches for bare module names.
+func (se *ScriptEngine) SetModulePaths(paths []string) {
+	se.js.SetModulePaths(paths)
 }
 
 // SetVariable sets a variable in both Go map and JS engine.
 func (se *ScriptEngine) SetVariable(name, value string) {
+	if value != "" && se.secretNames.IsSecret(name) && !secrets.Add(value) {
+		logger.Warn("secret variable %s is shorter than %d characters and is not redacted", name, secrets.MinLength)
+	}
 	se.variables[name] = value
 	se.js.SetVariable(name, value)
 }
 
+// AddSecretPatterns marks variables as secret by name or glob pattern (such
+// as "*_PASSWORD"). Values of secret variables set from then on are redacted
+// from reports, logs and console output.
+func (se *ScriptEngine) AddSecretPatterns(patterns ...string) {
+	added := false
+	for _, p := range patterns {
+		if !slices.Contains(se.secretPatterns, p) {
+			se.secretPatterns = append(se.secretPatterns, p)
+			added = true
+		}
+	}
+	if added || se.secretNames == nil {
+		se.secretNames = secrets.NewMatcher(se.secretPatterns...)
+	}
+}
+
 // SetVariables sets multiple variables.
 func (se *ScriptEngine) SetVariables(vars map[string]string) {
 	for k, v := range vars {
@@ -59,19 +118,23 @@ func (se *ScriptEngine) SetVariables(vars map[string]string) {
 }
 
 // ImportSystemEnv imports system environment variables into the script engine.
-// Only imports variables matching the pattern (uppercase with underscores).
 func (se *ScriptEngine) ImportSystemEnv() {
-	for _, env := range os.Environ() {
-		parts := strings.SplitN(env, "=", 2)
-		if len(parts) == 2 {
-			name := parts[0]
-			value := parts[1]
-			// Import if it matches env var pattern (uppercase like THING, MY_VAR)
-			if envVarPattern.MatchString(name) {
-				se.SetVariable(name, value)
-			}
+	for name, value := range SystemEnv() {
+		se.SetVariable(name, value)
+	}
+}
+
+// SystemEnv returns the system environment variables flows see: those whose
+// names look like env variables (uppercase like THING, MY_VAR).
+func SystemEnv() map[string]string {
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) == 2 && envVarPattern.MatchString(parts[0]) {
+			env[parts[0]] = parts[1]
 		}
 	}
+	return env
 }
 
 // GetVariable returns a variable value.
@@ -84,6 +147,12 @@ func (se *ScriptEngine) SetPlatform(platform string) {
 	se.js.SetPlatform(platform)
 }
 
+// SetDevice sets the device scripts reach through maestro.findElement,
+// maestro.tap and the other maestro.* device functions.
+func (se *ScriptEngine) SetDevice(d jsengine.Device) {
+	se.js.SetDevice(d)
+}
+
 // SetCopiedText sets the copied text in the JS engine.
 func (se *ScriptEngine) SetCopiedText(text string) {
 	se.js.SetCopiedText(text)
@@ -99,6 +168,13 @@ func (se *ScriptEngine) GetOutput() map[string]interface{} {
 	return se.js.GetOutput()
 }
 
+// SetOutput sets output variables, such as those of the suite setup flow:
+// scripts see them on output and steps as variables.
+func (se *ScriptEngine) SetOutput(vars map[string]interface{}) {
+	se.js.SetOutput(vars)
+	se.SyncOutputToVariables()
+}
+
 // SyncOutputToVariables copies JS output back to variables.
 func (se *ScriptEngine) SyncOutputToVariables() {
 	for k, v := range se.js.GetOutput() {
@@ -163,6 +239,18 @@ func expandDollarVar(text, name, value string) string {
 
 // RunScript executes a JavaScript script.
 func (se *ScriptEngine) RunScript(script string, env map[string]string) error {
+	return se.runScript(script, env, se.js.RunScript)
+}
+
+// RunScriptFile executes a JavaScript file's contents. require() in the
+// script resolves relative to path, and errors report the file's lines.
+func (se *ScriptEngine) RunScriptFile(path, script string, env map[string]string) error {
+	return se.runScript(script, env, func(script string) error {
+		return se.js.RunFile(path, script)
+	})
+}
+
+func (se *ScriptEngine) runScript(script string, env map[string]string, run func(string) error) error {
 	// Expand variables in script
 	script = se.ExpandVariables(script)
 
@@ -179,7 +267,7 @@ func (se *ScriptEngine) RunScript(script string, env map[string]string) error {
 	}
 
 	// Execute script
-	if err := se.js.RunScript(script); err != nil {
+	if err := run(script); err != nil {
 		return err
 	}
 
@@ -221,6 +309,89 @@ func (se *ScriptEngine) EvalCondition(script string) (bool, error) {
 	}
 }
 
+// Eval evaluates a JavaScript expression and returns its result as a string.
+// Assignments to output.* are synced back to variables.
+func (se *ScriptEngine) Eval(script string) (string, error) {
+	script = extractJS(script)
+
+	// Pre-define potential env variables as undefined to avoid ReferenceError
+	matches := envVarPattern.FindAllString(script, -1)
+	for _, name := range matches {
+		se.js.DefineUndefinedIfMissing(name)
+	}
+
+	result, err := se.js.EvalString(script)
+	if err != nil {
+		return "", err
+	}
+
+	se.SyncOutputToVariables()
+	return result, nil
+}
+
+// ForEachItems resolves the items of a forEach step. A YAML list is used as
+// is; a string that is a single ${...} expression returning an array yields
+// the array's elements; any other string is expanded and split on commas.
+func (se *ScriptEngine) ForEachItems(items any) ([]any, error) {
+	switch v := items.(type) {
+	case []any:
+		return v, nil
+	case nil:
+		return nil, nil
+	case string:
+		text := strings.TrimSpace(v)
+		if strings.HasPrefix(text, "${") && strings.HasSuffix(text, "}") && strings.Count(text, "${") == 1 {
+			script := extractJS(text)
+			for _, name := range envVarPattern.FindAllString(script, -1) {
+				se.js.DefineUndefinedIfMissing(name)
+			}
+			result, err := se.js.Eval(script)
+			if err != nil {
+				return nil, err
+			}
+			switch r := result.(type) {
+			case []any:
+				return r, nil
+			case nil:
+				return nil, nil
+			}
+			text = fmt.Sprintf("%v", result)
+		} else {
+			text = se.ExpandVariables(text)
+		}
+		var list []any
+		for _, part := range strings.Split(text, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				list = append(list, part)
+			}
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("forEach items must be a list or a string, got %T", items)
+}
+
+// SetItemVariable binds a forEach item. Scripts see the value itself, so
+// ${item.id} works for objects; ${item} in step fields and $item see its
+// string form, with objects and arrays as JSON.
+func (se *ScriptEngine) SetItemVariable(name string, value any) {
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case map[string]any, []any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			text = fmt.Sprintf("%v", v)
+		} else {
+			text = string(data)
+		}
+	default:
+		text = fmt.Sprintf("%v", v)
+	}
+	se.variables[name] = text
+	se.js.SetVariable(name, value)
+}
+
 // ResolvePath resolves a relative path against the flow directory.
 func (se *ScriptEngine) ResolvePath(path string) string {
 	if filepath.IsAbs(path) || se.flowDir == "" {
@@ -246,23 +417,28 @@ func (se *ScriptEngine) ExecuteDefineVariables(step *flow.DefineVariablesStep) *
 
 // ExecuteRunScript handles runScript step.
 func (se *ScriptEngine) ExecuteRunScript(step *flow.RunScriptStep) *core.CommandResult {
+	defer se.withStepTimeout(step.TimeoutMs)()
+
 	script := step.ScriptPath()
 
 	// Check if it's a file path (ends with .js)
+	var err error
 	if strings.HasSuffix(script, ".js") {
 		filePath := se.ResolvePath(script)
-		content, err := os.ReadFile(filePath)
-		if err != nil {
+		content, readErr := os.ReadFile(filePath)
+		if readErr != nil {
 			return &core.CommandResult{
 				Success: false,
-				Error:   err,
+				Error:   readErr,
 				Message: fmt.Sprintf("Cannot read script file: %s", filePath),
 			}
 		}
-		script = string(content)
+		err = se.RunScriptFile(filePath, string(content), step.Env)
+	} else {
+		err = se.RunScript(script, step.Env)
 	}
 
-	if err := se.RunScript(script, step.Env); err != nil {
+	if err != nil {
 		return &core.CommandResult{
 			Success: false,
 			Error:   err,
@@ -278,6 +454,8 @@ func (se *ScriptEngine) ExecuteRunScript(step *flow.RunScriptStep) *core.Command
 
 // ExecuteEvalScript handles evalScript step.
 func (se *ScriptEngine) ExecuteEvalScript(step *flow.EvalScriptStep) *core.CommandResult {
+	defer se.withStepTimeout(step.TimeoutMs)()
+
 	script := extractJS(step.Script)
 	if err := se.js.RunScript(script); err != nil {
 		return &core.CommandResult{
@@ -327,6 +505,8 @@ func (se *ScriptEngine) expandDollarVars(text string) string {
 
 // ExecuteAssertTrue handles assertTrue step.
 func (se *ScriptEngine) ExecuteAssertTrue(step *flow.AssertTrueStep) *core.CommandResult {
+	defer se.withStepTimeout(step.TimeoutMs)()
+
 	result, err := se.EvalCondition(step.Script)
 	if err != nil {
 		return &core.CommandResult{
@@ -350,6 +530,32 @@ func (se *ScriptEngine) ExecuteAssertTrue(step *flow.AssertTrueStep) *core.Comma
 	}
 }
 
+// ExecuteInputRandom types a value of the step's type from the seeded
+// generator, so a rerun with the same seed types the same text.
+func (se *ScriptEngine) ExecuteInputRandom(step *flow.InputRandomStep, driver core.Driver) *core.CommandResult {
+	text, err := se.random.Generate(step.DataType, step.Length, step.Locale)
+	if err != nil {
+		return &core.CommandResult{
+			Success: false,
+			Error:   err,
+			Message: fmt.Sprintf("inputRandom: %v", err),
+		}
+	}
+
+	input := &flow.InputTextStep{BaseStep: step.BaseStep, Text: text}
+	input.StepType = flow.StepInputText
+	result := driver.Execute(input)
+	if result.Success {
+		dataType := strings.ToUpper(step.DataType)
+		if dataType == "" {
+			dataType = "TEXT"
+		}
+		result.Message = fmt.Sprintf("Entered random %s: %s", dataType, text)
+		result.Data = text
+	}
+	return result
+}
+
 // ExecuteAssertCondition handles assertCondition step.
 func (se *ScriptEngine) ExecuteAssertCondition(ctx context.Context, step *flow.AssertConditionStep, driver core.Driver) *core.CommandResult {
 	cond := step.Condition
@@ -419,6 +625,14 @@ func (se *ScriptEngine) ExecuteAssertCondition(ctx context.Context, step *flow.A
 
 // CheckCondition evaluates a flow.Condition and returns true if met.
 func (se *ScriptEngine) CheckCondition(ctx context.Context, cond flow.Condition, driver core.Driver) bool {
+	// Check platform
+	if cond.Platform != "" {
+		info := driver.GetPlatformInfo()
+		if info == nil || !strings.EqualFold(info.Platform, cond.Platform) {
+			return false
+		}
+	}
+
 	// Check visible
 	if cond.Visible != nil {
 		visibleStep := &flow.AssertVisibleStep{Selector: *cond.Visible}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/devicelab-dev/maestro-runner/pkg/core"
 	"github.com/devicelab-dev/maestro-runner/pkg/flow"
@@ -399,6 +400,66 @@ func TestScriptEngine_ExecuteRunScript_File(t *testing.T) {
 	}
 }
 
+func TestScriptEngine_ExecuteRunScript_Require(t *testing.T) {
+	se := NewScriptEngine()
+	defer se.Close()
+
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"scripts/login.js":    "var auth = require('./lib/auth');\noutput.token = auth.token(USER) + require('config').env;",
+		"scripts/lib/auth.js": "exports.token = function(user) { return 'token-' + user; };",
+		"shared/config.js":    "module.exports = {env: '@staging'};",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to create test script: %v", err)
+		}
+	}
+
+	se.SetFlowDir(tmpDir)
+	se.SetModulePaths([]string{filepath.Join(tmpDir, "shared")})
+	se.SetVariable("USER", "alice")
+
+	result := se.ExecuteRunScript(&flow.RunScriptStep{Script: "scripts/login.js"})
+	if !result.Success {
+		t.Fatalf("ExecuteRunScript() success = false, error = %v", result.Error)
+	}
+	if got := se.GetVariable("token"); got != "token-alice@staging" {
+		t.Errorf("token = %q, want %q", got, "token-alice@staging")
+	}
+}
+
+func TestScriptEngine_StepTimeout(t *testing.T) {
+	se := NewScriptEngine()
+	defer se.Close()
+	se.SetTimeout(5 * time.Second)
+
+	step := &flow.EvalScriptStep{
+		BaseStep: flow.BaseStep{StepType: flow.StepEvalScript, TimeoutMs: 50},
+		Script:   "${while (true) {}}",
+	}
+	start := time.Now()
+	result := se.ExecuteEvalScript(step)
+	if result.Success {
+		t.Fatal("expected the script to time out")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("step took %v, want its 50ms timeout", elapsed)
+	}
+	if errInfo := commandResultToError(result); errInfo.Type != "timeout" {
+		t.Errorf("error type = %q, want timeout (%s)", errInfo.Type, errInfo.Message)
+	}
+
+	// The step's timeout doesn't outlive the step
+	if got := se.js.Timeout(); got != 5*time.Second {
+		t.Errorf("timeout after step = %v, want 5s", got)
+	}
+}
+
 func TestScriptEngine_ExecuteRunScript_FileNotFound(t *testing.T) {
 	se := NewScriptEngine()
 	defer se.Close()
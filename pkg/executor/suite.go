@@ -0,0 +1,99 @@
+package executor
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/devicelab-dev/maestro-runner/pkg/logger"
+	"github.com/devicelab-dev/maestro-runner/pkg/report"
+	"github.com/devicelab-dev/maestro-runner/pkg/secrets"
+)
+
+// Suite hook names, as in the workspace config.
+const (
+	hookBeforeSuite = "beforeSuite"
+	hookAfterSuite  = "afterSuite"
+)
+
+// Reasons flows are skipped without running.
+const (
+	skipOrderFailed = "skipped: an earlier flow in executionOrder failed"
+	skipHookFailed  = "skipped: beforeSuite failed"
+)
+
+// DeviceFailure is a device that ran no flows because its beforeSuite flow
+// failed. Its flows run on the other devices, if any.
+type DeviceFailure struct {
+	DeviceID string
+	Name     string
+	Error    string
+}
+
+// runSuiteHook runs a suite hook flow on the runner's device. Hooks are not in
+// the flow list and don't count in the run's results; their detail is written
+// to flows/<id>.json. The output variables of a passing beforeSuite are kept
+// for the runner's flows; a failing one marks the device failed. It returns
+// the hook's failure message, or "" if it passed or there is no hook.
+func (r *Runner) runSuiteHook(ctx context.Context, hook string, f *flow.Flow, id string, indexWriter *report.IndexWriter) string {
+	if f == nil || ctx.Err() != nil {
+		return ""
+	}
+	if r.config.OnSuiteHookStart != nil {
+		r.config.OnSuiteHookStart(hook, filepath.Base(f.SourcePath))
+	}
+
+	cfg := redactCallbacks(r.config)
+	cfg.OnFlowStart = nil // Announced by OnSuiteHookStart instead
+	cfg.OnFlowEnd = nil   // Hooks have no flow index; failures are logged below
+	cfg.StartStep = 0
+
+	device := r.config.DeviceInfo
+	if device == nil {
+		device = &r.config.Device
+	}
+	detail := report.NewFlowDetail(*f, id, device)
+
+	fr := &FlowRunner{
+		ctx:         ctx,
+		flow:        withFreshSteps(*f), // Every device runs the hook; each expands its own copy
+		detail:      &detail,
+		driver:      r.driver,
+		config:      cfg,
+		indexWriter: indexWriter,
+		suiteOutput: r.suiteOutput, // afterSuite sees what beforeSuite set up
+	}
+	result := fr.Run()
+	if result.Status != report.StatusFailed {
+		if hook == hookBeforeSuite {
+			r.suiteOutput = fr.output
+		}
+		return ""
+	}
+
+	msg := secrets.Redact(result.Error)
+	if msg == "" {
+		msg = "flow failed"
+	}
+	logger.Error("%s %s failed on %s: %s", hook, f.SourcePath, device.ID, msg)
+	if hook == hookBeforeSuite {
+		r.setupFailure = &DeviceFailure{DeviceID: device.ID, Name: device.Name, Error: msg}
+	}
+	return msg
+}
+
+// skipFlow marks a flow that will not run as skipped, with the reason.
+func skipFlow(indexWriter *report.IndexWriter, detail *report.FlowDetail, reason string) FlowResult {
+	indexWriter.UpdateFlow(detail.ID, &report.FlowUpdate{
+		Status:   report.StatusSkipped,
+		Error:    &reason,
+		Commands: report.CommandSummary{Total: len(detail.Commands), Skipped: len(detail.Commands)},
+	})
+	return FlowResult{
+		ID:         detail.ID,
+		Name:       detail.Name,
+		Status:     report.StatusSkipped,
+		Error:      reason,
+		StepsTotal: len(detail.Commands),
+	}
+}
@@ -0,0 +1,208 @@
+// Package faker generates random test data (names, emails, phone numbers,
+// addresses, ...) from a seed, so the values of a run can be reproduced by
+// running again with the same seed.
+package faker
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// generator produces values of one data type for Generate.
+type generator func(f *Faker, length int, locale string) string
+
+// generators maps inputRandom types to their generators.
+var generators = map[string]generator{
+	"TEXT":         func(f *Faker, n int, _ string) string { return f.Text(n) },
+	"NUMBER":       func(f *Faker, n int, _ string) string { return f.Number(n) },
+	"EMAIL":        func(f *Faker, _ int, l string) string { return f.Email(l) },
+	"PERSON_NAME":  func(f *Faker, _ int, l string) string { return f.PersonName(l) },
+	"FIRST_NAME":   func(f *Faker, _ int, l string) string { return f.FirstName(l) },
+	"LAST_NAME":    func(f *Faker, _ int, l string) string { return f.LastName(l) },
+	"PHONE_NUMBER": func(f *Faker, _ int, l string) string { return f.PhoneNumber(l) },
+	"ADDRESS":      func(f *Faker, _ int, l string) string { return f.Address(l) },
+	"CITY":         func(f *Faker, _ int, l string) string { return f.City(l) },
+	"UUID":         func(f *Faker, _ int, _ string) string { return f.UUID() },
+	"DATE":         func(f *Faker, _ int, _ string) string { return f.Date() },
+	"CREDIT_CARD":  func(f *Faker, _ int, _ string) string { return f.CreditCard() },
+}
+
+// DefaultLength is the length of TEXT and NUMBER values when none is given.
+const DefaultLength = 10
+
+// Faker generates random data from a seeded source. It is not safe for
+// concurrent use; give each flow its own.
+type Faker struct {
+	seed int64
+	rng  *rand.Rand
+}
+
+// New returns a Faker seeded with seed.
+func New(seed int64) *Faker {
+	return &Faker{seed: seed, rng: rand.New(rand.NewSource(seed))} //#nosec G404 -- test data, reproducible by design
+}
+
+// NewSeed returns a seed for a run that wasn't given one.
+func NewSeed() int64 {
+	return time.Now().UnixNano()
+}
+
+// Derive returns a seed for one part of a run, such as a flow, so its values
+// don't depend on which other parts ran before it.
+func Derive(seed int64, key string) int64 {
+	// FNV-1a over key, mixed into the run seed
+	h := uint64(14695981039346656037)
+	for i := 0; i < len(key); i++ {
+		h ^= uint64(key[i])
+		h *= 1099511628211
+	}
+	return seed ^ int64(h)
+}
+
+// Seed returns the seed the Faker was created with.
+func (f *Faker) Seed() int64 { return f.seed }
+
+// Types returns the data types Generate accepts, sorted.
+func Types() []string {
+	types := make([]string, 0, len(generators))
+	for t := range generators {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Generate returns a value of dataType (case-insensitive, empty = TEXT).
+// length applies to TEXT and NUMBER (0 = DefaultLength); locale to names,
+// emails, phone numbers and addresses.
+func (f *Faker) Generate(dataType string, length int, locale string) (string, error) {
+	if dataType == "" {
+		dataType = "TEXT"
+	}
+	gen, ok := generators[strings.ToUpper(dataType)]
+	if !ok {
+		return "", fmt.Errorf("unknown random data type %q (want one of %s)", dataType, strings.Join(Types(), ", "))
+	}
+	if length <= 0 {
+		length = DefaultLength
+	}
+	return gen(f, length, locale), nil
+}
+
+// Text returns length random letters and digits.
+func (f *Faker) Text(length int) string {
+	return f.chars("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789", length)
+}
+
+// Number returns length random digits.
+func (f *Faker) Number(length int) string {
+	return f.chars("0123456789", length)
+}
+
+// FirstName returns a first name common in locale.
+func (f *Faker) FirstName(locale string) string {
+	return f.pick(lookup(locale).firstNames)
+}
+
+// LastName returns a last name common in locale.
+func (f *Faker) LastName(locale string) string {
+	return f.pick(lookup(locale).lastNames)
+}
+
+// PersonName returns a first and last name.
+func (f *Faker) PersonName(locale string) string {
+	return f.FirstName(locale) + " " + f.LastName(locale)
+}
+
+// Email returns an address at a reserved example domain (RFC 2606), built
+// from an ASCII name.
+func (f *Faker) Email(locale string) string {
+	first, last := asciiLower(f.FirstName(locale)), asciiLower(f.LastName(locale))
+	domain := f.pick([]string{"example.com", "example.org", "example.net"})
+	return fmt.Sprintf("%s.%s%s@%s", first, last, f.Number(2), domain)
+}
+
+// PhoneNumber returns a number in locale's format, in a range reserved for
+// fiction where the locale has one.
+func (f *Faker) PhoneNumber(locale string) string {
+	return f.pattern(f.pick(lookup(locale).phoneFormats))
+}
+
+// City returns a city in locale's country.
+func (f *Faker) City(locale string) string {
+	return f.pick(lookup(locale).cities)
+}
+
+// Address returns a street address with city, in locale's format.
+func (f *Faker) Address(locale string) string {
+	l := lookup(locale)
+	r := strings.NewReplacer(
+		"{street}", f.pick(l.streets),
+		"{number}", fmt.Sprint(1+f.rng.Intn(199)),
+		"{postcode}", f.pattern(l.postcode),
+		"{city}", f.pick(l.cities),
+	)
+	return r.Replace(l.addressFormat)
+}
+
+// UUID returns a random (version 4) UUID.
+func (f *Faker) UUID() string {
+	var b [16]byte
+	f.rng.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40 // Version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// Date returns a date between 1970 and 2029 as YYYY-MM-DD.
+func (f *Faker) Date() string {
+	start := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	days := f.rng.Intn(int(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC).Sub(start).Hours() / 24))
+	return start.AddDate(0, 0, days).Format("2006-01-02")
+}
+
+// CreditCard returns a card number that payment providers publish for
+// testing. It passes the Luhn check but can't be charged.
+func (f *Faker) CreditCard() string {
+	return f.pick(testCards)
+}
+
+func (f *Faker) pick(items []string) string {
+	return items[f.rng.Intn(len(items))]
+}
+
+func (f *Faker) chars(set string, length int) string {
+	b := make([]byte, length)
+	for i := range b {
+		b[i] = set[f.rng.Intn(len(set))]
+	}
+	return string(b)
+}
+
+// pattern replaces each '#' in p with a random digit.
+func (f *Faker) pattern(p string) string {
+	b := []byte(p)
+	for i, c := range b {
+		if c == '#' {
+			b[i] = byte('0' + f.rng.Intn(10))
+		}
+	}
+	return string(b)
+}
+
+// asciiLower lowercases s and drops what isn't an ASCII letter, after
+// folding common accented letters.
+func asciiLower(s string) string {
+	s = strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss", "é", "e", "è", "e", "ê", "e",
+		"á", "a", "à", "a", "í", "i", "ó", "o", "ú", "u", "ñ", "n", "ç", "c", "ã", "a", "õ", "o").Replace(strings.ToLower(s))
+	var b strings.Builder
+	for _, r := range s {
+		if r >= 'a' && r <= 'z' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
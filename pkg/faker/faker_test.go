@@ -0,0 +1,118 @@
+package faker
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGenerate_SameSeedSameValues(t *testing.T) {
+	generate := func(seed int64) []string {
+		f := New(seed)
+		var values []string
+		for _, typ := range Types() {
+			v, err := f.Generate(typ, 0, "")
+			if err != nil {
+				t.Fatalf("Generate(%s) error = %v", typ, err)
+			}
+			values = append(values, v)
+		}
+		return values
+	}
+
+	a, b := generate(42), generate(42)
+	if strings.Join(a, "|") != strings.Join(b, "|") {
+		t.Errorf("same seed gave different values:\n%q\n%q", a, b)
+	}
+	if c := generate(43); strings.Join(a, "|") == strings.Join(c, "|") {
+		t.Errorf("different seeds gave the same values: %q", a)
+	}
+}
+
+func TestGenerate_Formats(t *testing.T) {
+	tests := []struct {
+		dataType string
+		length   int
+		locale   string
+		pattern  string
+	}{
+		{"TEXT", 0, "", `^[a-zA-Z0-9]{10}$`},
+		{"text", 4, "", `^[a-zA-Z0-9]{4}$`},
+		{"", 6, "", `^[a-zA-Z0-9]{6}$`},
+		{"NUMBER", 5, "", `^[0-9]{5}$`},
+		{"EMAIL", 0, "de_DE", `^[a-z]+\.[a-z]+[0-9]{2}@example\.(com|org|net)$`},
+		{"PERSON_NAME", 0, "", `^\S+ \S+$`},
+		{"PHONE_NUMBER", 0, "en_US", `555-01[0-9]{2}$`},
+		{"PHONE_NUMBER", 0, "en-GB", `7700 900[0-9]{3}$`},
+		{"ADDRESS", 0, "de", `^\S+ [0-9]+, [0-9]{5} \S+$`},
+		{"UUID", 0, "", `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`},
+		{"DATE", 0, "", `^(19[7-9][0-9]|20[0-2][0-9])-[01][0-9]-[0-3][0-9]$`},
+	}
+
+	f := New(1)
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			v, err := f.Generate(tt.dataType, tt.length, tt.locale)
+			if err != nil {
+				t.Fatalf("Generate(%q) error = %v", tt.dataType, err)
+			}
+			if !regexp.MustCompile(tt.pattern).MatchString(v) {
+				t.Errorf("Generate(%q, %d, %q) = %q, want match for %s", tt.dataType, tt.length, tt.locale, v, tt.pattern)
+				break
+			}
+		}
+	}
+}
+
+func TestGenerate_UnknownType(t *testing.T) {
+	_, err := New(1).Generate("SHOE_SIZE", 0, "")
+	if err == nil || !strings.Contains(err.Error(), "unknown random data type") || !strings.Contains(err.Error(), "UUID") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestCreditCard_PassesLuhn(t *testing.T) {
+	for _, number := range testCards {
+		sum := 0
+		for i := range number {
+			d := int(number[len(number)-1-i] - '0')
+			if i%2 == 1 {
+				if d *= 2; d > 9 {
+					d -= 9
+				}
+			}
+			sum += d
+		}
+		if sum%10 != 0 {
+			t.Errorf("%s fails the Luhn check", number)
+		}
+	}
+}
+
+func TestLocaleNames(t *testing.T) {
+	f := New(7)
+	for i := 0; i < 20; i++ {
+		name := f.LastName("de_DE")
+		found := false
+		for _, n := range locales["de_DE"].lastNames {
+			found = found || n == name
+		}
+		if !found {
+			t.Fatalf("LastName(de_DE) = %q, not a de_DE name", name)
+		}
+	}
+
+	// Unknown locales fall back to the default
+	if lookup("xx_YY") != locales[DefaultLocale] || lookup("en") != locales["en_US"] {
+		t.Error("lookup fallback")
+	}
+}
+
+func TestDerive(t *testing.T) {
+	if Derive(1, "login.yaml") == Derive(1, "checkout.yaml") {
+		t.Error("different keys derived the same seed")
+	}
+	if Derive(1, "login.yaml") != Derive(1, "login.yaml") {
+		t.Error("same key derived different seeds")
+	}
+}
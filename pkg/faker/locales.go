@@ -0,0 +1,129 @@
+package faker
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultLocale is used when no locale is given or it isn't known.
+const DefaultLocale = "en_US"
+
+// localeData is the data and formats of one locale.
+type localeData struct {
+	firstNames    []string
+	lastNames     []string
+	cities        []string
+	streets       []string
+	phoneFormats  []string // '#' is a random digit
+	postcode      string   // '#' is a random digit
+	addressFormat string   // {street}, {number}, {postcode}, {city}
+}
+
+var locales = map[string]*localeData{
+	"en_US": {
+		firstNames:    []string{"James", "Mary", "John", "Patricia", "Robert", "Jennifer", "Michael", "Linda", "David", "Emily", "William", "Olivia"},
+		lastNames:     []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez", "Wilson", "Taylor"},
+		cities:        []string{"Springfield", "Portland", "Austin", "Denver", "Madison", "Columbus", "Raleigh", "Boise"},
+		streets:       []string{"Main Street", "Oak Avenue", "Maple Drive", "Cedar Lane", "Park Road", "Elm Street", "Pine Court"},
+		phoneFormats:  []string{"+1 ###-555-01##", "(###) 555-01##"}, // 555-0100..0199 is reserved for fiction
+		postcode:      "#####",
+		addressFormat: "{number} {street}, {city} {postcode}",
+	},
+	"en_GB": {
+		firstNames:    []string{"Oliver", "Amelia", "George", "Isla", "Harry", "Ava", "Jack", "Emily", "Charlie", "Sophie"},
+		lastNames:     []string{"Smith", "Jones", "Taylor", "Brown", "Williams", "Wilson", "Evans", "Thomas", "Roberts", "Walker"},
+		cities:        []string{"London", "Manchester", "Bristol", "Leeds", "York", "Cardiff", "Glasgow", "Brighton"},
+		streets:       []string{"High Street", "Station Road", "Church Lane", "Victoria Road", "Park Avenue", "Mill Lane"},
+		phoneFormats:  []string{"+44 7700 900###", "07700 900###"}, // Ofcom drama range
+		postcode:      "SW# #AB",
+		addressFormat: "{number} {street}, {city} {postcode}",
+	},
+	"de_DE": {
+		firstNames:    []string{"Lukas", "Anna", "Leon", "Marie", "Finn", "Sophie", "Jonas", "Lena", "Paul", "Hannah"},
+		lastNames:     []string{"Müller", "Schmidt", "Schneider", "Fischer", "Weber", "Meyer", "Wagner", "Becker", "Schulz", "Hoffmann"},
+		cities:        []string{"Berlin", "Hamburg", "München", "Köln", "Frankfurt", "Stuttgart", "Leipzig", "Dresden"},
+		streets:       []string{"Hauptstraße", "Schulstraße", "Gartenstraße", "Bahnhofstraße", "Dorfstraße", "Bergstraße"},
+		phoneFormats:  []string{"+49 30 ########", "+49 151 ########"},
+		postcode:      "#####",
+		addressFormat: "{street} {number}, {postcode} {city}",
+	},
+	"fr_FR": {
+		firstNames:    []string{"Gabriel", "Louise", "Léo", "Emma", "Raphaël", "Jade", "Louis", "Alice", "Hugo", "Chloé"},
+		lastNames:     []string{"Martin", "Bernard", "Dubois", "Thomas", "Robert", "Richard", "Petit", "Durand", "Leroy", "Moreau"},
+		cities:        []string{"Paris", "Lyon", "Marseille", "Toulouse", "Nantes", "Bordeaux", "Lille", "Nice"},
+		streets:       []string{"rue de la Paix", "avenue Victor Hugo", "rue du Moulin", "place de la Mairie", "rue de l'Église"},
+		phoneFormats:  []string{"+33 6 ## ## ## ##", "01 ## ## ## ##"},
+		postcode:      "#####",
+		addressFormat: "{number} {street}, {postcode} {city}",
+	},
+	"es_ES": {
+		firstNames:    []string{"Hugo", "Lucía", "Martín", "Sofía", "Pablo", "María", "Daniel", "Paula", "Alejandro", "Carmen"},
+		lastNames:     []string{"García", "Fernández", "González", "Rodríguez", "López", "Martínez", "Sánchez", "Pérez", "Gómez", "Ruiz"},
+		cities:        []string{"Madrid", "Barcelona", "Valencia", "Sevilla", "Zaragoza", "Málaga", "Bilbao", "Granada"},
+		streets:       []string{"Calle Mayor", "Calle Real", "Avenida de la Constitución", "Calle del Sol", "Plaza de España"},
+		phoneFormats:  []string{"+34 6## ### ###", "+34 91# ### ###"},
+		postcode:      "#####",
+		addressFormat: "{street} {number}, {postcode} {city}",
+	},
+	"it_IT": {
+		firstNames:    []string{"Leonardo", "Sofia", "Francesco", "Giulia", "Alessandro", "Aurora", "Lorenzo", "Alice", "Mattia", "Ginevra"},
+		lastNames:     []string{"Rossi", "Russo", "Ferrari", "Esposito", "Bianchi", "Romano", "Colombo", "Ricci", "Marino", "Greco"},
+		cities:        []string{"Roma", "Milano", "Napoli", "Torino", "Bologna", "Firenze", "Genova", "Verona"},
+		streets:       []string{"Via Roma", "Via Garibaldi", "Corso Italia", "Via Dante", "Piazza Mazzini", "Via Verdi"},
+		phoneFormats:  []string{"+39 3## ### ####", "+39 06 #### ####"},
+		postcode:      "#####",
+		addressFormat: "{street} {number}, {postcode} {city}",
+	},
+	"pt_BR": {
+		firstNames:    []string{"Miguel", "Helena", "Arthur", "Alice", "Gael", "Laura", "Heitor", "Maria", "Theo", "Valentina"},
+		lastNames:     []string{"Silva", "Santos", "Oliveira", "Souza", "Rodrigues", "Ferreira", "Alves", "Pereira", "Lima", "Gomes"},
+		cities:        []string{"São Paulo", "Rio de Janeiro", "Salvador", "Fortaleza", "Curitiba", "Recife", "Manaus", "Belém"},
+		streets:       []string{"Rua das Flores", "Avenida Brasil", "Rua São João", "Rua XV de Novembro", "Avenida Paulista"},
+		phoneFormats:  []string{"+55 11 9####-####", "+55 21 9####-####"},
+		postcode:      "#####-###",
+		addressFormat: "{street}, {number}, {city}, {postcode}",
+	},
+}
+
+// testCards are card numbers published by payment providers for testing.
+var testCards = []string{
+	"4242424242424242", // Visa
+	"4000056655665556", // Visa (debit)
+	"5555555555554444", // Mastercard
+	"2223003122003222", // Mastercard (2-series)
+	"5200828282828210", // Mastercard (debit)
+	"378282246310005",  // American Express
+	"6011111111111117", // Discover
+	"3056930009020004", // Diners Club
+	"3566002020360505", // JCB
+}
+
+// Locales returns the supported locales, sorted.
+func Locales() []string {
+	names := make([]string, 0, len(locales))
+	for name := range locales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookup returns the data for locale: an exact match such as "de_DE" or
+// "de-DE", else a locale of the language ("de"; "en" is en_US), else the
+// default.
+func lookup(locale string) *localeData {
+	locale = strings.ReplaceAll(locale, "-", "_")
+	for name, l := range locales {
+		if strings.EqualFold(name, locale) {
+			return l
+		}
+	}
+	if lang, _, _ := strings.Cut(locale, "_"); lang != "" {
+		for _, name := range append([]string{DefaultLocale}, Locales()...) {
+			if strings.EqualFold(strings.SplitN(name, "_", 2)[0], lang) {
+				return locales[name]
+			}
+		}
+	}
+	return locales[DefaultLocale]
+}
@@ -0,0 +1,36 @@
+package flow
+
+import "testing"
+
+func TestClone(t *testing.T) {
+	orig := &ForEachStep{
+		BaseStep: BaseStep{StepType: StepForEach, When: &Condition{Platform: "android"}},
+		Items:    []any{"a", "b"},
+		Steps: []Step{
+			&TapOnStep{BaseStep: BaseStep{StepType: StepTapOn}, Selector: Selector{Text: "${item}"}},
+			&RunScriptStep{BaseStep: BaseStep{StepType: StepRunScript}, Env: map[string]string{"X": "1"}},
+		},
+	}
+
+	c := Clone(orig).(*ForEachStep)
+	c.When.Platform = "ios"
+	c.Items.([]any)[0] = "z"
+	c.Steps[0].(*TapOnStep).Selector.Text = "a"
+	c.Steps[1].(*RunScriptStep).Env["X"] = "2"
+
+	if orig.When.Platform != "android" {
+		t.Error("clone shares the when condition")
+	}
+	if orig.Items.([]any)[0] != "a" {
+		t.Error("clone shares items")
+	}
+	if got := orig.Steps[0].(*TapOnStep).Selector.Text; got != "${item}" {
+		t.Errorf("clone shares nested steps: text = %q", got)
+	}
+	if orig.Steps[1].(*RunScriptStep).Env["X"] != "1" {
+		t.Error("clone shares env maps")
+	}
+	if Clone(nil) != nil || CloneSteps(nil) != nil {
+		t.Error("nil should clone to nil")
+	}
+}
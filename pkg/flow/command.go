@@ -0,0 +1,277 @@
+package flow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CommandsDir is the directory custom commands are declared in. A custom
+// command is a flow file named after the command, whose config declares
+// its parameters:
+//
+//	# commands/login.yaml
+//	params:
+//	  user: string           # no default: required
+//	  pass:
+//	    type: string
+//	    default: secret
+//	---
+//	- tapOn: Username
+//	- inputText: ${user}
+//
+// Flows then use it like a built-in step, "- login: {user: alice}", and
+// the command's steps see the arguments as env variables.
+const CommandsDir = "commands"
+
+// Parameter types a custom command can declare.
+const (
+	ParamString  = "string"
+	ParamNumber  = "number"
+	ParamBoolean = "boolean"
+)
+
+// Param is a custom command parameter. It is written as a mapping or, for a
+// required parameter, as just its type.
+type Param struct {
+	Type        string  `yaml:"type"`    // string (default), number or boolean
+	Default     *string `yaml:"default"` // Parameters without a default are required
+	Description string  `yaml:"description"`
+}
+
+// Required reports whether the parameter has no default.
+func (p Param) Required() bool { return p.Default == nil }
+
+// UnmarshalYAML accepts a type name or a mapping.
+func (p *Param) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		p.Type = node.Value
+	} else {
+		type plain Param
+		if err := node.Decode((*plain)(p)); err != nil {
+			return err
+		}
+	}
+	if p.Type == "" {
+		p.Type = ParamString
+	}
+	switch p.Type {
+	case ParamString, ParamNumber, ParamBoolean:
+	default:
+		return fmt.Errorf("line %d: unknown parameter type %q (use string, number or boolean)", node.Line, p.Type)
+	}
+	if p.Default != nil {
+		if err := p.check(*p.Default); err != nil {
+			return fmt.Errorf("line %d: default %s", node.Line, err)
+		}
+	}
+	return nil
+}
+
+// check reports whether value suits the parameter's type. Values with
+// ${...} expressions are only known at run time and always pass.
+func (p Param) check(value string) error {
+	if strings.Contains(value, "${") {
+		return nil
+	}
+	switch p.Type {
+	case ParamNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("must be a number, got %q", value)
+		}
+	case ParamBoolean:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("must be true or false, got %q", value)
+		}
+	}
+	return nil
+}
+
+// reservedParams are option keys every step accepts, so they can't be
+// parameter names.
+var reservedParams = []string{"label", "optional", "when", "onFailure"}
+
+var commandNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// FindCommand returns the definition file of the custom command name for a
+// flow at flowPath: commands/<name>.yaml in the flow's directory or the
+// nearest parent that has one, stopping at the workspace root (the first
+// directory with a config.yaml). It returns "" if there is none.
+func FindCommand(name, flowPath string) string {
+	if !commandNameRe.MatchString(name) {
+		return ""
+	}
+	dir, err := filepath.Abs(filepath.Dir(flowPath))
+	if err != nil {
+		return ""
+	}
+	for {
+		for _, ext := range []string{".yaml", ".yml"} {
+			path := filepath.Join(dir, CommandsDir, name+ext)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+		if isWorkspaceRoot(dir) {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+func isWorkspaceRoot(dir string) bool {
+	for _, name := range []string{"config.yaml", "config.yml"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadParams reads the parameters a custom command declares. Only the
+// config header is read, so commands that use other commands (or
+// themselves) don't recurse; circular use is reported by the validator.
+func LoadParams(path string) (map[string]Param, error) {
+	data, err := os.ReadFile(path) //#nosec G304 -- command file found by FindCommand
+	if err != nil {
+		return nil, err
+	}
+	parts, offsets := splitYAMLDocumentsWithOffsets(string(data))
+	if len(parts) < 2 {
+		return nil, nil
+	}
+	var header yaml.Node
+	if err := yaml.Unmarshal([]byte(parts[0]), &header); err != nil {
+		return nil, &ParseError{Path: path, Message: fmt.Sprintf("invalid config: %v", err)}
+	}
+	shiftLines(&header, offsets[0])
+	var config struct {
+		Params map[string]Param `yaml:"params"`
+	}
+	if err := header.Decode(&config); err != nil {
+		return nil, &ParseError{Path: path, Message: fmt.Sprintf("invalid params: %v", err)}
+	}
+	for name := range config.Params {
+		if contains(reservedParams, name) {
+			return nil, &ParseError{Path: path, Message: fmt.Sprintf("%q can't be a parameter name; it is a step option", name)}
+		}
+	}
+	return config.Params, nil
+}
+
+// parseCustomCommand decodes a step that invokes the custom command name.
+// Arguments are checked against the declared parameters and defaults are
+// filled in. A scalar value is the argument of a single-parameter command.
+func parseCustomCommand(name string, valueNode *yaml.Node, sourcePath string) (Step, error) {
+	path := FindCommand(name, sourcePath)
+	if path == "" {
+		return nil, &ParseError{
+			Path:    sourcePath,
+			Line:    valueNode.Line,
+			Message: fmt.Sprintf("unknown step type: %s", name),
+		}
+	}
+	params, err := LoadParams(path)
+	if err != nil {
+		return nil, &ParseError{
+			Path:    sourcePath,
+			Line:    valueNode.Line,
+			Message: fmt.Sprintf("custom command %s: %v", name, err),
+		}
+	}
+
+	s := &CustomCommandStep{
+		BaseStep: BaseStep{StepType: StepType(name)},
+		Name:     name,
+		File:     path,
+		Params:   make(map[string]string, len(params)),
+	}
+	fail := func(node *yaml.Node, format string, args ...interface{}) error {
+		return &ParseError{
+			Path:    sourcePath,
+			Line:    node.Line,
+			Column:  node.Column,
+			Message: name + ": " + fmt.Sprintf(format, args...),
+		}
+	}
+
+	args := make(map[string]*yaml.Node)
+	switch {
+	case isNull(valueNode):
+	case valueNode.Kind == yaml.ScalarNode:
+		if len(params) != 1 {
+			return nil, fail(valueNode, "takes %d parameters; pass them as a mapping", len(params))
+		}
+		for p := range params {
+			args[p] = valueNode
+		}
+	case valueNode.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(valueNode.Content); i += 2 {
+			key, value := valueNode.Content[i], valueNode.Content[i+1]
+			switch key.Value {
+			case "label":
+				s.StepLabel = value.Value
+			case "optional":
+				if err := value.Decode(&s.Optional); err != nil {
+					return nil, fail(value, "optional must be true or false")
+				}
+			case "when":
+				if err := value.Decode(&s.When); err != nil {
+					return nil, fail(value, "when: %v", err)
+				}
+			case "onFailure":
+				// Parsed by parseRecovery
+			default:
+				if _, ok := params[key.Value]; !ok {
+					msg := fmt.Sprintf("unknown parameter %q", key.Value)
+					if sug := suggest(key.Value, paramNames(params)); sug != "" {
+						msg += fmt.Sprintf(" (did you mean %q?)", sug)
+					}
+					return nil, fail(key, "%s", msg)
+				}
+				args[key.Value] = value
+			}
+		}
+	default:
+		return nil, fail(valueNode, "parameters must be a mapping")
+	}
+
+	for _, p := range paramNames(params) {
+		param := params[p]
+		node, ok := args[p]
+		if !ok {
+			if param.Required() {
+				return nil, fail(valueNode, "missing required parameter %q", p)
+			}
+			s.Params[p] = *param.Default
+			continue
+		}
+		if node.Kind != yaml.ScalarNode {
+			return nil, fail(node, "parameter %q must be a %s", p, param.Type)
+		}
+		if err := param.check(node.Value); err != nil {
+			return nil, fail(node, "parameter %q %s", p, err)
+		}
+		s.Params[p] = node.Value
+	}
+	return s, nil
+}
+
+func paramNames(params map[string]Param) []string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,189 @@
+package flow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const loginCommand = `params:
+  user: string
+  pass:
+    type: string
+    default: secret
+  attempts:
+    type: number
+    default: 1
+  remember:
+    type: boolean
+    default: false
+---
+- tapOn: Username
+- inputText: ${user}
+`
+
+func writeCommandFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func parseWithCommands(t *testing.T, steps string) (*Flow, error) {
+	t.Helper()
+	dir := writeCommandFiles(t, map[string]string{
+		"commands/login.yaml":  loginCommand,
+		"commands/logout.yaml": "- tapOn: Logout\n",
+		"commands/search.yaml": "params:\n  query: string\n---\n- inputText: ${query}\n",
+	})
+	return Parse([]byte(steps), filepath.Join(dir, "flow.yaml"))
+}
+
+func TestParse_CustomCommand(t *testing.T) {
+	f, err := parseWithCommands(t, `- login:
+    user: alice
+    remember: true
+    label: Sign in
+- logout
+- search: shoes
+`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(f.Steps) != 3 {
+		t.Fatalf("expected 3 steps, got %d", len(f.Steps))
+	}
+
+	login, ok := f.Steps[0].(*CustomCommandStep)
+	if !ok {
+		t.Fatalf("step 0 is %T, want *CustomCommandStep", f.Steps[0])
+	}
+	if login.Name != "login" || login.Type() != "login" || login.Label() != "Sign in" {
+		t.Errorf("login = %+v", login)
+	}
+	if filepath.Base(login.File) != "login.yaml" {
+		t.Errorf("File = %q", login.File)
+	}
+	want := map[string]string{"user": "alice", "pass": "secret", "attempts": "1", "remember": "true"}
+	for k, v := range want {
+		if login.Params[k] != v {
+			t.Errorf("Params[%s] = %q, want %q", k, login.Params[k], v)
+		}
+	}
+	if got := login.Describe(); got != "login: attempts=1, pass=secret, remember=true, user=alice" {
+		t.Errorf("Describe() = %q", got)
+	}
+	if login.Position().Line != 1 || !strings.HasPrefix(login.Source(), "login:") {
+		t.Errorf("source = %v %q", login.Position(), login.Source())
+	}
+
+	if logout := f.Steps[1].(*CustomCommandStep); len(logout.Params) != 0 {
+		t.Errorf("logout params = %v", logout.Params)
+	}
+	// A single-parameter command takes its argument as a scalar
+	if search := f.Steps[2].(*CustomCommandStep); search.Params["query"] != "shoes" {
+		t.Errorf("search params = %v", search.Params)
+	}
+}
+
+func TestParse_CustomCommandErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps string
+		want  string
+	}{
+		{"missing required", "- login: {pass: x}\n", `missing required parameter "user"`},
+		{"unknown param", "- login: {user: a, pas: x}\n", `unknown parameter "pas" (did you mean "pass"?)`},
+		{"number", "- login: {user: a, attempts: many}\n", `parameter "attempts" must be a number`},
+		{"boolean", "- login: {user: a, remember: yes please}\n", `parameter "remember" must be true or false`},
+		{"not scalar", "- login: {user: [a, b]}\n", `parameter "user" must be a string`},
+		{"scalar for many params", "- login: alice\n", "takes 4 parameters"},
+		{"unknown command", "- logn: {user: a}\n", "unknown step type: logn"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseWithCommands(t, tt.steps)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_CustomCommandExpressionArgs(t *testing.T) {
+	f, err := parseWithCommands(t, "- login: {user: a, attempts: '${RETRIES}'}\n")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := f.Steps[0].(*CustomCommandStep).Params["attempts"]; got != "${RETRIES}" {
+		t.Errorf("attempts = %q", got)
+	}
+}
+
+func TestLoadParams_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    string
+	}{
+		{"bad type", "params:\n  n: integer\n---\n- back\n", `unknown parameter type "integer"`},
+		{"bad default", "params:\n  n: {type: number, default: abc}\n---\n- back\n", "must be a number"},
+		{"reserved", "params:\n  label: string\n---\n- back\n", `"label" can't be a parameter name`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeCommandFiles(t, map[string]string{"cmd.yaml": tt.command})
+			_, err := LoadParams(filepath.Join(dir, "cmd.yaml"))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindCommand(t *testing.T) {
+	dir := writeCommandFiles(t, map[string]string{
+		"config.yaml":                   "flows: ['**']\n",
+		"commands/login.yaml":           "- back\n",
+		"auth/commands/login.yml":       "- back\n",
+		"auth/deep/flow.yaml":           "- back\n",
+		"other/flow.yaml":               "- back\n",
+		"other/commands/nested/x.yaml":  "- back\n",
+		"../outside/commands/root.yaml": "- back\n",
+	})
+
+	// The nearest commands/ directory wins
+	if got := FindCommand("login", filepath.Join(dir, "auth/deep/flow.yaml")); got != filepath.Join(dir, "auth/commands/login.yml") {
+		t.Errorf("auth login = %q", got)
+	}
+	if got := FindCommand("login", filepath.Join(dir, "other/flow.yaml")); got != filepath.Join(dir, "commands/login.yaml") {
+		t.Errorf("other login = %q", got)
+	}
+	// Commands are looked up by plain name only, and not above the workspace root
+	for _, name := range []string{"nested/x", "../login", "root", "missing"} {
+		if got := FindCommand(name, filepath.Join(dir, "other/flow.yaml")); got != "" {
+			t.Errorf("FindCommand(%q) = %q, want none", name, got)
+		}
+	}
+}
+
+func TestCheck_CustomCommand(t *testing.T) {
+	dir := writeCommandFiles(t, map[string]string{"commands/login.yaml": loginCommand})
+	path := filepath.Join(dir, "flow.yaml")
+	problems := Check([]byte("- login: {user: a}\n- logout\n"), path)
+	if len(problems) != 1 || !strings.Contains(problems[0].Message, `unknown step type "logout"`) {
+		t.Errorf("problems = %v", problems)
+	}
+	if problems := Check([]byte(loginCommand), filepath.Join(dir, "commands/login.yaml")); len(problems) != 0 {
+		t.Errorf("command file problems = %v", problems)
+	}
+}
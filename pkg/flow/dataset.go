@@ -0,0 +1,260 @@
+package flow
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Dataset is the rows a data-driven flow runs with. Each row becomes a flow
+// instance whose env holds the row's values. It is written as a file path,
+// a list of rows, or a mapping:
+//
+//	dataset: users.csv
+//
+//	dataset:
+//	  - locale: en-US
+//	    account: free
+//
+//	dataset:
+//	  file: users.json   # or rows: [...]
+//	  key: locale
+type Dataset struct {
+	File string              // CSV or JSON file, relative to the flow file
+	Key  string              // Column that names each instance (default: the first)
+	Rows []map[string]string // Inline rows
+
+	columns []string // Column order of inline rows
+}
+
+// UnmarshalYAML accepts a file path, a list of rows or a mapping.
+func (d *Dataset) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		d.File = node.Value
+	case yaml.SequenceNode:
+		columns, rows, err := decodeRows(node)
+		if err != nil {
+			return err
+		}
+		d.columns, d.Rows = columns, rows
+	case yaml.MappingNode:
+		var raw struct {
+			File string    `yaml:"file"`
+			Key  string    `yaml:"key"`
+			Rows yaml.Node `yaml:"rows"`
+		}
+		if err := node.Decode(&raw); err != nil {
+			return err
+		}
+		d.File, d.Key = raw.File, raw.Key
+		if raw.Rows.Kind != 0 {
+			columns, rows, err := decodeRows(&raw.Rows)
+			if err != nil {
+				return err
+			}
+			d.columns, d.Rows = columns, rows
+		}
+		if (d.File == "") == (d.Rows == nil) {
+			return fmt.Errorf("line %d: dataset needs either file or rows", node.Line)
+		}
+	default:
+		return fmt.Errorf("line %d: dataset must be a file path, a list of rows or a mapping", node.Line)
+	}
+	return nil
+}
+
+// Load returns the dataset's columns and rows. A dataset file is resolved
+// relative to flowPath's directory.
+func (d *Dataset) Load(flowPath string) ([]string, []map[string]string, error) {
+	columns, rows := d.columns, d.Rows
+	if d.File != "" {
+		path := d.Path(flowPath)
+		var err error
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".csv":
+			columns, rows, err = loadCSV(path)
+		case ".json", ".yaml", ".yml":
+			columns, rows, err = loadRows(path)
+		default:
+			return nil, nil, fmt.Errorf("dataset %s: unsupported format (use .csv or .json)", d.File)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("dataset %s: %w", d.File, err)
+		}
+	}
+	if len(rows) == 0 {
+		return nil, nil, fmt.Errorf("dataset has no rows")
+	}
+	if d.Key != "" && !contains(columns, d.Key) {
+		return nil, nil, fmt.Errorf("dataset key %q is not a column (columns: %s)", d.Key, strings.Join(columns, ", "))
+	}
+	return columns, rows, nil
+}
+
+// Path returns the dataset file's path, or "" for inline rows.
+func (d *Dataset) Path(flowPath string) string {
+	if d.File == "" || filepath.IsAbs(d.File) {
+		return d.File
+	}
+	return filepath.Join(filepath.Dir(flowPath), d.File)
+}
+
+// rowKey names a row by its key column, or its first column.
+func (d *Dataset) rowKey(columns []string, row map[string]string) string {
+	if d.Key != "" {
+		return row[d.Key]
+	}
+	return row[columns[0]]
+}
+
+func loadCSV(path string) ([]string, []map[string]string, error) {
+	file, err := os.Open(path) //#nosec G304 -- dataset referenced by the flow
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil, nil
+	}
+	header := records[0]
+	for i, name := range header {
+		header[i] = strings.TrimSpace(name)
+		if header[i] == "" {
+			return nil, nil, fmt.Errorf("column %d has no name", i+1)
+		}
+	}
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			row[name] = strings.TrimSpace(record[i])
+		}
+		rows = append(rows, row)
+	}
+	return header, rows, nil
+}
+
+// loadRows reads a JSON (or YAML) list of objects. It decodes through
+// yaml.Node to keep the column order.
+func loadRows(path string) ([]string, []map[string]string, error) {
+	data, err := os.ReadFile(path) //#nosec G304 -- dataset referenced by the flow
+	if err != nil {
+		return nil, nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil, nil
+	}
+	return decodeRows(doc.Content[0])
+}
+
+// decodeRows decodes a sequence of flat mappings.
+func decodeRows(node *yaml.Node) ([]string, []map[string]string, error) {
+	if node.Kind != yaml.SequenceNode {
+		return nil, nil, fmt.Errorf("line %d: expected a list of rows", node.Line)
+	}
+	var columns []string
+	rows := make([]map[string]string, 0, len(node.Content))
+	for _, item := range node.Content {
+		if item.Kind != yaml.MappingNode {
+			return nil, nil, fmt.Errorf("line %d: dataset row must be a mapping of column to value", item.Line)
+		}
+		row := make(map[string]string, len(item.Content)/2)
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			key, value := item.Content[i], item.Content[i+1]
+			if value.Kind != yaml.ScalarNode {
+				return nil, nil, fmt.Errorf("line %d: dataset value for %q must be a string, number or boolean", value.Line, key.Value)
+			}
+			if !contains(columns, key.Value) {
+				columns = append(columns, key.Value)
+			}
+			if !isNull(value) {
+				row[key.Value] = value.Value
+			} else {
+				row[key.Value] = ""
+			}
+		}
+		rows = append(rows, row)
+	}
+	return columns, rows, nil
+}
+
+// Expand returns one flow per row of f's dataset, each parsed afresh from
+// f's source (steps are expanded in place when they run, so instances must
+// not share them). Row values are added to the instance's env, overriding
+// the flow's own env, and the instance is named "<name> [<row key>]", with
+// " #n" added when rows share a key.
+// Flows without a dataset are returned as is.
+func Expand(f *Flow) ([]*Flow, error) {
+	ds := f.Config.Dataset
+	if ds == nil {
+		return []*Flow{f}, nil
+	}
+	if f.source == nil {
+		return nil, fmt.Errorf("%s: dataset flow has no source to expand", f.SourcePath)
+	}
+	columns, rows, err := ds.Load(f.SourcePath)
+	if err != nil {
+		return nil, &ParseError{Path: f.SourcePath, Message: err.Error()}
+	}
+
+	name := f.Config.Name
+	if name == "" {
+		base := filepath.Base(f.SourcePath)
+		name = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+
+	flows := make([]*Flow, 0, len(rows))
+	seen := make(map[string]int, len(rows))
+	for _, row := range rows {
+		inst, err := Parse(f.source, f.SourcePath)
+		if err != nil {
+			return nil, err
+		}
+		env := make(map[string]string, len(f.Config.Env)+len(row))
+		for k, v := range f.Config.Env {
+			env[k] = v
+		}
+		for k, v := range row {
+			env[k] = v
+		}
+		inst.Config.Env = env
+		inst.Config.Dataset = nil
+		key := ds.rowKey(columns, row)
+		if seen[key]++; seen[key] > 1 {
+			key = fmt.Sprintf("%s #%d", key, seen[key])
+		}
+		inst.DataKey = key
+		inst.Config.Name = fmt.Sprintf("%s [%s]", name, inst.DataKey)
+		flows = append(flows, inst)
+	}
+	return flows, nil
+}
+
+// ExpandAll expands every flow's dataset, keeping the flows' order.
+func ExpandAll(flows []*Flow) ([]*Flow, error) {
+	var expanded []*Flow
+	for _, f := range flows {
+		instances, err := Expand(f)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, instances...)
+	}
+	return expanded, nil
+}
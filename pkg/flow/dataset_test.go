@@ -0,0 +1,176 @@
+package flow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeDatasetFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestExpand_CSV(t *testing.T) {
+	dir := writeDatasetFiles(t, map[string]string{
+		"login.yaml": "appId: com.example\nname: Login\nenv:\n  PASSWORD: secret\n  LOCALE: xx\ndataset: users.csv\n---\n- inputText: ${USER}\n",
+		"users.csv":  "LOCALE,USER\nen-US, alice\nde-DE,bob\nen-US,carol\n",
+	})
+
+	f, err := ParseFile(filepath.Join(dir, "login.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	flows, err := Expand(f)
+	if err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	if len(flows) != 3 {
+		t.Fatalf("expected 3 instances, got %d", len(flows))
+	}
+
+	wantNames := []string{"Login [en-US]", "Login [de-DE]", "Login [en-US #2]"}
+	wantUsers := []string{"alice", "bob", "carol"}
+	for i, inst := range flows {
+		if inst.Config.Name != wantNames[i] {
+			t.Errorf("instance %d name = %q, want %q", i, inst.Config.Name, wantNames[i])
+		}
+		if inst.Config.Env["USER"] != wantUsers[i] || inst.Config.Env["PASSWORD"] != "secret" {
+			t.Errorf("instance %d env = %v", i, inst.Config.Env)
+		}
+		if inst.Config.Dataset != nil {
+			t.Errorf("instance %d still has a dataset", i)
+		}
+	}
+	// Row values override the flow's env
+	if flows[1].Config.Env["LOCALE"] != "de-DE" {
+		t.Errorf("LOCALE = %q, want de-DE", flows[1].Config.Env["LOCALE"])
+	}
+	if flows[2].DataKey != "en-US #2" {
+		t.Errorf("DataKey = %q", flows[2].DataKey)
+	}
+	// Instances must not share steps: running one expands its steps in place
+	if flows[0].Steps[0] == flows[1].Steps[0] {
+		t.Error("instances share step values")
+	}
+}
+
+func TestExpand_InlineAndJSON(t *testing.T) {
+	dir := writeDatasetFiles(t, map[string]string{
+		"inline.yaml": "appId: x\ndataset:\n  key: account\n  rows:\n    - LIMIT: 1\n      account: free\n    - LIMIT: 10\n      account: pro\n---\n- back\n",
+		"list.yaml":   "appId: x\ndataset:\n  - id: first\n  - id: second\n---\n- back\n",
+		"json.yaml":   "appId: x\ndataset: rows.json\n---\n- back\n",
+		"rows.json":   `[{"user": "a", "admin": true}, {"user": "b", "admin": null}]`,
+	})
+
+	tests := []struct {
+		file  string
+		names []string
+		env   map[string]string // Env of the last instance
+	}{
+		{"inline.yaml", []string{"inline [free]", "inline [pro]"}, map[string]string{"LIMIT": "10", "account": "pro"}},
+		{"list.yaml", []string{"list [first]", "list [second]"}, map[string]string{"id": "second"}},
+		{"json.yaml", []string{"json [a]", "json [b]"}, map[string]string{"user": "b", "admin": ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			f, err := ParseFile(filepath.Join(dir, tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			flows, err := Expand(f)
+			if err != nil {
+				t.Fatalf("Expand() error = %v", err)
+			}
+			if len(flows) != len(tt.names) {
+				t.Fatalf("got %d instances, want %d", len(flows), len(tt.names))
+			}
+			for i, name := range tt.names {
+				if flows[i].Config.Name != name {
+					t.Errorf("instance %d name = %q, want %q", i, flows[i].Config.Name, name)
+				}
+			}
+			last := flows[len(flows)-1].Config.Env
+			for k, v := range tt.env {
+				if last[k] != v {
+					t.Errorf("env[%s] = %q, want %q", k, last[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestExpand_NoDataset(t *testing.T) {
+	f, err := Parse([]byte("- back\n"), "flow.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	flows, err := Expand(f)
+	if err != nil || len(flows) != 1 || flows[0] != f {
+		t.Errorf("Expand() = %v, %v; want the flow itself", flows, err)
+	}
+}
+
+func TestDataset_Errors(t *testing.T) {
+	dir := writeDatasetFiles(t, map[string]string{
+		"empty.csv":  "USER\n",
+		"ragged.csv": "A,B\n1\n",
+		"data.txt":   "x",
+	})
+
+	loadErrors := []struct {
+		config string
+		want   string
+	}{
+		{"dataset: missing.csv", "no such file"},
+		{"dataset: empty.csv", "no rows"},
+		{"dataset: ragged.csv", "wrong number of fields"},
+		{"dataset: data.txt", "unsupported format"},
+		{"dataset:\n  file: empty.csv\n  key: USERS", "no rows"},
+		{"dataset:\n  rows: [{a: 1}]\n  key: b", `key "b" is not a column`},
+	}
+	for _, tt := range loadErrors {
+		f, err := Parse([]byte("appId: x\n"+tt.config+"\n---\n- back\n"), filepath.Join(dir, "flow.yaml"))
+		if err != nil {
+			t.Fatalf("%q: parse error = %v", tt.config, err)
+		}
+		_, err = Expand(f)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: Expand() error = %v, want %q", tt.config, err, tt.want)
+		}
+	}
+
+	parseErrors := []string{
+		"dataset:\n  key: a",
+		"dataset:\n  file: a.csv\n  rows: [{a: 1}]",
+		"dataset:\n  - [1, 2]",
+		"dataset:\n  - a: [1]",
+	}
+	for _, config := range parseErrors {
+		if _, err := Parse([]byte("appId: x\n"+config+"\n---\n- back\n"), "flow.yaml"); err == nil {
+			t.Errorf("%q: expected parse error", config)
+		}
+	}
+}
+
+func TestParseDirectory_ExpandsDatasets(t *testing.T) {
+	dir := writeDatasetFiles(t, map[string]string{
+		"a.yaml": "appId: x\ntags: [smoke]\ndataset:\n  - id: one\n  - id: two\n---\n- back\n",
+		"b.yaml": "appId: x\ntags: [slow]\ndataset:\n  - id: three\n---\n- back\n",
+	})
+
+	flows, err := ParseDirectory(dir, []string{"smoke"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flows) != 2 || flows[0].DataKey != "one" || flows[1].DataKey != "two" {
+		t.Errorf("unexpected flows: %+v", flows)
+	}
+}
@@ -6,18 +6,27 @@ type Flow struct {
 	SourcePath string // Path to the source file
 	Config     Config // Flow configuration (appId, tags, etc.)
 	Steps      []Step // Steps to execute
+	DataKey    string // Dataset row this instance runs with (see Expand)
+
+	source []byte // Original YAML, for expanding datasets
 }
 
 // Config represents flow-level configuration.
 type Config struct {
-	AppID              string            `yaml:"appId"`
-	URL                string            `yaml:"url"` // Web app URL (alternative to appId)
-	Name               string            `yaml:"name"`
-	Tags               []string          `yaml:"tags"`
-	Env                map[string]string `yaml:"env"`
-	Timeout            int               `yaml:"timeout"`            // Flow timeout in ms
-	CommandTimeout     int               `yaml:"commandTimeout"`     // Default timeout for all commands in ms (overrides driver default)
-	WaitForIdleTimeout *int              `yaml:"waitForIdleTimeout"` // Wait for device idle in ms (nil = use global, 0 = disabled)
-	OnFlowStart        []Step            `yaml:"-"`                  // Lifecycle hook: runs before commands
-	OnFlowComplete     []Step            `yaml:"-"`                  // Lifecycle hook: runs after commands
+	AppID              string              `yaml:"appId"`
+	URL                string              `yaml:"url"` // Web app URL (alternative to appId)
+	Name               string              `yaml:"name"`
+	Tags               []string            `yaml:"tags"`
+	Env                map[string]string   `yaml:"env"`
+	Secrets            []string            `yaml:"secrets"`            // Variable names or patterns (e.g. "*_PASSWORD") whose values are redacted from output
+	Dataset            *Dataset            `yaml:"dataset"`            // Rows to run the flow with, one instance each
+	Params             map[string]Param    `yaml:"params"`             // Parameters when used as a custom command
+	Timeout            int                 `yaml:"timeout"`            // Flow timeout in ms
+	CommandTimeout     int                 `yaml:"commandTimeout"`     // Default timeout for all commands in ms (overrides driver default)
+	WaitForIdleTimeout *int                `yaml:"waitForIdleTimeout"` // Wait for device idle in ms (nil = use global, 0 = disabled)
+	ScriptTimeout      int                 `yaml:"scriptTimeout"`      // Time limit for each script in ms, including its timers (0 = use global)
+	Device             *DeviceRequirements `yaml:"device"`             // Devices the flow can run on (nil = any)
+	OnFlowStart        []Step              `yaml:"-"`                  // Lifecycle hook: runs before commands
+	OnFlowComplete     []Step              `yaml:"-"`                  // Lifecycle hook: runs after commands
+	OnFlowFailure      []Step              `yaml:"-"`                  // Lifecycle hook: runs after commands when the flow failed
 }
@@ -0,0 +1,312 @@
+package flow
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// shorthandKeys maps step types to the key their scalar form sets, for steps
+// where "step: value" decodes exactly like "step: {key: value}".
+var shorthandKeys = map[StepType]string{
+	StepTapOn:            "text",
+	StepDoubleTapOn:      "text",
+	StepLongPressOn:      "text",
+	StepCopyTextFrom:     "text",
+	StepAssertVisible:    "text",
+	StepAssertNotVisible: "text",
+	StepSwipe:            "direction",
+	StepScroll:           "direction",
+	StepInputText:        "text",
+	StepInputRandom:      "type",
+	StepSetClipboard:     "text",
+	StepAssertTrue:       "condition",
+	StepAssertWithAI:     "assertion",
+	StepLaunchApp:        "appId",
+	StepStopApp:          "appId",
+	StepKillApp:          "appId",
+	StepClearState:       "appId",
+	StepSetOrientation:   "orientation",
+	StepOpenLink:         "link",
+	StepOpenBrowser:      "url",
+	StepRunFlow:          "file",
+	StepRunScript:        "script",
+	StepEvalScript:       "script",
+	StepTakeScreenshot:   "path",
+	StepStartRecording:   "path",
+	StepStopRecording:    "path",
+	StepPressKey:         "key",
+}
+
+// baseKeys are the BaseStep keys, which Format puts after a step's own
+// options.
+var baseKeys = []string{"label", "optional", "timeout", "when"}
+
+// Format rewrites flow YAML in canonical form:
+//   - config keys, step options and selector keys follow the order of their
+//     struct definitions; label, optional, timeout and when come after a
+//     step's own options and nested steps last; unknown keys keep their order at the end
+//   - steps use the shorthand form when it means the same thing, e.g.
+//     "tapOn: {text: Login}" becomes "tapOn: Login" and "back: {}" becomes
+//     "back"
+//   - quotes are dropped unless the value needs them, flow-style mappings
+//     become block mappings, and indentation is two spaces
+//
+// Comments are kept. Format is idempotent: formatting its output again
+// returns the same bytes.
+func Format(data []byte) ([]byte, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var docs []*yaml.Node
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+		docs = append(docs, &doc)
+	}
+	if len(docs) == 0 {
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, doc := range docs {
+		if len(doc.Content) > 0 {
+			normalizeStyle(doc.Content[0])
+			switch root := doc.Content[0]; root.Kind {
+			case yaml.MappingNode:
+				// A comment above the first key is the file's header, not
+				// the key's: keep it at the top when keys move
+				if len(root.Content) > 0 {
+					doc.HeadComment = joinComments(doc.HeadComment, root.Content[0].HeadComment)
+					root.Content[0].HeadComment = ""
+				}
+				formatConfig(root)
+			case yaml.SequenceNode:
+				formatSteps(root)
+			}
+		}
+		if err := enc.Encode(doc); err != nil {
+			return nil, err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// normalizeStyle drops unneeded quotes and flow-style mappings. The encoder
+// adds quotes back where a plain scalar would change the value's type or
+// could not be parsed. Strings a YAML 1.1 parser would read as something
+// else keep their quotes, as yaml.Marshal quotes them.
+func normalizeStyle(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Style&yaml.TaggedStyle == 0 && node.ShortTag() == "!!str" && !isYAML11Scalar(node.Value) {
+			node.Style &^= yaml.SingleQuotedStyle | yaml.DoubleQuotedStyle
+		}
+	case yaml.MappingNode:
+		node.Style &^= yaml.FlowStyle
+	}
+	for _, child := range node.Content {
+		normalizeStyle(child)
+	}
+}
+
+// base60Float matches YAML 1.1 base 60 numbers such as "1:30".
+var base60Float = regexp.MustCompile(`^[-+]?[0-9][0-9_]*(?::[0-5]?[0-9])+(?:\.[0-9_]*)?$`)
+
+// isYAML11Scalar reports whether a plain s would be a bool or a base 60
+// number in YAML 1.1, like "yes", "off" or "1:30".
+func isYAML11Scalar(s string) bool {
+	switch s {
+	case "y", "Y", "yes", "Yes", "YES", "on", "On", "ON",
+		"n", "N", "no", "No", "NO", "off", "Off", "OFF":
+		return true
+	}
+	return base60Float.MatchString(s)
+}
+
+func formatConfig(node *yaml.Node) {
+	order := fieldNames(yamlFields(reflect.TypeOf(Config{})))
+	order = append(order, configExtraKeys...)
+	sortKeys(node, order)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		switch node.Content[i].Value {
+		case "onFlowStart", "onFlowComplete", "onFlowFailure":
+			formatSteps(node.Content[i+1])
+		}
+	}
+}
+
+func formatSteps(node *yaml.Node) {
+	if node.Kind != yaml.SequenceNode {
+		return
+	}
+	for i, item := range node.Content {
+		node.Content[i] = formatStep(item)
+	}
+}
+
+// formatStep formats a step and returns it, or its scalar replacement.
+func formatStep(node *yaml.Node) *yaml.Node {
+	// Leave invalid steps, such as two commands in one item, as they are
+	if node.Kind != yaml.MappingNode || len(node.Content) != 2 {
+		return node
+	}
+	key, value := node.Content[0], node.Content[1]
+	stepType := StepType(key.Value)
+	schema, ok := stepSchemas[stepType]
+	if !ok {
+		return node
+	}
+
+	if (isNull(value) || (value.Kind == yaml.MappingNode && len(value.Content) == 0)) && !hasComments(value) {
+		return scalarStep(node, key)
+	}
+
+	if schema.typ.Kind() == reflect.Map || value.Kind != yaml.MappingNode {
+		return node
+	}
+	formatValue(value, schema.typ)
+	order := []string{}
+	for _, name := range fieldNames(yamlFields(schema.typ)) {
+		if !contains(baseKeys, name) {
+			order = append(order, name)
+		}
+	}
+	order = append(order, baseKeys...)
+	order = append(order, schema.stepKeys()...)
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		if contains(schema.stepKeys(), value.Content[i].Value) {
+			formatSteps(value.Content[i+1])
+		}
+	}
+	sortKeys(value, order)
+
+	// "tapOn: {text: Login}" -> "tapOn: Login"
+	if field, ok := shorthandKeys[stepType]; ok && len(value.Content) == 2 {
+		k, v := value.Content[0], value.Content[1]
+		if k.Value == field && v.Kind == yaml.ScalarNode && !isNull(v) && !hasComments(k) && v.HeadComment == "" && v.FootComment == "" {
+			v.LineComment = joinLine(key.LineComment, v.LineComment)
+			key.LineComment = ""
+			node.Content[1] = v
+		}
+	}
+	return node
+}
+
+// scalarStep replaces a step with no options by its bare name, keeping the
+// comments around it.
+func scalarStep(node, key *yaml.Node) *yaml.Node {
+	value := node.Content[1]
+	return &yaml.Node{
+		Kind:        yaml.ScalarNode,
+		Tag:         "!!str",
+		Value:       key.Value,
+		HeadComment: joinComments(node.HeadComment, key.HeadComment),
+		LineComment: joinLine(key.LineComment, value.LineComment),
+		FootComment: joinComments(key.FootComment, node.FootComment),
+		Line:        node.Line,
+		Column:      node.Column,
+	}
+}
+
+// formatValue orders the keys of nested values, such as relative selectors
+// and conditions, by the struct they decode into.
+func formatValue(node *yaml.Node, t reflect.Type) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		fields := valueFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if f, ok := findField(fields, node.Content[i].Value); ok {
+				formatValue(node.Content[i+1], f.Type)
+			}
+		}
+		sortKeys(node, fieldNames(fields))
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for _, item := range node.Content {
+			formatValue(item, t.Elem())
+		}
+	}
+}
+
+// sortKeys reorders a mapping's keys to follow order. Keys not in order keep
+// their relative order after the known ones.
+func sortKeys(node *yaml.Node, order []string) {
+	rank := make(map[string]int, len(order))
+	for i, name := range order {
+		rank[name] = i
+	}
+	type pair struct{ key, value *yaml.Node }
+	pairs := make([]pair, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, pair{node.Content[i], node.Content[i+1]})
+	}
+	pos := func(p pair) int {
+		if r, ok := rank[p.key.Value]; ok {
+			return r
+		}
+		return len(order)
+	}
+	// Insertion sort keeps equal ranks (unknown keys) stable
+	for i := 1; i < len(pairs); i++ {
+		for j := i; j > 0 && pos(pairs[j]) < pos(pairs[j-1]); j-- {
+			pairs[j], pairs[j-1] = pairs[j-1], pairs[j]
+		}
+	}
+	for i, p := range pairs {
+		node.Content[2*i] = p.key
+		node.Content[2*i+1] = p.value
+	}
+}
+
+func hasComments(node *yaml.Node) bool {
+	if node.HeadComment != "" || node.LineComment != "" || node.FootComment != "" {
+		return true
+	}
+	for _, child := range node.Content {
+		if hasComments(child) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinComments(comments ...string) string {
+	return joinNonEmpty(comments, "\n")
+}
+
+func joinLine(comments ...string) string {
+	return joinNonEmpty(comments, " ")
+}
+
+func joinNonEmpty(list []string, sep string) string {
+	var parts []string
+	for _, s := range list {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, sep)
+}
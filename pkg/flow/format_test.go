@@ -0,0 +1,200 @@
+package flow
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	input := `# Login flow
+name: "Login"
+tags: [smoke, login]
+appId: com.example # the app
+env:
+  USER: "alice"
+  PIN: "1234"
+onFlowStart:
+  - clearState: {}
+---
+- launchApp:
+- tapOn: {text: "Sign in"}
+# enter user
+- tapOn:
+    optional: true
+    id: 'user'
+    childOf: {index: 0, id: form}
+- inputText:
+    text: ${USER} # from env
+- repeat:
+    commands:
+      - scroll
+    label: scroll a bit
+    times: 3
+- swipe: {direction: LEFT, duration: 300}
+- tapOn: "true"
+`
+	want := `# Login flow
+
+appId: com.example # the app
+name: Login
+tags: [smoke, login]
+env:
+  USER: alice
+  PIN: "1234"
+onFlowStart:
+  - clearState
+---
+- launchApp
+- tapOn: Sign in
+# enter user
+- tapOn:
+    id: user
+    childOf:
+      id: form
+      index: 0
+    optional: true
+- inputText: ${USER} # from env
+- repeat:
+    times: 3
+    label: scroll a bit
+    commands:
+      - scroll
+- swipe:
+    direction: LEFT
+    duration: 300
+- tapOn: "true"
+`
+	got, err := Format([]byte(input))
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("Format() =\n%s\nwant:\n%s", got, want)
+	}
+
+	again, err := Format(got)
+	if err != nil {
+		t.Fatalf("Format() of formatted output error = %v", err)
+	}
+	if string(again) != string(got) {
+		t.Errorf("Format() is not idempotent:\n%s", again)
+	}
+}
+
+func TestFormat_KeepsYAML11Quotes(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`- tapOn: "yes"`, "- tapOn: \"yes\"\n"},
+		{`- tapOn: 'Off'`, "- tapOn: 'Off'\n"},
+		{`- tapOn: "n"`, "- tapOn: \"n\"\n"},
+		{`- inputText: "1:30"`, "- inputText: \"1:30\"\n"},
+		{`- inputText: "-12:05:59.5"`, "- inputText: \"-12:05:59.5\"\n"},
+		// Not YAML 1.1 bools or numbers either
+		{`- tapOn: "yesterday"`, "- tapOn: yesterday\n"},
+		{`- inputText: "1:60"`, "- inputText: 1:60\n"},
+	}
+	for _, tt := range tests {
+		got, err := Format([]byte(tt.input))
+		if err != nil {
+			t.Fatalf("Format(%q) error = %v", tt.input, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("Format(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFormat_SameFlow(t *testing.T) {
+	input := `appId: com.example
+---
+- runFlow:
+    when:
+      visible: {text: OK}
+    commands:
+      - tapOn: 'OK'
+- runFlow: {file: sub.yaml}
+- assertTrue: {condition: "${output.ok}"}
+- runScript:
+    script: |
+      output.a = 1
+- eraseText: {characters: 5}
+- takeScreenshot: {path: shot}
+`
+	before, err := Parse([]byte(input), "flow.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	formatted, err := Format([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := Parse(formatted, "flow.yaml")
+	if err != nil {
+		t.Fatalf("formatted flow does not parse: %v\n%s", err, formatted)
+	}
+	if len(before.Steps) != len(after.Steps) {
+		t.Fatalf("step count changed: %d -> %d", len(before.Steps), len(after.Steps))
+	}
+	for i := range before.Steps {
+		b, a := *before.Steps[i].(interface{ base() *BaseStep }).base(), *after.Steps[i].(interface{ base() *BaseStep }).base()
+		b.SourcePos, b.SourceText, a.SourcePos, a.SourceText = Position{}, "", Position{}, ""
+		if !reflect.DeepEqual(b, a) {
+			t.Errorf("step %d base changed: %+v -> %+v", i, b, a)
+		}
+	}
+	if got := after.Steps[1].(*RunFlowStep).File; got != "sub.yaml" {
+		t.Errorf("runFlow file = %q", got)
+	}
+	if got := after.Steps[2].(*AssertTrueStep).Script; got != "${output.ok}" {
+		t.Errorf("assertTrue condition = %q", got)
+	}
+	if got := after.Steps[4].(*EraseTextStep).Characters; got != 5 {
+		t.Errorf("eraseText characters = %d", got)
+	}
+	inline := after.Steps[0].(*RunFlowStep)
+	if inline.When == nil || inline.When.Visible == nil || len(inline.Steps) != 1 {
+		t.Errorf("runFlow when/commands lost: %+v", inline)
+	}
+}
+
+func TestFormat_LeavesUnknownKeysAndInvalidSteps(t *testing.T) {
+	input := "- tapOn:\n    zeta: 1\n    id: x\n    alpha: 2\n- tapOn: a\n  back: b\n- notAStep: {a: 1}\n"
+	want := "- tapOn:\n    id: x\n    zeta: 1\n    alpha: 2\n- tapOn: a\n  back: b\n- notAStep:\n    a: 1\n"
+	got, err := Format([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("Format() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormat_Empty(t *testing.T) {
+	for _, input := range []string{"", "# just a comment\n"} {
+		got, err := Format([]byte(input))
+		if err != nil {
+			t.Errorf("Format(%q) error = %v", input, err)
+		}
+		if string(got) != input {
+			t.Errorf("Format(%q) = %q", input, got)
+		}
+	}
+	if _, err := Format([]byte("- tapOn: [\n")); err == nil {
+		t.Error("expected error for invalid YAML")
+	}
+}
+
+func TestShorthandKeys_MatchFields(t *testing.T) {
+	for stepType, key := range shorthandKeys {
+		schema := stepSchemas[stepType]
+		if !schema.scalar {
+			t.Errorf("%s has a shorthand key but no scalar form", stepType)
+		}
+		fields := fieldNames(valueFields(schema.typ))
+		if !contains(fields, key) {
+			t.Errorf("%s: shorthand key %q is not a field", stepType, key)
+		}
+	}
+}
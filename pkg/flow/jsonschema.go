@@ -0,0 +1,170 @@
+package flow
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// JSONSchema returns a JSON Schema (draft-07) for flow files, generated from
+// the step structs. A flow file's config header and its step list are
+// separate YAML documents, so the schema accepts either; editors using the
+// YAML language server apply it to each document.
+func JSONSchema() ([]byte, error) {
+	defs := map[string]interface{}{
+		"selector": map[string]interface{}{
+			"anyOf": []interface{}{
+				map[string]interface{}{"type": "string"},
+				objectSchema(valueFields(selectorType), nil),
+			},
+		},
+		"condition": objectSchema(yamlFields(reflect.TypeOf(Condition{})), nil),
+		"steps": map[string]interface{}{
+			"type":  "array",
+			"items": ref("step"),
+		},
+	}
+
+	names := stepTypeNames()
+	stepProps := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		defs[name] = stepValueSchema(stepSchemas[StepType(name)])
+		stepProps[name] = ref(name)
+	}
+	defs["step"] = map[string]interface{}{
+		"anyOf": []interface{}{
+			map[string]interface{}{"type": "string", "enum": names},
+			map[string]interface{}{
+				"type":                 "object",
+				"properties":           stepProps,
+				"additionalProperties": false,
+				"minProperties":        1,
+				"maxProperties":        1,
+			},
+		},
+	}
+
+	config := objectSchema(yamlFields(reflect.TypeOf(Config{})), map[string]interface{}{
+		"onFlowStart":    ref("steps"),
+		"onFlowComplete": ref("steps"),
+		"onFlowFailure":  ref("steps"),
+		"jsEngine":       map[string]interface{}{"type": "string"},
+		"properties":     map[string]interface{}{"type": "object"},
+		"dataset": map[string]interface{}{
+			"anyOf": []interface{}{
+				map[string]interface{}{"type": "string"},
+				ref("datasetRows"),
+				map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"file": map[string]interface{}{"type": "string"},
+						"key":  map[string]interface{}{"type": "string"},
+						"rows": ref("datasetRows"),
+					},
+					"additionalProperties": false,
+				},
+			},
+		},
+		"params": map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": ref("param"),
+		},
+	})
+	paramType := map[string]interface{}{"type": "string", "enum": []string{ParamString, ParamNumber, ParamBoolean}}
+	defs["param"] = map[string]interface{}{
+		"anyOf": []interface{}{
+			paramType,
+			map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"type":        paramType,
+					"default":     map[string]interface{}{"type": []string{"string", "number", "boolean"}},
+					"description": map[string]interface{}{"type": "string"},
+				},
+				"additionalProperties": false,
+			},
+		},
+	}
+	defs["datasetRows"] = map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": map[string]interface{}{"type": []string{"string", "number", "boolean", "null"}},
+		},
+	}
+	defs["config"] = config
+
+	schema := map[string]interface{}{
+		"$schema":     "http://json-schema.org/draft-07/schema#",
+		"title":       "Maestro flow",
+		"definitions": defs,
+		"anyOf":       []interface{}{ref("config"), ref("steps")},
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// stepValueSchema describes the value under a step's key.
+func stepValueSchema(s stepSchema) map[string]interface{} {
+	if s.typ.Kind() == reflect.Map {
+		return typeSchema(s.typ)
+	}
+	extra := make(map[string]interface{}, len(s.steps)+len(baseStepKeys))
+	for _, key := range s.stepKeys() {
+		extra[key] = ref("steps")
+	}
+	obj := objectSchema(yamlFields(s.typ), extra)
+	types := []interface{}{obj, map[string]interface{}{"type": "null"}}
+	if s.scalar {
+		types = append(types, typeSchema(reflect.TypeOf("")))
+	}
+	return map[string]interface{}{"anyOf": types}
+}
+
+func objectSchema(fields []yamlField, extra map[string]interface{}) map[string]interface{} {
+	props := make(map[string]interface{}, len(fields)+len(extra))
+	for _, f := range fields {
+		props[f.Name] = typeSchema(f.Type)
+	}
+	for k, v := range extra {
+		props[k] = v
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+}
+
+// typeSchema maps a Go field type to a JSON Schema.
+func typeSchema(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case selectorType:
+		return ref("selector")
+	case reflect.TypeOf(Condition{}):
+		return ref("condition")
+	}
+	switch t.Kind() {
+	case reflect.String:
+		// Any scalar decodes into a string field (times: 3, index: 0)
+		return map[string]interface{}{"type": []string{"string", "number", "boolean"}}
+	case reflect.Int, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return objectSchema(yamlFields(t), nil)
+	}
+	return map[string]interface{}{}
+}
+
+func ref(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/definitions/" + name}
+}
@@ -14,10 +14,14 @@ import (
 type ParseError struct {
 	Path    string
 	Line    int
+	Column  int
 	Message string
 }
 
 func (e *ParseError) Error() string {
+	if e.Line > 0 && e.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", e.Path, e.Line, e.Column, e.Message)
+	}
 	if e.Line > 0 {
 		return fmt.Sprintf("%s:%d: %s", e.Path, e.Line, e.Message)
 	}
@@ -35,10 +39,11 @@ func ParseFile(path string) (*Flow, error) {
 
 // Parse parses Maestro YAML content.
 func Parse(data []byte, sourcePath string) (*Flow, error) {
-	parts := splitYAMLDocuments(string(data))
+	parts, offsets := splitYAMLDocumentsWithOffsets(string(data))
 
 	flow := &Flow{
 		SourcePath: sourcePath,
+		source:     data,
 	}
 
 	if len(parts) == 0 {
@@ -50,24 +55,39 @@ func Parse(data []byte, sourcePath string) (*Flow, error) {
 	}
 
 	if len(parts) == 1 {
-		if err := parseSteps(parts[0], flow); err != nil {
+		if err := parseSteps(parts[0], offsets[0], flow); err != nil {
 			return nil, err
 		}
 	} else {
-		if err := parseConfig(parts[0], flow); err != nil {
+		if err := parseConfig(parts[0], offsets[0], flow); err != nil {
 			return nil, err
 		}
-		if err := parseSteps(parts[1], flow); err != nil {
+		if err := parseSteps(parts[1], offsets[1], flow); err != nil {
 			return nil, err
 		}
 	}
 
+	lines := strings.Split(string(data), "\n")
+	attachSource(flow.Config.OnFlowStart, lines)
+	attachSource(flow.Config.OnFlowComplete, lines)
+	attachSource(flow.Config.OnFlowFailure, lines)
+	attachSource(flow.Steps, lines)
+
 	return flow, nil
 }
 
 func splitYAMLDocuments(content string) []string {
+	parts, _ := splitYAMLDocumentsWithOffsets(content)
+	return parts
+}
+
+// splitYAMLDocumentsWithOffsets splits content on "---" and also returns, for
+// each part, the number of file lines that precede it.
+func splitYAMLDocumentsWithOffsets(content string) ([]string, []int) {
 	var parts []string
+	var offsets []int
 	var current strings.Builder
+	start := 0
 	inMultiline := false
 	multilineIndent := 0
 
@@ -94,9 +114,13 @@ func splitYAMLDocuments(content string) []string {
 		if !inMultiline && trimmed == "---" && strings.TrimLeft(line, " \t") == "---" {
 			if current.Len() > 0 {
 				parts = append(parts, current.String())
+				offsets = append(offsets, start)
 				current.Reset()
 			}
 		} else {
+			if current.Len() == 0 {
+				start = i
+			}
 			current.WriteString(line)
 			current.WriteString("\n")
 		}
@@ -106,13 +130,14 @@ func splitYAMLDocuments(content string) []string {
 		s := strings.TrimSpace(current.String())
 		if s != "" {
 			parts = append(parts, current.String())
+			offsets = append(offsets, start)
 		}
 	}
 
-	return parts
+	return parts, offsets
 }
 
-func parseConfig(content string, flow *Flow) error {
+func parseConfig(content string, offset int, flow *Flow) error {
 	var config Config
 	if err := yaml.Unmarshal([]byte(content), &config); err != nil {
 		return &ParseError{
@@ -121,10 +146,11 @@ func parseConfig(content string, flow *Flow) error {
 		}
 	}
 
-	// Parse lifecycle hooks (onFlowStart, onFlowComplete)
+	// Parse lifecycle hooks (onFlowStart, onFlowComplete, onFlowFailure)
 	var rawConfig struct {
 		OnFlowStart    []yaml.Node `yaml:"onFlowStart"`
 		OnFlowComplete []yaml.Node `yaml:"onFlowComplete"`
+		OnFlowFailure  []yaml.Node `yaml:"onFlowFailure"`
 	}
 	if err := yaml.Unmarshal([]byte(content), &rawConfig); err != nil {
 		return &ParseError{
@@ -134,6 +160,7 @@ func parseConfig(content string, flow *Flow) error {
 	}
 
 	for _, node := range rawConfig.OnFlowStart {
+		shiftLines(&node, offset)
 		step, err := parseStep(&node, flow.SourcePath)
 		if err != nil {
 			return err
@@ -142,6 +169,7 @@ func parseConfig(content string, flow *Flow) error {
 	}
 
 	for _, node := range rawConfig.OnFlowComplete {
+		shiftLines(&node, offset)
 		step, err := parseStep(&node, flow.SourcePath)
 		if err != nil {
 			return err
@@ -149,11 +177,20 @@ func parseConfig(content string, flow *Flow) error {
 		config.OnFlowComplete = append(config.OnFlowComplete, step)
 	}
 
+	for _, node := range rawConfig.OnFlowFailure {
+		shiftLines(&node, offset)
+		step, err := parseStep(&node, flow.SourcePath)
+		if err != nil {
+			return err
+		}
+		config.OnFlowFailure = append(config.OnFlowFailure, step)
+	}
+
 	flow.Config = config
 	return nil
 }
 
-func parseSteps(content string, flow *Flow) error {
+func parseSteps(content string, offset int, flow *Flow) error {
 	var rawSteps []yaml.Node
 	if err := yaml.Unmarshal([]byte(content), &rawSteps); err != nil {
 		return &ParseError{
@@ -163,6 +200,7 @@ func parseSteps(content string, flow *Flow) error {
 	}
 
 	for _, node := range rawSteps {
+		shiftLines(&node, offset)
 		step, err := parseStep(&node, flow.SourcePath)
 		if err != nil {
 			return err
@@ -174,18 +212,60 @@ func parseSteps(content string, flow *Flow) error {
 }
 
 func parseStep(node *yaml.Node, sourcePath string) (Step, error) {
+	step, err := parseStepNode(node, sourcePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := parseRecovery(step, node, sourcePath); err != nil {
+		return nil, err
+	}
+	setSource(step, node, sourcePath)
+	return step, nil
+}
+
+// parseRecovery parses the onFailure steps any step may have under its
+// value, e.g. "tapOn: {text: Pay, onFailure: [back]}".
+func parseRecovery(step Step, node *yaml.Node, sourcePath string) error {
+	if node.Kind != yaml.MappingNode || len(node.Content) < 2 {
+		return nil
+	}
+	value := node.Content[1]
+	if stepType, v := extractStepType(node); stepType != "" {
+		value = v
+	}
+	b, ok := step.(interface{ base() *BaseStep })
+	if !ok || value.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		if value.Content[i].Value != "onFailure" {
+			continue
+		}
+		var nodes []yaml.Node
+		if err := value.Content[i+1].Decode(&nodes); err != nil {
+			return wrapParseError(sourcePath, value.Content[i+1].Line, err)
+		}
+		base := b.base()
+		for j := range nodes {
+			recovery, err := parseStep(&nodes[j], sourcePath)
+			if err != nil {
+				return err
+			}
+			base.OnFailure = append(base.OnFailure, recovery)
+		}
+	}
+	return nil
+}
+
+func parseStepNode(node *yaml.Node, sourcePath string) (Step, error) {
 	// Handle scalar nodes like "- waitForAnimationToEnd" (no colon, no params)
 	if node.Kind == yaml.ScalarNode {
 		stepType := node.Value
+		// Create empty value node for steps with no parameters
+		emptyNode := &yaml.Node{Kind: yaml.MappingNode, Line: node.Line, Column: node.Column}
 		if !isStepType(stepType) {
-			return nil, &ParseError{
-				Path:    sourcePath,
-				Line:    node.Line,
-				Message: fmt.Sprintf("unknown step type: %s", stepType),
-			}
+			return parseCustomCommand(stepType, emptyNode, sourcePath)
 		}
-		// Create empty value node for steps with no parameters
-		emptyNode := &yaml.Node{Kind: yaml.MappingNode}
 		return decodeStep(StepType(stepType), emptyNode, sourcePath)
 	}
 
@@ -198,6 +278,9 @@ func parseStep(node *yaml.Node, sourcePath string) (Step, error) {
 	}
 
 	stepType, valueNode := extractStepType(node)
+	if stepType == "" && len(node.Content) == 2 {
+		return parseCustomCommand(node.Content[0].Value, node.Content[1], sourcePath)
+	}
 	if stepType == "" || valueNode == nil {
 		return nil, &ParseError{
 			Path:    sourcePath,
@@ -230,7 +313,7 @@ func isStepType(key string) bool {
 		StepAssertNoDefectsWithAI, StepAssertWithAI, StepExtractTextWithAI, StepWaitUntil,
 		StepLaunchApp, StepStopApp, StepKillApp, StepClearState, StepClearKeychain, StepSetPermissions,
 		StepSetLocation, StepSetOrientation, StepSetAirplaneMode, StepToggleAirplaneMode,
-		StepTravel, StepOpenLink, StepOpenBrowser, StepRepeat, StepRetry, StepRunFlow,
+		StepTravel, StepOpenLink, StepOpenBrowser, StepRepeat, StepForEach, StepRetry, StepRunFlow, StepIf,
 		StepRunScript, StepEvalScript, StepTakeScreenshot, StepStartRecording,
 		StepStopRecording, StepAddMedia, StepPressKey, StepWaitForAnimationToEnd,
 		StepDefineVariables:
@@ -311,10 +394,18 @@ func decodeStep(stepType StepType, valueNode *yaml.Node, sourcePath string) (Ste
 		return &s, nil
 
 	case StepBack:
-		return &BackStep{BaseStep: BaseStep{StepType: stepType}}, nil
+		base, err := decodeBase(stepType, valueNode, sourcePath)
+		if err != nil {
+			return nil, err
+		}
+		return &BackStep{BaseStep: base}, nil
 
 	case StepHideKeyboard:
-		return &HideKeyboardStep{BaseStep: BaseStep{StepType: stepType}}, nil
+		base, err := decodeBase(stepType, valueNode, sourcePath)
+		if err != nil {
+			return nil, err
+		}
+		return &HideKeyboardStep{BaseStep: base}, nil
 
 	case StepInputText:
 		var s InputTextStep
@@ -337,28 +428,32 @@ func decodeStep(stepType StepType, valueNode *yaml.Node, sourcePath string) (Ste
 		return &s, nil
 
 	case StepInputRandomEmail:
-		return &InputRandomStep{
-			BaseStep: BaseStep{StepType: StepInputRandom},
-			DataType: "EMAIL",
-		}, nil
+		base, err := decodeBase(StepInputRandom, valueNode, sourcePath)
+		if err != nil {
+			return nil, err
+		}
+		return &InputRandomStep{BaseStep: base, DataType: "EMAIL"}, nil
 
 	case StepInputRandomNumber:
-		return &InputRandomStep{
-			BaseStep: BaseStep{StepType: StepInputRandom},
-			DataType: "NUMBER",
-		}, nil
+		base, err := decodeBase(StepInputRandom, valueNode, sourcePath)
+		if err != nil {
+			return nil, err
+		}
+		return &InputRandomStep{BaseStep: base, DataType: "NUMBER"}, nil
 
 	case StepInputRandomPersonName:
-		return &InputRandomStep{
-			BaseStep: BaseStep{StepType: StepInputRandom},
-			DataType: "PERSON_NAME",
-		}, nil
+		base, err := decodeBase(StepInputRandom, valueNode, sourcePath)
+		if err != nil {
+			return nil, err
+		}
+		return &InputRandomStep{BaseStep: base, DataType: "PERSON_NAME"}, nil
 
 	case StepInputRandomText:
-		return &InputRandomStep{
-			BaseStep: BaseStep{StepType: StepInputRandom},
-			DataType: "TEXT",
-		}, nil
+		base, err := decodeBase(StepInputRandom, valueNode, sourcePath)
+		if err != nil {
+			return nil, err
+		}
+		return &InputRandomStep{BaseStep: base, DataType: "TEXT"}, nil
 
 	case StepEraseText:
 		var s EraseTextStep
@@ -384,7 +479,11 @@ func decodeStep(stepType StepType, valueNode *yaml.Node, sourcePath string) (Ste
 		return &s, nil
 
 	case StepPasteText:
-		return &PasteTextStep{BaseStep: BaseStep{StepType: stepType}}, nil
+		base, err := decodeBase(stepType, valueNode, sourcePath)
+		if err != nil {
+			return nil, err
+		}
+		return &PasteTextStep{BaseStep: base}, nil
 
 	case StepSetClipboard:
 		var s SetClipboardStep
@@ -509,7 +608,11 @@ func decodeStep(stepType StepType, valueNode *yaml.Node, sourcePath string) (Ste
 		return &s, nil
 
 	case StepClearKeychain:
-		return &ClearKeychainStep{BaseStep: BaseStep{StepType: stepType}}, nil
+		base, err := decodeBase(stepType, valueNode, sourcePath)
+		if err != nil {
+			return nil, err
+		}
+		return &ClearKeychainStep{BaseStep: base}, nil
 
 	case StepSetPermissions:
 		var s SetPermissionsStep
@@ -546,7 +649,11 @@ func decodeStep(stepType StepType, valueNode *yaml.Node, sourcePath string) (Ste
 		return &s, nil
 
 	case StepToggleAirplaneMode:
-		return &ToggleAirplaneModeStep{BaseStep: BaseStep{StepType: stepType}}, nil
+		base, err := decodeBase(stepType, valueNode, sourcePath)
+		if err != nil {
+			return nil, err
+		}
+		return &ToggleAirplaneModeStep{BaseStep: base}, nil
 
 	case StepTravel:
 		var s TravelStep
@@ -579,12 +686,18 @@ func decodeStep(stepType StepType, valueNode *yaml.Node, sourcePath string) (Ste
 	case StepRepeat:
 		return parseRepeatStep(valueNode, sourcePath)
 
+	case StepForEach:
+		return parseForEachStep(valueNode, sourcePath)
+
 	case StepRetry:
 		return parseRetryStep(valueNode, sourcePath)
 
 	case StepRunFlow:
 		return parseRunFlowStep(valueNode, sourcePath)
 
+	case StepIf:
+		return parseIfStep(valueNode, sourcePath)
+
 	case StepRunScript:
 		var s RunScriptStep
 		if valueNode.Kind == yaml.ScalarNode {
@@ -688,6 +801,7 @@ func parseRepeatStep(valueNode *yaml.Node, sourcePath string) (Step, error) {
 		Commands []yaml.Node `yaml:"commands"`
 		Optional bool        `yaml:"optional"`
 		Label    string      `yaml:"label"`
+		When     *Condition  `yaml:"when"`
 	}
 
 	if err := valueNode.Decode(&raw); err != nil {
@@ -699,6 +813,7 @@ func parseRepeatStep(valueNode *yaml.Node, sourcePath string) (Step, error) {
 			StepType:  StepRepeat,
 			Optional:  raw.Optional,
 			StepLabel: raw.Label,
+			When:      raw.When,
 		},
 		Times: raw.Times,
 		While: raw.While,
@@ -715,6 +830,63 @@ func parseRepeatStep(valueNode *yaml.Node, sourcePath string) (Step, error) {
 	return s, nil
 }
 
+// parseForEachStep handles forEach with nested commands.
+func parseForEachStep(valueNode *yaml.Node, sourcePath string) (Step, error) {
+	var raw struct {
+		Items      yaml.Node   `yaml:"items"`
+		As         string      `yaml:"as"`
+		IndexAs    string      `yaml:"indexAs"`
+		BreakIf    *Condition  `yaml:"breakIf"`
+		ContinueIf *Condition  `yaml:"continueIf"`
+		Commands   []yaml.Node `yaml:"commands"`
+		Optional   bool        `yaml:"optional"`
+		Label      string      `yaml:"label"`
+		When       *Condition  `yaml:"when"`
+	}
+
+	if err := valueNode.Decode(&raw); err != nil {
+		return nil, wrapParseError(sourcePath, valueNode.Line, err)
+	}
+
+	s := &ForEachStep{
+		BaseStep: BaseStep{
+			StepType:  StepForEach,
+			Optional:  raw.Optional,
+			StepLabel: raw.Label,
+			When:      raw.When,
+		},
+		As:         raw.As,
+		IndexAs:    raw.IndexAs,
+		BreakIf:    raw.BreakIf,
+		ContinueIf: raw.ContinueIf,
+	}
+
+	switch raw.Items.Kind {
+	case 0:
+		return nil, &ParseError{Path: sourcePath, Line: valueNode.Line, Message: "forEach requires items"}
+	case yaml.SequenceNode:
+		var items []any
+		if err := raw.Items.Decode(&items); err != nil {
+			return nil, wrapParseError(sourcePath, raw.Items.Line, err)
+		}
+		s.Items = items
+	case yaml.ScalarNode:
+		s.Items = raw.Items.Value
+	default:
+		return nil, &ParseError{Path: sourcePath, Line: raw.Items.Line, Message: "forEach items must be a list or a string"}
+	}
+
+	for _, cmdNode := range raw.Commands {
+		step, err := parseStep(&cmdNode, sourcePath)
+		if err != nil {
+			return nil, err
+		}
+		s.Steps = append(s.Steps, step)
+	}
+
+	return s, nil
+}
+
 // parseRetryStep handles retry with nested commands.
 func parseRetryStep(valueNode *yaml.Node, sourcePath string) (Step, error) {
 	var raw struct {
@@ -724,6 +896,7 @@ func parseRetryStep(valueNode *yaml.Node, sourcePath string) (Step, error) {
 		Env        map[string]string `yaml:"env"`
 		Optional   bool              `yaml:"optional"`
 		Label      string            `yaml:"label"`
+		When       *Condition        `yaml:"when"`
 	}
 
 	if err := valueNode.Decode(&raw); err != nil {
@@ -735,6 +908,7 @@ func parseRetryStep(valueNode *yaml.Node, sourcePath string) (Step, error) {
 			StepType:  StepRetry,
 			Optional:  raw.Optional,
 			StepLabel: raw.Label,
+			When:      raw.When,
 		},
 		MaxRetries: raw.MaxRetries,
 		File:       raw.File,
@@ -752,6 +926,49 @@ func parseRetryStep(valueNode *yaml.Node, sourcePath string) (Step, error) {
 	return s, nil
 }
 
+// parseIfStep handles if with then and else branches.
+func parseIfStep(valueNode *yaml.Node, sourcePath string) (Step, error) {
+	var raw struct {
+		Condition Condition   `yaml:",inline"`
+		Then      []yaml.Node `yaml:"then"`
+		Else      []yaml.Node `yaml:"else"`
+		Optional  bool        `yaml:"optional"`
+		Label     string      `yaml:"label"`
+		When      *Condition  `yaml:"when"`
+	}
+
+	if err := valueNode.Decode(&raw); err != nil {
+		return nil, wrapParseError(sourcePath, valueNode.Line, err)
+	}
+
+	s := &IfStep{
+		BaseStep: BaseStep{
+			StepType:  StepIf,
+			Optional:  raw.Optional,
+			StepLabel: raw.Label,
+			When:      raw.When,
+		},
+		Condition: raw.Condition,
+	}
+
+	for _, cmdNode := range raw.Then {
+		step, err := parseStep(&cmdNode, sourcePath)
+		if err != nil {
+			return nil, err
+		}
+		s.Then = append(s.Then, step)
+	}
+	for _, cmdNode := range raw.Else {
+		step, err := parseStep(&cmdNode, sourcePath)
+		if err != nil {
+			return nil, err
+		}
+		s.Else = append(s.Else, step)
+	}
+
+	return s, nil
+}
+
 // parseRunFlowStep handles runFlow with optional nested commands.
 func parseRunFlowStep(valueNode *yaml.Node, sourcePath string) (Step, error) {
 	s := &RunFlowStep{BaseStep: BaseStep{StepType: StepRunFlow}}
@@ -791,6 +1008,19 @@ func parseRunFlowStep(valueNode *yaml.Node, sourcePath string) (Step, error) {
 	return s, nil
 }
 
+// decodeBase decodes the common options of a step that has none of its own,
+// e.g. "back: {label: Close, when: {platform: android}}".
+func decodeBase(stepType StepType, valueNode *yaml.Node, sourcePath string) (BaseStep, error) {
+	base := BaseStep{StepType: stepType}
+	if valueNode.Kind == yaml.MappingNode {
+		if err := valueNode.Decode(&base); err != nil {
+			return BaseStep{}, wrapParseError(sourcePath, valueNode.Line, err)
+		}
+		base.StepType = stepType
+	}
+	return base, nil
+}
+
 func wrapParseError(path string, line int, err error) error {
 	return &ParseError{
 		Path:    path,
@@ -808,6 +1038,9 @@ func ParseDirectory(dir string, includeTags, excludeTags []string) ([]*Flow, err
 			return err
 		}
 		if info.IsDir() {
+			if path != dir && info.Name() == CommandsDir {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 		ext := strings.ToLower(filepath.Ext(path))
@@ -826,8 +1059,11 @@ func ParseDirectory(dir string, includeTags, excludeTags []string) ([]*Flow, err
 		}
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	return flows, err
+	return ExpandAll(flows)
 }
 
 // ShouldIncludeFlow checks if a flow matches tag filters.
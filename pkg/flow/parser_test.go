@@ -360,6 +360,141 @@ func TestParse_RunFlowWithInlineSteps(t *testing.T) {
 	}
 }
 
+func TestParse_ForEachStep(t *testing.T) {
+	yaml := `
+- forEach:
+    items: [101, 102]
+    as: productId
+    breakIf:
+      visible: "Out of stock"
+    commands:
+      - openLink: "app://product/${productId}"
+- forEach:
+    items: ${PRODUCT_IDS}
+    commands:
+      - tapOn: ${item}
+`
+	flow, err := Parse([]byte(yaml), "test.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	list, ok := flow.Steps[0].(*ForEachStep)
+	if !ok {
+		t.Fatalf("expected ForEachStep, got %T", flow.Steps[0])
+	}
+	if items, ok := list.Items.([]any); !ok || len(items) != 2 {
+		t.Errorf("items = %#v, want a list of 2", list.Items)
+	}
+	if list.ItemVar() != "productId" || list.IndexVar() != "index" {
+		t.Errorf("vars = %s/%s, want productId/index", list.ItemVar(), list.IndexVar())
+	}
+	if list.BreakIf == nil || list.BreakIf.Visible == nil || list.ContinueIf != nil {
+		t.Errorf("breakIf/continueIf = %+v/%+v", list.BreakIf, list.ContinueIf)
+	}
+	if len(list.Steps) != 1 {
+		t.Errorf("expected 1 nested step, got %d", len(list.Steps))
+	}
+
+	expr := flow.Steps[1].(*ForEachStep)
+	if expr.Items != "${PRODUCT_IDS}" || expr.ItemVar() != "item" {
+		t.Errorf("items = %#v, item var = %s", expr.Items, expr.ItemVar())
+	}
+}
+
+func TestParse_ForEachStepWithoutItems(t *testing.T) {
+	yaml := `
+- forEach:
+    commands:
+      - back
+`
+	if _, err := Parse([]byte(yaml), "test.yaml"); err == nil || !strings.Contains(err.Error(), "forEach requires items") {
+		t.Errorf("err = %v, want forEach requires items", err)
+	}
+}
+
+func TestParse_WhenOnAnyStep(t *testing.T) {
+	yaml := `
+- tapOn:
+    text: "Accept"
+    when:
+      visible: "Cookie banner"
+- back:
+    when:
+      platform: Android
+- inputRandomEmail:
+    when:
+      scriptCondition: ${NEEDS_EMAIL}
+- repeat:
+    times: "2"
+    when:
+      notVisible: "Done"
+    commands:
+      - scroll
+`
+	flow, err := Parse([]byte(yaml), "test.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(flow.Steps) != 4 {
+		t.Fatalf("expected 4 steps, got %d", len(flow.Steps))
+	}
+
+	if w := flow.Steps[0].WhenCondition(); w == nil || w.Visible == nil || w.Visible.Text != "Cookie banner" {
+		t.Errorf("tapOn when = %+v, want visible Cookie banner", w)
+	}
+	if w := flow.Steps[1].WhenCondition(); w == nil || w.Platform != "Android" {
+		t.Errorf("back when = %+v, want platform Android", w)
+	}
+	if flow.Steps[1].Type() != StepBack {
+		t.Errorf("back type = %s", flow.Steps[1].Type())
+	}
+	random, ok := flow.Steps[2].(*InputRandomStep)
+	if !ok || random.DataType != "EMAIL" || random.When == nil || random.When.Script != "${NEEDS_EMAIL}" {
+		t.Errorf("inputRandomEmail = %+v", flow.Steps[2])
+	}
+	if w := flow.Steps[3].WhenCondition(); w == nil || w.NotVisible == nil {
+		t.Errorf("repeat when = %+v, want notVisible", w)
+	}
+}
+
+func TestParse_IfStep(t *testing.T) {
+	yaml := `
+- if:
+    visible: "Welcome back"
+    label: "returning user"
+    then:
+      - tapOn: "Continue"
+    else:
+      - tapOn: "Sign up"
+      - inputText: "alice"
+`
+	flow, err := Parse([]byte(yaml), "test.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	step, ok := flow.Steps[0].(*IfStep)
+	if !ok {
+		t.Fatalf("expected IfStep, got %T", flow.Steps[0])
+	}
+	if step.Condition.Visible == nil || step.Condition.Visible.Text != "Welcome back" {
+		t.Errorf("condition = %+v, want visible Welcome back", step.Condition)
+	}
+	if step.Label() != "returning user" {
+		t.Errorf("label = %q", step.Label())
+	}
+	if len(step.Then) != 1 || len(step.Else) != 2 {
+		t.Errorf("then/else = %d/%d steps, want 1/2", len(step.Then), len(step.Else))
+	}
+	if got := step.Describe(); got != `if: visible text="Welcome back"` {
+		t.Errorf("Describe() = %q", got)
+	}
+	if step.Else[1].Position().Line != 9 {
+		t.Errorf("else step line = %d, want 9", step.Else[1].Position().Line)
+	}
+}
+
 func TestParse_NestedRepeat(t *testing.T) {
 	yaml := `
 - repeat:
@@ -1409,6 +1544,75 @@ onFlowComplete:
 	}
 }
 
+func TestParse_OnFlowFailure(t *testing.T) {
+	yaml := `
+appId: com.example.app
+onFlowFailure:
+  - takeScreenshot: failure
+  - evalScript: ${output.error = FLOW_ERROR}
+---
+- tapOn: "Button"
+`
+	flow, err := Parse([]byte(yaml), "test.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(flow.Config.OnFlowFailure) != 2 {
+		t.Fatalf("expected 2 onFlowFailure steps, got %d", len(flow.Config.OnFlowFailure))
+	}
+	if _, ok := flow.Config.OnFlowFailure[0].(*TakeScreenshotStep); !ok {
+		t.Errorf("expected TakeScreenshotStep, got %T", flow.Config.OnFlowFailure[0])
+	}
+	if pos := flow.Config.OnFlowFailure[1].Position(); pos.Line != 5 {
+		t.Errorf("onFlowFailure step line = %d, want 5", pos.Line)
+	}
+}
+
+func TestParse_OnFailure(t *testing.T) {
+	yaml := `
+- tapOn:
+    text: Pay
+    onFailure:
+      - back
+      - tapOn: Cart
+- repeat:
+    times: 2
+    onFailure:
+      - back
+    commands:
+      - tapOn: Next
+- inputText: hello
+`
+	flow, err := Parse([]byte(yaml), "test.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tap := flow.Steps[0].(*TapOnStep)
+	if tap.Selector.Text != "Pay" {
+		t.Errorf("Selector.Text = %q, want Pay", tap.Selector.Text)
+	}
+	recovery := tap.Recovery()
+	if len(recovery) != 2 {
+		t.Fatalf("expected 2 onFailure steps, got %d", len(recovery))
+	}
+	if _, ok := recovery[0].(*BackStep); !ok {
+		t.Errorf("expected BackStep, got %T", recovery[0])
+	}
+	if pos := recovery[1].Position(); pos.Line != 6 {
+		t.Errorf("onFailure step line = %d, want 6", pos.Line)
+	}
+
+	repeat := flow.Steps[1].(*RepeatStep)
+	if len(repeat.Recovery()) != 1 || len(repeat.Steps) != 1 {
+		t.Errorf("repeat: %d onFailure steps, %d commands, want 1 and 1", len(repeat.Recovery()), len(repeat.Steps))
+	}
+	if len(flow.Steps[2].Recovery()) != 0 {
+		t.Errorf("inputText has onFailure steps: %v", flow.Steps[2].Recovery())
+	}
+}
+
 func TestParse_OpenBrowserStep(t *testing.T) {
 	yaml := `
 - openBrowser: "https://example.com"
@@ -1473,3 +1677,83 @@ appId: [invalid yaml
 		t.Error("expected error for invalid config YAML")
 	}
 }
+
+func TestParse_StepPositions(t *testing.T) {
+	yaml := `appId: com.example
+onFlowStart:
+  - launchApp
+---
+- tapOn: "Login"
+# comment
+- inputText:
+    text: "hello"   # greet
+    label: Type greeting
+
+- repeat:
+    times: 2
+    commands:
+      - back
+      - {tapOn: OK}
+`
+	f, err := Parse([]byte(yaml), "login.yaml")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got := f.Config.OnFlowStart[0].Position().String(); got != "login.yaml:3:5" {
+		t.Errorf("onFlowStart position = %q, want login.yaml:3:5", got)
+	}
+	wantPos := []string{"login.yaml:5:3", "login.yaml:7:3", "login.yaml:11:3"}
+	for i, want := range wantPos {
+		if got := f.Steps[i].Position().String(); got != want {
+			t.Errorf("step %d position = %q, want %q", i, got, want)
+		}
+	}
+
+	if got := f.Steps[0].Source(); got != `tapOn: "Login"` {
+		t.Errorf("step 0 source = %q", got)
+	}
+	wantSource := "inputText:\n  text: \"hello\"   # greet\n  label: Type greeting"
+	if got := f.Steps[1].Source(); got != wantSource {
+		t.Errorf("step 1 source = %q, want %q", got, wantSource)
+	}
+
+	repeat := f.Steps[2].(*RepeatStep)
+	if got := repeat.Steps[0].Position().String(); got != "login.yaml:14:9" {
+		t.Errorf("nested position = %q, want login.yaml:14:9", got)
+	}
+	if got := repeat.Steps[0].Source(); got != "back" {
+		t.Errorf("nested scalar source = %q", got)
+	}
+	if got := repeat.Steps[1].Source(); got != "{tapOn: OK}" {
+		t.Errorf("nested flow-style source = %q", got)
+	}
+}
+
+func TestParse_ErrorLineCountsConfigHeader(t *testing.T) {
+	yaml := `appId: com.example
+---
+- tapOn: "Login"
+- notAValidStep: value
+`
+	_, err := Parse([]byte(yaml), "test.yaml")
+	parseErr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T", err)
+	}
+	if parseErr.Line != 4 {
+		t.Errorf("Line = %d, want 4", parseErr.Line)
+	}
+}
+
+func TestPosition_String(t *testing.T) {
+	if got := (Position{}).String(); got != "" {
+		t.Errorf("zero position = %q, want empty", got)
+	}
+	if got := (Position{File: "a.yaml", Line: 3}).String(); got != "a.yaml:3" {
+		t.Errorf("no column = %q", got)
+	}
+	if got := (Position{File: "a.yaml", Line: 3, Column: 5}).String(); got != "a.yaml:3:5" {
+		t.Errorf("full = %q", got)
+	}
+}
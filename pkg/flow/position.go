@@ -0,0 +1,118 @@
+package flow
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Position is where a step appears in its flow file. Line and Column are
+// 1-based and count from the start of the file, not the YAML document.
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// IsZero reports whether the position is unknown (step not parsed from a file).
+func (p Position) IsZero() bool { return p.Line == 0 }
+
+// String returns "file:line:col", or "" if the position is unknown.
+func (p Position) String() string {
+	if p.IsZero() {
+		return ""
+	}
+	if p.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d", p.File, p.Line)
+}
+
+// shiftLines adds offset to the line of node and all its descendants, so
+// positions in a later YAML document are relative to the whole file.
+func shiftLines(node *yaml.Node, offset int) {
+	if offset == 0 {
+		return
+	}
+	node.Line += offset
+	for _, child := range node.Content {
+		shiftLines(child, offset)
+	}
+}
+
+// setSource records where a step came from. Scalar and flow-style steps are
+// rendered from the node; block mappings are cut from the file later by
+// attachSource, which keeps comments and formatting.
+func setSource(step Step, node *yaml.Node, sourcePath string) {
+	b, ok := step.(interface{ base() *BaseStep })
+	if !ok {
+		return
+	}
+	base := b.base()
+	base.SourcePos = Position{File: sourcePath, Line: node.Line, Column: node.Column}
+	switch {
+	case node.Kind == yaml.ScalarNode:
+		base.SourceText = node.Value
+	case node.Style&yaml.FlowStyle != 0:
+		if out, err := yaml.Marshal(node); err == nil {
+			base.SourceText = strings.TrimSpace(string(out))
+		}
+	}
+}
+
+// attachSource fills in the original YAML text of block-mapping steps,
+// including nested and onFailure steps, from the lines of the flow file.
+func attachSource(steps []Step, lines []string) {
+	for _, step := range steps {
+		b, ok := step.(interface{ base() *BaseStep })
+		if !ok {
+			continue
+		}
+		base := b.base()
+		if base.SourceText == "" && !base.SourcePos.IsZero() {
+			base.SourceText = blockText(lines, base.SourcePos.Line, base.SourcePos.Column)
+		}
+		attachSource(base.OnFailure, lines)
+		switch s := step.(type) {
+		case *RepeatStep:
+			attachSource(s.Steps, lines)
+		case *ForEachStep:
+			attachSource(s.Steps, lines)
+		case *RetryStep:
+			attachSource(s.Steps, lines)
+		case *RunFlowStep:
+			attachSource(s.Steps, lines)
+		case *IfStep:
+			attachSource(s.Then, lines)
+			attachSource(s.Else, lines)
+		}
+	}
+}
+
+// blockText returns the block mapping starting at line:col, dedented. The
+// block ends at the first non-blank line indented less than col.
+func blockText(lines []string, line, col int) string {
+	if line < 1 || line > len(lines) || col < 1 || col-1 > len(lines[line-1]) {
+		return ""
+	}
+	indent := col - 1
+	out := []string{strings.TrimRight(lines[line-1][indent:], "\r")}
+	for _, l := range lines[line:] {
+		l = strings.TrimRight(l, "\r")
+		trimmed := strings.TrimSpace(l)
+		if trimmed != "" && len(l)-len(strings.TrimLeft(l, " ")) < indent {
+			break
+		}
+		if len(l) >= indent {
+			l = l[indent:]
+		} else {
+			l = ""
+		}
+		out = append(out, l)
+	}
+	for len(out) > 1 && strings.TrimSpace(out[len(out)-1]) == "" {
+		out = out[:len(out)-1]
+	}
+	return strings.TrimRight(strings.Join(out, "\n"), " \t\r")
+}
@@ -0,0 +1,105 @@
+package flow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Form factors a flow can require.
+const (
+	FormFactorPhone  = "phone"
+	FormFactorTablet = "tablet"
+)
+
+// DeviceRequirements restricts the devices a flow runs on. When running on
+// several devices, each flow goes to a device that meets all of them.
+type DeviceRequirements struct {
+	Platform     string `yaml:"platform"`     // android or ios
+	MinOSVersion string `yaml:"minOSVersion"` // e.g. "14" (Android 14) or "17.2"
+	FormFactor   string `yaml:"formFactor"`   // phone or tablet
+	Locale       string `yaml:"locale"`       // e.g. "de_DE", or "de" for any German locale
+	Group        string `yaml:"group"`        // Device group from the workspace config's deviceGroups
+}
+
+// Validate checks the requirement values.
+func (r *DeviceRequirements) Validate() error {
+	if r == nil {
+		return nil
+	}
+	switch strings.ToLower(r.Platform) {
+	case "", "android", "ios":
+	default:
+		return fmt.Errorf("device.platform must be android or ios, got %q", r.Platform)
+	}
+	if r.MinOSVersion != "" {
+		if _, err := ParseVersion(r.MinOSVersion); err != nil {
+			return fmt.Errorf("device.minOSVersion: %w", err)
+		}
+	}
+	switch r.FormFactor {
+	case "", FormFactorPhone, FormFactorTablet:
+	default:
+		return fmt.Errorf("device.formFactor must be %s or %s, got %q", FormFactorPhone, FormFactorTablet, r.FormFactor)
+	}
+	return nil
+}
+
+// String describes the requirements, e.g. "android, OS >= 14, tablet".
+func (r *DeviceRequirements) String() string {
+	if r == nil {
+		return ""
+	}
+	var parts []string
+	if r.Platform != "" {
+		parts = append(parts, strings.ToLower(r.Platform))
+	}
+	if r.MinOSVersion != "" {
+		parts = append(parts, "OS >= "+r.MinOSVersion)
+	}
+	if r.FormFactor != "" {
+		parts = append(parts, r.FormFactor)
+	}
+	if r.Locale != "" {
+		parts = append(parts, "locale "+r.Locale)
+	}
+	if r.Group != "" {
+		parts = append(parts, "group "+r.Group)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ParseVersion parses a dotted numeric version such as "17.2.1".
+func ParseVersion(s string) ([]int, error) {
+	fields := strings.Split(strings.TrimSpace(s), ".")
+	version := make([]int, len(fields))
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version %q", s)
+		}
+		version[i] = n
+	}
+	return version, nil
+}
+
+// CompareVersions compares dotted numeric versions, returning -1, 0 or 1.
+// Missing components count as 0, so "14" equals "14.0".
+func CompareVersions(a, b []int) int {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,70 @@
+package flow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseFile_DeviceRequirements(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tablet.yaml")
+	content := `appId: com.example
+device:
+  platform: android
+  minOSVersion: "14"
+  formFactor: tablet
+  locale: de_DE
+  group: lab
+---
+- launchApp
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := ParseFileStrict(path)
+	if err != nil {
+		t.Fatalf("ParseFileStrict() error = %v", err)
+	}
+	req := f.Config.Device
+	if req == nil || req.Validate() != nil {
+		t.Fatalf("Device = %+v", req)
+	}
+	if got, want := req.String(), "android, OS >= 14, tablet, locale de_DE, group lab"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestDeviceRequirements_Validate(t *testing.T) {
+	invalid := []DeviceRequirements{
+		{Platform: "windows"},
+		{MinOSVersion: "fourteen"},
+		{MinOSVersion: "14."},
+		{FormFactor: "watch"},
+	}
+	for _, r := range invalid {
+		if err := r.Validate(); err == nil {
+			t.Errorf("Validate(%+v) should fail", r)
+		}
+	}
+	if err := (&DeviceRequirements{Platform: "iOS", MinOSVersion: "17.2", FormFactor: FormFactorPhone}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"14", "14.0", 0},
+		{"17.2", "17.10", -1},
+		{"15", "14.9.9", 1},
+	}
+	for _, tt := range tests {
+		a, _ := ParseVersion(tt.a)
+		b, _ := ParseVersion(tt.b)
+		if got := CompareVersions(a, b); got != tt.want {
+			t.Errorf("CompareVersions(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,536 @@
+package flow
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// stepSchema describes the YAML a step accepts. It mirrors decodeStep: typ is
+// the struct the step's value decodes into.
+type stepSchema struct {
+	typ       reflect.Type
+	scalar    bool       // Accepts a scalar shorthand, e.g. tapOn: "Login"
+	steps     []string   // Keys that hold nested steps, e.g. "commands"
+	exclusive [][]string // Groups of keys; keys from at most one group may be set
+}
+
+// baseStepKeys are the keys holding nested steps that every step accepts.
+var baseStepKeys = []string{"onFailure"}
+
+// stepKeys returns the keys that hold nested steps, the step's own first.
+func (s stepSchema) stepKeys() []string {
+	keys := append([]string(nil), s.steps...)
+	return append(keys, baseStepKeys...)
+}
+
+var (
+	baseStepType = reflect.TypeOf(BaseStep{})
+	selectorType = reflect.TypeOf(Selector{})
+)
+
+// stepSchemas maps each step type to its schema.
+var stepSchemas = map[StepType]stepSchema{
+	StepTapOn:              {typ: reflect.TypeOf(TapOnStep{}), scalar: true},
+	StepDoubleTapOn:        {typ: reflect.TypeOf(DoubleTapOnStep{}), scalar: true},
+	StepLongPressOn:        {typ: reflect.TypeOf(LongPressOnStep{}), scalar: true},
+	StepTapOnPoint:         {typ: reflect.TypeOf(TapOnPointStep{}), exclusive: [][]string{{"x", "y"}, {"point"}}},
+	StepSwipe:              {typ: reflect.TypeOf(SwipeStep{}), scalar: true, exclusive: [][]string{{"direction"}, {"start", "end"}, {"startX", "startY", "endX", "endY"}}},
+	StepScroll:             {typ: reflect.TypeOf(ScrollStep{}), scalar: true},
+	StepScrollUntilVisible: {typ: reflect.TypeOf(ScrollUntilVisibleStep{}), scalar: true},
+	StepBack:               {typ: baseStepType},
+	StepHideKeyboard:       {typ: baseStepType},
+
+	StepInputText:             {typ: reflect.TypeOf(InputTextStep{}), scalar: true},
+	StepInputRandom:           {typ: reflect.TypeOf(InputRandomStep{}), scalar: true},
+	StepInputRandomEmail:      {typ: baseStepType},
+	StepInputRandomNumber:     {typ: baseStepType},
+	StepInputRandomPersonName: {typ: baseStepType},
+	StepInputRandomText:       {typ: baseStepType},
+	StepEraseText:             {typ: reflect.TypeOf(EraseTextStep{}), scalar: true},
+	StepCopyTextFrom:          {typ: reflect.TypeOf(CopyTextFromStep{}), scalar: true},
+	StepPasteText:             {typ: baseStepType},
+	StepSetClipboard:          {typ: reflect.TypeOf(SetClipboardStep{}), scalar: true},
+
+	StepAssertVisible:         {typ: reflect.TypeOf(AssertVisibleStep{}), scalar: true},
+	StepAssertNotVisible:      {typ: reflect.TypeOf(AssertNotVisibleStep{}), scalar: true},
+	StepAssertTrue:            {typ: reflect.TypeOf(AssertTrueStep{}), scalar: true},
+	StepAssertCondition:       {typ: reflect.TypeOf(AssertConditionStep{})},
+	StepAssertNoDefectsWithAI: {typ: reflect.TypeOf(AssertNoDefectsWithAIStep{})},
+	StepAssertWithAI:          {typ: reflect.TypeOf(AssertWithAIStep{}), scalar: true},
+	StepExtractTextWithAI:     {typ: reflect.TypeOf(ExtractTextWithAIStep{})},
+	StepWaitUntil:             {typ: reflect.TypeOf(WaitUntilStep{}), exclusive: [][]string{{"visible"}, {"notVisible"}}},
+
+	StepLaunchApp:      {typ: reflect.TypeOf(LaunchAppStep{}), scalar: true},
+	StepStopApp:        {typ: reflect.TypeOf(StopAppStep{}), scalar: true},
+	StepKillApp:        {typ: reflect.TypeOf(KillAppStep{}), scalar: true},
+	StepClearState:     {typ: reflect.TypeOf(ClearStateStep{}), scalar: true},
+	StepClearKeychain:  {typ: baseStepType},
+	StepSetPermissions: {typ: reflect.TypeOf(SetPermissionsStep{})},
+
+	StepSetLocation:        {typ: reflect.TypeOf(SetLocationStep{})},
+	StepSetOrientation:     {typ: reflect.TypeOf(SetOrientationStep{}), scalar: true},
+	StepSetAirplaneMode:    {typ: reflect.TypeOf(SetAirplaneModeStep{})},
+	StepToggleAirplaneMode: {typ: baseStepType},
+	StepTravel:             {typ: reflect.TypeOf(TravelStep{})},
+	StepOpenLink:           {typ: reflect.TypeOf(OpenLinkStep{}), scalar: true},
+	StepOpenBrowser:        {typ: reflect.TypeOf(OpenBrowserStep{}), scalar: true},
+
+	StepRepeat:     {typ: reflect.TypeOf(RepeatStep{}), steps: []string{"commands"}},
+	StepForEach:    {typ: reflect.TypeOf(ForEachStep{}), steps: []string{"commands"}},
+	StepRetry:      {typ: reflect.TypeOf(RetryStep{}), steps: []string{"commands"}, exclusive: [][]string{{"file"}, {"commands"}}},
+	StepRunFlow:    {typ: reflect.TypeOf(RunFlowStep{}), scalar: true, steps: []string{"commands"}, exclusive: [][]string{{"file"}, {"commands"}}},
+	StepIf:         {typ: reflect.TypeOf(IfStep{}), steps: []string{"then", "else"}},
+	StepRunScript:  {typ: reflect.TypeOf(RunScriptStep{}), scalar: true, exclusive: [][]string{{"script"}, {"file"}}},
+	StepEvalScript: {typ: reflect.TypeOf(EvalScriptStep{}), scalar: true},
+
+	StepTakeScreenshot: {typ: reflect.TypeOf(TakeScreenshotStep{}), scalar: true},
+	StepStartRecording: {typ: reflect.TypeOf(StartRecordingStep{}), scalar: true},
+	StepStopRecording:  {typ: reflect.TypeOf(StopRecordingStep{}), scalar: true},
+	StepAddMedia:       {typ: reflect.TypeOf(AddMediaStep{})},
+
+	StepPressKey:              {typ: reflect.TypeOf(PressKeyStep{}), scalar: true},
+	StepWaitForAnimationToEnd: {typ: reflect.TypeOf(WaitForAnimationToEndStep{})},
+	StepDefineVariables:       {typ: reflect.TypeOf(map[string]string{})},
+}
+
+// configExtraKeys are config keys that are not plain Config fields: hooks
+// hold steps, the others are Maestro settings this runner ignores.
+var configExtraKeys = []string{"onFlowStart", "onFlowComplete", "onFlowFailure", "jsEngine", "properties"}
+
+// yamlField is a key a struct accepts, with the Go type of its value.
+type yamlField struct {
+	Name string
+	Type reflect.Type
+}
+
+// yamlFields lists the keys t decodes, flattening inline structs. A key
+// defined twice keeps its first definition, as BaseStep and Selector share
+// a few.
+func yamlFields(t reflect.Type) []yamlField {
+	var fields []yamlField
+	seen := make(map[string]bool)
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag, ok := f.Tag.Lookup("yaml")
+			if !ok || tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			if opts == "inline" {
+				walk(f.Type)
+				continue
+			}
+			if !seen[name] {
+				seen[name] = true
+				fields = append(fields, yamlField{Name: name, Type: f.Type})
+			}
+		}
+	}
+	walk(t)
+	return fields
+}
+
+// valueFields lists the keys a non-inline value of type t accepts. Selectors
+// decode through selectorRaw, which also accepts "element".
+func valueFields(t reflect.Type) []yamlField {
+	if t == selectorType {
+		return yamlFields(reflect.TypeOf(selectorRaw{}))
+	}
+	return yamlFields(t)
+}
+
+// SchemaError lists every schema problem found in a flow file.
+type SchemaError struct {
+	Path     string
+	Problems []*ParseError
+}
+
+func (e *SchemaError) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		lines[i] = p.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ParseFileStrict parses a flow file like ParseFile, but first checks it
+// against the step schema (see Check).
+func ParseFileStrict(path string) (*Flow, error) {
+	data, err := os.ReadFile(path) //#nosec G304 -- path is user-provided flow file
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return ParseStrict(data, path)
+}
+
+// ParseStrict parses like Parse, but returns a *SchemaError if Check finds
+// unknown keys, wrong value types or conflicting keys.
+func ParseStrict(data []byte, sourcePath string) (*Flow, error) {
+	if problems := Check(data, sourcePath); len(problems) > 0 {
+		return nil, &SchemaError{Path: sourcePath, Problems: problems}
+	}
+	return Parse(data, sourcePath)
+}
+
+// Check validates flow YAML against the step schema and returns every
+// problem found: unknown keys (with a suggestion for likely typos), values
+// of the wrong type, and mutually exclusive keys used together. Parse
+// ignores all of these. YAML syntax errors are left to Parse.
+func Check(data []byte, sourcePath string) []*ParseError {
+	parts, offsets := splitYAMLDocumentsWithOffsets(string(data))
+	c := &checker{path: sourcePath}
+
+	stepsPart := 0
+	if len(parts) > 1 {
+		stepsPart = 1
+		var config yaml.Node
+		if err := yaml.Unmarshal([]byte(parts[0]), &config); err != nil {
+			return nil
+		}
+		shiftLines(&config, offsets[0])
+		if len(config.Content) > 0 {
+			c.checkConfig(config.Content[0])
+		}
+	}
+	if len(parts) > 0 {
+		var steps yaml.Node
+		if err := yaml.Unmarshal([]byte(parts[stepsPart]), &steps); err != nil {
+			return nil
+		}
+		shiftLines(&steps, offsets[stepsPart])
+		if len(steps.Content) > 0 {
+			c.checkSteps(steps.Content[0])
+		}
+	}
+	return c.problems
+}
+
+type checker struct {
+	path     string
+	problems []*ParseError
+}
+
+func (c *checker) addf(node *yaml.Node, format string, args ...interface{}) {
+	c.problems = append(c.problems, &ParseError{
+		Path:    c.path,
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *checker) checkConfig(node *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	fields := yamlFields(reflect.TypeOf(Config{}))
+	known := fieldNames(fields)
+	known = append(known, configExtraKeys...)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch key.Value {
+		case "onFlowStart", "onFlowComplete", "onFlowFailure":
+			c.checkSteps(value)
+			continue
+		case "jsEngine", "properties":
+			continue
+		case "dataset", "params":
+			continue // Dataset and Param report their own errors
+		}
+		f, ok := findField(fields, key.Value)
+		if !ok {
+			c.unknownKey(key, "config", known)
+			continue
+		}
+		c.checkValue(value, f.Type, key.Value)
+	}
+}
+
+func (c *checker) checkSteps(node *yaml.Node) {
+	if isNull(node) {
+		return
+	}
+	if node.Kind != yaml.SequenceNode {
+		c.addf(node, "expected a list of steps")
+		return
+	}
+	for _, item := range node.Content {
+		c.checkStep(item)
+	}
+}
+
+func (c *checker) checkStep(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if !isStepType(node.Value) && FindCommand(node.Value, c.path) == "" {
+			c.unknownStep(node)
+		}
+		return
+	case yaml.MappingNode:
+	default:
+		c.addf(node, "step must be a mapping or command name")
+		return
+	}
+
+	stepType, value := extractStepType(node)
+	if stepType == "" {
+		if len(node.Content) == 2 && FindCommand(node.Content[0].Value, c.path) != "" {
+			return // parseCustomCommand checks the arguments
+		}
+		if len(node.Content) > 0 {
+			c.unknownStep(node.Content[0])
+		}
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		switch {
+		case key.Value == stepType:
+		case isStepType(key.Value):
+			c.addf(key, "%s and %s in one step; put each command in its own list item", stepType, key.Value)
+		default:
+			c.addf(key, "unexpected key %q next to %s (options go inside the command)", key.Value, stepType)
+		}
+	}
+	c.checkStepValue(StepType(stepType), value)
+}
+
+func (c *checker) checkStepValue(stepType StepType, node *yaml.Node) {
+	schema, ok := stepSchemas[stepType]
+	if !ok || isNull(node) {
+		return
+	}
+	name := string(stepType)
+	if schema.typ.Kind() == reflect.Map {
+		c.checkValue(node, schema.typ, name)
+		return
+	}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if !schema.scalar {
+			c.addf(node, "%s expects a mapping of options, not a value", name)
+		}
+		return
+	case yaml.MappingNode:
+	default:
+		c.addf(node, "%s expects a mapping of options", name)
+		return
+	}
+
+	fields := yamlFields(schema.typ)
+	known := fieldNames(fields)
+	known = append(known, schema.stepKeys()...)
+	set := make(map[string]*yaml.Node)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		set[key.Value] = key
+		if contains(schema.stepKeys(), key.Value) {
+			c.checkSteps(value)
+			continue
+		}
+		f, ok := findField(fields, key.Value)
+		if !ok {
+			c.unknownKey(key, name, known)
+			continue
+		}
+		c.checkValue(value, f.Type, key.Value)
+	}
+	c.checkExclusive(name, schema.exclusive, set)
+}
+
+// checkExclusive reports keys from more than one exclusive group.
+func (c *checker) checkExclusive(name string, groups [][]string, set map[string]*yaml.Node) {
+	var first string
+	for _, group := range groups {
+		for _, key := range group {
+			node, ok := set[key]
+			if !ok {
+				continue
+			}
+			if first == "" {
+				first = key
+				break
+			}
+			if !contains(group, first) {
+				c.addf(node, "%s: %q cannot be used together with %q", name, key, first)
+			}
+		}
+	}
+}
+
+// checkValue checks a value against the Go type it decodes into.
+func (c *checker) checkValue(node *yaml.Node, t reflect.Type, key string) {
+	if isNull(node) {
+		return
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		if node.Kind != yaml.ScalarNode {
+			c.addf(node, "%s: expected a string", key)
+		}
+	case reflect.Int, reflect.Int64:
+		if node.Kind != yaml.ScalarNode || node.ShortTag() != "!!int" {
+			c.addf(node, "%s: expected a whole number, got %s", key, describeNode(node))
+		}
+	case reflect.Float64:
+		if node.Kind != yaml.ScalarNode || (node.ShortTag() != "!!int" && node.ShortTag() != "!!float") {
+			c.addf(node, "%s: expected a number, got %s", key, describeNode(node))
+		}
+	case reflect.Bool:
+		if node.Kind != yaml.ScalarNode || node.ShortTag() != "!!bool" {
+			c.addf(node, "%s: expected true or false, got %s", key, describeNode(node))
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			c.addf(node, "%s: expected a list", key)
+			return
+		}
+		for _, item := range node.Content {
+			c.checkValue(item, t.Elem(), key)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			c.addf(node, "%s: expected a mapping", key)
+			return
+		}
+		for i := 1; i < len(node.Content); i += 2 {
+			c.checkValue(node.Content[i], t.Elem(), node.Content[i-1].Value)
+		}
+	case reflect.Struct:
+		if t == selectorType && node.Kind == yaml.ScalarNode {
+			return // Shorthand for text
+		}
+		if node.Kind != yaml.MappingNode {
+			c.addf(node, "%s: expected a mapping", key)
+			return
+		}
+		fields := valueFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, v := node.Content[i], node.Content[i+1]
+			f, ok := findField(fields, k.Value)
+			if !ok {
+				c.unknownKey(k, key, fieldNames(fields))
+				continue
+			}
+			c.checkValue(v, f.Type, k.Value)
+		}
+	}
+}
+
+func (c *checker) unknownKey(key *yaml.Node, context string, known []string) {
+	msg := fmt.Sprintf("unknown key %q in %s", key.Value, context)
+	if s := suggest(key.Value, known); s != "" {
+		msg += fmt.Sprintf(" (did you mean %q?)", s)
+	}
+	c.addf(key, "%s", msg)
+}
+
+func (c *checker) unknownStep(key *yaml.Node) {
+	msg := fmt.Sprintf("unknown step type %q", key.Value)
+	if s := suggest(key.Value, stepTypeNames()); s != "" {
+		msg += fmt.Sprintf(" (did you mean %q?)", s)
+	}
+	c.addf(key, "%s", msg)
+}
+
+// suggest returns the candidate closest to word if it is close enough to be
+// a likely typo, or "".
+func suggest(word string, candidates []string) string {
+	best, bestDist := "", -1
+	lower := strings.ToLower(word)
+	for _, cand := range candidates {
+		d := editDistance(lower, strings.ToLower(cand))
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = cand, d
+		}
+	}
+	limit := len(word) / 3
+	if limit < 2 {
+		limit = 2
+	}
+	if bestDist < 0 || bestDist > limit {
+		return ""
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func stepTypeNames() []string {
+	names := make([]string, 0, len(stepSchemas))
+	for t := range stepSchemas {
+		names = append(names, string(t))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func fieldNames(fields []yamlField) []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return names
+}
+
+func findField(fields []yamlField, name string) (yamlField, bool) {
+	for _, f := range fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return yamlField{}, false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null"
+}
+
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	return fmt.Sprintf("%q", node.Value)
+}
@@ -0,0 +1,213 @@
+package flow
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStepSchemas_MatchStepTypes(t *testing.T) {
+	for stepType, schema := range stepSchemas {
+		if !isStepType(string(stepType)) {
+			t.Errorf("schema for %q, which is not a step type", stepType)
+		}
+		if schema.typ == nil {
+			t.Errorf("schema for %q has no type", stepType)
+		}
+	}
+}
+
+func TestCheck_ValidFlow(t *testing.T) {
+	yaml := `appId: com.example
+name: Login
+tags: [smoke]
+env:
+  USER: alice
+dataset:
+  - USER: bob
+jsEngine: graaljs
+onFlowStart:
+  - launchApp
+onFlowFailure:
+  - takeScreenshot: failure
+---
+- launchApp:
+    appId: com.example
+    clearState: true
+    arguments:
+      debug: true
+- tapOn: "Login"
+- tapOn:
+    text: Pay
+    onFailure:
+      - back
+- tapOn:
+    id: btn
+    below: {text: Header}
+    retryTapIfNoChange: false
+    index: 0
+- inputText: ${USER}
+- eraseText: 5
+- swipe:
+    start: 50%, 80%
+    end: 50%, 20%
+- scrollUntilVisible:
+    element: "Footer"
+    direction: DOWN
+- extendedWaitUntil:
+    visible: Ready
+    timeout: 10000
+- repeat:
+    times: 3
+    while:
+      visible: More
+    commands:
+      - back
+      - tapOn: More
+- runFlow:
+    when:
+      platform: Android
+    commands:
+      - pressKey: ENTER
+- defineVariables:
+    COUNT: 3
+- back:
+- travel: {points: ["0,0"], speed: 50}
+- tapOn:
+    text: Accept
+    when:
+      visible: Cookies
+- if:
+    scriptCondition: ${COUNT > 2}
+    then:
+      - back
+    else:
+      - tapOn: Next
+- forEach:
+    items: [1, 2]
+    as: id
+    continueIf:
+      scriptCondition: ${id == 1}
+    commands:
+      - inputText: ${id}
+`
+	if problems := Check([]byte(yaml), "login.yaml"); len(problems) > 0 {
+		for _, p := range problems {
+			t.Errorf("unexpected problem: %v", p)
+		}
+	}
+}
+
+func TestCheck_Problems(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"unknown selector key", "- tapOn: {txt: Login}", `test.yaml:1:11: unknown key "txt" in tapOn (did you mean "text"?)`},
+		{"misspelled option", "- tapOn:\n    text: OK\n    retryTapIfNotChange: true", `test.yaml:3:5: unknown key "retryTapIfNotChange" in tapOn (did you mean "retryTapIfNoChange"?)`},
+		{"no suggestion", "- tapOn: {banana: 1}", `unknown key "banana" in tapOn`},
+		{"unknown step", "- tapon: Login", `test.yaml:1:3: unknown step type "tapon" (did you mean "tapOn"?)`},
+		{"unknown scalar step", "- hideKeybaord", `unknown step type "hideKeybaord" (did you mean "hideKeyboard"?)`},
+		{"option next to command", "- inputText: hi\n  label: Greet", `test.yaml:2:3: unexpected key "label" next to inputText`},
+		{"two commands", "- back:\n  tapOn: x", `back and tapOn in one step`},
+		{"nested selector", "- assertVisible:\n    below: {idd: x}", `unknown key "idd" in below (did you mean "id"?)`},
+		{"nested command", "- repeat:\n    times: 2\n    commands:\n      - back: {lable: x}", `test.yaml:4:16: unknown key "lable" in back (did you mean "label"?)`},
+		{"hook step", "appId: x\nonFlowComplete:\n  - stopApp: {appid: x}\n---\n- back", `test.yaml:3:15: unknown key "appid" in stopApp (did you mean "appId"?)`},
+		{"config key", "appId: x\ntag: [a]\n---\n- back", `test.yaml:2:1: unknown key "tag" in config (did you mean "tags"?)`},
+		{"int type", "- tapOn:\n    text: x\n    repeat: twice", `repeat: expected a whole number, got "twice"`},
+		{"bool type", "- launchApp:\n    clearState: yes please", `clearState: expected true or false`},
+		{"list type", "- addMedia: {files: img.png}", `files: expected a list`},
+		{"string type", "- inputText: {text: {a: b}}", `text: expected a string`},
+		{"scalar not allowed", "- tapOnPoint: \"50%\"", `tapOnPoint expects a mapping of options, not a value`},
+		{"exclusive swipe", "- swipe:\n    direction: UP\n    start: 10%, 10%", `swipe: "start" cannot be used together with "direction"`},
+		{"exclusive runFlow", "- runFlow:\n    file: a.yaml\n    commands: [back]", `runFlow: "commands" cannot be used together with "file"`},
+		{"exclusive wait", "- extendedWaitUntil: {visible: a, notVisible: b}", `"notVisible" cannot be used together with "visible"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := Check([]byte(tt.yaml), "test.yaml")
+			if len(problems) != 1 {
+				t.Fatalf("got %d problems %v, want 1", len(problems), problems)
+			}
+			if got := problems[0].Error(); !strings.Contains(got, tt.want) {
+				t.Errorf("problem = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	f, err := ParseStrict([]byte("- tapOn: Login\n"), "test.yaml")
+	if err != nil || len(f.Steps) != 1 {
+		t.Fatalf("ParseStrict() = %v, %v", f, err)
+	}
+
+	_, err = ParseStrict([]byte("- tapOn: {txt: Login}\n- back: {lable: x}\n"), "test.yaml")
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) || len(schemaErr.Problems) != 2 {
+		t.Fatalf("ParseStrict() error = %v, want SchemaError with 2 problems", err)
+	}
+	if !strings.Contains(err.Error(), "test.yaml:2:10:") {
+		t.Errorf("error should list each problem with its position: %v", err)
+	}
+
+	// Syntax errors come from Parse
+	if _, err := ParseStrict([]byte("- tapOn: [x\n"), "test.yaml"); err == nil {
+		t.Error("expected parse error for invalid YAML")
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"text", "id", "index", "retryTapIfNoChange"}
+	tests := map[string]string{
+		"txt":                 "text",
+		"Text":                "text",
+		"indx":                "index",
+		"retryTapIfNotChange": "retryTapIfNoChange",
+		"banana":              "",
+	}
+	for word, want := range tests {
+		if got := suggest(word, candidates); got != want {
+			t.Errorf("suggest(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	data, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("JSONSchema() error = %v", err)
+	}
+	var schema struct {
+		Definitions map[string]struct {
+			AnyOf []struct {
+				Properties map[string]interface{} `json:"properties"`
+			} `json:"anyOf"`
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"definitions"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+
+	for stepType := range stepSchemas {
+		if _, ok := schema.Definitions[string(stepType)]; !ok {
+			t.Errorf("no definition for %s", stepType)
+		}
+	}
+	tapOn := schema.Definitions["tapOn"].AnyOf[0].Properties
+	for _, key := range []string{"text", "id", "below", "retryTapIfNoChange", "label"} {
+		if _, ok := tapOn[key]; !ok {
+			t.Errorf("tapOn schema missing %q", key)
+		}
+	}
+	if _, ok := schema.Definitions["repeat"].AnyOf[0].Properties["commands"]; !ok {
+		t.Error("repeat schema missing commands")
+	}
+	if _, ok := schema.Definitions["config"].Properties["onFlowStart"]; !ok {
+		t.Error("config schema missing onFlowStart")
+	}
+}
@@ -1,6 +1,12 @@
 // Package flow handles parsing and representation of Maestro YAML flow files.
 package flow
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
 // StepType represents the type of step.
 type StepType string
 
@@ -58,8 +64,10 @@ const (
 
 	// Flow Control
 	StepRepeat     StepType = "repeat"
+	StepForEach    StepType = "forEach"
 	StepRetry      StepType = "retry"
 	StepRunFlow    StepType = "runFlow"
+	StepIf         StepType = "if"
 	StepRunScript  StepType = "runScript"
 	StepEvalScript StepType = "evalScript"
 
@@ -81,14 +89,23 @@ type Step interface {
 	IsOptional() bool
 	Label() string
 	Describe() string
+	Position() Position
+	Source() string
+	WhenCondition() *Condition
+	Recovery() []Step
 }
 
 // BaseStep contains common fields for all steps.
 type BaseStep struct {
-	StepType  StepType `yaml:"-"`
-	Optional  bool     `yaml:"optional"`
-	StepLabel string   `yaml:"label"`
-	TimeoutMs int      `yaml:"timeout"`
+	StepType  StepType   `yaml:"-"`
+	Optional  bool       `yaml:"optional"`
+	StepLabel string     `yaml:"label"`
+	TimeoutMs int        `yaml:"timeout"`
+	When      *Condition `yaml:"when"` // Run the step only if this holds
+	OnFailure []Step     `yaml:"-"`    // Run when the step fails, before retrying it once
+
+	SourcePos  Position `yaml:"-"` // Where the step appears in its flow file
+	SourceText string   `yaml:"-"` // Original YAML of the step
 }
 
 // Type returns the step type.
@@ -103,6 +120,20 @@ func (b *BaseStep) Label() string { return b.StepLabel }
 // Describe returns a human-readable description.
 func (b *BaseStep) Describe() string { return string(b.StepType) }
 
+// Position returns where the step appears in its flow file.
+func (b *BaseStep) Position() Position { return b.SourcePos }
+
+// Source returns the step's original YAML, or "" if it was not parsed from a file.
+func (b *BaseStep) Source() string { return b.SourceText }
+
+// WhenCondition returns the condition the step runs under, or nil if it always runs.
+func (b *BaseStep) WhenCondition() *Condition { return b.When }
+
+// Recovery returns the steps to run when the step fails, before it is retried once.
+func (b *BaseStep) Recovery() []Step { return b.OnFailure }
+
+func (b *BaseStep) base() *BaseStep { return b }
+
 // ============================================
 // Navigation & Interaction Steps
 // ============================================
@@ -210,6 +241,7 @@ type InputRandomStep struct {
 	BaseStep `yaml:",inline"`
 	DataType string `yaml:"type"` // TEXT, NUMBER, EMAIL, PERSON_NAME, etc.
 	Length   int    `yaml:"length"`
+	Locale   string `yaml:"locale"` // For names, emails, phone numbers and addresses (e.g. de_DE)
 }
 
 // EraseTextStep erases text.
@@ -265,6 +297,24 @@ type Condition struct {
 	Platform   string    `yaml:"platform"`
 }
 
+// Describe returns a human-readable description of the condition.
+func (c Condition) Describe() string {
+	var parts []string
+	if c.Visible != nil {
+		parts = append(parts, "visible "+c.Visible.DescribeQuoted())
+	}
+	if c.NotVisible != nil {
+		parts = append(parts, "notVisible "+c.NotVisible.DescribeQuoted())
+	}
+	if c.Script != "" {
+		parts = append(parts, c.Script)
+	}
+	if c.Platform != "" {
+		parts = append(parts, "platform "+c.Platform)
+	}
+	return strings.Join(parts, ", ")
+}
+
 // AssertConditionStep asserts a condition.
 type AssertConditionStep struct {
 	BaseStep  `yaml:",inline"`
@@ -405,6 +455,34 @@ type RepeatStep struct {
 	Steps    []Step    `yaml:"-"`
 }
 
+// ForEachStep runs its steps once per item. Items is a YAML list, or a
+// string: a ${...} expression returning an array, or comma-separated values.
+type ForEachStep struct {
+	BaseStep   `yaml:",inline"`
+	Items      any        `yaml:"items"`
+	As         string     `yaml:"as"`         // Item variable (default "item")
+	IndexAs    string     `yaml:"indexAs"`    // Index variable (default "index")
+	BreakIf    *Condition `yaml:"breakIf"`    // Checked before each item; stops the loop
+	ContinueIf *Condition `yaml:"continueIf"` // Checked before each item; skips it
+	Steps      []Step     `yaml:"-"`
+}
+
+// ItemVar returns the name of the variable bound to the current item.
+func (s *ForEachStep) ItemVar() string {
+	if s.As != "" {
+		return s.As
+	}
+	return "item"
+}
+
+// IndexVar returns the name of the variable bound to the current index.
+func (s *ForEachStep) IndexVar() string {
+	if s.IndexAs != "" {
+		return s.IndexAs
+	}
+	return "index"
+}
+
 // RetryStep retries steps on failure.
 type RetryStep struct {
 	BaseStep   `yaml:",inline"`
@@ -419,10 +497,26 @@ type RunFlowStep struct {
 	BaseStep `yaml:",inline"`
 	File     string            `yaml:"file"`
 	Steps    []Step            `yaml:"-"` // Inline steps
-	When     *Condition        `yaml:"when"`
 	Env      map[string]string `yaml:"env"`
 }
 
+// IfStep runs Then if its condition holds and Else otherwise.
+type IfStep struct {
+	BaseStep  `yaml:",inline"`
+	Condition Condition `yaml:",inline"`
+	Then      []Step    `yaml:"-"`
+	Else      []Step    `yaml:"-"`
+}
+
+// CustomCommandStep runs a custom command: a flow in a commands/ directory,
+// invoked by its file name with typed parameters (see FindCommand).
+type CustomCommandStep struct {
+	BaseStep `yaml:",inline"`
+	Name     string            `yaml:"-"`
+	File     string            `yaml:"-"` // Definition file
+	Params   map[string]string `yaml:"-"` // Arguments, with defaults applied
+}
+
 // RunScriptStep runs a script.
 type RunScriptStep struct {
 	BaseStep `yaml:",inline"`
@@ -576,6 +670,42 @@ func (s *RunFlowStep) Describe() string {
 	return "runFlow"
 }
 
+// Describe returns a human-readable description of the forEach step.
+func (s *ForEachStep) Describe() string {
+	if list, ok := s.Items.([]any); ok {
+		return fmt.Sprintf("forEach: %d items", len(list))
+	}
+	if expr, ok := s.Items.(string); ok && expr != "" {
+		return "forEach: " + expr
+	}
+	return "forEach"
+}
+
+// Describe returns a human-readable description of the if step.
+func (s *IfStep) Describe() string {
+	if cond := s.Condition.Describe(); cond != "" {
+		return "if: " + cond
+	}
+	return "if"
+}
+
+// Describe returns a human-readable description of the custom command step.
+func (s *CustomCommandStep) Describe() string {
+	if len(s.Params) == 0 {
+		return s.Name
+	}
+	names := make([]string, 0, len(s.Params))
+	for name := range s.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	args := make([]string, len(names))
+	for i, name := range names {
+		args[i] = name + "=" + s.Params[name]
+	}
+	return s.Name + ": " + strings.Join(args, ", ")
+}
+
 // Describe returns a human-readable description of the press key step.
 func (s *PressKeyStep) Describe() string {
 	return "pressKey: " + s.Key
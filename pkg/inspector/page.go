@@ -0,0 +1,208 @@
+package inspector
+
+// pageHTML is the single-page inspector UI. It polls the API, draws element
+// bounds over the screenshot, asks for suggestions on click and highlights
+// the matches of the selector typed into the query box.
+const pageHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>maestro-runner inspect</title>
+<style>
+  * { box-sizing: border-box; }
+  body { margin: 0; font: 13px -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; color: #1f2328; background: #f6f8fa; display: flex; height: 100vh; }
+  #left { flex: 0 0 auto; padding: 12px; overflow: auto; }
+  #right { flex: 1 1 auto; padding: 12px; overflow: auto; border-left: 1px solid #d0d7de; background: #fff; }
+  #screen { position: relative; display: inline-block; cursor: crosshair; }
+  #screen img { display: block; max-height: calc(100vh - 60px); }
+  #overlay { position: absolute; inset: 0; }
+  .box { position: absolute; border: 1px solid rgba(9, 105, 218, 0.25); pointer-events: none; }
+  .box.hover { border: 2px solid #0969da; background: rgba(9, 105, 218, 0.08); }
+  .box.selected { border: 2px solid #8250df; background: rgba(130, 80, 223, 0.12); }
+  .box.match { border: 2px solid #bf8700; background: rgba(191, 135, 0, 0.12); }
+  .box.target { border: 2px solid #cf222e; background: rgba(207, 34, 46, 0.15); }
+  .toolbar { margin-bottom: 8px; display: flex; gap: 8px; align-items: center; }
+  h2 { font-size: 14px; margin: 16px 0 8px; }
+  textarea { width: 100%; height: 90px; font: 12px ui-monospace, SFMono-Regular, Menlo, monospace; }
+  button { font: inherit; padding: 4px 10px; }
+  pre { margin: 0; font: 12px ui-monospace, SFMono-Regular, Menlo, monospace; white-space: pre-wrap; }
+  .suggestion { border: 1px solid #d0d7de; border-radius: 6px; padding: 6px 8px; margin-bottom: 6px; cursor: pointer; }
+  .suggestion:hover { background: #f6f8fa; }
+  .tag { display: inline-block; font-size: 11px; padding: 0 6px; border-radius: 10px; background: #ddf4ff; margin-right: 4px; }
+  .tag.warn { background: #fff8c5; }
+  table { border-collapse: collapse; }
+  td { padding: 2px 8px 2px 0; vertical-align: top; }
+  td:first-child { color: #656d76; }
+  #status { color: #656d76; }
+  #error { color: #cf222e; }
+</style>
+</head>
+<body>
+<div id="left">
+  <div class="toolbar">
+    <button id="refresh">Refresh</button>
+    <label><input type="checkbox" id="auto"> Auto-refresh</label>
+    <span id="status"></span>
+  </div>
+  <div id="screen"><img id="shot" alt="screenshot"><div id="overlay"></div></div>
+</div>
+<div id="right">
+  <div id="error"></div>
+  <h2>Selector</h2>
+  <textarea id="query" placeholder='id: "login_button"&#10;or&#10;text: "Sign in"&#10;below:&#10;  text: "Password"'></textarea>
+  <div class="toolbar"><button id="match">Highlight matches</button><span id="matchInfo"></span></div>
+  <h2>Element</h2>
+  <div id="element">Click an element on the screenshot.</div>
+  <h2>Suggested selectors</h2>
+  <div id="suggestions"></div>
+</div>
+<script>
+(function () {
+  var state = { elements: [], width: 0, height: 0, selected: -1, matches: [], target: -1, hover: -1 };
+  var shot = document.getElementById('shot');
+  var overlay = document.getElementById('overlay');
+  var timer = null;
+
+  function $(id) { return document.getElementById(id); }
+  function esc(s) { return String(s).replace(/[&<>"]/g, function (c) { return { '&': '&amp;', '<': '&lt;', '>': '&gt;', '"': '&quot;' }[c]; }); }
+  function showError(msg) { $('error').textContent = msg || ''; }
+
+  function api(path, opts) {
+    return fetch(path, opts).then(function (r) {
+      return r.json().then(function (body) {
+        if (!r.ok) { throw new Error(body.error || r.statusText); }
+        return body;
+      });
+    });
+  }
+
+  function refresh() {
+    $('status').textContent = 'Loading…';
+    var img = new Image();
+    var imgLoaded = new Promise(function (resolve, reject) {
+      img.onload = resolve;
+      img.onerror = function () { reject(new Error('screenshot failed')); };
+    });
+    img.src = '/api/screenshot?t=' + Date.now();
+    Promise.all([api('/api/hierarchy'), imgLoaded]).then(function (res) {
+      var h = res[0];
+      shot.src = img.src;
+      state.elements = h.elements;
+      state.width = h.width;
+      state.height = h.height;
+      state.selected = -1;
+      state.hover = -1;
+      $('status').textContent = (h.device || '') + ' · ' + h.elements.length + ' elements';
+      showError('');
+      if ($('query').value.trim()) { runMatch(); } else { draw(); }
+    }).catch(function (e) { $('status').textContent = ''; showError(e.message); });
+  }
+
+  function scale() {
+    if (!state.width || !shot.clientWidth) { return 1; }
+    return shot.clientWidth / state.width;
+  }
+
+  function box(i, cls) {
+    var e = state.elements[i], s = scale(), b = e.bounds;
+    var d = document.createElement('div');
+    d.className = 'box ' + cls;
+    d.style.left = (b.x * s) + 'px';
+    d.style.top = (b.y * s) + 'px';
+    d.style.width = (b.width * s) + 'px';
+    d.style.height = (b.height * s) + 'px';
+    overlay.appendChild(d);
+  }
+
+  function draw() {
+    overlay.innerHTML = '';
+    state.elements.forEach(function (e, i) {
+      if (e.visible && e.bounds.width > 0 && e.bounds.height > 0) { box(i, ''); }
+    });
+    state.matches.forEach(function (i) { if (i !== state.target) { box(i, 'match'); } });
+    if (state.target >= 0) { box(state.target, 'target'); }
+    if (state.selected >= 0) { box(state.selected, 'selected'); }
+    if (state.hover >= 0 && state.hover !== state.selected) { box(state.hover, 'hover'); }
+  }
+
+  // elementAt returns the smallest visible element containing the point.
+  function elementAt(ev) {
+    var rect = shot.getBoundingClientRect(), s = scale();
+    var x = (ev.clientX - rect.left) / s, y = (ev.clientY - rect.top) / s;
+    var best = -1, bestArea = Infinity;
+    state.elements.forEach(function (e, i) {
+      var b = e.bounds, area = b.width * b.height;
+      if (!e.visible || area === 0) { return; }
+      if (x >= b.x && x < b.x + b.width && y >= b.y && y < b.y + b.height && area <= bestArea) {
+        best = i; bestArea = area;
+      }
+    });
+    return best;
+  }
+
+  function showElement(e) {
+    var rows = [['class', e.class], ['id', e.id], ['text', e.text], ['accessibility', e.accessibilityLabel],
+      ['hint', e.hintText], ['bounds', e.bounds.x + ',' + e.bounds.y + ' ' + e.bounds.width + '×' + e.bounds.height],
+      ['clickable', e.clickable], ['enabled', e.enabled]];
+    $('element').innerHTML = '<table>' + rows.filter(function (r) { return r[1] !== undefined && r[1] !== ''; })
+      .map(function (r) { return '<tr><td>' + r[0] + '</td><td>' + esc(r[1]) + '</td></tr>'; }).join('') + '</table>';
+  }
+
+  function suggest(i) {
+    state.selected = i;
+    draw();
+    showElement(state.elements[i]);
+    $('suggestions').textContent = 'Loading…';
+    api('/api/suggest?index=' + i).then(function (res) {
+      if (!res.suggestions.length) {
+        $('suggestions').textContent = 'No selector resolves to this element. Use a point tap instead.';
+        return;
+      }
+      $('suggestions').innerHTML = '';
+      res.suggestions.forEach(function (s) {
+        var d = document.createElement('div');
+        d.className = 'suggestion';
+        d.title = 'Click to try this selector';
+        d.innerHTML = '<span class="tag">' + s.kind + '</span>' +
+          (s.unique ? '' : '<span class="tag warn">not unique</span>') + '<pre>' + esc(s.yaml) + '</pre>';
+        d.onclick = function () { $('query').value = s.yaml; runMatch(); };
+        $('suggestions').appendChild(d);
+      });
+    }).catch(function (e) { $('suggestions').textContent = ''; showError(e.message); });
+  }
+
+  function runMatch() {
+    var q = $('query').value;
+    if (!q.trim()) { state.matches = []; state.target = -1; $('matchInfo').textContent = ''; draw(); return; }
+    api('/api/match', { method: 'POST', body: q }).then(function (m) {
+      state.matches = m.matches;
+      state.target = m.target;
+      $('matchInfo').textContent = m.matches.length + ' match' + (m.matches.length === 1 ? '' : 'es') +
+        (m.target >= 0 ? '' : ' · no target');
+      showError('');
+      draw();
+    }).catch(function (e) { $('matchInfo').textContent = ''; showError(e.message); });
+  }
+
+  $('screen').addEventListener('mousemove', function (ev) {
+    var i = elementAt(ev);
+    if (i !== state.hover) { state.hover = i; draw(); }
+  });
+  $('screen').addEventListener('mouseleave', function () { state.hover = -1; draw(); });
+  $('screen').addEventListener('click', function (ev) { var i = elementAt(ev); if (i >= 0) { suggest(i); } });
+  $('match').addEventListener('click', runMatch);
+  $('query').addEventListener('keydown', function (ev) {
+    if (ev.key === 'Enter' && (ev.metaKey || ev.ctrlKey)) { runMatch(); }
+  });
+  $('refresh').addEventListener('click', refresh);
+  $('auto').addEventListener('change', function () {
+    clearInterval(timer);
+    if (this.checked) { timer = setInterval(refresh, 3000); }
+  });
+  window.addEventListener('resize', draw);
+  refresh();
+})();
+</script>
+</body>
+</html>
+`
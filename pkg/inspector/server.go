@@ -0,0 +1,248 @@
+package inspector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/core"
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/devicelab-dev/maestro-runner/pkg/logger"
+)
+
+// maxSelectorSize limits the size of a selector posted to /api/match.
+const maxSelectorSize = 64 * 1024
+
+// Inspector serves the inspector UI for one driver.
+// Driver calls are serialized; drivers are not safe for concurrent use.
+type Inspector struct {
+	driver    core.Driver
+	inspector core.Inspector
+
+	mu   sync.Mutex
+	snap core.InspectSnapshot
+}
+
+// New creates an inspector for driver. The driver must implement core.Inspector.
+func New(driver core.Driver) (*Inspector, error) {
+	insp, ok := driver.(core.Inspector)
+	if !ok {
+		return nil, fmt.Errorf("driver %T does not support inspect", driver)
+	}
+	return &Inspector{driver: driver, inspector: insp}, nil
+}
+
+// hierarchyResponse is the body of GET /api/hierarchy.
+type hierarchyResponse struct {
+	Platform string                `json:"platform,omitempty"`
+	Device   string                `json:"device,omitempty"`
+	Width    int                   `json:"width"`  // Screen width in element coordinates
+	Height   int                   `json:"height"` // Screen height in element coordinates
+	Elements []core.InspectElement `json:"elements"`
+}
+
+// suggestResponse is the body of GET /api/suggest.
+type suggestResponse struct {
+	Element     core.InspectElement `json:"element"`
+	Suggestions []Suggestion        `json:"suggestions"`
+}
+
+// Handler returns the HTTP handler for the inspector UI and its API.
+func (i *Inspector) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", i.handleIndex)
+	mux.HandleFunc("/api/screenshot", i.handleScreenshot)
+	mux.HandleFunc("/api/hierarchy", i.handleHierarchy)
+	mux.HandleFunc("/api/suggest", i.handleSuggest)
+	mux.HandleFunc("/api/match", i.handleMatch)
+	return mux
+}
+
+func (i *Inspector) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	io.WriteString(w, pageHTML)
+}
+
+func (i *Inspector) handleScreenshot(w http.ResponseWriter, r *http.Request) {
+	i.mu.Lock()
+	data, err := i.driver.Screenshot()
+	i.mu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusBadGateway, fmt.Errorf("screenshot: %w", err))
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(data)
+}
+
+// handleHierarchy captures a new snapshot; later suggest/match calls resolve
+// against it so they agree with what the page is showing.
+func (i *Inspector) handleHierarchy(w http.ResponseWriter, r *http.Request) {
+	i.mu.Lock()
+	snap, err := i.inspector.Inspect()
+	if err == nil {
+		i.snap = snap
+	}
+	i.mu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusBadGateway, fmt.Errorf("inspect: %w", err))
+		return
+	}
+
+	elements := snap.Elements()
+	resp := hierarchyResponse{Elements: elements}
+	for _, e := range elements {
+		resp.Width = max(resp.Width, e.Bounds.X+e.Bounds.Width)
+		resp.Height = max(resp.Height, e.Bounds.Y+e.Bounds.Height)
+	}
+	if info := i.driver.GetPlatformInfo(); info != nil {
+		resp.Platform = info.Platform
+		resp.Device = info.DeviceName
+	}
+	writeJSON(w, resp)
+}
+
+func (i *Inspector) handleSuggest(w http.ResponseWriter, r *http.Request) {
+	index, err := strconv.Atoi(r.URL.Query().Get("index"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid index: %q", r.URL.Query().Get("index")))
+		return
+	}
+
+	snap, err := i.snapshot()
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	suggestions, err := Suggest(snap, index)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if suggestions == nil {
+		suggestions = []Suggestion{}
+	}
+	writeJSON(w, suggestResponse{Element: snap.Elements()[index], Suggestions: suggestions})
+}
+
+// handleMatch resolves a selector posted as YAML (the body of a tapOn step,
+// e.g. `id: "login"` or just `Login`) and returns the matching elements.
+func (i *Inspector) handleMatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, errors.New("use POST"))
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSelectorSize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	sel, err := ParseSelector(string(body))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	snap, err := i.snapshot()
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	match, err := snap.Match(sel)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, match)
+}
+
+// snapshot returns the last captured snapshot, capturing one if needed.
+func (i *Inspector) snapshot() (core.InspectSnapshot, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.snap == nil {
+		snap, err := i.inspector.Inspect()
+		if err != nil {
+			return nil, fmt.Errorf("inspect: %w", err)
+		}
+		i.snap = snap
+	}
+	return i.snap, nil
+}
+
+// ParseSelector parses selector YAML as written in a flow step.
+// A plain scalar is a text selector, as in `tapOn: Login`.
+func ParseSelector(text string) (flow.Selector, error) {
+	var sel flow.Selector
+	if err := yaml.Unmarshal([]byte(text), &sel); err != nil {
+		return sel, fmt.Errorf("invalid selector: %w", err)
+	}
+	if sel.IsEmpty() {
+		return sel, errors.New("selector is empty")
+	}
+	return sel, nil
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Warn("inspector: failed to write response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// Server serves an Inspector over HTTP.
+type Server struct {
+	srv      *http.Server
+	listener net.Listener
+}
+
+// Serve starts serving the inspector on addr (e.g. "127.0.0.1:9999"). It
+// returns once the address is bound.
+func Serve(i *Inspector, addr string) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", addr, err)
+	}
+
+	s := &Server{
+		srv:      &http.Server{Handler: i.Handler(), ReadHeaderTimeout: 10 * time.Second},
+		listener: ln,
+	}
+	go func() {
+		if err := s.srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Inspector server stopped: %v", err)
+		}
+	}()
+	return s, nil
+}
+
+// Addr returns the bound address (useful when addr used port 0).
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	return s.srv.Close()
+}
@@ -0,0 +1,183 @@
+package inspector
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/core"
+	"github.com/devicelab-dev/maestro-runner/pkg/driver/mock"
+)
+
+type inspectableDriver struct {
+	*mock.Driver
+	snap     *fakeSnapshot
+	inspects int
+}
+
+func (d *inspectableDriver) Inspect() (core.InspectSnapshot, error) {
+	d.inspects++
+	return d.snap, nil
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, *inspectableDriver) {
+	t.Helper()
+	driver := &inspectableDriver{
+		Driver: mock.New(mock.Config{Platform: "android"}),
+		snap: &fakeSnapshot{elements: []core.InspectElement{
+			row(0, "title", "Settings", 0),
+			row(1, "save", "Save", 100),
+		}},
+	}
+	insp, err := New(driver)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	srv := httptest.NewServer(insp.Handler())
+	t.Cleanup(srv.Close)
+	return srv, driver
+}
+
+func TestNew_RequiresInspector(t *testing.T) {
+	if _, err := New(mock.New(mock.Config{})); err == nil {
+		t.Error("expected error for driver without Inspect support")
+	}
+}
+
+func TestServer_Index(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	resp, err := http.Get(srv.URL + "/")
+	if err != nil {
+		t.Fatalf("GET / failed: %v", err)
+	}
+	resp.Body.Close()
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		t.Errorf("Content-Type = %q", resp.Header.Get("Content-Type"))
+	}
+
+	resp, _ = http.Get(srv.URL + "/nope")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestServer_Screenshot(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	resp, err := http.Get(srv.URL + "/api/screenshot")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "image/png" {
+		t.Errorf("Content-Type = %q", resp.Header.Get("Content-Type"))
+	}
+}
+
+func TestServer_Hierarchy(t *testing.T) {
+	srv, driver := newTestServer(t)
+
+	resp, err := http.Get(srv.URL + "/api/hierarchy")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var h hierarchyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&h); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if len(h.Elements) != 2 || h.Width != 100 || h.Height != 140 || h.Platform != "android" {
+		t.Errorf("unexpected hierarchy: %+v", h)
+	}
+	if driver.inspects != 1 {
+		t.Errorf("inspects = %d, want 1", driver.inspects)
+	}
+}
+
+func TestServer_Suggest(t *testing.T) {
+	srv, driver := newTestServer(t)
+
+	resp, err := http.Get(srv.URL + "/api/suggest?index=1")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var s suggestResponse
+	if err := json.NewDecoder(resp.Body).Decode(&s); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if s.Element.ID != "save" || len(s.Suggestions) == 0 || s.Suggestions[0].YAML != `id: "save"` {
+		t.Errorf("unexpected suggestions: %+v", s)
+	}
+
+	// Snapshot is reused until the hierarchy is refreshed
+	resp2, _ := http.Get(srv.URL + "/api/suggest?index=0")
+	resp2.Body.Close()
+	if driver.inspects != 1 {
+		t.Errorf("inspects = %d, want 1", driver.inspects)
+	}
+
+	resp3, _ := http.Get(srv.URL + "/api/suggest?index=x")
+	resp3.Body.Close()
+	if resp3.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", resp3.StatusCode)
+	}
+}
+
+func TestServer_Match(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	resp, err := http.Post(srv.URL+"/api/match", "text/yaml", strings.NewReader("Save"))
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var m core.InspectMatch
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if m.Target != 1 || len(m.Matches) != 1 {
+		t.Errorf("unexpected match: %+v", m)
+	}
+
+	for _, body := range []string{"", "id: [unclosed"} {
+		resp, _ := http.Post(srv.URL+"/api/match", "text/yaml", strings.NewReader(body))
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("body %q: status = %d, want 400", body, resp.StatusCode)
+		}
+	}
+
+	resp, _ = http.Get(srv.URL + "/api/match")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want 405", resp.StatusCode)
+	}
+}
+
+func TestServe(t *testing.T) {
+	driver := &inspectableDriver{Driver: mock.New(mock.Config{}), snap: &fakeSnapshot{}}
+	insp, _ := New(driver)
+
+	srv, err := Serve(insp, "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+	defer srv.Close()
+
+	resp, err := http.Get("http://" + srv.Addr() + "/")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,310 @@
+// Package inspector implements the interactive element inspector: a local web
+// UI that shows a live device screenshot with the element hierarchy overlaid,
+// suggests selectors for clicked elements and highlights selector matches.
+package inspector
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/core"
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+)
+
+// Suggestion kinds, in order of preference.
+const (
+	KindID       = "id"
+	KindText     = "text"
+	KindRelative = "relative"
+	KindIndex    = "index"
+)
+
+// maxAnchors limits how many nearby elements are tried as relative anchors.
+const maxAnchors = 10
+
+// Suggestion is a selector that resolves to a given element.
+type Suggestion struct {
+	Kind     string        `json:"kind"`
+	Selector flow.Selector `json:"-"`
+	YAML     string        `json:"yaml"`
+	// Unique is true when the selector matches exactly one element, so it does
+	// not depend on match ordering and survives layout changes better.
+	Unique bool `json:"unique"`
+}
+
+// Suggest returns selectors that resolve to the element at index, most stable
+// first: id, then text, then relative to a nearby uniquely identifiable
+// element, then a non-unique selector with an index. Every suggestion is
+// verified against the snapshot with the driver's own matching logic.
+func Suggest(snap core.InspectSnapshot, index int) ([]Suggestion, error) {
+	elements := snap.Elements()
+	if index < 0 || index >= len(elements) {
+		return nil, fmt.Errorf("element index %d out of range (0-%d)", index, len(elements)-1)
+	}
+	target := elements[index]
+
+	var suggestions []Suggestion
+	seen := make(map[string]bool)
+	add := func(kind string, sel flow.Selector, unique bool) {
+		y := SelectorYAML(sel)
+		if seen[y] {
+			return
+		}
+		seen[y] = true
+		suggestions = append(suggestions, Suggestion{Kind: kind, Selector: sel, YAML: y, Unique: unique})
+	}
+
+	// Own selectors (id, then text-like attributes), then those of
+	// descendants, since a tap on a child lands on its clickable ancestor.
+	// Ambiguous ones become bases for relative and index selectors.
+	var bases []candidate
+	for _, c := range selectorsFor(elements, index) {
+		m, err := snap.Match(c.sel)
+		if err != nil || !contains(m.Matches, c.owner) {
+			continue
+		}
+		unique := len(m.Matches) == 1
+		if actsOn(m, target) {
+			add(c.kind, c.sel, unique)
+		}
+		if !unique {
+			bases = append(bases, c)
+		}
+	}
+
+	// Relative selectors for ambiguous own selectors
+	if len(bases) > 0 && !hasUnique(suggestions) {
+		for _, anchor := range nearestAnchors(snap, elements, target) {
+			for _, base := range bases {
+				for _, sel := range relativeTo(base.sel, anchor, elements[base.owner].Bounds) {
+					if m, ok := resolves(snap, sel, target); ok {
+						add(KindRelative, sel, len(m.Matches) == 1)
+					}
+				}
+			}
+		}
+	}
+
+	// Index fallback: position of the element among the base selector's matches
+	for _, base := range bases {
+		m, err := snap.Match(base.sel)
+		if err != nil {
+			continue
+		}
+		for pos, i := range m.Matches {
+			if i != base.owner {
+				continue
+			}
+			sel := base.sel
+			sel.Index = strconv.Itoa(pos)
+			if _, ok := resolves(snap, sel, target); ok {
+				add(KindIndex, sel, false)
+			}
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Unique && !suggestions[j].Unique
+	})
+	return suggestions, nil
+}
+
+// candidate is a selector built from the attributes of element owner.
+type candidate struct {
+	kind  string
+	sel   flow.Selector
+	owner int
+}
+
+// selectorsFor lists the selectors of the element at index followed by those
+// of its descendants.
+func selectorsFor(elements []core.InspectElement, index int) []candidate {
+	result := ownSelectors(elements[index])
+	for _, e := range elements {
+		if e.Index != index && isDescendant(elements, e, index) {
+			result = append(result, ownSelectors(e)...)
+		}
+	}
+	return result
+}
+
+func isDescendant(elements []core.InspectElement, e core.InspectElement, ancestor int) bool {
+	for p := e.Parent; p >= 0 && p < len(elements); p = elements[p].Parent {
+		if p == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// ownSelectors lists the selectors built from an element's own attributes.
+// Text values that contain regex metacharacters are also tried escaped,
+// since text selectors may be interpreted as patterns.
+func ownSelectors(e core.InspectElement) []candidate {
+	var result []candidate
+	if e.ID != "" {
+		result = append(result, candidate{KindID, flow.Selector{ID: e.ID}, e.Index})
+	}
+	for _, text := range []string{e.Text, e.AccessibilityLabel, e.HintText} {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		result = append(result, candidate{KindText, flow.Selector{Text: text}, e.Index})
+		if quoted := regexp.QuoteMeta(text); quoted != text {
+			result = append(result, candidate{KindText, flow.Selector{Text: quoted}, e.Index})
+		}
+	}
+	return result
+}
+
+// resolves reports whether sel would act on target.
+func resolves(snap core.InspectSnapshot, sel flow.Selector, target core.InspectElement) (*core.InspectMatch, bool) {
+	m, err := snap.Match(sel)
+	if err != nil {
+		return nil, false
+	}
+	return m, actsOn(m, target)
+}
+
+// actsOn reports whether a step using the match would act on target: either
+// the selected element is target itself, or the tap lands on target's bounds
+// (target is the clickable ancestor of the selected element).
+func actsOn(m *core.InspectMatch, target core.InspectElement) bool {
+	if m.Target < 0 {
+		return false
+	}
+	if m.Target == target.Index {
+		return true
+	}
+	return m.TapBounds != nil && *m.TapBounds == target.Bounds
+}
+
+func contains(indices []int, index int) bool {
+	for _, i := range indices {
+		if i == index {
+			return true
+		}
+	}
+	return false
+}
+
+func hasUnique(suggestions []Suggestion) bool {
+	for _, s := range suggestions {
+		if s.Unique {
+			return true
+		}
+	}
+	return false
+}
+
+type anchor struct {
+	sel    flow.Selector
+	bounds core.Bounds
+}
+
+// nearestAnchors returns up to maxAnchors elements closest to target that can
+// be identified by a unique id or text selector.
+func nearestAnchors(snap core.InspectSnapshot, elements []core.InspectElement, target core.InspectElement) []anchor {
+	tx, ty := target.Bounds.Center()
+
+	others := make([]core.InspectElement, 0, len(elements))
+	for _, e := range elements {
+		if e.Index == target.Index || e.Bounds.Width == 0 || e.Bounds.Height == 0 {
+			continue
+		}
+		others = append(others, e)
+	}
+	distance := func(e core.InspectElement) int {
+		x, y := e.Bounds.Center()
+		return abs(x-tx) + abs(y-ty)
+	}
+	sort.SliceStable(others, func(i, j int) bool {
+		return distance(others[i]) < distance(others[j])
+	})
+
+	var result []anchor
+	for _, e := range others {
+		if len(result) >= maxAnchors {
+			break
+		}
+		for _, c := range ownSelectors(e) {
+			m, err := snap.Match(c.sel)
+			if err != nil || len(m.Matches) != 1 || m.Target != e.Index {
+				continue
+			}
+			result = append(result, anchor{sel: c.sel, bounds: e.Bounds})
+			break
+		}
+	}
+	return result
+}
+
+// relativeTo builds base selectors positioned relative to anchor, for each
+// direction in which target lies entirely on one side of the anchor.
+func relativeTo(base flow.Selector, a anchor, target core.Bounds) []flow.Selector {
+	var result []flow.Selector
+	with := func(set func(*flow.Selector, *flow.Selector)) {
+		sel := base
+		anchorSel := a.sel
+		set(&sel, &anchorSel)
+		result = append(result, sel)
+	}
+	if target.Y >= a.bounds.Y+a.bounds.Height {
+		with(func(s, as *flow.Selector) { s.Below = as })
+	}
+	if target.Y+target.Height <= a.bounds.Y {
+		with(func(s, as *flow.Selector) { s.Above = as })
+	}
+	if target.X+target.Width <= a.bounds.X {
+		with(func(s, as *flow.Selector) { s.LeftOf = as })
+	}
+	if target.X >= a.bounds.X+a.bounds.Width {
+		with(func(s, as *flow.Selector) { s.RightOf = as })
+	}
+	return result
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// SelectorYAML renders the fields of a selector that the inspector produces,
+// in the form used inside a flow step (e.g. under tapOn:).
+func SelectorYAML(sel flow.Selector) string {
+	var b strings.Builder
+	writeSelectorYAML(&b, sel, "")
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func writeSelectorYAML(b *strings.Builder, sel flow.Selector, indent string) {
+	if sel.ID != "" {
+		fmt.Fprintf(b, "%sid: %s\n", indent, strconv.Quote(sel.ID))
+	}
+	if sel.Text != "" {
+		fmt.Fprintf(b, "%stext: %s\n", indent, strconv.Quote(sel.Text))
+	}
+	if sel.Index != "" {
+		fmt.Fprintf(b, "%sindex: %s\n", indent, sel.Index)
+	}
+	for _, rel := range []struct {
+		key string
+		sel *flow.Selector
+	}{
+		{"below", sel.Below},
+		{"above", sel.Above},
+		{"leftOf", sel.LeftOf},
+		{"rightOf", sel.RightOf},
+		{"childOf", sel.ChildOf},
+	} {
+		if rel.sel != nil {
+			fmt.Fprintf(b, "%s%s:\n", indent, rel.key)
+			writeSelectorYAML(b, *rel.sel, indent+"  ")
+		}
+	}
+}
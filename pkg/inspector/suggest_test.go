@@ -0,0 +1,182 @@
+package inspector
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/core"
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+)
+
+// fakeSnapshot matches id/text exactly and supports below/above, picking the
+// Index-th match (first by default) and tapping its nearest clickable
+// ancestor-or-self.
+type fakeSnapshot struct {
+	elements []core.InspectElement
+}
+
+func (s *fakeSnapshot) Elements() []core.InspectElement { return s.elements }
+
+func (s *fakeSnapshot) Match(sel flow.Selector) (*core.InspectMatch, error) {
+	m := &core.InspectMatch{Matches: []int{}, Target: -1}
+	var anchor *core.InspectElement
+	for _, rel := range []*flow.Selector{sel.Below, sel.Above} {
+		if rel != nil {
+			am, _ := s.Match(*rel)
+			if am.Target < 0 {
+				return m, nil
+			}
+			anchor = &s.elements[am.Target]
+		}
+	}
+	for _, e := range s.elements {
+		if sel.ID != "" && e.ID != sel.ID || sel.Text != "" && e.Text != sel.Text {
+			continue
+		}
+		if sel.Below != nil && e.Bounds.Y < anchor.Bounds.Y+anchor.Bounds.Height {
+			continue
+		}
+		if sel.Above != nil && e.Bounds.Y+e.Bounds.Height > anchor.Bounds.Y {
+			continue
+		}
+		m.Matches = append(m.Matches, e.Index)
+	}
+	idx := 0
+	if sel.Index != "" {
+		idx, _ = strconv.Atoi(sel.Index)
+	}
+	if idx < len(m.Matches) {
+		m.Target = m.Matches[idx]
+		tap := s.elements[m.Target]
+		for e := tap; e.Parent >= 0; {
+			e = s.elements[e.Parent]
+			if e.Clickable && !tap.Clickable {
+				tap = e
+			}
+		}
+		m.TapBounds = &tap.Bounds
+	}
+	return m, nil
+}
+
+func row(index int, id, text string, y int) core.InspectElement {
+	return core.InspectElement{
+		Index:  index,
+		Parent: -1,
+		ID:     id,
+		Text:   text,
+		Bounds: core.Bounds{X: 0, Y: y, Width: 100, Height: 40},
+	}
+}
+
+func TestSuggest_UniqueID(t *testing.T) {
+	snap := &fakeSnapshot{elements: []core.InspectElement{
+		row(0, "title", "Settings", 0),
+		row(1, "save", "Save", 100),
+	}}
+
+	got, err := Suggest(snap, 1)
+	if err != nil {
+		t.Fatalf("Suggest failed: %v", err)
+	}
+	if len(got) < 2 {
+		t.Fatalf("expected id and text suggestions, got %+v", got)
+	}
+	if got[0].Kind != KindID || got[0].YAML != `id: "save"` || !got[0].Unique {
+		t.Errorf("first suggestion = %+v, want unique id", got[0])
+	}
+	if got[1].Kind != KindText || got[1].YAML != `text: "Save"` {
+		t.Errorf("second suggestion = %+v, want text", got[1])
+	}
+}
+
+func TestSuggest_RelativeAndIndex(t *testing.T) {
+	snap := &fakeSnapshot{elements: []core.InspectElement{
+		row(0, "", "Edit", 0),
+		row(1, "", "Profile", 100),
+		row(2, "", "Edit", 200),
+	}}
+
+	got, err := Suggest(snap, 2)
+	if err != nil {
+		t.Fatalf("Suggest failed: %v", err)
+	}
+	if got[0].Kind != KindRelative || !got[0].Unique {
+		t.Fatalf("first suggestion = %+v, want unique relative", got[0])
+	}
+	want := "text: \"Edit\"\nbelow:\n  text: \"Profile\""
+	if got[0].YAML != want {
+		t.Errorf("relative YAML = %q, want %q", got[0].YAML, want)
+	}
+
+	var kinds []string
+	for _, s := range got {
+		kinds = append(kinds, s.Kind)
+		if s.Kind == KindText {
+			t.Errorf("ambiguous text selector should not be suggested: %+v", s)
+		}
+	}
+	last := got[len(got)-1]
+	if last.Kind != KindIndex || last.YAML != "text: \"Edit\"\nindex: 1" || last.Unique {
+		t.Errorf("last suggestion = %+v (kinds %v), want index fallback", last, kinds)
+	}
+}
+
+func TestSuggest_ClickableContainer(t *testing.T) {
+	container := row(0, "", "", 0)
+	container.Clickable = true
+	label := row(1, "", "Open", 0)
+	label.Parent = 0
+	label.Bounds = core.Bounds{X: 10, Y: 10, Width: 50, Height: 20}
+	snap := &fakeSnapshot{elements: []core.InspectElement{container, label}}
+
+	got, err := Suggest(snap, 0)
+	if err != nil {
+		t.Fatalf("Suggest failed: %v", err)
+	}
+	if len(got) != 1 || got[0].YAML != `text: "Open"` || !got[0].Unique {
+		t.Errorf("expected the child's text selector, got %+v", got)
+	}
+}
+
+func TestSuggest_RegexText(t *testing.T) {
+	snap := &fakeSnapshot{elements: []core.InspectElement{
+		row(0, "", `Total (USD)`, 0),
+	}}
+
+	got, err := Suggest(snap, 0)
+	if err != nil {
+		t.Fatalf("Suggest failed: %v", err)
+	}
+	if len(got) != 1 || got[0].Selector.Text != `Total (USD)` {
+		t.Errorf("expected the literal text only (escaped variant does not match here), got %+v", got)
+	}
+}
+
+func TestSuggest_OutOfRange(t *testing.T) {
+	snap := &fakeSnapshot{elements: []core.InspectElement{row(0, "a", "", 0)}}
+	if _, err := Suggest(snap, 5); err == nil {
+		t.Error("expected error for out-of-range index")
+	}
+}
+
+func TestSelectorYAML(t *testing.T) {
+	sel := flow.Selector{
+		ID:      "row",
+		Index:   "2",
+		RightOf: &flow.Selector{Text: `say "hi"`},
+	}
+	want := "id: \"row\"\nindex: 2\nrightOf:\n  text: \"say \\\"hi\\\"\""
+	if got := SelectorYAML(sel); got != want {
+		t.Errorf("SelectorYAML = %q, want %q", got, want)
+	}
+
+	// Round-trips through the flow parser
+	parsed, err := ParseSelector(want)
+	if err != nil {
+		t.Fatalf("ParseSelector failed: %v", err)
+	}
+	if parsed.ID != "row" || parsed.Index != "2" || parsed.RightOf == nil || parsed.RightOf.Text != `say "hi"` {
+		t.Errorf("unexpected round-trip: %+v", parsed)
+	}
+}
@@ -0,0 +1,184 @@
+package jsengine
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/core"
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/devicelab-dev/maestro-runner/pkg/logger"
+	"github.com/dop251/goja"
+	"gopkg.in/yaml.v3"
+)
+
+// Device is the device scripts query and act on through the maestro.*
+// functions. The executor implements it over the flow's driver.
+type Device interface {
+	// FindElement returns the element matching selector, or nil if none is
+	// visible within timeoutMs (0 = the driver's find timeout).
+	FindElement(selector flow.Selector, timeoutMs int) (*core.ElementInfo, error)
+
+	// IsVisible reports whether an element matching selector is visible
+	// within timeoutMs.
+	IsVisible(selector flow.Selector, timeoutMs int) (bool, error)
+
+	// GetText returns the text of the element matching selector.
+	GetText(selector flow.Selector, timeoutMs int) (string, error)
+
+	// Tap taps the element matching selector.
+	Tap(selector flow.Selector) error
+
+	// Screenshot saves a screenshot to path and returns where it was written.
+	Screenshot(path string) (string, error)
+
+	// Hierarchy returns the UI hierarchy.
+	Hierarchy() ([]byte, error)
+
+	// State returns the current device and app state.
+	State() (*core.StateSnapshot, error)
+}
+
+// SetDevice sets the device behind the maestro.* device functions. Without
+// one they throw.
+func (e *Engine) SetDevice(d Device) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.device = d
+}
+
+// setupDevice adds the device functions to the maestro object:
+// findElement, isVisible, getText, tap, screenshot, hierarchy and state.
+func (e *Engine) setupDevice(obj *goja.Object) {
+	funcs := map[string]func(goja.FunctionCall) goja.Value{
+		// maestro.findElement(selector, [options]) - element info or null
+		"findElement": func(call goja.FunctionCall) goja.Value {
+			sel, timeout := e.selectorArgs("findElement", call)
+			info, err := e.deviceFor("findElement").FindElement(sel, timeout)
+			e.throwIf("findElement", err)
+			if info == nil {
+				return goja.Null()
+			}
+			return e.jsonValue("findElement", info)
+		},
+		// maestro.isVisible(selector, [options])
+		"isVisible": func(call goja.FunctionCall) goja.Value {
+			sel, timeout := e.selectorArgs("isVisible", call)
+			visible, err := e.deviceFor("isVisible").IsVisible(sel, timeout)
+			e.throwIf("isVisible", err)
+			return e.runtime.ToValue(visible)
+		},
+		// maestro.getText(selector, [options])
+		"getText": func(call goja.FunctionCall) goja.Value {
+			sel, timeout := e.selectorArgs("getText", call)
+			text, err := e.deviceFor("getText").GetText(sel, timeout)
+			e.throwIf("getText", err)
+			return e.runtime.ToValue(text)
+		},
+		// maestro.tap(selector)
+		"tap": func(call goja.FunctionCall) goja.Value {
+			sel, _ := e.selectorArgs("tap", call)
+			e.throwIf("tap", e.deviceFor("tap").Tap(sel))
+			return goja.Undefined()
+		},
+		// maestro.screenshot(path) - path the PNG was written to
+		"screenshot": func(call goja.FunctionCall) goja.Value {
+			if len(call.Arguments) < 1 || goja.IsUndefined(call.Arguments[0]) {
+				panic(e.runtime.NewTypeError("maestro.screenshot requires a path"))
+			}
+			path, err := e.deviceFor("screenshot").Screenshot(call.Arguments[0].String())
+			e.throwIf("screenshot", err)
+			return e.runtime.ToValue(path)
+		},
+		// maestro.hierarchy() - parsed hierarchy, or the raw text if not JSON
+		"hierarchy": func(call goja.FunctionCall) goja.Value {
+			data, err := e.deviceFor("hierarchy").Hierarchy()
+			e.throwIf("hierarchy", err)
+			var parsed interface{}
+			if json.Unmarshal(data, &parsed) != nil {
+				return e.runtime.ToValue(string(data))
+			}
+			return e.runtime.ToValue(parsed)
+		},
+		// maestro.state()
+		"state": func(call goja.FunctionCall) goja.Value {
+			state, err := e.deviceFor("state").State()
+			e.throwIf("state", err)
+			if state == nil {
+				return goja.Null()
+			}
+			return e.jsonValue("state", state)
+		},
+	}
+
+	for name, fn := range funcs {
+		if err := obj.Set(name, fn); err != nil {
+			logger.Warn("failed to set maestro.%s: %v", name, err)
+		}
+	}
+}
+
+// deviceFor returns the device, throwing if none is set.
+func (e *Engine) deviceFor(name string) Device {
+	if e.device == nil {
+		panic(e.newError(fmt.Sprintf("maestro.%s: no device available", name)))
+	}
+	return e.device
+}
+
+// throwIf throws err as a JS Error from maestro.<name>.
+func (e *Engine) throwIf(name string, err error) {
+	if err != nil {
+		panic(e.newError(fmt.Sprintf("maestro.%s: %v", name, err)))
+	}
+}
+
+// selectorArgs reads a selector and an optional {timeout} options object.
+// A string selector matches text; an object takes the same keys as a
+// selector in YAML (id, text, index, below, childOf, ...).
+func (e *Engine) selectorArgs(name string, call goja.FunctionCall) (flow.Selector, int) {
+	var sel flow.Selector
+	arg := call.Argument(0)
+	if goja.IsUndefined(arg) || goja.IsNull(arg) {
+		panic(e.runtime.NewTypeError(fmt.Sprintf("maestro.%s requires a selector", name)))
+	}
+
+	switch v := arg.Export().(type) {
+	case string:
+		sel.Text = v
+	default:
+		data, err := yaml.Marshal(v)
+		if err == nil {
+			err = yaml.Unmarshal(data, &sel)
+		}
+		if err != nil {
+			panic(e.runtime.NewTypeError(fmt.Sprintf("maestro.%s: invalid selector: %v", name, err)))
+		}
+	}
+	if sel.IsEmpty() {
+		panic(e.runtime.NewTypeError(fmt.Sprintf("maestro.%s: empty selector", name)))
+	}
+
+	timeout := 0
+	if opts, ok := call.Argument(1).Export().(map[string]interface{}); ok {
+		switch v := opts["timeout"].(type) {
+		case int64:
+			timeout = int(v)
+		case float64:
+			timeout = int(v)
+		}
+	}
+	return sel, timeout
+}
+
+// jsonValue converts v to a plain JS object with its JSON field names.
+func (e *Engine) jsonValue(name string, v interface{}) goja.Value {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(e.newError(fmt.Sprintf("maestro.%s: %v", name, err)))
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		panic(e.newError(fmt.Sprintf("maestro.%s: %v", name, err)))
+	}
+	return e.runtime.ToValue(obj)
+}
@@ -0,0 +1,126 @@
+package jsengine
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/core"
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+)
+
+// fakeDevice is a Device with one element and a record of its calls.
+type fakeDevice struct {
+	element *core.ElementInfo
+	calls   []string
+}
+
+func (d *fakeDevice) match(sel flow.Selector) *core.ElementInfo {
+	if sel.Text == d.element.Text || (sel.ID != "" && sel.ID == d.element.ID) {
+		return d.element
+	}
+	return nil
+}
+
+func (d *fakeDevice) FindElement(sel flow.Selector, timeoutMs int) (*core.ElementInfo, error) {
+	d.calls = append(d.calls, "findElement "+sel.DescribeQuoted())
+	return d.match(sel), nil
+}
+
+func (d *fakeDevice) IsVisible(sel flow.Selector, timeoutMs int) (bool, error) {
+	d.calls = append(d.calls, "isVisible "+sel.DescribeQuoted())
+	return d.match(sel) != nil, nil
+}
+
+func (d *fakeDevice) GetText(sel flow.Selector, timeoutMs int) (string, error) {
+	if el := d.match(sel); el != nil {
+		return el.Text, nil
+	}
+	return "", errors.New("element not found")
+}
+
+func (d *fakeDevice) Tap(sel flow.Selector) error {
+	d.calls = append(d.calls, "tap "+sel.DescribeQuoted())
+	return nil
+}
+
+func (d *fakeDevice) Screenshot(path string) (string, error) { return "/out/" + path, nil }
+
+func (d *fakeDevice) Hierarchy() ([]byte, error) {
+	return []byte(`{"children": [{"text": "Pay"}]}`), nil
+}
+
+func (d *fakeDevice) State() (*core.StateSnapshot, error) {
+	return &core.StateSnapshot{AppState: "foreground", KeyboardVisible: true}, nil
+}
+
+func TestMaestroDevice(t *testing.T) {
+	device := &fakeDevice{element: &core.ElementInfo{ID: "pay_btn", Text: "Pay", Bounds: core.Bounds{X: 10, Width: 100}}}
+
+	engine := New()
+	defer engine.Close()
+	engine.SetDevice(device)
+
+	err := engine.RunScript(`
+var el = maestro.findElement({id: 'pay_btn', index: 0});
+output.bounds = el.bounds.x + el.bounds.width;
+output.missing = maestro.findElement('Cancel') === null;
+output.visible = maestro.isVisible('Pay', {timeout: 500});
+if (maestro.getText({id: 'pay_btn'}) === 'Pay') { maestro.tap('Pay'); }
+output.shot = maestro.screenshot('after-pay');
+output.tree = maestro.hierarchy().children[0].text;
+var state = maestro.state();
+output.state = state.appState + ' ' + state.keyboardVisible;`)
+	if err != nil {
+		t.Fatalf("RunScript() error = %v", err)
+	}
+
+	want := map[string]interface{}{
+		"bounds":  int64(110),
+		"missing": true,
+		"visible": true,
+		"shot":    "/out/after-pay",
+		"tree":    "Pay",
+		"state":   "foreground true",
+	}
+	out := engine.GetOutput()
+	for k, v := range want {
+		if out[k] != v {
+			t.Errorf("output.%s = %v (%T), want %v", k, out[k], out[k], v)
+		}
+	}
+
+	wantCalls := `findElement id="pay_btn",findElement text="Cancel",isVisible text="Pay",tap text="Pay"`
+	if got := strings.Join(device.calls, ","); got != wantCalls {
+		t.Errorf("calls = %s, want %s", got, wantCalls)
+	}
+}
+
+func TestMaestroDevice_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		device Device
+		script string
+		want   string
+	}{
+		{"no device", nil, "maestro.tap('Pay');", "maestro.tap: no device available"},
+		{"device error", &fakeDevice{element: &core.ElementInfo{Text: "Pay"}}, "maestro.getText('Cancel');", "maestro.getText: element not found"},
+		{"missing selector", &fakeDevice{}, "maestro.isVisible();", "maestro.isVisible requires a selector"},
+		{"empty selector", &fakeDevice{}, "maestro.findElement({});", "maestro.findElement: empty selector"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := New()
+			defer engine.Close()
+			if tt.device != nil {
+				engine.SetDevice(tt.device)
+			}
+
+			err := engine.RunScript(tt.script)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("RunScript() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
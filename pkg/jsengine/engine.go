@@ -7,7 +7,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/devicelab-dev/maestro-runner/pkg/faker"
 	"github.com/devicelab-dev/maestro-runner/pkg/logger"
+	"github.com/devicelab-dev/maestro-runner/pkg/secrets"
 	"github.com/dop251/goja"
 )
 
@@ -18,36 +20,24 @@ type Engine struct {
 	output     map[string]interface{}
 	copiedText string
 	platform   string
-	timers     *timerRegistry
+	device     Device
+	random     *faker.Faker // Behind faker.*; seeded per flow by the executor
+	loop       *eventLoop
+	modules    modules
+	timeout    time.Duration // Per-script limit, including timers (0 = none)
 	mu         sync.Mutex
 }
 
-// timerRegistry manages setTimeout/setInterval timers
-type timerRegistry struct {
-	timers    map[int]*time.Timer
-	tickers   map[int]*time.Ticker
-	nextID    int
-	mu        sync.Mutex
-	stopChan  chan struct{}
-	closeOnce sync.Once
-}
-
-func newTimerRegistry() *timerRegistry {
-	return &timerRegistry{
-		timers:   make(map[int]*time.Timer),
-		tickers:  make(map[int]*time.Ticker),
-		nextID:   1,
-		stopChan: make(chan struct{}),
-	}
-}
-
 // New creates a new JS engine instance
 func New() *Engine {
 	e := &Engine{
 		runtime:   goja.New(),
 		variables: make(map[string]interface{}),
 		output:    make(map[string]interface{}),
-		timers:    newTimerRegistry(),
+		loop:      newEventLoop(),
+		modules:   modules{cache: make(map[string]*goja.Object)},
+		timeout:   DefaultTimeout,
+		random:    faker.New(faker.NewSeed()),
 	}
 
 	e.setupBuiltins()
@@ -67,6 +57,11 @@ func (e *Engine) setupBuiltins() {
 		logger.Warn("failed to set JS runtime global 'json': %v", err)
 	}
 
+	// Random data generator
+	if err := e.runtime.Set("faker", e.fakerObject()); err != nil {
+		logger.Warn("failed to set JS runtime global 'faker': %v", err)
+	}
+
 	// HTTP module
 	if err := e.runtime.Set("http", e.httpModule()); err != nil {
 		logger.Warn("failed to set JS runtime global 'http': %v", err)
@@ -81,9 +76,13 @@ func (e *Engine) setupBuiltins() {
 	if err := e.runtime.Set("maestro", e.maestroObject()); err != nil {
 		logger.Warn("failed to set JS runtime global 'maestro': %v", err)
 	}
+
+	// CommonJS require()
+	e.setRequire("")
 }
 
-// setupConsole adds console.log, console.error, etc.
+// setupConsole adds console.log, console.error, etc. Secret values are
+// redacted from what they print.
 func (e *Engine) setupConsole() {
 	// Helper to create console methods
 	makeConsoleFunc := func(prefix string) func(goja.FunctionCall) goja.Value {
@@ -92,11 +91,11 @@ func (e *Engine) setupConsole() {
 			for i, arg := range call.Arguments {
 				args[i] = arg.Export()
 			}
+			line := fmt.Sprintln(args...)
 			if prefix != "" {
-				fmt.Println(prefix, args)
-			} else {
-				fmt.Println(args...)
+				line = fmt.Sprintln(prefix, args)
 			}
+			fmt.Print(secrets.Redact(line))
 			return goja.Undefined()
 		}
 	}
@@ -116,133 +115,6 @@ func (e *Engine) setupConsole() {
 	}
 }
 
-// setupTimers adds setTimeout, setInterval, clearTimeout, clearInterval
-func (e *Engine) setupTimers() {
-	// setTimeout
-	if err := e.runtime.Set("setTimeout", func(call goja.FunctionCall) goja.Value {
-		if len(call.Arguments) < 2 {
-			panic(e.runtime.NewTypeError("setTimeout requires 2 arguments"))
-		}
-
-		callback, ok := goja.AssertFunction(call.Arguments[0])
-		if !ok {
-			panic(e.runtime.NewTypeError("first argument must be a function"))
-		}
-
-		delay := call.Arguments[1].ToInteger()
-
-		e.timers.mu.Lock()
-		id := e.timers.nextID
-		e.timers.nextID++
-
-		timer := time.AfterFunc(time.Duration(delay)*time.Millisecond, func() {
-			e.mu.Lock()
-			defer e.mu.Unlock()
-
-			// Call the callback
-			_, err := callback(goja.Undefined())
-			if err != nil {
-				fmt.Printf("setTimeout callback error: %v\n", err)
-			}
-
-			// Clean up
-			e.timers.mu.Lock()
-			delete(e.timers.timers, id)
-			e.timers.mu.Unlock()
-		})
-
-		e.timers.timers[id] = timer
-		e.timers.mu.Unlock()
-
-		return e.runtime.ToValue(id)
-	}); err != nil {
-		logger.Warn("failed to set JS runtime global 'setTimeout': %v", err)
-	}
-
-	// clearTimeout
-	if err := e.runtime.Set("clearTimeout", func(call goja.FunctionCall) goja.Value {
-		if len(call.Arguments) < 1 {
-			return goja.Undefined()
-		}
-
-		id := int(call.Arguments[0].ToInteger())
-
-		e.timers.mu.Lock()
-		if timer, ok := e.timers.timers[id]; ok {
-			timer.Stop()
-			delete(e.timers.timers, id)
-		}
-		e.timers.mu.Unlock()
-
-		return goja.Undefined()
-	}); err != nil {
-		logger.Warn("failed to set JS runtime global 'clearTimeout': %v", err)
-	}
-
-	// setInterval
-	if err := e.runtime.Set("setInterval", func(call goja.FunctionCall) goja.Value {
-		if len(call.Arguments) < 2 {
-			panic(e.runtime.NewTypeError("setInterval requires 2 arguments"))
-		}
-
-		callback, ok := goja.AssertFunction(call.Arguments[0])
-		if !ok {
-			panic(e.runtime.NewTypeError("first argument must be a function"))
-		}
-
-		interval := call.Arguments[1].ToInteger()
-
-		e.timers.mu.Lock()
-		id := e.timers.nextID
-		e.timers.nextID++
-
-		ticker := time.NewTicker(time.Duration(interval) * time.Millisecond)
-		e.timers.tickers[id] = ticker
-		e.timers.mu.Unlock()
-
-		go func() {
-			defer ticker.Stop()
-			for {
-				select {
-				case <-e.timers.stopChan:
-					return
-				case <-ticker.C:
-					e.mu.Lock()
-					_, err := callback(goja.Undefined())
-					if err != nil {
-						fmt.Printf("setInterval callback error: %v\n", err)
-					}
-					e.mu.Unlock()
-				}
-			}
-		}()
-
-		return e.runtime.ToValue(id)
-	}); err != nil {
-		logger.Warn("failed to set JS runtime global 'setInterval': %v", err)
-	}
-
-	// clearInterval
-	if err := e.runtime.Set("clearInterval", func(call goja.FunctionCall) goja.Value {
-		if len(call.Arguments) < 1 {
-			return goja.Undefined()
-		}
-
-		id := int(call.Arguments[0].ToInteger())
-
-		e.timers.mu.Lock()
-		if ticker, ok := e.timers.tickers[id]; ok {
-			ticker.Stop()
-			delete(e.timers.tickers, id)
-		}
-		e.timers.mu.Unlock()
-
-		return goja.Undefined()
-	}); err != nil {
-		logger.Warn("failed to set JS runtime global 'clearInterval': %v", err)
-	}
-}
-
 // jsonFunc returns the json() helper function
 func (e *Engine) jsonFunc() func(call goja.FunctionCall) goja.Value {
 	return func(call goja.FunctionCall) goja.Value {
@@ -280,6 +152,9 @@ func (e *Engine) maestroObject() *goja.Object {
 		logger.Warn("failed to define maestro.platform: %v", err)
 	}
 
+	// maestro.findElement, isVisible, getText, tap, screenshot, hierarchy, state
+	e.setupDevice(obj)
+
 	return obj
 }
 
@@ -349,12 +224,32 @@ func (e *Engine) GetOutput() map[string]interface{} {
 	return result
 }
 
+// SetOutput sets properties of the output object, as if a script had
+// assigned them
+func (e *Engine) SetOutput(vars map[string]interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	obj := e.runtime.Get("output")
+	if obj == nil || goja.IsUndefined(obj) || goja.IsNull(obj) {
+		return
+	}
+	out := obj.ToObject(e.runtime)
+	for k, v := range vars {
+		if err := out.Set(k, v); err != nil {
+			logger.Warn("failed to set output.%s: %v", k, err)
+		}
+	}
+}
+
 // Eval evaluates a JavaScript expression and returns the result
 func (e *Engine) Eval(script string) (interface{}, error) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	result, err := e.runtime.RunString(script)
+	result, err := e.run(func() (goja.Value, error) {
+		return e.runtime.RunString(script)
+	}, true)
 	if err != nil {
 		return nil, fmt.Errorf("JS eval error: %w", err)
 	}
@@ -381,10 +276,13 @@ func (e *Engine) RunScript(script string) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	_, err := e.runtime.RunString(script)
+	prog, async, err := compileScript("", script)
 	if err != nil {
 		return fmt.Errorf("JS runtime error: %w", err)
 	}
+	if _, err := e.run(func() (goja.Value, error) { return e.runtime.RunProgram(prog) }, async); err != nil {
+		return fmt.Errorf("JS runtime error: %w", err)
+	}
 
 	return nil
 }
@@ -458,26 +356,10 @@ func (e *Engine) ExpandVariables(text string) (string, error) {
 	return result, nil
 }
 
-// Close cleans up the engine (stops timers, etc.)
+// Close cleans up the engine (cancels pending timers and async operations).
 // Safe to call multiple times.
 func (e *Engine) Close() {
-	e.timers.closeOnce.Do(func() {
-		e.timers.mu.Lock()
-		defer e.timers.mu.Unlock()
-
-		// Stop all timers
-		for _, timer := range e.timers.timers {
-			timer.Stop()
-		}
-		e.timers.timers = make(map[int]*time.Timer)
-
-		// Stop all tickers
-		for _, ticker := range e.timers.tickers {
-			ticker.Stop()
-		}
-		e.timers.tickers = make(map[int]*time.Ticker)
-
-		// Signal stop to goroutines
-		close(e.timers.stopChan)
-	})
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.loop.cancel()
 }
@@ -184,26 +184,19 @@ func TestSetInterval(t *testing.T) {
 
 	engine.SetVariable("counter", int64(0))
 
+	// The interval fires while the script waits for its setTimeout
 	err := engine.RunScript(`
 		var id = setInterval(function() {
 			counter = counter + 1;
-		}, 30);
-
-		// Store id for later clearInterval
-		intervalId = id;
+		}, 20);
+		setTimeout(function() {
+			clearInterval(id);
+		}, 100);
 	`)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	// Wait for a few intervals
-	time.Sleep(100 * time.Millisecond)
-
-	// Clear the interval
-	if err := engine.RunScript("clearInterval(intervalId)"); err != nil {
-		t.Fatalf("failed to clear interval: %v", err)
-	}
-
 	result, err := engine.Eval("counter")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -264,6 +257,24 @@ func TestOutput(t *testing.T) {
 	}
 }
 
+func TestSetOutput(t *testing.T) {
+	engine := New()
+	defer engine.Close()
+
+	engine.SetOutput(map[string]interface{}{"token": "abc", "user": map[string]interface{}{"id": int64(7)}})
+
+	value, err := engine.EvalString("output.token + ':' + output.user.id")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value != "abc:7" {
+		t.Errorf("expected 'abc:7', got %q", value)
+	}
+	if engine.GetOutput()["token"] != "abc" {
+		t.Errorf("GetOutput() = %v", engine.GetOutput())
+	}
+}
+
 func TestMaestroObject(t *testing.T) {
 	engine := New()
 	defer engine.Close()
@@ -0,0 +1,95 @@
+package jsengine
+
+import (
+	"github.com/devicelab-dev/maestro-runner/pkg/faker"
+	"github.com/devicelab-dev/maestro-runner/pkg/logger"
+	"github.com/dop251/goja"
+)
+
+// SetFaker sets the generator behind the faker.* functions, so scripts draw
+// from the same seeded values as inputRandom.
+func (e *Engine) SetFaker(f *faker.Faker) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.random = f
+}
+
+// fakerObject returns the faker global: text, number, email, firstName,
+// lastName, personName, phoneNumber, address, city, uuid, date, creditCard
+// and generate(type, [length], [locale]), plus the seed in faker.seed.
+func (e *Engine) fakerObject() *goja.Object {
+	obj := e.runtime.NewObject()
+
+	withLength := map[string]func(*faker.Faker, int) string{
+		"text":   (*faker.Faker).Text,
+		"number": (*faker.Faker).Number,
+	}
+	for name, gen := range withLength {
+		e.setFakerFunc(obj, name, func(call goja.FunctionCall) goja.Value {
+			length := int(call.Argument(0).ToInteger())
+			if length <= 0 {
+				length = faker.DefaultLength
+			}
+			return e.runtime.ToValue(gen(e.random, length))
+		})
+	}
+
+	withLocale := map[string]func(*faker.Faker, string) string{
+		"email":       (*faker.Faker).Email,
+		"firstName":   (*faker.Faker).FirstName,
+		"lastName":    (*faker.Faker).LastName,
+		"personName":  (*faker.Faker).PersonName,
+		"phoneNumber": (*faker.Faker).PhoneNumber,
+		"address":     (*faker.Faker).Address,
+		"city":        (*faker.Faker).City,
+	}
+	for name, gen := range withLocale {
+		e.setFakerFunc(obj, name, func(call goja.FunctionCall) goja.Value {
+			return e.runtime.ToValue(gen(e.random, optionalString(call.Argument(0))))
+		})
+	}
+
+	plain := map[string]func(*faker.Faker) string{
+		"uuid":       (*faker.Faker).UUID,
+		"date":       (*faker.Faker).Date,
+		"creditCard": (*faker.Faker).CreditCard,
+	}
+	for name, gen := range plain {
+		e.setFakerFunc(obj, name, func(call goja.FunctionCall) goja.Value {
+			return e.runtime.ToValue(gen(e.random))
+		})
+	}
+
+	// faker.generate(type, [length], [locale]) - any inputRandom type
+	e.setFakerFunc(obj, "generate", func(call goja.FunctionCall) goja.Value {
+		value, err := e.random.Generate(optionalString(call.Argument(0)),
+			int(call.Argument(1).ToInteger()), optionalString(call.Argument(2)))
+		if err != nil {
+			panic(e.newError("faker.generate: " + err.Error()))
+		}
+		return e.runtime.ToValue(value)
+	})
+
+	// faker.seed - seed of the current generator
+	if err := obj.DefineAccessorProperty("seed", e.runtime.ToValue(func() int64 {
+		return e.random.Seed()
+	}), nil, goja.FLAG_FALSE, goja.FLAG_TRUE); err != nil {
+		logger.Warn("failed to define faker.seed: %v", err)
+	}
+
+	return obj
+}
+
+func (e *Engine) setFakerFunc(obj *goja.Object, name string, fn func(goja.FunctionCall) goja.Value) {
+	if err := obj.Set(name, fn); err != nil {
+		logger.Warn("failed to set faker.%s: %v", name, err)
+	}
+}
+
+// optionalString returns v as a string, or "" if it is undefined or null.
+func optionalString(v goja.Value) string {
+	if goja.IsUndefined(v) || goja.IsNull(v) {
+		return ""
+	}
+	return v.String()
+}
@@ -0,0 +1,40 @@
+package jsengine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/faker"
+)
+
+func TestFaker(t *testing.T) {
+	engine := New()
+	defer engine.Close()
+	engine.SetFaker(faker.New(3))
+
+	err := engine.RunScript(`
+output.values = [faker.text(4), faker.number(), faker.personName('fr_FR'), faker.uuid(),
+	faker.generate('city', 0, 'de'), faker.creditCard()].join('|');
+output.seed = faker.seed;`)
+	if err != nil {
+		t.Fatalf("RunScript() error = %v", err)
+	}
+
+	// Scripts draw from the same sequence as Go callers with the same seed
+	f := faker.New(3)
+	values := []string{f.Text(4), f.Number(faker.DefaultLength), f.PersonName("fr_FR"), f.UUID()}
+	city, _ := f.Generate("CITY", 0, "de")
+	want := strings.Join(append(values, city, f.CreditCard()), "|")
+
+	out := engine.GetOutput()
+	if out["values"] != want {
+		t.Errorf("values = %v, want %v", out["values"], want)
+	}
+	if out["seed"] != int64(3) {
+		t.Errorf("seed = %v, want 3", out["seed"])
+	}
+
+	if _, err := engine.Eval("faker.generate('SHOE_SIZE')"); err == nil || !strings.Contains(err.Error(), "faker.generate: unknown random data type") {
+		t.Errorf("Eval() error = %v", err)
+	}
+}
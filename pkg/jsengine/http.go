@@ -2,63 +2,62 @@ package jsengine
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/dop251/goja"
 )
 
-// httpModule returns the http object with get, post, put, delete methods
+// httpModule returns the http object with get, post, put, delete and
+// request methods, which block until the response arrives, and their
+// promise-returning variants getAsync, postAsync, putAsync, deleteAsync and
+// requestAsync.
 func (e *Engine) httpModule() *goja.Object {
 	obj := e.runtime.NewObject()
 
-	// http.get(url, [options])
-	if err := obj.Set("get", func(call goja.FunctionCall) goja.Value {
-		return e.doHTTPRequest("GET", call)
-	}); err != nil {
-		panic(e.runtime.NewTypeError(fmt.Sprintf("failed to set http.get: %v", err)))
-	}
-
-	// http.post(url, [options])
-	if err := obj.Set("post", func(call goja.FunctionCall) goja.Value {
-		return e.doHTTPRequest("POST", call)
-	}); err != nil {
-		panic(e.runtime.NewTypeError(fmt.Sprintf("failed to set http.post: %v", err)))
-	}
+	for _, method := range []string{"GET", "POST", "PUT", "DELETE"} {
+		name := strings.ToLower(method)
 
-	// http.put(url, [options])
-	if err := obj.Set("put", func(call goja.FunctionCall) goja.Value {
-		return e.doHTTPRequest("PUT", call)
-	}); err != nil {
-		panic(e.runtime.NewTypeError(fmt.Sprintf("failed to set http.put: %v", err)))
-	}
+		// http.<method>(url, [options])
+		if err := obj.Set(name, func(call goja.FunctionCall) goja.Value {
+			return e.doHTTPRequest(method, call)
+		}); err != nil {
+			panic(e.runtime.NewTypeError(fmt.Sprintf("failed to set http.%s: %v", name, err)))
+		}
 
-	// http.delete(url, [options])
-	if err := obj.Set("delete", func(call goja.FunctionCall) goja.Value {
-		return e.doHTTPRequest("DELETE", call)
-	}); err != nil {
-		panic(e.runtime.NewTypeError(fmt.Sprintf("failed to set http.delete: %v", err)))
+		// http.<method>Async(url, [options])
+		if err := obj.Set(name+"Async", func(call goja.FunctionCall) goja.Value {
+			return e.doHTTPRequestAsync(method, call)
+		}); err != nil {
+			panic(e.runtime.NewTypeError(fmt.Sprintf("failed to set http.%sAsync: %v", name, err)))
+		}
 	}
 
-	// http.request(method, url, [options])
-	if err := obj.Set("request", func(call goja.FunctionCall) goja.Value {
-		if len(call.Arguments) < 2 {
-			panic(e.runtime.NewTypeError("http.request requires method and url"))
-		}
-		method := call.Arguments[0].String()
-		// Shift arguments
-		newArgs := call.Arguments[1:]
-		newCall := goja.FunctionCall{
-			This:      call.This,
-			Arguments: newArgs,
+	// http.request(method, url, [options]) and http.requestAsync
+	request := func(name string, do func(string, goja.FunctionCall) goja.Value) {
+		if err := obj.Set(name, func(call goja.FunctionCall) goja.Value {
+			if len(call.Arguments) < 2 {
+				panic(e.runtime.NewTypeError("http." + name + " requires method and url"))
+			}
+			method := call.Arguments[0].String()
+			// Shift arguments
+			newCall := goja.FunctionCall{
+				This:      call.This,
+				Arguments: call.Arguments[1:],
+			}
+			return do(method, newCall)
+		}); err != nil {
+			panic(e.runtime.NewTypeError(fmt.Sprintf("failed to set http.%s: %v", name, err)))
 		}
-		return e.doHTTPRequest(method, newCall)
-	}); err != nil {
-		panic(e.runtime.NewTypeError(fmt.Sprintf("failed to set http.request: %v", err)))
 	}
+	request("request", e.doHTTPRequest)
+	request("requestAsync", e.doHTTPRequestAsync)
 
 	return obj
 }
@@ -74,6 +73,41 @@ type HTTPResponse struct {
 
 // doHTTPRequest performs an HTTP request and returns the response
 func (e *Engine) doHTTPRequest(method string, call goja.FunctionCall) goja.Value {
+	req, client := e.newHTTPRequest(method, call)
+
+	response, err := sendHTTPRequest(client, req)
+	if err != nil {
+		if errors.Is(req.Context().Err(), context.DeadlineExceeded) {
+			// Out of script time: stop the script as the timeout would
+			e.runtime.Interrupt(errInterrupted)
+			return goja.Undefined()
+		}
+		panic(e.runtime.NewTypeError(err.Error()))
+	}
+
+	responseObj, err := e.httpResponseObject(response)
+	if err != nil {
+		panic(e.runtime.NewTypeError(err.Error()))
+	}
+	return responseObj
+}
+
+// doHTTPRequestAsync starts an HTTP request and returns a promise of the
+// response. The request runs on its own goroutine; the promise settles on
+// the event loop.
+func (e *Engine) doHTTPRequestAsync(method string, call goja.FunctionCall) goja.Value {
+	req, client := e.newHTTPRequest(method, call)
+
+	return e.async(func() (interface{}, error) {
+		return sendHTTPRequest(client, req)
+	}, func(result interface{}) (goja.Value, error) {
+		return e.httpResponseObject(result.(*HTTPResponse))
+	})
+}
+
+// newHTTPRequest builds a request from the url and options arguments of an
+// http call.
+func (e *Engine) newHTTPRequest(method string, call goja.FunctionCall) (*http.Request, *http.Client) {
 	if len(call.Arguments) < 1 {
 		panic(e.runtime.NewTypeError(fmt.Sprintf("http.%s requires url", method)))
 	}
@@ -123,8 +157,8 @@ func (e *Engine) doHTTPRequest(method string, call goja.FunctionCall) goja.Value
 		}
 	}
 
-	// Create request
-	req, err := http.NewRequest(method, url, body)
+	// Create request, aborted when the script ends or times out
+	req, err := http.NewRequestWithContext(e.loop.ctx, method, url, body)
 	if err != nil {
 		panic(e.runtime.NewTypeError(fmt.Sprintf("failed to create request: %v", err)))
 	}
@@ -135,25 +169,27 @@ func (e *Engine) doHTTPRequest(method string, call goja.FunctionCall) goja.Value
 	}
 
 	// Create client with timeout
-	client := &http.Client{
-		Timeout: timeout,
-	}
+	return req, &http.Client{Timeout: timeout}
+}
 
+// sendHTTPRequest executes a request and reads its response. It doesn't
+// touch the JS runtime, so it can run off the event loop.
+func sendHTTPRequest(client *http.Client, req *http.Request) (*HTTPResponse, error) {
 	// Execute request
 	resp, err := client.Do(req)
 	if err != nil {
-		panic(e.runtime.NewTypeError(fmt.Sprintf("HTTP request failed: %v", err)))
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Read body
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		panic(e.runtime.NewTypeError(fmt.Sprintf("failed to read response: %v", err)))
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	// Build response object
-	response := HTTPResponse{
+	response := &HTTPResponse{
 		Status:  resp.StatusCode,
 		Body:    string(bodyBytes),
 		Headers: make(map[string]string),
@@ -173,7 +209,11 @@ func (e *Engine) doHTTPRequest(method string, call goja.FunctionCall) goja.Value
 		response.JSON = jsonBody
 	}
 
-	// Convert to JS object
+	return response, nil
+}
+
+// httpResponseObject converts a response to the JS object scripts get.
+func (e *Engine) httpResponseObject(response *HTTPResponse) (*goja.Object, error) {
 	responseObj := e.runtime.NewObject()
 	for _, kv := range []struct {
 		key string
@@ -185,20 +225,18 @@ func (e *Engine) doHTTPRequest(method string, call goja.FunctionCall) goja.Value
 		{"ok", response.Ok},
 	} {
 		if err := responseObj.Set(kv.key, kv.val); err != nil {
-			panic(e.runtime.NewTypeError(fmt.Sprintf("failed to set response.%s: %v", kv.key, err)))
+			return nil, fmt.Errorf("failed to set response.%s: %w", kv.key, err)
 		}
 	}
 
 	// Add json as parsed data (or null if not JSON)
+	var jsonVal interface{} = goja.Null()
 	if response.JSON != nil {
-		if err := responseObj.Set("json", response.JSON); err != nil {
-			panic(e.runtime.NewTypeError(fmt.Sprintf("failed to set response.json: %v", err)))
-		}
-	} else {
-		if err := responseObj.Set("json", goja.Null()); err != nil {
-			panic(e.runtime.NewTypeError(fmt.Sprintf("failed to set response.json: %v", err)))
-		}
+		jsonVal = response.JSON
+	}
+	if err := responseObj.Set("json", jsonVal); err != nil {
+		return nil, fmt.Errorf("failed to set response.json: %w", err)
 	}
 
-	return responseObj
+	return responseObj, nil
 }
@@ -0,0 +1,274 @@
+package jsengine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/logger"
+	"github.com/dop251/goja"
+)
+
+// asyncWrapper wraps a script that uses await at the top level in an async
+// function. The header stays on the script's first line so line numbers in
+// errors match the file.
+const asyncWrapper = "(async function() {"
+
+// eventLoop runs a script's asynchronous work on the script's goroutine once
+// its main body has finished: timer callbacks and the completions of async
+// operations such as http.getAsync. goja runs promise jobs after each of
+// them, so promise reactions and awaits resume on the loop too.
+type eventLoop struct {
+	timers  map[int]*timer
+	nextID  int
+	pending int // Async operations started but not completed
+	done    chan func() error
+	stop    chan struct{} // Closed when the script ends, abandoning pending operations
+
+	// ctx is done when the running script ends or reaches its deadline, so
+	// requests it made don't outlive it.
+	ctx       context.Context
+	cancelCtx context.CancelFunc
+}
+
+// timer is a pending setTimeout or setInterval callback.
+type timer struct {
+	due      time.Time
+	interval time.Duration // 0 for setTimeout
+	callback goja.Callable
+}
+
+func newEventLoop() *eventLoop {
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	return &eventLoop{
+		timers:    make(map[int]*timer),
+		nextID:    1,
+		done:      make(chan func() error),
+		stop:      make(chan struct{}),
+		ctx:       ctx,
+		cancelCtx: cancelCtx,
+	}
+}
+
+// start begins a script run that must end by deadline (zero = no limit).
+func (l *eventLoop) start(deadline time.Time) {
+	l.cancelCtx()
+	if deadline.IsZero() {
+		l.ctx, l.cancelCtx = context.WithCancel(context.Background())
+	} else {
+		l.ctx, l.cancelCtx = context.WithDeadline(context.Background(), deadline)
+	}
+}
+
+// setupTimers adds setTimeout, setInterval, clearTimeout, clearInterval
+func (e *Engine) setupTimers() {
+	add := func(name string, interval bool) {
+		if err := e.runtime.Set(name, func(call goja.FunctionCall) goja.Value {
+			if len(call.Arguments) < 2 {
+				panic(e.runtime.NewTypeError(name + " requires 2 arguments"))
+			}
+
+			callback, ok := goja.AssertFunction(call.Arguments[0])
+			if !ok {
+				panic(e.runtime.NewTypeError("first argument must be a function"))
+			}
+
+			delay := time.Duration(call.Arguments[1].ToInteger()) * time.Millisecond
+			t := &timer{due: time.Now().Add(delay), callback: callback}
+			if interval {
+				t.interval = max(delay, time.Millisecond)
+			}
+
+			id := e.loop.nextID
+			e.loop.nextID++
+			e.loop.timers[id] = t
+			return e.runtime.ToValue(id)
+		}); err != nil {
+			logger.Warn("failed to set JS runtime global '%s': %v", name, err)
+		}
+	}
+	add("setTimeout", false)
+	add("setInterval", true)
+
+	clear := func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) > 0 {
+			delete(e.loop.timers, int(call.Arguments[0].ToInteger()))
+		}
+		return goja.Undefined()
+	}
+	for _, name := range []string{"clearTimeout", "clearInterval"} {
+		if err := e.runtime.Set(name, clear); err != nil {
+			logger.Warn("failed to set JS runtime global '%s': %v", name, err)
+		}
+	}
+}
+
+// async runs work on its own goroutine and returns a promise settled on the
+// loop: rejected if work fails, else resolved with toValue of its result.
+// work must not touch the runtime; toValue runs on the loop. Work finishing
+// after the script's deadline settles nothing: the loop reports the timeout.
+func (e *Engine) async(work func() (interface{}, error), toValue func(interface{}) (goja.Value, error)) goja.Value {
+	promise, resolve, reject := e.runtime.NewPromise()
+	done, stop, ctx := e.loop.done, e.loop.stop, e.loop.ctx
+	e.loop.pending++
+
+	go func() {
+		result, err := work()
+		if ctx.Err() != nil {
+			return // The script ended or timed out; nobody waits for this
+		}
+		complete := func() error {
+			if err == nil {
+				var value goja.Value
+				if value, err = toValue(result); err == nil {
+					return resolve(value)
+				}
+			}
+			return reject(e.newError(err.Error()))
+		}
+		select {
+		case done <- complete:
+		case <-stop:
+		}
+	}()
+
+	return e.runtime.ToValue(promise)
+}
+
+// runLoop runs timers and async completions in the order they become due
+// until nothing the script waits for is left: setTimeout callbacks, async
+// operations and, while promise is pending, intervals. Intervals alone
+// don't keep a script running.
+func (e *Engine) runLoop(deadline time.Time, promise *goja.Promise) error {
+	l := e.loop
+	for {
+		waiting := promise != nil && promise.State() == goja.PromiseStatePending
+		if !l.hasTimeouts() && l.pending == 0 && !(waiting && len(l.timers) > 0) {
+			return nil
+		}
+
+		id, next := l.nextTimer()
+		wait := time.Duration(-1) // No timer: wait for async operations only
+		if next != nil {
+			wait = max(time.Until(next.due), 0)
+		}
+		if !deadline.IsZero() && (next == nil || next.due.After(deadline)) {
+			next, wait = nil, max(time.Until(deadline), 0)
+		}
+
+		if complete := l.wait(wait); complete != nil {
+			l.pending--
+			if err := complete(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if next == nil {
+			return timeoutError(e.timeout, nil)
+		}
+		if next.interval > 0 {
+			next.due = next.due.Add(next.interval)
+		} else {
+			delete(l.timers, id)
+		}
+		if _, err := next.callback(goja.Undefined()); err != nil {
+			return err
+		}
+	}
+}
+
+// wait blocks until an async operation completes, returning its completion,
+// or until d has passed, returning nil. A negative d waits for completions only.
+func (l *eventLoop) wait(d time.Duration) func() error {
+	var wake <-chan time.Time
+	if d >= 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		wake = t.C
+	}
+	select {
+	case complete := <-l.done:
+		return complete
+	case <-wake:
+		return nil
+	}
+}
+
+// hasTimeouts reports whether a setTimeout callback is pending.
+func (l *eventLoop) hasTimeouts() bool {
+	for _, t := range l.timers {
+		if t.interval == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// nextTimer returns the timer due first, lowest id first on ties, or nil.
+func (l *eventLoop) nextTimer() (int, *timer) {
+	if len(l.timers) == 0 {
+		return 0, nil
+	}
+	ids := make([]int, 0, len(l.timers))
+	for id := range l.timers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	first := ids[0]
+	for _, id := range ids[1:] {
+		if l.timers[id].due.Before(l.timers[first].due) {
+			first = id
+		}
+	}
+	return first, l.timers[first]
+}
+
+// cancel drops the timers and async operations a script left behind, and
+// aborts its requests still in flight.
+func (l *eventLoop) cancel() {
+	if n := len(l.timers) + l.pending; n > 0 {
+		logger.Debug("cancelled %d pending JS timer(s) and async operation(s) at script end", n)
+	}
+	l.timers = make(map[int]*timer)
+	l.pending = 0
+	close(l.stop)
+	l.cancelCtx()
+	l.stop = make(chan struct{})
+	l.done = make(chan func() error)
+}
+
+// compileScript compiles a script. A script that only compiles with await
+// allowed at the top level is wrapped in an async function; async reports
+// whether it was, and running it returns the function's promise.
+func compileScript(name, src string) (prog *goja.Program, async bool, err error) {
+	prog, err = goja.Compile(name, src, false)
+	if err == nil || !strings.Contains(src, "await") {
+		return prog, false, err
+	}
+	if wrapped, wrapErr := goja.Compile(name, asyncWrapper+src+"\n})()", false); wrapErr == nil {
+		return wrapped, true, nil
+	}
+	return nil, false, err
+}
+
+// settle returns the result of a promise the script awaited.
+func settle(p *goja.Promise) (goja.Value, error) {
+	switch p.State() {
+	case goja.PromiseStateFulfilled:
+		return p.Result(), nil
+	case goja.PromiseStateRejected:
+		reason := p.Result()
+		if obj, ok := reason.(*goja.Object); ok {
+			if stack := obj.Get("stack"); stack != nil && !goja.IsUndefined(stack) {
+				return nil, fmt.Errorf("uncaught (in promise) %s", strings.TrimSpace(stack.String()))
+			}
+		}
+		return nil, fmt.Errorf("uncaught (in promise) %s", reason)
+	default:
+		return nil, errors.New("promise never settled: no timers or async operations left to wait for")
+	}
+}
@@ -0,0 +1,186 @@
+package jsengine
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunScript_TopLevelAwait(t *testing.T) {
+	engine := New()
+	defer engine.Close()
+
+	err := engine.RunScript(`
+var order = [];
+setTimeout(function() { order.push('timer'); }, 0);
+await new Promise(function(resolve) { setTimeout(resolve, 10); });
+order.push('after await');
+output.order = order.join(',');`)
+	if err != nil {
+		t.Fatalf("RunScript() error = %v", err)
+	}
+	if got := engine.GetOutput()["order"]; got != "timer,after await" {
+		t.Errorf("order = %v", got)
+	}
+}
+
+func TestRunScript_PromiseChainWithoutAwait(t *testing.T) {
+	engine := New()
+	defer engine.Close()
+
+	// Reactions to a promise resolved by a timer run before the script returns
+	err := engine.RunScript(`
+new Promise(function(resolve) { setTimeout(function() { resolve(2); }, 10); })
+	.then(function(n) { output.n = n * 21; });`)
+	if err != nil {
+		t.Fatalf("RunScript() error = %v", err)
+	}
+	if got := engine.GetOutput()["n"]; got != int64(42) {
+		t.Errorf("n = %v, want 42", got)
+	}
+}
+
+func TestEval_AwaitsPromise(t *testing.T) {
+	engine := New()
+	defer engine.Close()
+
+	result, err := engine.Eval("new Promise(function(r) { setTimeout(function() { r(5); }, 5); })")
+	if err != nil {
+		t.Fatalf("Eval() error = %v", err)
+	}
+	if result != int64(5) {
+		t.Errorf("result = %v, want 5", result)
+	}
+}
+
+func TestRunFile_TopLevelAwait(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"lib.js": `exports.later = function(v) { return new Promise(function(r) { setTimeout(function() { r(v); }, 5); }); };`,
+	})
+
+	engine := New()
+	defer engine.Close()
+
+	path := filepath.Join(dir, "main.js")
+	if err := engine.RunFile(path, "output.v = await require('./lib').later('ok');"); err != nil {
+		t.Fatalf("RunFile() error = %v", err)
+	}
+	if got := engine.GetOutput()["v"]; got != "ok" {
+		t.Errorf("v = %v, want ok", got)
+	}
+}
+
+func TestRunScript_AwaitErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"rejected", "await Promise.reject(new Error('nope'));", "uncaught (in promise) Error: nope"},
+		{"thrown after await", "await null;\nthrow new Error('late');", "late"},
+		{"never settled", "await new Promise(function() {});", "promise never settled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := New()
+			defer engine.Close()
+
+			err := engine.RunScript(tt.script)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("RunScript() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPAsync(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(20 * time.Millisecond)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"method": %q, "path": %q}`, r.Method, r.URL.Path)
+	}))
+	defer server.Close()
+
+	engine := New()
+	defer engine.Close()
+	engine.SetVariable("BASE", server.URL)
+
+	err := engine.RunScript(`
+var results = await Promise.all([
+	http.getAsync(BASE + '/slow'),
+	http.postAsync(BASE + '/a', {body: {x: 1}}),
+	http.requestAsync('PUT', BASE + '/b'),
+]);
+output.async = results.map(function(r) { return r.json.method + ' ' + r.json.path; }).join(',');
+output.sync = http.get(BASE + '/c').json.path;
+output.ok = results[0].ok && results[0].status === 200;`)
+	if err != nil {
+		t.Fatalf("RunScript() error = %v", err)
+	}
+
+	out := engine.GetOutput()
+	if out["async"] != "GET /slow,POST /a,PUT /b" {
+		t.Errorf("async = %v", out["async"])
+	}
+	if out["sync"] != "/c" {
+		t.Errorf("sync = %v", out["sync"])
+	}
+	if out["ok"] != true {
+		t.Errorf("ok = %v", out["ok"])
+	}
+}
+
+func TestHTTPAsync_FailureRejects(t *testing.T) {
+	engine := New()
+	defer engine.Close()
+
+	err := engine.RunScript(`
+try {
+	await http.getAsync('http://127.0.0.1:1/unreachable', {timeout: 1000});
+	output.caught = 'no';
+} catch (e) {
+	output.caught = e.message.indexOf('HTTP request failed') === 0;
+}`)
+	if err != nil {
+		t.Fatalf("RunScript() error = %v", err)
+	}
+	if got := engine.GetOutput()["caught"]; got != true {
+		t.Errorf("caught = %v, want true", got)
+	}
+}
+
+func TestHTTPAsync_AbandonedAtScriptEnd(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	engine := New()
+	defer engine.Close()
+	engine.SetVariable("BASE", server.URL)
+	engine.SetTimeout(100 * time.Millisecond)
+
+	// A request nobody awaits still keeps the script waiting until the timeout
+	err := engine.RunScript("http.getAsync(BASE);")
+	if err == nil || !strings.Contains(err.Error(), "waiting for timers or async operations") {
+		t.Fatalf("RunScript() error = %v", err)
+	}
+	if engine.loop.pending != 0 {
+		t.Errorf("pending = %d after script end, want 0", engine.loop.pending)
+	}
+
+	// The engine stays usable
+	if err := engine.RunScript("output.next = 1;"); err != nil {
+		t.Fatalf("RunScript() error = %v", err)
+	}
+}
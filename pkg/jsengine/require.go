@@ -0,0 +1,199 @@
+package jsengine
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/logger"
+	"github.com/dop251/goja"
+)
+
+// moduleWrapper wraps a module's source in a function, CommonJS style. The
+// header stays on the module's first line so line numbers in errors match
+// the file.
+const moduleWrapper = "(function(exports, require, module, __filename, __dirname) {"
+
+// modules implements CommonJS require() for scripts: relative paths resolve
+// against the requiring file, bare names against the search path.
+type modules struct {
+	baseDir string                  // Directory for require() in inline scripts
+	paths   []string                // Directories searched for bare module names
+	cache   map[string]*goja.Object // Loaded module objects by absolute path
+	loading []string                // Modules being loaded, outermost first
+}
+
+// SetBaseDir sets the directory require() resolves relative paths against
+// in inline scripts (evalScript, inline runScript).
+func (e *Engine) SetBaseDir(dir string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.modules.baseDir = dir
+	e.setRequire(dir)
+}
+
+// SetModulePaths sets the directories require() searches for bare module
+// names such as require('api').
+func (e *Engine) SetModulePaths(paths []string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.modules.paths = append([]string(nil), paths...)
+}
+
+// RunFile runs the script read from path. require() in the script resolves
+// relative to the file, and errors carry the file's line numbers.
+func (e *Engine) RunFile(path, script string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	prog, async, err := compileScript(path, script)
+	if err != nil {
+		return fmt.Errorf("JS runtime error: %w", err)
+	}
+
+	e.setRequire(filepath.Dir(path))
+	defer e.setRequire(e.modules.baseDir)
+
+	if _, err := e.run(func() (goja.Value, error) { return e.runtime.RunProgram(prog) }, async); err != nil {
+		if ex, ok := err.(*goja.Exception); ok {
+			err = &scriptError{ex}
+		}
+		return fmt.Errorf("JS runtime error: %w", err)
+	}
+	return nil
+}
+
+// scriptError reports an exception at the innermost script position in its
+// stack, so errors thrown by Go functions such as require() point at the
+// line that called them.
+type scriptError struct {
+	ex *goja.Exception
+}
+
+func (e *scriptError) Error() string {
+	for _, frame := range e.ex.Stack() {
+		if pos := frame.Position(); pos.Filename != "" {
+			return fmt.Sprintf("%s at %s:%d:%d", e.ex.Value(), pos.Filename, pos.Line, pos.Column)
+		}
+	}
+	return e.ex.Error()
+}
+
+func (e *scriptError) Unwrap() error { return e.ex }
+
+// newError returns a JS Error with message.
+func (e *Engine) newError(message string) *goja.Object {
+	obj, err := e.runtime.New(e.runtime.Get("Error"), e.runtime.ToValue(message))
+	if err != nil {
+		return e.runtime.NewGoError(errors.New(message))
+	}
+	return obj
+}
+
+// setRequire binds the global require() to dir.
+func (e *Engine) setRequire(dir string) {
+	if err := e.runtime.Set("require", e.requireFunc(dir)); err != nil {
+		logger.Warn("failed to set JS runtime global 'require': %v", err)
+	}
+}
+
+// requireFunc returns a require() that resolves relative paths against dir.
+// Called with e.mu held: modules run inside the script that requires them.
+func (e *Engine) requireFunc(dir string) func(goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) < 1 {
+			panic(e.runtime.NewTypeError("require requires 1 argument"))
+		}
+		path, err := e.modules.resolve(dir, call.Arguments[0].String())
+		if err != nil {
+			panic(e.newError(err.Error()))
+		}
+		exports, err := e.loadModule(path)
+		if err != nil {
+			if ex, ok := err.(*goja.Exception); ok {
+				panic(ex) // Keep the module's own stack
+			}
+			panic(e.newError(err.Error()))
+		}
+		return exports
+	}
+}
+
+// resolve finds the file a require() specifier refers to: the path itself,
+// with ".js" added, or its index.js.
+func (m *modules) resolve(dir, spec string) (string, error) {
+	var candidates []string
+	switch {
+	case filepath.IsAbs(spec):
+		candidates = []string{spec}
+	case strings.HasPrefix(spec, "./"), strings.HasPrefix(spec, "../"):
+		candidates = []string{filepath.Join(dir, spec)}
+	default:
+		for _, p := range m.paths {
+			candidates = append(candidates, filepath.Join(p, spec))
+		}
+	}
+
+	for _, c := range candidates {
+		for _, file := range []string{c, c + ".js", filepath.Join(c, "index.js")} {
+			if info, err := os.Stat(file); err == nil && !info.IsDir() {
+				return filepath.Abs(file)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("cannot find module '%s' (no module paths configured)", spec)
+	}
+	return "", fmt.Errorf("cannot find module '%s'", spec)
+}
+
+// loadModule runs the module at path once and returns its module.exports.
+// Later calls return the cached exports.
+func (e *Engine) loadModule(path string) (goja.Value, error) {
+	if module, ok := e.modules.cache[path]; ok {
+		return module.Get("exports"), nil
+	}
+	for i, p := range e.modules.loading {
+		if p == path {
+			chain := append(append([]string(nil), e.modules.loading[i:]...), path)
+			return nil, fmt.Errorf("require cycle: %s", strings.Join(chain, " -> "))
+		}
+	}
+
+	data, err := os.ReadFile(path) //#nosec G304 -- module required by a flow script
+	if err != nil {
+		return nil, err
+	}
+	prog, err := goja.Compile(path, moduleWrapper+string(data)+"\n})", false)
+	if err != nil {
+		return nil, err
+	}
+	wrapper, err := e.runtime.RunProgram(prog)
+	if err != nil {
+		return nil, err
+	}
+	fn, ok := goja.AssertFunction(wrapper)
+	if !ok {
+		return nil, fmt.Errorf("%s: not a module", path)
+	}
+
+	module := e.runtime.NewObject()
+	exports := e.runtime.NewObject()
+	if err := module.Set("exports", exports); err != nil {
+		return nil, err
+	}
+
+	e.modules.loading = append(e.modules.loading, path)
+	defer func() { e.modules.loading = e.modules.loading[:len(e.modules.loading)-1] }()
+
+	dir := filepath.Dir(path)
+	if _, err := fn(goja.Undefined(), exports, e.runtime.ToValue(e.requireFunc(dir)), module,
+		e.runtime.ToValue(path), e.runtime.ToValue(dir)); err != nil {
+		return nil, err
+	}
+
+	e.modules.cache[path] = module
+	return module.Get("exports"), nil
+}
@@ -0,0 +1,141 @@
+package jsengine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates files under dir from a map of relative path to contents.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRequire(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"scripts/login.js": `var api = require('./lib/api.js');
+var token = require('./lib/token');
+output.result = api.get('/me') + ' ' + token.value + ' ' + require('shared').name;`,
+		"scripts/lib/api.js": `var token = require('./token.js');
+exports.get = function(path) { return 'GET ' + path + ' ' + token.value; };`,
+		"scripts/lib/token.js": `loads = (typeof loads === 'undefined' ? 0 : loads) + 1;
+module.exports = {value: 'abc'};`,
+		"modules/shared/index.js": `module.exports.name = 'shared:' + __filename.endsWith('index.js');`,
+	})
+
+	engine := New()
+	defer engine.Close()
+	engine.SetModulePaths([]string{filepath.Join(dir, "modules")})
+
+	path := filepath.Join(dir, "scripts/login.js")
+	data, _ := os.ReadFile(path)
+	if err := engine.RunFile(path, string(data)); err != nil {
+		t.Fatalf("RunFile() error = %v", err)
+	}
+
+	if got := engine.GetOutput()["result"]; got != "GET /me abc abc shared:true" {
+		t.Errorf("result = %v", got)
+	}
+	// token.js is required twice but runs once
+	if loads, _ := engine.Eval("loads"); loads != int64(1) {
+		t.Errorf("token.js ran %v times, want 1", loads)
+	}
+}
+
+func TestRequire_InlineScriptUsesBaseDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"helpers.js": `exports.twice = function(n) { return n * 2; };`})
+
+	engine := New()
+	defer engine.Close()
+	engine.SetBaseDir(dir)
+
+	result, err := engine.Eval("require('./helpers').twice(21)")
+	if err != nil {
+		t.Fatalf("Eval() error = %v", err)
+	}
+	if result != int64(42) {
+		t.Errorf("result = %v, want 42", result)
+	}
+}
+
+func TestRequire_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			name:  "missing module",
+			files: map[string]string{"main.js": `require('./nope');`},
+			want:  []string{"cannot find module './nope' at ", "main.js:1:"},
+		},
+		{
+			name:  "bare name without module paths",
+			files: map[string]string{"main.js": `require('api');`},
+			want:  []string{"cannot find module 'api' (no module paths configured)"},
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"main.js": `require('./a');`,
+				"a.js":    `require('./b');`,
+				"b.js":    `require('./a');`,
+			},
+			want: []string{"require cycle:", "a.js -> ", "b.js -> ", "a.js"},
+		},
+		{
+			name: "error keeps module file and line",
+			files: map[string]string{
+				"main.js": `require('./lib');`,
+				"lib.js":  "var x = 1;\nthrow new Error('boom');",
+			},
+			want: []string{"boom", "lib.js:2:"},
+		},
+		{
+			name: "syntax error keeps module file and line",
+			files: map[string]string{
+				"main.js": `require('./lib');`,
+				"lib.js":  "var x = 1;\nvar = ;",
+			},
+			want: []string{"lib.js", "Line 2:"},
+		},
+		{
+			name:  "error in main script has its file and line",
+			files: map[string]string{"main.js": "var a = 1;\nundefinedFn();"},
+			want:  []string{"main.js:2:"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			engine := New()
+			defer engine.Close()
+
+			path := filepath.Join(dir, "main.js")
+			err := engine.RunFile(path, tt.files["main.js"])
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package jsengine
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/core"
+	"github.com/dop251/goja"
+)
+
+// DefaultTimeout is how long a script may run, including its timers, when
+// neither the step nor the flow sets a timeout.
+const DefaultTimeout = 30 * time.Second
+
+// errInterrupted is the value a timed-out script is interrupted with.
+var errInterrupted = errors.New("script interrupted")
+
+// SetTimeout sets how long each script may run, including its pending
+// timers. Zero or less means no limit.
+func (e *Engine) SetTimeout(d time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.timeout = d
+}
+
+// Timeout returns the current script timeout.
+func (e *Engine) Timeout() time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.timeout
+}
+
+// run runs a script's main body, then its event loop, within the script
+// timeout. A script still running at the deadline is interrupted. With
+// await set and a promise from body, run waits for the promise and returns
+// its result. When run returns, nothing of the script is left running:
+// setTimeout callbacks have run, and intervals and async operations still
+// active are cancelled. Called with e.mu held.
+func (e *Engine) run(body func() (goja.Value, error), await bool) (goja.Value, error) {
+	timeout := e.timeout
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+		fired := make(chan struct{})
+		t := time.AfterFunc(timeout, func() {
+			e.runtime.Interrupt(errInterrupted)
+			close(fired)
+		})
+		defer func() {
+			if !t.Stop() {
+				<-fired
+			}
+			e.runtime.ClearInterrupt()
+		}()
+	}
+	e.loop.start(deadline)
+	defer e.loop.cancel()
+
+	value, err := body()
+	var promise *goja.Promise
+	if err == nil && await && value != nil {
+		promise, _ = value.Export().(*goja.Promise)
+	}
+	if err == nil {
+		err = e.runLoop(deadline, promise)
+	}
+	if err == nil && promise != nil {
+		value, err = settle(promise)
+	}
+
+	var interrupted *goja.InterruptedError
+	if errors.As(err, &interrupted) && interrupted.Value() == errInterrupted {
+		return nil, timeoutError(timeout, interrupted.Stack())
+	}
+	return value, err
+}
+
+// timeoutError reports a script timeout with the JS stack where the script
+// was interrupted.
+func timeoutError(timeout time.Duration, stack []goja.StackFrame) error {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "script timed out after %v", timeout)
+	if len(stack) == 0 {
+		b.WriteString(" waiting for timers or async operations")
+	}
+	for i := range stack {
+		b.WriteString("\n\tat ")
+		stack[i].Write(&b)
+	}
+	return core.ErrTimeout.WithMessage(b.String())
+}
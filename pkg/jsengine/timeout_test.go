@@ -0,0 +1,129 @@
+package jsengine
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/core"
+)
+
+func TestTimeout(t *testing.T) {
+	tests := []struct {
+		name      string
+		script    string
+		wantStack string
+	}{
+		{"infinite loop", "function spin() { while (true) {} }\nspin();", "spin"},
+		{"pending setTimeout", "setTimeout(function() {}, 5000);", ""},
+		{"busy timer callback", "setTimeout(function busy() { while (true) {} }, 10);", "busy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := New()
+			defer engine.Close()
+			engine.SetTimeout(100 * time.Millisecond)
+
+			start := time.Now()
+			err := engine.RunScript(tt.script)
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("RunScript took %v, want about 100ms", elapsed)
+			}
+
+			var execErr *core.ExecutionError
+			if !errors.As(err, &execErr) || execErr.Code != core.ErrTimeout.Code {
+				t.Fatalf("error = %v, want timeout", err)
+			}
+			if !strings.Contains(err.Error(), "script timed out after 100ms") {
+				t.Errorf("error = %q", err)
+			}
+			if tt.wantStack != "" && !strings.Contains(err.Error(), "at "+tt.wantStack) {
+				t.Errorf("error %q has no JS stack with %q", err, tt.wantStack)
+			}
+
+			// The engine is usable again after an interruption
+			if v, err := engine.Eval("1 + 1"); err != nil || v != int64(2) {
+				t.Errorf("Eval after timeout = %v, %v", v, err)
+			}
+		})
+	}
+}
+
+func TestTimeout_SlowHTTP(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	for _, script := range []string{
+		"http.get(BASE);",
+		"try { http.get(BASE); } catch (e) { output.caught = e.message; }",
+		"await http.getAsync(BASE);",
+	} {
+		t.Run(script, func(t *testing.T) {
+			engine := New()
+			defer engine.Close()
+			engine.SetVariable("BASE", server.URL)
+			engine.SetTimeout(100 * time.Millisecond)
+
+			start := time.Now()
+			err := engine.RunScript(script)
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("RunScript took %v, want about 100ms", elapsed)
+			}
+
+			var execErr *core.ExecutionError
+			if !errors.As(err, &execErr) || execErr.Code != core.ErrTimeout.Code {
+				t.Fatalf("error = %v, want timeout", err)
+			}
+			if caught, ok := engine.GetOutput()["caught"]; ok {
+				t.Errorf("script caught %v, want the timeout to stop it", caught)
+			}
+		})
+	}
+}
+
+func TestTimers_CancelledAtScriptEnd(t *testing.T) {
+	engine := New()
+	defer engine.Close()
+
+	engine.SetVariable("ticks", int64(0))
+	if err := engine.RunScript("setInterval(function() { ticks = ticks + 1; }, 10);"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// An interval left running doesn't fire after the script has returned
+	time.Sleep(50 * time.Millisecond)
+	if ticks, _ := engine.Eval("ticks"); ticks != int64(0) {
+		t.Errorf("ticks = %v, want 0", ticks)
+	}
+	if n := len(engine.loop.timers); n != 0 {
+		t.Errorf("%d timers pending after script end", n)
+	}
+}
+
+func TestTimers_RunInDueOrder(t *testing.T) {
+	engine := New()
+	defer engine.Close()
+
+	err := engine.RunScript(`
+		var order = [];
+		setTimeout(function() { order.push('b'); }, 30);
+		setTimeout(function() { order.push('a'); }, 10);
+		setTimeout(function() { order.push('c'); setTimeout(function() { order.push('d'); }, 0); }, 30);
+	`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := engine.EvalString("order.join('')"); got != "abcd" {
+		t.Errorf("order = %q, want abcd", got)
+	}
+}
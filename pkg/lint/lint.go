@@ -0,0 +1,216 @@
+// Package lint checks Maestro flow files against style rules that catch
+// brittle or hard-to-maintain flows: missing tags, raw coordinates,
+// hard-coded sleeps and the like. Rules see both the parsed flow and the
+// original YAML nodes.
+package lint
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLongFlowSteps is the step count above which long-flow-labels asks
+// for step labels.
+const DefaultLongFlowSteps = 15
+
+// Finding is a rule violation.
+type Finding struct {
+	Rule    string `json:"rule"`
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+func (f Finding) String() string {
+	pos := flow.Position{File: f.File, Line: f.Line, Column: f.Column}
+	return fmt.Sprintf("%s: %s (%s)", pos, f.Message, f.Rule)
+}
+
+// Config selects and tunes rules.
+type Config struct {
+	Disable       []string // Rule names to turn off
+	LongFlowSteps int      // Threshold for long-flow-labels (0 = DefaultLongFlowSteps)
+}
+
+// File is a flow file as rules see it.
+type File struct {
+	Path    string
+	Flow    *flow.Flow
+	Config  *yaml.Node // Config header mapping, nil if the file has none
+	Steps   *yaml.Node // Step sequence, nil if the file has none
+	Subflow bool       // Only used through runFlow, not run as a test
+}
+
+// Rule is a lint rule.
+type Rule struct {
+	Name        string
+	Description string
+	check       func(f *File, cfg Config) []Finding
+}
+
+// Linter applies the enabled rules to flow files.
+type Linter struct {
+	cfg   Config
+	rules []Rule
+}
+
+// New creates a Linter. It fails if cfg disables a rule that does not exist.
+func New(cfg Config) (*Linter, error) {
+	if cfg.LongFlowSteps <= 0 {
+		cfg.LongFlowSteps = DefaultLongFlowSteps
+	}
+	disabled := make(map[string]bool, len(cfg.Disable))
+	for _, name := range cfg.Disable {
+		if !isRule(name) {
+			return nil, fmt.Errorf("unknown lint rule %q", name)
+		}
+		disabled[name] = true
+	}
+	l := &Linter{cfg: cfg}
+	for _, r := range Rules {
+		if !disabled[r.Name] {
+			l.rules = append(l.rules, r)
+		}
+	}
+	return l, nil
+}
+
+// LintFile reads, parses and lints a flow file. subflow marks files that are
+// only run through runFlow, which rules about test metadata skip.
+func (l *Linter) LintFile(path string, subflow bool) ([]Finding, error) {
+	data, err := os.ReadFile(path) //#nosec G304 -- path is user-provided flow file
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return l.Lint(data, path, subflow)
+}
+
+// Lint lints flow YAML read from path.
+func (l *Linter) Lint(data []byte, path string, subflow bool) ([]Finding, error) {
+	f, err := load(data, path)
+	if err != nil {
+		return nil, err
+	}
+	f.Subflow = subflow
+
+	var findings []Finding
+	for _, r := range l.rules {
+		findings = append(findings, r.check(f, l.cfg)...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Line != findings[j].Line {
+			return findings[i].Line < findings[j].Line
+		}
+		return findings[i].Column < findings[j].Column
+	})
+	return findings, nil
+}
+
+// load parses data into a File. Line numbers in the nodes count from the
+// start of the file, like step positions.
+func load(data []byte, path string) (*File, error) {
+	parsed, err := flow.Parse(data, path)
+	if err != nil {
+		return nil, err
+	}
+	f := &File{Path: path, Flow: parsed}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, &flow.ParseError{Path: path, Message: err.Error()}
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		switch root := doc.Content[0]; root.Kind {
+		case yaml.MappingNode:
+			if f.Config == nil {
+				f.Config = root
+			}
+		case yaml.SequenceNode:
+			if f.Steps == nil {
+				f.Steps = root
+			}
+		}
+	}
+	return f, nil
+}
+
+func isRule(name string) bool {
+	for _, r := range Rules {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// configValue returns the node for key in the config header, or nil.
+func (f *File) configValue(key string) (*yaml.Node, *yaml.Node) {
+	if f.Config == nil {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(f.Config.Content); i += 2 {
+		if f.Config.Content[i].Value == key {
+			return f.Config.Content[i], f.Config.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// finding returns a finding for rule located at pos.
+func (f *File) finding(rule string, pos flow.Position, format string, args ...interface{}) Finding {
+	return Finding{
+		Rule:    rule,
+		File:    f.Path,
+		Line:    pos.Line,
+		Column:  pos.Column,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// nodePos returns the position of a YAML node.
+func nodePos(node *yaml.Node) flow.Position {
+	return flow.Position{Line: node.Line, Column: node.Column}
+}
+
+// walkSteps calls fn for every step, including nested and hook steps.
+func (f *File) walkSteps(fn func(flow.Step)) {
+	walk(f.Flow.Config.OnFlowStart, fn)
+	walk(f.Flow.Steps, fn)
+	walk(f.Flow.Config.OnFlowComplete, fn)
+	walk(f.Flow.Config.OnFlowFailure, fn)
+}
+
+func walk(steps []flow.Step, fn func(flow.Step)) {
+	for _, step := range steps {
+		fn(step)
+		walk(step.Recovery(), fn)
+		switch s := step.(type) {
+		case *flow.RepeatStep:
+			walk(s.Steps, fn)
+		case *flow.ForEachStep:
+			walk(s.Steps, fn)
+		case *flow.RetryStep:
+			walk(s.Steps, fn)
+		case *flow.RunFlowStep:
+			walk(s.Steps, fn)
+		case *flow.IfStep:
+			walk(s.Then, fn)
+			walk(s.Else, fn)
+		}
+	}
+}
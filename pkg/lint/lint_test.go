@@ -0,0 +1,158 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func lintString(t *testing.T, cfg Config, yaml string, subflow bool) []Finding {
+	t.Helper()
+	l, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings, err := l.Lint([]byte(yaml), filepath.Join(t.TempDir(), "flow.yaml"), subflow)
+	if err != nil {
+		t.Fatalf("Lint() error = %v", err)
+	}
+	return findings
+}
+
+func rulesOf(findings []Finding) []string {
+	rules := make([]string, len(findings))
+	for i, f := range findings {
+		rules[i] = f.Rule
+	}
+	return rules
+}
+
+func TestLint_Rules(t *testing.T) {
+	yaml := `appId: com.example
+env:
+  USER: alice
+  UNUSED: x
+---
+- launchApp
+- tapOnPoint: {x: 10, y: 20}
+- assertVisible:
+    text: Welcome
+    optional: true
+- evalScript: ${sleep(1000)}
+- inputText: ${USER}
+- repeat:
+    times: 2
+    commands:
+      - tapOnPoint: {point: "50%, 50%"}
+`
+	findings := lintString(t, Config{}, yaml, false)
+	want := []struct {
+		rule string
+		line int
+	}{
+		{"require-tags", 1},
+		{"unused-env", 4},
+		{"no-tap-on-point", 7},
+		{"no-optional-assertion", 8},
+		{"no-hardcoded-delay", 11},
+		{"no-tap-on-point", 16},
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("got findings %v, want %d", findings, len(want))
+	}
+	for i, w := range want {
+		if findings[i].Rule != w.rule || findings[i].Line != w.line {
+			t.Errorf("finding %d = %s, want %s at line %d", i, findings[i], w.rule, w.line)
+		}
+	}
+	if !strings.Contains(findings[1].Message, "UNUSED") {
+		t.Errorf("unused-env message = %q", findings[1].Message)
+	}
+}
+
+func TestLint_Disable(t *testing.T) {
+	findings := lintString(t, Config{Disable: []string{"require-tags", "no-tap-on-point"}},
+		"appId: x\n---\n- tapOnPoint: {x: 1, y: 2}\n", false)
+	if len(findings) != 0 {
+		t.Errorf("expected no findings, got %v", findings)
+	}
+
+	if _, err := New(Config{Disable: []string{"no-such-rule"}}); err == nil {
+		t.Error("expected error for unknown rule")
+	}
+}
+
+func TestLint_SubflowSkipsTags(t *testing.T) {
+	findings := lintString(t, Config{}, "appId: x\n---\n- back\n", true)
+	if len(findings) != 0 {
+		t.Errorf("expected no findings for subflow, got %v", findings)
+	}
+}
+
+func TestLint_LongFlowLabels(t *testing.T) {
+	yaml := "appId: x\ntags: [a]\n---\n- tapOn:\n    text: a\n    label: first\n- back\n- back\n- back\n"
+	findings := lintString(t, Config{LongFlowSteps: 3}, yaml, false)
+	if len(findings) != 1 || findings[0].Rule != "long-flow-labels" || findings[0].Line != 7 {
+		t.Fatalf("unexpected findings: %v", findings)
+	}
+	if !strings.Contains(findings[0].Message, "4 steps and 3 without a label") {
+		t.Errorf("message = %q", findings[0].Message)
+	}
+
+	if findings := lintString(t, Config{LongFlowSteps: 4}, yaml, false); len(findings) != 0 {
+		t.Errorf("flow at the threshold should pass, got %v", findings)
+	}
+}
+
+func TestLint_Dependencies(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.yaml":      "appId: x\ntags: [a]\nenv:\n  SUB: 1\n  SCRIPT: 2\n  CONFIG: 3\nname: ${CONFIG}\n---\n- runFlow: sub/child.yaml\n- runScript: wait.js\n",
+		"sub/child.yaml": "- inputText: ${SUB}\n",
+		"wait.js":        "const until = Date.now() + SCRIPT\nwhile (Date.now() < until) {}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings, err := l.LintFile(filepath.Join(dir, "main.yaml"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Env used in a subflow, a script and the config counts as used; the
+	// script's busy loop is a hard-coded delay
+	if got := rulesOf(findings); len(got) != 1 || got[0] != "no-hardcoded-delay" {
+		t.Fatalf("unexpected findings: %v", findings)
+	}
+	if !strings.HasPrefix(findings[0].Message, "wait.js: ") {
+		t.Errorf("message = %q", findings[0].Message)
+	}
+}
+
+func TestLint_ParseError(t *testing.T) {
+	l, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Lint([]byte("- notAStep\n"), "flow.yaml", false); err == nil {
+		t.Error("expected parse error")
+	}
+}
+
+func TestFinding_String(t *testing.T) {
+	f := Finding{Rule: "require-tags", File: "a.yaml", Line: 1, Column: 1, Message: "flow has no tags"}
+	if got := f.String(); got != "a.yaml:1:1: flow has no tags (require-tags)" {
+		t.Errorf("String() = %q", got)
+	}
+}
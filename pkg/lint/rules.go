@@ -0,0 +1,244 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"gopkg.in/yaml.v3"
+)
+
+// Rules lists every rule, in the order they are documented.
+var Rules = []Rule{
+	{
+		Name:        "require-tags",
+		Description: "Flows run as tests should have tags so --include-tags and --exclude-tags can select them",
+		check:       checkRequireTags,
+	},
+	{
+		Name:        "no-tap-on-point",
+		Description: "tapOnPoint uses raw coordinates that break across screen sizes and layouts; tap an element instead",
+		check:       checkTapOnPoint,
+	},
+	{
+		Name:        "no-hardcoded-delay",
+		Description: "Scripts should not sleep for a fixed time; wait for a condition with extendedWaitUntil instead",
+		check:       checkHardcodedDelay,
+	},
+	{
+		Name:        "long-flow-labels",
+		Description: "Steps in long flows should have a label so reports and logs are readable",
+		check:       checkLongFlowLabels,
+	},
+	{
+		Name:        "unused-env",
+		Description: "env variables defined in the flow config should be used by the flow, its subflows or its scripts",
+		check:       checkUnusedEnv,
+	},
+	{
+		Name:        "no-optional-assertion",
+		Description: "Assertions should not be optional: an optional assertion never fails the flow",
+		check:       checkOptionalAssertion,
+	},
+}
+
+func checkRequireTags(f *File, _ Config) []Finding {
+	if f.Subflow || len(f.Flow.Config.Tags) > 0 {
+		return nil
+	}
+	pos := flow.Position{Line: 1, Column: 1}
+	if f.Config != nil {
+		pos = nodePos(f.Config)
+	}
+	return []Finding{f.finding("require-tags", pos, "flow has no tags")}
+}
+
+func checkTapOnPoint(f *File, _ Config) []Finding {
+	var findings []Finding
+	f.walkSteps(func(step flow.Step) {
+		if step.Type() == flow.StepTapOnPoint {
+			findings = append(findings, f.finding("no-tap-on-point", step.Position(),
+				"tapOnPoint taps raw coordinates; tap an element selector instead"))
+		}
+	})
+	return findings
+}
+
+// sleepPattern matches fixed waits in JavaScript: sleep/delay helpers,
+// setTimeout and busy loops on the clock.
+var sleepPattern = regexp.MustCompile(`\b(?:sleep|delay)\s*\(|\bsetTimeout\s*\(|while\s*\(\s*(?:Date\.now\(\)|new Date\(\))`)
+
+func checkHardcodedDelay(f *File, _ Config) []Finding {
+	var findings []Finding
+	dir := filepath.Dir(f.Path)
+	f.walkSteps(func(step flow.Step) {
+		var script, source string
+		switch s := step.(type) {
+		case *flow.EvalScriptStep:
+			script = s.Script
+		case *flow.RunScriptStep:
+			script = s.ScriptPath()
+			if strings.HasSuffix(script, ".js") {
+				data, err := os.ReadFile(resolvePath(dir, script)) //#nosec G304 -- script referenced by the flow
+				if err != nil {
+					return // Missing scripts are reported by validate
+				}
+				source = script
+				script = string(data)
+			}
+		default:
+			return
+		}
+		if sleepPattern.MatchString(script) {
+			msg := "script waits for a fixed time; use extendedWaitUntil with a condition instead"
+			if source != "" {
+				msg = source + ": " + msg
+			}
+			findings = append(findings, f.finding("no-hardcoded-delay", step.Position(), "%s", msg))
+		}
+	})
+	return findings
+}
+
+func checkLongFlowLabels(f *File, cfg Config) []Finding {
+	steps := f.Flow.Steps
+	if len(steps) <= cfg.LongFlowSteps {
+		return nil
+	}
+	var first flow.Step
+	unlabeled := 0
+	for _, step := range steps {
+		if step.Label() == "" {
+			if first == nil {
+				first = step
+			}
+			unlabeled++
+		}
+	}
+	if unlabeled == 0 {
+		return nil
+	}
+	return []Finding{f.finding("long-flow-labels", first.Position(),
+		"flow has %d steps and %d without a label (first here); label steps in flows longer than %d steps",
+		len(steps), unlabeled, cfg.LongFlowSteps)}
+}
+
+func checkUnusedEnv(f *File, _ Config) []Finding {
+	_, env := f.configValue("env")
+	if env == nil || env.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var text strings.Builder
+	collectScalars(f.Config, env, &text)
+	collectScalars(f.Steps, nil, &text)
+	collectDependencies(f.Flow, filepath.Dir(f.Path), map[string]bool{f.Path: true}, &text)
+	corpus := text.String()
+
+	var findings []Finding
+	for i := 0; i+1 < len(env.Content); i += 2 {
+		key := env.Content[i]
+		pattern := regexp.MustCompile(`(^|[^\w$])` + regexp.QuoteMeta(key.Value) + `($|[^\w$])`)
+		if !pattern.MatchString(corpus) {
+			findings = append(findings, f.finding("unused-env", nodePos(key),
+				"env variable %s is not used by the flow, its subflows or its scripts", key.Value))
+		}
+	}
+	return findings
+}
+
+// collectScalars writes every scalar below node to text, one per line,
+// skipping the keys of the env mapping that defines the variables.
+func collectScalars(node, env *yaml.Node, text *strings.Builder) {
+	if node == nil {
+		return
+	}
+	if node.Kind == yaml.ScalarNode {
+		text.WriteString(node.Value)
+		text.WriteByte('\n')
+		return
+	}
+	for i, child := range node.Content {
+		if node == env && i%2 == 0 {
+			continue
+		}
+		collectScalars(child, env, text)
+	}
+}
+
+// collectDependencies writes the contents of the runFlow files, custom
+// commands and scripts f uses, recursively, to text. Env variables are visible to all of them.
+func collectDependencies(f *flow.Flow, dir string, seen map[string]bool, text *strings.Builder) {
+	var visit func(steps []flow.Step)
+	visit = func(steps []flow.Step) {
+		walk(steps, func(step flow.Step) {
+			var path string
+			switch s := step.(type) {
+			case *flow.RunFlowStep:
+				path = s.File
+			case *flow.RetryStep:
+				path = s.File
+			case *flow.CustomCommandStep:
+				path = s.File
+			case *flow.RunScriptStep:
+				if p := s.ScriptPath(); strings.HasSuffix(p, ".js") {
+					path = p
+				}
+			}
+			if path == "" {
+				return
+			}
+			path = resolvePath(dir, path)
+			if seen[path] {
+				return
+			}
+			seen[path] = true
+			data, err := os.ReadFile(path) //#nosec G304 -- file referenced by the flow
+			if err != nil {
+				return
+			}
+			text.Write(data)
+			text.WriteByte('\n')
+			if strings.HasSuffix(path, ".js") {
+				return
+			}
+			if sub, err := flow.Parse(data, path); err == nil {
+				collectDependencies(sub, filepath.Dir(path), seen, text)
+			}
+		})
+	}
+	visit(f.Config.OnFlowStart)
+	visit(f.Steps)
+	visit(f.Config.OnFlowComplete)
+	visit(f.Config.OnFlowFailure)
+}
+
+// assertionTypes are the steps that check app state.
+var assertionTypes = map[flow.StepType]bool{
+	flow.StepAssertVisible:         true,
+	flow.StepAssertNotVisible:      true,
+	flow.StepAssertTrue:            true,
+	flow.StepAssertCondition:       true,
+	flow.StepAssertWithAI:          true,
+	flow.StepAssertNoDefectsWithAI: true,
+}
+
+func checkOptionalAssertion(f *File, _ Config) []Finding {
+	var findings []Finding
+	f.walkSteps(func(step flow.Step) {
+		if assertionTypes[step.Type()] && step.IsOptional() {
+			findings = append(findings, f.finding("no-optional-assertion", step.Position(),
+				"%s is optional, so it can never fail the flow", step.Type()))
+		}
+	})
+	return findings
+}
+
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -6,11 +6,14 @@ import (
 	"log"
 	"os"
 	"sync"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/secrets"
 )
 
 var (
 	globalLogger *log.Logger
 	logFile      *os.File
+	logWriter    io.Writer // logFile with secret values redacted
 	mu           sync.Mutex
 )
 
@@ -31,7 +34,8 @@ func Init(logPath string) error {
 	}
 
 	logFile = f
-	globalLogger = log.New(f, "", log.Ltime|log.Lmicroseconds)
+	logWriter = secrets.Writer(f)
+	globalLogger = log.New(logWriter, "", log.Ltime|log.Lmicroseconds)
 
 	return nil
 }
@@ -44,6 +48,7 @@ func Close() {
 	if logFile != nil {
 		logFile.Close()
 		logFile = nil
+		logWriter = nil
 	}
 }
 
@@ -87,13 +92,14 @@ func Warn(format string, v ...interface{}) {
 	}
 }
 
-// GetWriter returns the underlying writer for use by drivers.
+// GetWriter returns the underlying writer for use by drivers. Secret values
+// are redacted from what is written to it.
 func GetWriter() io.Writer {
 	mu.Lock()
 	defer mu.Unlock()
 
-	if logFile != nil {
-		return logFile
+	if logWriter != nil {
+		return logWriter
 	}
 	return io.Discard
 }
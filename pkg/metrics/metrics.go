@@ -0,0 +1,212 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Default is the registry served by Serve and written to by the Record* helpers.
+var Default = NewRegistry()
+
+// commandBuckets are upper bounds (seconds) for command durations. Most steps
+// finish within a second; element waits and app launches can take much longer.
+var commandBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
+
+// flowBuckets are upper bounds (seconds) for flow durations.
+var flowBuckets = []float64{5, 15, 30, 60, 120, 300, 600, 1200}
+
+var (
+	flowsTotal = Default.NewCounterVec("maestro_flows_total",
+		"Flows finished, by status.", "status")
+	commandsTotal = Default.NewCounterVec("maestro_commands_total",
+		"Commands executed, by step type, driver and status.", "step_type", "driver", "status")
+	commandDuration = Default.NewHistogramVec("maestro_command_duration_seconds",
+		"Command execution time, by step type and driver.", commandBuckets, "step_type", "driver")
+	elementFindRetries = Default.NewCounterVec("maestro_element_find_retries_total",
+		"Element lookup attempts that missed while polling for an element.", "driver")
+	driverReconnects = Default.NewCounterVec("maestro_driver_reconnects_total",
+		"Driver sessions re-created after the first session on the same connection (WDA only).", "driver")
+	deviceFlows = Default.NewCounterVec("maestro_device_flows_total",
+		"Flows finished per device worker, by status.", "device", "status")
+	deviceFlowDuration = Default.NewHistogramVec("maestro_device_flow_duration_seconds",
+		"Flow execution time per device worker.", flowBuckets, "device")
+	workers = newWorkerTracker(Default)
+)
+
+// RecordFlow counts a finished flow.
+func RecordFlow(status string) {
+	flowsTotal.Inc(status)
+}
+
+// RecordCommand counts a finished command and observes its duration.
+func RecordCommand(stepType, driver, status string, d time.Duration) {
+	commandsTotal.Inc(stepType, driver, status)
+	commandDuration.Observe(d.Seconds(), stepType, driver)
+}
+
+// RecordFindRetry counts an element lookup attempt that missed while polling.
+func RecordFindRetry(driver string) {
+	elementFindRetries.Inc(driver)
+}
+
+// RecordReconnect counts a driver session re-created after the first one.
+// Only WDA re-creates sessions (on launchApp); UIAutomator2 keeps the one
+// session created at startup, so Android reconnects aren't measured.
+func RecordReconnect(driver string) {
+	driverReconnects.Inc(driver)
+}
+
+// WorkerStarted marks a device worker as available. Utilization is measured
+// from this point.
+func WorkerStarted(device string) {
+	workers.started(device, time.Now())
+}
+
+// WorkerFlowStarted marks a device worker as busy.
+func WorkerFlowStarted(device string) {
+	workers.busy(device, time.Now())
+}
+
+// WorkerFlowFinished marks a device worker as idle and records the flow outcome.
+func WorkerFlowFinished(device, status string, d time.Duration) {
+	workers.idle(device, time.Now())
+	deviceFlows.Inc(device, status)
+	deviceFlowDuration.Observe(d.Seconds(), device)
+}
+
+// WorkerStopped marks a device worker as finished. Its busy time stops
+// accumulating but remains exported.
+func WorkerStopped(device string) {
+	workers.stopped(device, time.Now())
+}
+
+// ============================================================================
+// WORKER UTILIZATION
+// ============================================================================
+
+// workerTracker tracks busy/idle time per device worker. Busy seconds and
+// utilization are computed at scrape time so a long flow is visible while it
+// is still running, not only when it finishes.
+type workerTracker struct {
+	mu      sync.Mutex
+	devices map[string]*workerState
+	now     func() time.Time
+}
+
+type workerState struct {
+	start     time.Time
+	stop      time.Time // zero while the worker is running
+	busySince time.Time // zero while idle
+	busyTotal time.Duration
+}
+
+func newWorkerTracker(r *Registry) *workerTracker {
+	t := &workerTracker{
+		devices: make(map[string]*workerState),
+		now:     time.Now,
+	}
+	r.register(t)
+	return t
+}
+
+func (t *workerTracker) state(device string, now time.Time) *workerState {
+	s, ok := t.devices[device]
+	if !ok {
+		s = &workerState{start: now}
+		t.devices[device] = s
+	}
+	return s
+}
+
+func (t *workerTracker) started(device string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// A later run on the same device keeps accumulating (busy seconds is a counter)
+	s := t.state(device, now)
+	s.stop = time.Time{}
+}
+
+func (t *workerTracker) busy(device string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.state(device, now)
+	if s.busySince.IsZero() {
+		s.busySince = now
+	}
+}
+
+func (t *workerTracker) idle(device string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.state(device, now)
+	if !s.busySince.IsZero() {
+		s.busyTotal += now.Sub(s.busySince)
+		s.busySince = time.Time{}
+	}
+}
+
+func (t *workerTracker) stopped(device string, now time.Time) {
+	t.idle(device, now)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.state(device, now).stop = now
+}
+
+func (t *workerTracker) write(w io.Writer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	devices := make([]string, 0, len(t.devices))
+	for d := range t.devices {
+		devices = append(devices, d)
+	}
+	sort.Strings(devices)
+
+	type sample struct {
+		busy        bool
+		busySeconds float64
+		utilization float64
+	}
+	samples := make([]sample, len(devices))
+	for i, d := range devices {
+		s := t.devices[d]
+		busy := s.busyTotal
+		if !s.busySince.IsZero() {
+			busy += now.Sub(s.busySince)
+		}
+		end := now
+		if !s.stop.IsZero() {
+			end = s.stop
+		}
+		var util float64
+		if elapsed := end.Sub(s.start); elapsed > 0 {
+			util = busy.Seconds() / elapsed.Seconds()
+		}
+		samples[i] = sample{busy: !s.busySince.IsZero(), busySeconds: busy.Seconds(), utilization: util}
+	}
+
+	labels := []string{"device"}
+
+	writeHeader(w, "maestro_device_worker_busy", "1 if the device worker is running a flow, 0 if idle.", "gauge")
+	for i, d := range devices {
+		v := 0.0
+		if samples[i].busy {
+			v = 1
+		}
+		fmt.Fprintf(w, "maestro_device_worker_busy%s %s\n", formatLabels(labels, []string{d}), formatValue(v))
+	}
+
+	writeHeader(w, "maestro_device_worker_busy_seconds_total", "Time the device worker spent running flows.", "counter")
+	for i, d := range devices {
+		fmt.Fprintf(w, "maestro_device_worker_busy_seconds_total%s %s\n", formatLabels(labels, []string{d}), formatValue(samples[i].busySeconds))
+	}
+
+	writeHeader(w, "maestro_device_worker_utilization_ratio", "Fraction of the worker's lifetime spent running flows.", "gauge")
+	for i, d := range devices {
+		fmt.Fprintf(w, "maestro_device_worker_utilization_ratio%s %s\n", formatLabels(labels, []string{d}), formatValue(samples[i].utilization))
+	}
+}
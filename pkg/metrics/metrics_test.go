@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorkerTracker(t *testing.T) {
+	r := NewRegistry()
+	tr := newWorkerTracker(r)
+
+	base := time.Now()
+	tr.started("emulator-5554", base)
+	tr.busy("emulator-5554", base.Add(2*time.Second))
+	tr.idle("emulator-5554", base.Add(8*time.Second))
+	tr.busy("emulator-5554", base.Add(9*time.Second))
+
+	// Scrape mid-flow: the running flow's time is included
+	tr.now = func() time.Time { return base.Add(10 * time.Second) }
+
+	var buf bytes.Buffer
+	tr.write(&buf)
+	out := buf.String()
+
+	for _, want := range []string{
+		`maestro_device_worker_busy{device="emulator-5554"} 1`,
+		`maestro_device_worker_busy_seconds_total{device="emulator-5554"} 7`,
+		`maestro_device_worker_utilization_ratio{device="emulator-5554"} 0.7`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	// Stopped worker: busy time is frozen and utilization uses the stop time
+	tr.stopped("emulator-5554", base.Add(10*time.Second))
+	tr.now = func() time.Time { return base.Add(100 * time.Second) }
+
+	buf.Reset()
+	tr.write(&buf)
+	out = buf.String()
+	for _, want := range []string{
+		`maestro_device_worker_busy{device="emulator-5554"} 0`,
+		`maestro_device_worker_busy_seconds_total{device="emulator-5554"} 7`,
+		`maestro_device_worker_utilization_ratio{device="emulator-5554"} 0.7`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestRecordHelpers(t *testing.T) {
+	before := flowsTotal.Value("passed")
+	RecordFlow("passed")
+	if got := flowsTotal.Value("passed"); got != before+1 {
+		t.Errorf("flows passed = %v, want %v", got, before+1)
+	}
+
+	beforeCount := commandDuration.Count("tapOn", "mock")
+	RecordCommand("tapOn", "mock", "passed", 300*time.Millisecond)
+	if got := commandDuration.Count("tapOn", "mock"); got != beforeCount+1 {
+		t.Errorf("command observations = %d, want %d", got, beforeCount+1)
+	}
+
+	beforeRetries := elementFindRetries.Value("mock")
+	RecordFindRetry("mock")
+	if got := elementFindRetries.Value("mock"); got != beforeRetries+1 {
+		t.Errorf("retries = %v, want %v", got, beforeRetries+1)
+	}
+
+	beforeReconnects := driverReconnects.Value("mock")
+	RecordReconnect("mock")
+	if got := driverReconnects.Value("mock"); got != beforeReconnects+1 {
+		t.Errorf("reconnects = %v, want %v", got, beforeReconnects+1)
+	}
+}
+
+func TestServe(t *testing.T) {
+	srv, err := Serve("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+	defer srv.Close()
+
+	RecordFlow("failed")
+
+	resp, err := http.Get("http://" + srv.Addr() + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), `maestro_flows_total{status="failed"}`) {
+		t.Errorf("flows metric missing from:\n%s", body)
+	}
+	if !strings.Contains(string(body), "# TYPE maestro_command_duration_seconds histogram") {
+		t.Error("command histogram missing")
+	}
+}
+
+func TestServe_AddressInUse(t *testing.T) {
+	srv, err := Serve("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+	defer srv.Close()
+
+	if _, err := Serve(srv.Addr()); err == nil {
+		t.Error("expected error when address is already in use")
+	}
+}
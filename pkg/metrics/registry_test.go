@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func render(r *Registry) string {
+	var buf bytes.Buffer
+	r.Write(&buf)
+	return buf.String()
+}
+
+func TestCounterVec(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("test_total", "Test counter.", "status")
+
+	c.Inc("passed")
+	c.Inc("passed")
+	c.Add(3, "failed")
+	c.Add(-1, "failed") // ignored
+
+	if got := c.Value("passed"); got != 2 {
+		t.Errorf("passed = %v, want 2", got)
+	}
+	if got := c.Value("failed"); got != 3 {
+		t.Errorf("failed = %v, want 3", got)
+	}
+
+	want := `# HELP test_total Test counter.
+# TYPE test_total counter
+test_total{status="failed"} 3
+test_total{status="passed"} 2
+`
+	if got := render(r); got != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGaugeVec(t *testing.T) {
+	r := NewRegistry()
+	g := r.NewGaugeVec("test_gauge", "Test gauge.")
+
+	g.Set(5)
+	g.Add(-2)
+	if got := g.Value(); got != 3 {
+		t.Errorf("value = %v, want 3", got)
+	}
+	if !strings.Contains(render(r), "test_gauge 3\n") {
+		t.Errorf("unexpected output:\n%s", render(r))
+	}
+}
+
+func TestHistogramVec(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogramVec("test_seconds", "Test histogram.", []float64{1, 0.5}, "type")
+
+	h.Observe(0.2, "tap")
+	h.Observe(0.5, "tap") // le is inclusive
+	h.Observe(0.7, "tap")
+	h.Observe(3, "tap")
+
+	if got := h.Count("tap"); got != 4 {
+		t.Errorf("count = %d, want 4", got)
+	}
+
+	want := `# HELP test_seconds Test histogram.
+# TYPE test_seconds histogram
+test_seconds_bucket{type="tap",le="0.5"} 2
+test_seconds_bucket{type="tap",le="1"} 3
+test_seconds_bucket{type="tap",le="+Inf"} 4
+test_seconds_sum{type="tap"} 4.4
+test_seconds_count{type="tap"} 4
+`
+	if got := render(r); got != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestLabelEscaping(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("test_total", "Test.", "name")
+	c.Inc("say \"hi\"\\\n")
+
+	if !strings.Contains(render(r), `test_total{name="say \"hi\"\\\n"} 1`) {
+		t.Errorf("label not escaped:\n%s", render(r))
+	}
+}
+
+func TestLabelCountMismatchPanics(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("test_total", "Test.", "a", "b")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for wrong label count")
+		}
+	}()
+	c.Inc("only-one")
+}
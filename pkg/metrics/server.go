@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/logger"
+)
+
+// Handler returns an HTTP handler that serves the registry in the Prometheus text format.
+func Handler(r *Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.Write(w)
+	})
+}
+
+// Server serves the default registry on /metrics.
+type Server struct {
+	srv      *http.Server
+	listener net.Listener
+}
+
+// Serve starts serving metrics on addr (e.g. ":9464"). It returns once the
+// address is bound, so a port conflict is reported before the run starts.
+func Serve(addr string) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler(Default))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintln(w, "maestro-runner metrics: /metrics")
+	})
+
+	s := &Server{
+		srv:      &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second},
+		listener: ln,
+	}
+	go func() {
+		if err := s.srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Metrics server stopped: %v", err)
+		}
+	}()
+	return s, nil
+}
+
+// Addr returns the bound address (useful when addr used port 0).
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	return s.srv.Close()
+}
@@ -0,0 +1,96 @@
+package recorder
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/core"
+)
+
+var (
+	wmSizeRe     = regexp.MustCompile(`(Physical|Override) size:\s*(\d+)x(\d+)`)
+	focusedAppRe = regexp.MustCompile(`mCurrentFocus=Window\{\S+ \S+ ([\w.]+)/`)
+	imeFrameRe   = regexp.MustCompile(`\b(?:mFrame|frame)=\[(\d+),(\d+)\]\[(\d+),(\d+)\]`)
+)
+
+// Shell runs a shell command on the device.
+type Shell func(cmd string) (string, error)
+
+// ParseScreenSize parses `wm size` output. An override size takes
+// precedence over the physical size.
+func ParseScreenSize(output string) (width, height int, err error) {
+	for _, m := range wmSizeRe.FindAllStringSubmatch(output, -1) {
+		w, _ := strconv.Atoi(m[2])
+		h, _ := strconv.Atoi(m[3])
+		if width == 0 || m[1] == "Override" {
+			width, height = w, h
+		}
+	}
+	if width == 0 {
+		return 0, 0, fmt.Errorf("unexpected wm size output: %q", strings.TrimSpace(output))
+	}
+	return width, height, nil
+}
+
+// ParseFocusedPackage returns the package of the focused window from
+// `dumpsys window` output, or "" if none is focused.
+func ParseFocusedPackage(output string) string {
+	if m := focusedAppRe.FindStringSubmatch(output); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// ParseKeyboardShown reports whether `dumpsys input_method` output says the
+// soft keyboard is shown.
+func ParseKeyboardShown(output string) bool {
+	return strings.Contains(output, "mInputShown=true")
+}
+
+// ParseKeyboardFrame returns the input method window frame from
+// `dumpsys window InputMethod` output.
+func ParseKeyboardFrame(output string) (core.Bounds, bool) {
+	m := imeFrameRe.FindStringSubmatch(output)
+	if m == nil {
+		return core.Bounds{}, false
+	}
+	left, _ := strconv.Atoi(m[1])
+	top, _ := strconv.Atoi(m[2])
+	right, _ := strconv.Atoi(m[3])
+	bottom, _ := strconv.Atoi(m[4])
+	if right <= left || bottom <= top {
+		return core.Bounds{}, false
+	}
+	return core.Bounds{X: left, Y: top, Width: right - left, Height: bottom - top}, true
+}
+
+// AndroidDevice adapts a UIAutomator2 driver and adb shell to the recorder.
+type AndroidDevice struct {
+	core.Inspector
+	shell        Shell
+	screenWidth  int
+	screenHeight int
+}
+
+// NewAndroidDevice creates a recorder device for a screen of the given size.
+func NewAndroidDevice(inspector core.Inspector, shell Shell, screenWidth, screenHeight int) *AndroidDevice {
+	return &AndroidDevice{Inspector: inspector, shell: shell, screenWidth: screenWidth, screenHeight: screenHeight}
+}
+
+// Keyboard implements Device. When the keyboard is shown but its window frame
+// can't be read, the bottom 40% of the screen is assumed.
+func (d *AndroidDevice) Keyboard() (core.Bounds, bool) {
+	out, err := d.shell("dumpsys input_method")
+	if err != nil || !ParseKeyboardShown(out) {
+		return core.Bounds{}, false
+	}
+	if out, err := d.shell("dumpsys window InputMethod"); err == nil {
+		if frame, ok := ParseKeyboardFrame(out); ok {
+			return frame, true
+		}
+	}
+	top := d.screenHeight * 6 / 10
+	return core.Bounds{X: 0, Y: top, Width: d.screenWidth, Height: d.screenHeight - top}, true
+}
@@ -0,0 +1,69 @@
+package recorder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/core"
+)
+
+func TestParseScreenSize(t *testing.T) {
+	w, h, err := ParseScreenSize("Physical size: 1080x2340\n")
+	if err != nil || w != 1080 || h != 2340 {
+		t.Errorf("got %dx%d, %v", w, h, err)
+	}
+
+	w, h, _ = ParseScreenSize("Physical size: 1080x2340\nOverride size: 720x1560\n")
+	if w != 720 || h != 1560 {
+		t.Errorf("override: got %dx%d", w, h)
+	}
+
+	if _, _, err := ParseScreenSize("error: no devices"); err == nil {
+		t.Error("expected error for unexpected output")
+	}
+}
+
+func TestParseFocusedPackage(t *testing.T) {
+	out := "  mCurrentFocus=Window{3c1f0e1 u0 com.example.app/com.example.app.MainActivity}\n"
+	if got := ParseFocusedPackage(out); got != "com.example.app" {
+		t.Errorf("got %q", got)
+	}
+	if got := ParseFocusedPackage("mCurrentFocus=null"); got != "" {
+		t.Errorf("got %q, want empty", got)
+	}
+}
+
+func TestParseKeyboardFrame(t *testing.T) {
+	b, ok := ParseKeyboardFrame("    mWindowFrames: frame=[0,1380][1080,2340] display=[0,0][1080,2340]")
+	if !ok || b != (core.Bounds{X: 0, Y: 1380, Width: 1080, Height: 960}) {
+		t.Errorf("got %+v, %v", b, ok)
+	}
+	if _, ok := ParseKeyboardFrame("mFrame=[0,0][0,0]"); ok {
+		t.Error("empty frame should not be reported")
+	}
+}
+
+func TestAndroidDeviceKeyboard(t *testing.T) {
+	outputs := map[string]string{
+		"dumpsys input_method":       "  mInputShown=true",
+		"dumpsys window InputMethod": "no frame here",
+	}
+	shell := func(cmd string) (string, error) {
+		if out, ok := outputs[cmd]; ok {
+			return out, nil
+		}
+		return "", errors.New("unexpected command")
+	}
+	d := NewAndroidDevice(nil, shell, 1000, 2000)
+
+	// Frame unknown: bottom 40% of the screen
+	b, ok := d.Keyboard()
+	if !ok || b != (core.Bounds{X: 0, Y: 1200, Width: 1000, Height: 800}) {
+		t.Errorf("got %+v, %v", b, ok)
+	}
+
+	outputs["dumpsys input_method"] = "mInputShown=false"
+	if _, ok := d.Keyboard(); ok {
+		t.Error("keyboard should not be shown")
+	}
+}
@@ -0,0 +1,125 @@
+package recorder
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/devicelab-dev/maestro-runner/pkg/inspector"
+)
+
+// Encode serializes recorded steps as a flow file that flow.Parse accepts.
+// The config section is written only when appID is set.
+func Encode(appID string, steps []flow.Step) ([]byte, error) {
+	seq := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, step := range steps {
+		node, err := stepNode(step)
+		if err != nil {
+			return nil, err
+		}
+		seq.Content = append(seq.Content, node)
+	}
+
+	var buf bytes.Buffer
+	if appID != "" {
+		fmt.Fprintf(&buf, "appId: %s\n---\n", appID)
+	}
+	if len(steps) == 0 {
+		buf.WriteString("[]\n")
+		return buf.Bytes(), nil
+	}
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(seq); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func stepNode(step flow.Step) (*yaml.Node, error) {
+	switch s := step.(type) {
+	case *flow.TapOnStep:
+		if s.Point != "" {
+			value := mapping("point", str(s.Point))
+			if s.LongPress {
+				value.Content = append(value.Content, key("longPress"), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+			}
+			return mapping(string(s.Type()), value), nil
+		}
+		value, err := selectorNode(s.Selector)
+		if err != nil {
+			return nil, err
+		}
+		return mapping(string(s.Type()), value), nil
+
+	case *flow.LongPressOnStep:
+		value, err := selectorNode(s.Selector)
+		if err != nil {
+			return nil, err
+		}
+		return mapping(string(s.Type()), value), nil
+
+	case *flow.SwipeStep:
+		value := mapping("start", str(s.Start), "end", str(s.End))
+		if s.Duration > 0 {
+			value.Content = append(value.Content, key("duration"), integer(s.Duration))
+		}
+		return mapping(string(s.Type()), value), nil
+
+	case *flow.InputTextStep:
+		return mapping(string(s.Type()), str(s.Text)), nil
+
+	case *flow.EraseTextStep:
+		return mapping(string(s.Type()), integer(s.Characters)), nil
+
+	case *flow.PressKeyStep:
+		return mapping(string(s.Type()), &yaml.Node{Kind: yaml.ScalarNode, Value: s.Key}), nil
+
+	case *flow.BackStep:
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: string(s.Type())}, nil
+
+	default:
+		return nil, fmt.Errorf("cannot encode %s step", step.Type())
+	}
+}
+
+// selectorNode encodes a selector in the same shape the inspector suggests.
+// A text-only selector uses the scalar shorthand (tapOn: "Login").
+func selectorNode(sel flow.Selector) (*yaml.Node, error) {
+	if sel.Text != "" && sel.ID == "" && sel.Index == "" && !sel.HasRelativeSelector() {
+		return str(sel.Text), nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(inspector.SelectorYAML(sel)), &doc); err != nil {
+		return nil, fmt.Errorf("encode selector: %w", err)
+	}
+	return doc.Content[0], nil
+}
+
+// mapping builds a mapping node from alternating keys and values.
+func mapping(pairs ...interface{}) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		node.Content = append(node.Content, key(pairs[i].(string)), pairs[i+1].(*yaml.Node))
+	}
+	return node
+}
+
+func key(name string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: name}
+}
+
+func str(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Style: yaml.DoubleQuotedStyle, Value: value}
+}
+
+func integer(value int) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(value)}
+}
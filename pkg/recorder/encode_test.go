@@ -0,0 +1,84 @@
+package recorder
+
+import (
+	"testing"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+)
+
+func TestEncode_RoundTrip(t *testing.T) {
+	steps := []flow.Step{
+		&flow.TapOnStep{BaseStep: flow.BaseStep{StepType: flow.StepTapOn}, Selector: flow.Selector{ID: "email"}},
+		&flow.InputTextStep{BaseStep: flow.BaseStep{StepType: flow.StepInputText}, Text: "user@example.com"},
+		&flow.EraseTextStep{BaseStep: flow.BaseStep{StepType: flow.StepEraseText}, Characters: 4},
+		&flow.TapOnStep{BaseStep: flow.BaseStep{StepType: flow.StepTapOn}, Selector: flow.Selector{Text: "Sign in"}},
+		&flow.TapOnStep{BaseStep: flow.BaseStep{StepType: flow.StepTapOn}, Selector: flow.Selector{
+			Text:  "Edit",
+			Below: &flow.Selector{Text: "Profile"},
+		}},
+		&flow.TapOnStep{BaseStep: flow.BaseStep{StepType: flow.StepTapOn}, Point: "50%, 95%", LongPress: true},
+		&flow.LongPressOnStep{BaseStep: flow.BaseStep{StepType: flow.StepLongPressOn}, Selector: flow.Selector{Text: "Item", Index: "2"}},
+		&flow.SwipeStep{BaseStep: flow.BaseStep{StepType: flow.StepSwipe}, Start: "50%, 80%", End: "50%, 20%", Duration: 300},
+		&flow.PressKeyStep{BaseStep: flow.BaseStep{StepType: flow.StepPressKey}, Key: "Enter"},
+		&flow.BackStep{BaseStep: flow.BaseStep{StepType: flow.StepBack}},
+	}
+
+	data, err := Encode("com.example.app", steps)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	f, err := flow.Parse(data, "recorded.yaml")
+	if err != nil {
+		t.Fatalf("Parse failed: %v\n%s", err, data)
+	}
+	if f.Config.AppID != "com.example.app" {
+		t.Errorf("appId = %q", f.Config.AppID)
+	}
+	if len(f.Steps) != len(steps) {
+		t.Fatalf("parsed %d steps, want %d:\n%s", len(f.Steps), len(steps), data)
+	}
+	for i, s := range f.Steps {
+		if s.Type() != steps[i].Type() {
+			t.Errorf("step %d type = %s, want %s", i, s.Type(), steps[i].Type())
+		}
+	}
+
+	if s := f.Steps[0].(*flow.TapOnStep); s.Selector.ID != "email" {
+		t.Errorf("tapOn id = %q", s.Selector.ID)
+	}
+	if s := f.Steps[2].(*flow.EraseTextStep); s.Characters != 4 {
+		t.Errorf("eraseText = %d", s.Characters)
+	}
+	if s := f.Steps[3].(*flow.TapOnStep); s.Selector.Text != "Sign in" {
+		t.Errorf("tapOn text = %q", s.Selector.Text)
+	}
+	if s := f.Steps[4].(*flow.TapOnStep); s.Selector.Below == nil || s.Selector.Below.Text != "Profile" {
+		t.Errorf("relative selector lost: %+v", s.Selector)
+	}
+	if s := f.Steps[5].(*flow.TapOnStep); !s.LongPress || s.Point != "50%, 95%" {
+		t.Errorf("point tap = %+v", s)
+	}
+	if s := f.Steps[6].(*flow.LongPressOnStep); s.Selector.Index != "2" {
+		t.Errorf("longPressOn index = %q", s.Selector.Index)
+	}
+	if s := f.Steps[7].(*flow.SwipeStep); s.Start != "50%, 80%" || s.Duration != 300 {
+		t.Errorf("swipe = %+v", s)
+	}
+}
+
+func TestEncode_NoAppID(t *testing.T) {
+	data, err := Encode("", []flow.Step{&flow.BackStep{BaseStep: flow.BaseStep{StepType: flow.StepBack}}})
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if string(data) != "- back\n" {
+		t.Errorf("got %q", data)
+	}
+}
+
+func TestEncode_Unsupported(t *testing.T) {
+	if _, err := Encode("", []flow.Step{&flow.HideKeyboardStep{BaseStep: flow.BaseStep{StepType: flow.StepHideKeyboard}}}); err == nil {
+		t.Error("expected error for unsupported step")
+	}
+}
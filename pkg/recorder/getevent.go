@@ -0,0 +1,172 @@
+package recorder
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AxisRange is the raw coordinate range of a touch input device.
+type AxisRange struct {
+	MaxX int
+	MaxY int
+}
+
+var (
+	addDeviceRe = regexp.MustCompile(`^add device \d+: (\S+)`)
+	absMaxRe    = regexp.MustCompile(`(ABS_MT_POSITION_[XY])\s*:.*\bmax (\d+)`)
+	eventLineRe = regexp.MustCompile(`^\[\s*(\d+)\.(\d+)\]\s+(\S+):\s+(\S+)\s+(\S+)\s+(\S+)`)
+)
+
+// ParseInputDevices parses `getevent -lp` output and returns the coordinate
+// ranges of devices that report multi-touch positions, keyed by device path.
+func ParseInputDevices(output string) map[string]AxisRange {
+	devices := make(map[string]AxisRange)
+	current := ""
+	for _, line := range strings.Split(output, "\n") {
+		if m := addDeviceRe.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			current = m[1]
+			continue
+		}
+		m := absMaxRe.FindStringSubmatch(line)
+		if m == nil || current == "" {
+			continue
+		}
+		limit, _ := strconv.Atoi(m[2])
+		r := devices[current]
+		if m[1] == "ABS_MT_POSITION_X" {
+			r.MaxX = limit
+		} else {
+			r.MaxY = limit
+		}
+		devices[current] = r
+	}
+	for path, r := range devices {
+		if r.MaxX == 0 || r.MaxY == 0 {
+			delete(devices, path)
+		}
+	}
+	return devices
+}
+
+// Point is a screen coordinate in pixels.
+type Point struct {
+	X int
+	Y int
+}
+
+// Gesture is one completed single-finger touch: from finger down to finger up.
+type Gesture struct {
+	Start    Point
+	End      Point
+	Duration time.Duration
+}
+
+// Event is a user interaction read from the device: a gesture or a key press.
+type Event struct {
+	Gesture *Gesture
+	Key     string // Linux key name, e.g. KEY_BACK
+}
+
+// EventParser turns `getevent -lt` output into gestures and key presses.
+// Only the first touch slot is tracked; extra fingers are ignored.
+type EventParser struct {
+	devices      map[string]AxisRange
+	screenWidth  int
+	screenHeight int
+
+	slot      int
+	down      bool
+	lifting   bool
+	started   bool
+	pos       Point
+	start     Point
+	startTime time.Duration
+}
+
+// NewEventParser creates a parser that scales raw touch coordinates of the
+// given input devices to a screen of the given size.
+func NewEventParser(devices map[string]AxisRange, screenWidth, screenHeight int) *EventParser {
+	return &EventParser{devices: devices, screenWidth: screenWidth, screenHeight: screenHeight}
+}
+
+// Feed processes one line of getevent output and returns an event when the
+// line completes one.
+func (p *EventParser) Feed(line string) (Event, bool) {
+	m := eventLineRe.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return Event{}, false
+	}
+	ts := parseTimestamp(m[1], m[2])
+	device, typ, code, value := m[3], m[4], m[5], m[6]
+
+	if typ == "EV_KEY" {
+		if strings.HasPrefix(code, "KEY_") && value == "UP" {
+			return Event{Key: code}, true
+		}
+		return Event{}, false
+	}
+
+	axes, ok := p.devices[device]
+	if !ok {
+		return Event{}, false
+	}
+
+	switch {
+	case typ == "EV_ABS" && code == "ABS_MT_SLOT":
+		p.slot = int(parseHex(value))
+	case p.slot != 0:
+		// Secondary fingers are not recorded
+	case typ == "EV_ABS" && code == "ABS_MT_TRACKING_ID":
+		if value == "ffffffff" {
+			p.lifting = p.down
+		} else if !p.down {
+			p.down = true
+			p.started = false
+			p.startTime = ts
+		}
+	case typ == "EV_ABS" && code == "ABS_MT_POSITION_X":
+		p.pos.X = scale(parseHex(value), axes.MaxX, p.screenWidth)
+	case typ == "EV_ABS" && code == "ABS_MT_POSITION_Y":
+		p.pos.Y = scale(parseHex(value), axes.MaxY, p.screenHeight)
+	case typ == "EV_SYN" && code == "SYN_REPORT":
+		if p.down && !p.started {
+			p.start = p.pos
+			p.started = true
+		}
+		if p.lifting {
+			p.down, p.lifting = false, false
+			return Event{Gesture: &Gesture{
+				Start:    p.start,
+				End:      p.pos,
+				Duration: ts - p.startTime,
+			}}, true
+		}
+	}
+	return Event{}, false
+}
+
+func parseTimestamp(sec, frac string) time.Duration {
+	s, _ := strconv.ParseInt(sec, 10, 64)
+	for len(frac) < 9 {
+		frac += "0"
+	}
+	ns, _ := strconv.ParseInt(frac[:9], 10, 64)
+	return time.Duration(s)*time.Second + time.Duration(ns)
+}
+
+func parseHex(value string) int64 {
+	v, err := strconv.ParseUint(value, 16, 32)
+	if err != nil {
+		return 0
+	}
+	return int64(int32(v))
+}
+
+func scale(raw int64, limit, size int) int {
+	if limit <= 0 || size <= 0 {
+		return int(raw)
+	}
+	return int(raw * int64(size) / int64(limit+1))
+}
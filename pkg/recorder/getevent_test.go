@@ -0,0 +1,100 @@
+package recorder
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const geteventCaps = `add device 1: /dev/input/event3
+  name:     "gpio-keys"
+  events:
+    KEY (0001): KEY_VOLUMEDOWN        KEY_VOLUMEUP          KEY_POWER
+add device 2: /dev/input/event1
+  name:     "virtio_input_multi_touch_6"
+  events:
+    ABS (0003): ABS_MT_SLOT           : value 0, min 0, max 9, fuzz 0, flat 0, resolution 0
+                ABS_MT_POSITION_X     : value 0, min 0, max 32767, fuzz 0, flat 0, resolution 0
+                ABS_MT_POSITION_Y     : value 0, min 0, max 32767, fuzz 0, flat 0, resolution 0
+                ABS_MT_TRACKING_ID    : value 0, min 0, max 9, fuzz 0, flat 0, resolution 0
+`
+
+func TestParseInputDevices(t *testing.T) {
+	devices := ParseInputDevices(geteventCaps)
+	if len(devices) != 1 {
+		t.Fatalf("expected 1 touch device, got %v", devices)
+	}
+	if r := devices["/dev/input/event1"]; r.MaxX != 32767 || r.MaxY != 32767 {
+		t.Errorf("unexpected range: %+v", r)
+	}
+}
+
+func feedAll(p *EventParser, lines string) []Event {
+	var events []Event
+	for _, line := range strings.Split(lines, "\n") {
+		if ev, ok := p.Feed(line); ok {
+			events = append(events, ev)
+		}
+	}
+	return events
+}
+
+func TestEventParser_Tap(t *testing.T) {
+	p := NewEventParser(ParseInputDevices(geteventCaps), 1080, 1920)
+
+	events := feedAll(p, `[   51337.500000] /dev/input/event1: EV_ABS       ABS_MT_TRACKING_ID   00000000
+[   51337.500000] /dev/input/event1: EV_ABS       ABS_MT_POSITION_X    00004000
+[   51337.500000] /dev/input/event1: EV_ABS       ABS_MT_POSITION_Y    00002000
+[   51337.500000] /dev/input/event1: EV_SYN       SYN_REPORT           00000000
+[   51337.580000] /dev/input/event1: EV_ABS       ABS_MT_TRACKING_ID   ffffffff
+[   51337.580000] /dev/input/event1: EV_SYN       SYN_REPORT           00000000`)
+
+	if len(events) != 1 || events[0].Gesture == nil {
+		t.Fatalf("expected one gesture, got %+v", events)
+	}
+	g := events[0].Gesture
+	if g.Start != (Point{X: 540, Y: 480}) || g.End != g.Start {
+		t.Errorf("unexpected points: %+v", g)
+	}
+	if g.Duration != 80*time.Millisecond {
+		t.Errorf("duration = %v, want 80ms", g.Duration)
+	}
+}
+
+func TestEventParser_SwipeIgnoresSecondFinger(t *testing.T) {
+	p := NewEventParser(map[string]AxisRange{"/dev/input/event1": {MaxX: 1079, MaxY: 1919}}, 1080, 1920)
+
+	events := feedAll(p, `[ 1.000000] /dev/input/event1: EV_ABS ABS_MT_TRACKING_ID 00000001
+[ 1.000000] /dev/input/event1: EV_ABS ABS_MT_POSITION_X 0000021c
+[ 1.000000] /dev/input/event1: EV_ABS ABS_MT_POSITION_Y 000005dc
+[ 1.000000] /dev/input/event1: EV_SYN SYN_REPORT 00000000
+[ 1.100000] /dev/input/event1: EV_ABS ABS_MT_SLOT 00000001
+[ 1.100000] /dev/input/event1: EV_ABS ABS_MT_POSITION_X 00000010
+[ 1.100000] /dev/input/event1: EV_ABS ABS_MT_SLOT 00000000
+[ 1.100000] /dev/input/event1: EV_ABS ABS_MT_POSITION_Y 000001f4
+[ 1.100000] /dev/input/event1: EV_SYN SYN_REPORT 00000000
+[ 1.300000] /dev/input/event1: EV_ABS ABS_MT_TRACKING_ID ffffffff
+[ 1.300000] /dev/input/event1: EV_SYN SYN_REPORT 00000000`)
+
+	if len(events) != 1 || events[0].Gesture == nil {
+		t.Fatalf("expected one gesture, got %+v", events)
+	}
+	g := events[0].Gesture
+	if g.Start != (Point{X: 540, Y: 1500}) || g.End != (Point{X: 540, Y: 500}) {
+		t.Errorf("unexpected points: %+v", g)
+	}
+}
+
+func TestEventParser_KeysAndNoise(t *testing.T) {
+	p := NewEventParser(ParseInputDevices(geteventCaps), 1080, 1920)
+
+	events := feedAll(p, `add device 1: /dev/input/event3
+[ 2.000000] /dev/input/event3: EV_KEY KEY_BACK DOWN
+[ 2.050000] /dev/input/event3: EV_KEY KEY_BACK UP
+[ 2.060000] /dev/input/event9: EV_ABS ABS_MT_TRACKING_ID 00000002
+garbage`)
+
+	if len(events) != 1 || events[0].Key != "KEY_BACK" {
+		t.Errorf("expected KEY_BACK, got %+v", events)
+	}
+}
@@ -0,0 +1,290 @@
+// Package recorder turns interactions on a device into flow steps: taps,
+// long presses, swipes, text entry and navigation keys. Touch coordinates are
+// mapped back to elements through the UI hierarchy, and each element gets the
+// most stable selector the inspector can verify.
+package recorder
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/core"
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+	"github.com/devicelab-dev/maestro-runner/pkg/inspector"
+)
+
+// Gesture classification thresholds.
+const (
+	longPressDuration = 500 * time.Millisecond
+	tapSlopPercent    = 3 // Max movement for a tap, in percent of screen width
+)
+
+// DefaultSettle is how long to wait after an interaction before capturing
+// the hierarchy the next interaction is mapped against.
+const DefaultSettle = 700 * time.Millisecond
+
+// Device is what the recorder needs from the device under test.
+type Device interface {
+	core.Inspector
+	// Keyboard returns the on-screen keyboard bounds, if it is shown.
+	Keyboard() (core.Bounds, bool)
+}
+
+// Options configures a Recorder.
+type Options struct {
+	ScreenWidth  int
+	ScreenHeight int
+	Settle       time.Duration   // Default: DefaultSettle
+	OnStep       func(flow.Step) // Called for each recorded step
+}
+
+// Recorder converts device events into flow steps.
+type Recorder struct {
+	device Device
+	opts   Options
+
+	snap          core.InspectSnapshot
+	keyboard      core.Bounds
+	keyboardShown bool
+
+	// typing is set while the user types on the soft keyboard. It holds the
+	// focused field's text before typing started.
+	typing *string
+
+	steps []flow.Step
+}
+
+// New creates a recorder.
+func New(device Device, opts Options) *Recorder {
+	if opts.Settle == 0 {
+		opts.Settle = DefaultSettle
+	}
+	return &Recorder{device: device, opts: opts}
+}
+
+// Start captures the initial hierarchy. Call it before the first event.
+func (r *Recorder) Start() error {
+	return r.capture()
+}
+
+// Handle records one event. Events are mapped against the hierarchy captured
+// after the previous event settled, i.e. what the user saw when acting.
+func (r *Recorder) Handle(ev Event) error {
+	switch {
+	case ev.Gesture != nil:
+		r.handleGesture(*ev.Gesture)
+	case ev.Key != "":
+		if !r.handleKey(ev.Key) {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	time.Sleep(r.opts.Settle)
+	return r.capture()
+}
+
+// Finish flushes pending text entry and returns all recorded steps.
+func (r *Recorder) Finish() []flow.Step {
+	r.flushText()
+	return r.steps
+}
+
+// Steps returns the steps recorded so far.
+func (r *Recorder) Steps() []flow.Step {
+	return r.steps
+}
+
+func (r *Recorder) capture() error {
+	snap, err := r.device.Inspect()
+	if err != nil {
+		return fmt.Errorf("capture hierarchy: %w", err)
+	}
+	r.snap = snap
+	r.keyboard, r.keyboardShown = r.device.Keyboard()
+	return nil
+}
+
+func (r *Recorder) handleGesture(g Gesture) {
+	if r.keyboardShown && contains(r.keyboard, g.Start) {
+		// Keystrokes are recorded as text once typing ends
+		if r.typing == nil {
+			text := r.focusedText()
+			r.typing = &text
+		}
+		return
+	}
+	r.flushText()
+
+	slop := r.opts.ScreenWidth * tapSlopPercent / 100
+	moved := abs(g.End.X-g.Start.X) > slop || abs(g.End.Y-g.Start.Y) > slop
+	switch {
+	case moved:
+		r.emit(&flow.SwipeStep{
+			BaseStep: flow.BaseStep{StepType: flow.StepSwipe},
+			Start:    r.percent(g.Start),
+			End:      r.percent(g.End),
+			Duration: int(g.Duration.Milliseconds()),
+		})
+	case g.Duration >= longPressDuration:
+		r.emit(r.tapStep(g.Start, true))
+	default:
+		r.emit(r.tapStep(g.Start, false))
+	}
+}
+
+// handleKey records navigation keys and reports whether the key was recorded.
+func (r *Recorder) handleKey(key string) bool {
+	switch key {
+	case "KEY_BACK":
+		r.flushText()
+		r.emit(&flow.BackStep{BaseStep: flow.BaseStep{StepType: flow.StepBack}})
+	case "KEY_HOME", "KEY_HOMEPAGE":
+		r.flushText()
+		r.emit(&flow.PressKeyStep{BaseStep: flow.BaseStep{StepType: flow.StepPressKey}, Key: "Home"})
+	case "KEY_ENTER":
+		r.flushText()
+		r.emit(&flow.PressKeyStep{BaseStep: flow.BaseStep{StepType: flow.StepPressKey}, Key: "Enter"})
+	default:
+		return false
+	}
+	return true
+}
+
+// tapStep builds a tap (or long press) on the element under p, falling back
+// to a point tap when no selector resolves to it.
+func (r *Recorder) tapStep(p Point, long bool) flow.Step {
+	if sel, ok := r.selectorAt(p); ok {
+		if long {
+			return &flow.LongPressOnStep{BaseStep: flow.BaseStep{StepType: flow.StepLongPressOn}, Selector: sel}
+		}
+		return &flow.TapOnStep{BaseStep: flow.BaseStep{StepType: flow.StepTapOn}, Selector: sel}
+	}
+	return &flow.TapOnStep{
+		BaseStep:  flow.BaseStep{StepType: flow.StepTapOn},
+		Point:     r.percent(p),
+		LongPress: long,
+	}
+}
+
+// selectorAt returns the best selector for the smallest element under p, or
+// for its clickable ancestor (where a tap on the element lands).
+func (r *Recorder) selectorAt(p Point) (flow.Selector, bool) {
+	if r.snap == nil {
+		return flow.Selector{}, false
+	}
+	elements := r.snap.Elements()
+	index := elementAt(elements, p)
+	if index < 0 {
+		return flow.Selector{}, false
+	}
+
+	candidates := []int{index}
+	for i := elements[index].Parent; i >= 0; i = elements[i].Parent {
+		if elements[i].Clickable {
+			candidates = append(candidates, i)
+			break
+		}
+	}
+	for _, i := range candidates {
+		suggestions, err := inspector.Suggest(r.snap, i)
+		if err == nil && len(suggestions) > 0 {
+			return suggestions[0].Selector, true
+		}
+	}
+	return flow.Selector{}, false
+}
+
+// flushText emits the text typed since typing started, comparing the focused
+// field before and after.
+func (r *Recorder) flushText() {
+	if r.typing == nil {
+		return
+	}
+	before, after := *r.typing, r.focusedText()
+	r.typing = nil
+
+	switch {
+	case after == before:
+		return
+	case strings.HasPrefix(after, before):
+		r.emit(inputText(strings.TrimPrefix(after, before)))
+	default:
+		if n := utf8.RuneCountInString(before); n > 0 {
+			r.emit(&flow.EraseTextStep{BaseStep: flow.BaseStep{StepType: flow.StepEraseText}, Characters: n})
+		}
+		if after != "" {
+			r.emit(inputText(after))
+		}
+	}
+}
+
+// focusedText returns the text of the focused editable field. Android reports
+// the hint as the text of an empty field, so that counts as empty.
+func (r *Recorder) focusedText() string {
+	if r.snap == nil {
+		return ""
+	}
+	for _, e := range r.snap.Elements() {
+		if e.Focused && isEditable(e) {
+			if e.Text == e.HintText {
+				return ""
+			}
+			return e.Text
+		}
+	}
+	return ""
+}
+
+func (r *Recorder) emit(step flow.Step) {
+	r.steps = append(r.steps, step)
+	if r.opts.OnStep != nil {
+		r.opts.OnStep(step)
+	}
+}
+
+func (r *Recorder) percent(p Point) string {
+	x, y := p.X, p.Y
+	if r.opts.ScreenWidth > 0 && r.opts.ScreenHeight > 0 {
+		x = p.X * 100 / r.opts.ScreenWidth
+		y = p.Y * 100 / r.opts.ScreenHeight
+	}
+	return fmt.Sprintf("%d%%, %d%%", x, y)
+}
+
+func inputText(text string) flow.Step {
+	return &flow.InputTextStep{BaseStep: flow.BaseStep{StepType: flow.StepInputText}, Text: text}
+}
+
+// elementAt returns the smallest visible element containing p, or -1.
+func elementAt(elements []core.InspectElement, p Point) int {
+	best, bestArea := -1, 0
+	for i, e := range elements {
+		area := e.Bounds.Width * e.Bounds.Height
+		if !e.Visible || area == 0 || !contains(e.Bounds, p) {
+			continue
+		}
+		if best < 0 || area <= bestArea {
+			best, bestArea = i, area
+		}
+	}
+	return best
+}
+
+func isEditable(e core.InspectElement) bool {
+	return strings.Contains(e.Class, "EditText") || strings.Contains(e.Class, "TextField")
+}
+
+func contains(b core.Bounds, p Point) bool {
+	return p.X >= b.X && p.X < b.X+b.Width && p.Y >= b.Y && p.Y < b.Y+b.Height
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
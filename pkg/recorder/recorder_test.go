@@ -0,0 +1,180 @@
+package recorder
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/core"
+	"github.com/devicelab-dev/maestro-runner/pkg/flow"
+)
+
+// fakeSnapshot matches id/text exactly and selects the first match.
+type fakeSnapshot struct {
+	elements []core.InspectElement
+}
+
+func (s *fakeSnapshot) Elements() []core.InspectElement { return s.elements }
+
+func (s *fakeSnapshot) Match(sel flow.Selector) (*core.InspectMatch, error) {
+	m := &core.InspectMatch{Matches: []int{}, Target: -1}
+	for _, e := range s.elements {
+		if sel.HasRelativeSelector() || sel.Index != "" {
+			break
+		}
+		if sel.ID != "" && e.ID != sel.ID || sel.Text != "" && e.Text != sel.Text {
+			continue
+		}
+		m.Matches = append(m.Matches, e.Index)
+	}
+	if len(m.Matches) > 0 {
+		m.Target = m.Matches[0]
+		b := s.elements[m.Target].Bounds
+		m.TapBounds = &b
+	}
+	return m, nil
+}
+
+// fakeDevice returns the next snapshot on each Inspect call and keeps
+// returning the last one.
+type fakeDevice struct {
+	snaps    []*fakeSnapshot
+	keyboard bool
+}
+
+func (d *fakeDevice) Inspect() (core.InspectSnapshot, error) {
+	snap := d.snaps[0]
+	if len(d.snaps) > 1 {
+		d.snaps = d.snaps[1:]
+	}
+	return snap, nil
+}
+
+func (d *fakeDevice) Keyboard() (core.Bounds, bool) {
+	return core.Bounds{X: 0, Y: 1200, Width: 1000, Height: 800}, d.keyboard
+}
+
+func elem(index int, id, text, class string, b core.Bounds) core.InspectElement {
+	return core.InspectElement{Index: index, Parent: -1, ID: id, Text: text, Class: class, Bounds: b, Visible: true}
+}
+
+func loginScreen(fieldText string, focused bool) *fakeSnapshot {
+	field := elem(1, "email", fieldText, "android.widget.EditText", core.Bounds{X: 0, Y: 100, Width: 1000, Height: 100})
+	field.HintText = "Email"
+	field.Focused = focused
+	return &fakeSnapshot{elements: []core.InspectElement{
+		elem(0, "", "", "android.widget.FrameLayout", core.Bounds{Width: 1000, Height: 2000}),
+		field,
+		elem(2, "", "Sign in", "android.widget.Button", core.Bounds{X: 0, Y: 300, Width: 1000, Height: 100}),
+	}}
+}
+
+func tap(x, y int) Event {
+	return Event{Gesture: &Gesture{Start: Point{x, y}, End: Point{x, y}, Duration: 50 * time.Millisecond}}
+}
+
+func describe(steps []flow.Step) string {
+	var parts []string
+	for _, s := range steps {
+		parts = append(parts, s.Describe())
+	}
+	return strings.Join(parts, " | ")
+}
+
+func TestRecorder_TapTypeAndSubmit(t *testing.T) {
+	device := &fakeDevice{snaps: []*fakeSnapshot{
+		loginScreen("Email", false), // initial
+		loginScreen("Email", true),  // after tapping the field
+		loginScreen("a", true),      // after keystrokes
+		loginScreen("ab", true),
+		loginScreen("ab", true), // after tapping Sign in
+	}}
+	var live []flow.Step
+	rec := New(device, Options{ScreenWidth: 1000, ScreenHeight: 2000, Settle: time.Millisecond, OnStep: func(s flow.Step) {
+		live = append(live, s)
+	}})
+	if err := rec.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	events := []Event{tap(500, 150)}
+	device.keyboard = true
+	events = append(events, tap(100, 1500), tap(200, 1500), tap(500, 350))
+	for _, ev := range events {
+		if err := rec.Handle(ev); err != nil {
+			t.Fatalf("Handle failed: %v", err)
+		}
+	}
+	steps := rec.Finish()
+
+	if len(steps) != 3 {
+		t.Fatalf("expected 3 steps, got %d: %s", len(steps), describe(steps))
+	}
+	if s, ok := steps[0].(*flow.TapOnStep); !ok || s.Selector.ID != "email" {
+		t.Errorf("step 0 = %#v, want tapOn id email", steps[0])
+	}
+	if s, ok := steps[1].(*flow.InputTextStep); !ok || s.Text != "ab" {
+		t.Errorf("step 1 = %#v, want inputText ab", steps[1])
+	}
+	if s, ok := steps[2].(*flow.TapOnStep); !ok || s.Selector.Text != "Sign in" {
+		t.Errorf("step 2 = %#v, want tapOn Sign in", steps[2])
+	}
+	if len(live) != 3 {
+		t.Errorf("OnStep called %d times, want 3", len(live))
+	}
+}
+
+func TestRecorder_EditReplacesText(t *testing.T) {
+	device := &fakeDevice{
+		snaps:    []*fakeSnapshot{loginScreen("old", true), loginScreen("new", true)},
+		keyboard: true,
+	}
+	rec := New(device, Options{ScreenWidth: 1000, ScreenHeight: 2000, Settle: time.Millisecond})
+	_ = rec.Start()
+	_ = rec.Handle(tap(100, 1500))
+	steps := rec.Finish()
+
+	if len(steps) != 2 {
+		t.Fatalf("expected eraseText + inputText, got %s", describe(steps))
+	}
+	if s, ok := steps[0].(*flow.EraseTextStep); !ok || s.Characters != 3 {
+		t.Errorf("step 0 = %#v", steps[0])
+	}
+	if s, ok := steps[1].(*flow.InputTextStep); !ok || s.Text != "new" {
+		t.Errorf("step 1 = %#v", steps[1])
+	}
+}
+
+func TestRecorder_GesturesAndKeys(t *testing.T) {
+	device := &fakeDevice{snaps: []*fakeSnapshot{loginScreen("", false)}}
+	rec := New(device, Options{ScreenWidth: 1000, ScreenHeight: 2000, Settle: time.Millisecond})
+	_ = rec.Start()
+
+	events := []Event{
+		{Gesture: &Gesture{Start: Point{500, 1600}, End: Point{500, 400}, Duration: 300 * time.Millisecond}},
+		{Gesture: &Gesture{Start: Point{500, 350}, End: Point{505, 352}, Duration: 800 * time.Millisecond}},
+		tap(500, 1900), // Only the root container: point tap
+		{Key: "KEY_VOLUMEUP"},
+		{Key: "KEY_BACK"},
+	}
+	for _, ev := range events {
+		_ = rec.Handle(ev)
+	}
+	steps := rec.Finish()
+
+	if len(steps) != 4 {
+		t.Fatalf("expected 4 steps, got %s", describe(steps))
+	}
+	if s, ok := steps[0].(*flow.SwipeStep); !ok || s.Start != "50%, 80%" || s.End != "50%, 20%" || s.Duration != 300 {
+		t.Errorf("step 0 = %#v", steps[0])
+	}
+	if s, ok := steps[1].(*flow.LongPressOnStep); !ok || s.Selector.Text != "Sign in" {
+		t.Errorf("step 1 = %#v", steps[1])
+	}
+	if s, ok := steps[2].(*flow.TapOnStep); !ok || s.Point != "50%, 95%" {
+		t.Errorf("step 2 = %#v", steps[2])
+	}
+	if _, ok := steps[3].(*flow.BackStep); !ok {
+		t.Errorf("step 3 = %#v", steps[3])
+	}
+}
@@ -331,7 +331,7 @@ func mapAllureStatus(s Status) string {
 		return "passed"
 	case StatusFailed:
 		return "failed"
-	case StatusSkipped:
+	case StatusSkipped, StatusConditionSkipped:
 		return "skipped"
 	default:
 		return "unknown"
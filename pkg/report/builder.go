@@ -16,6 +16,7 @@ type BuilderConfig struct {
 	CI            *CI    // CI/CD information (optional)
 	RunnerVersion string // Maestro runner version
 	DriverName    string // Driver name (appium, native, detox)
+	Seed          int64  // Random data seed of the run
 }
 
 // BuildSkeleton creates the initial report structure from parsed flows.
@@ -38,6 +39,7 @@ func BuildSkeleton(flows []flow.Flow, cfg BuilderConfig) (*Index, []FlowDetail,
 			Version: cfg.RunnerVersion,
 			Driver:  cfg.DriverName,
 		},
+		Seed: cfg.Seed,
 		Summary: Summary{
 			Total:   len(flows),
 			Pending: len(flows),
@@ -52,9 +54,6 @@ func BuildSkeleton(flows []flow.Flow, cfg BuilderConfig) (*Index, []FlowDetail,
 		flowID := fmt.Sprintf("flow-%03d", i)
 		flowName := extractFlowName(f)
 
-		// Build commands for this flow
-		commands := buildCommands(f.Steps)
-
 		// Create flow entry for index
 		index.Flows[i] = FlowEntry{
 			Index:      i,
@@ -62,33 +61,41 @@ func BuildSkeleton(flows []flow.Flow, cfg BuilderConfig) (*Index, []FlowDetail,
 			Name:       flowName,
 			SourceFile: f.SourcePath,
 			Tags:       f.Config.Tags,
+			DataKey:    f.DataKey,
 			Device:     &cfg.Device,
 			DataFile:   filepath.Join("flows", flowID+".json"),
 			AssetsDir:  filepath.Join("assets", flowID),
 			Status:     StatusPending,
 			UpdateSeq:  0,
 			Commands: CommandSummary{
-				Total:   len(commands),
-				Pending: len(commands),
+				Total:   len(f.Steps),
+				Pending: len(f.Steps),
 			},
 			Attempts: 0,
 		}
 
 		// Create flow detail
-		flowDetails[i] = FlowDetail{
-			ID:         flowID,
-			Name:       flowName,
-			SourceFile: f.SourcePath,
-			Tags:       f.Config.Tags,
-			Device:     &cfg.Device, // Device that runs this flow (for multi-device support)
-			Commands:   commands,
-			Artifacts:  FlowArtifacts{},
-		}
+		flowDetails[i] = NewFlowDetail(f, flowID, &cfg.Device)
 	}
 
 	return index, flowDetails, nil
 }
 
+// NewFlowDetail creates the pending detail of a flow with the given ID.
+// BuildSkeleton uses it for the flows of a run; it is also used for flows
+// outside the flow list, such as suite hooks.
+func NewFlowDetail(f flow.Flow, id string, device *Device) FlowDetail {
+	return FlowDetail{
+		ID:         id,
+		Name:       extractFlowName(f),
+		SourceFile: f.SourcePath,
+		Tags:       f.Config.Tags,
+		Device:     device, // Device that runs this flow (for multi-device support)
+		Commands:   buildCommands(f.Steps),
+		Artifacts:  FlowArtifacts{},
+	}
+}
+
 // extractFlowName extracts a display name from the flow.
 func extractFlowName(f flow.Flow) string {
 	if f.Config.Name != "" {
@@ -109,7 +116,8 @@ func buildCommands(steps []flow.Step) []Command {
 			Index:     i,
 			Type:      string(step.Type()),
 			Label:     step.Label(),
-			YAML:      step.Describe(),
+			YAML:      StepYAML(step),
+			Location:  step.Position().String(),
 			Status:    StatusPending,
 			Params:    extractParams(step),
 			Artifacts: CommandArtifacts{},
@@ -118,6 +126,15 @@ func buildCommands(steps []flow.Step) []Command {
 	return commands
 }
 
+// StepYAML returns the step's original YAML, falling back to its description
+// for steps that were not parsed from a file.
+func StepYAML(step flow.Step) string {
+	if src := step.Source(); src != "" {
+		return src
+	}
+	return step.Describe()
+}
+
 // extractParams extracts command parameters from a step.
 func extractParams(step flow.Step) *CommandParams {
 	params := &CommandParams{}
@@ -152,6 +169,11 @@ func extractParams(step flow.Step) *CommandParams {
 			params.Direction = s.Direction
 			hasContent = true
 		}
+	case *flow.CustomCommandStep:
+		if len(s.Params) > 0 {
+			params.Arguments = s.Params
+			hasContent = true
+		}
 	}
 
 	// Extract timeout
@@ -157,6 +157,57 @@ func TestBuildSkeleton_ExtractParams(t *testing.T) {
 	}
 }
 
+func TestBuildSkeleton_SourceLocation(t *testing.T) {
+	f, err := flow.Parse([]byte("- launchApp\n- tapOn:\n    id: btn\n"), "login.yaml")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	f.Steps = append(f.Steps, &flow.BackStep{BaseStep: flow.BaseStep{StepType: flow.StepBack}})
+
+	_, flowDetails, err := BuildSkeleton([]flow.Flow{*f}, BuilderConfig{})
+	if err != nil {
+		t.Fatalf("BuildSkeleton() error = %v", err)
+	}
+
+	commands := flowDetails[0].Commands
+	if commands[1].Location != "login.yaml:2:3" {
+		t.Errorf("commands[1].Location = %q, want login.yaml:2:3", commands[1].Location)
+	}
+	if commands[1].YAML != "tapOn:\n  id: btn" {
+		t.Errorf("commands[1].YAML = %q, want original YAML", commands[1].YAML)
+	}
+	// Steps built in code have no position and fall back to Describe()
+	if commands[2].Location != "" || commands[2].YAML != "back" {
+		t.Errorf("commands[2] = %q at %q", commands[2].YAML, commands[2].Location)
+	}
+}
+
+func TestBuildSkeleton_DatasetInstances(t *testing.T) {
+	f, err := flow.Parse([]byte("appId: x\nname: Login\ndataset:\n  - locale: en-US\n  - locale: de-DE\n---\n- back\n"), "login.yaml")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	instances, err := flow.Expand(f)
+	if err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	flows := []flow.Flow{*instances[0], *instances[1]}
+
+	index, _, err := BuildSkeleton(flows, BuilderConfig{})
+	if err != nil {
+		t.Fatalf("BuildSkeleton() error = %v", err)
+	}
+	for i, want := range []string{"en-US", "de-DE"} {
+		entry := index.Flows[i]
+		if entry.DataKey != want || entry.Name != "Login ["+want+"]" || entry.SourceFile != "login.yaml" {
+			t.Errorf("Flows[%d] = %q (dataKey %q, source %q)", i, entry.Name, entry.DataKey, entry.SourceFile)
+		}
+	}
+	if index.Flows[0].ID == index.Flows[1].ID {
+		t.Error("instances share a flow ID")
+	}
+}
+
 func TestWriteSkeleton(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/devicelab-dev/maestro-runner/pkg/logger"
+	"github.com/devicelab-dev/maestro-runner/pkg/secrets"
 )
 
 // FlowWriter writes updates for a single flow.
@@ -85,6 +86,7 @@ func (w *FlowWriter) CommandEndWithSubs(cmdIndex int, status Status, element *El
 	cmd.Error = err
 	cmd.Artifacts = artifacts
 	cmd.SubCommands = subCommands
+	redactCommand(cmd)
 
 	w.flush()
 	w.updateIndexProgress()
@@ -94,6 +96,7 @@ func (w *FlowWriter) CommandEndWithSubs(cmdIndex int, status Status, element *El
 func (w *FlowWriter) End(status Status) {
 	now := time.Now()
 	w.flow.EndTime = &now
+	redactCommands(w.flow.Commands) // Including those that never ran
 
 	var duration int64
 	if !w.flow.StartTime.IsZero() {
@@ -127,6 +130,15 @@ func (w *FlowWriter) End(status Status) {
 	}
 }
 
+// SetHookCommands records the steps run by the onFlowFailure and
+// onFlowComplete hooks. Call before End.
+func (w *FlowWriter) SetHookCommands(onFlowFailure, onFlowComplete []Command) {
+	w.flow.OnFlowFailure = onFlowFailure
+	w.flow.OnFlowComplete = onFlowComplete
+	redactCommands(w.flow.OnFlowFailure)
+	redactCommands(w.flow.OnFlowComplete)
+}
+
 // SetFlowArtifacts sets flow-level artifacts (video, logs).
 func (w *FlowWriter) SetFlowArtifacts(artifacts FlowArtifacts) {
 	w.flow.Artifacts = artifacts
@@ -160,7 +172,7 @@ func (w *FlowWriter) SaveViewHierarchy(cmdIndex int, data []byte) (string, error
 	filename := fmt.Sprintf("cmd-%03d-hierarchy.xml", cmdIndex)
 	absPath := filepath.Join(w.assetsDir, filename)
 
-	if err := os.WriteFile(absPath, data, 0o644); err != nil {
+	if err := os.WriteFile(absPath, secrets.RedactBytes(data), 0o644); err != nil {
 		return "", err
 	}
 
@@ -172,13 +184,23 @@ func (w *FlowWriter) SaveDeviceLog(data []byte) (string, error) {
 	filename := "device.log"
 	absPath := filepath.Join(w.assetsDir, filename)
 
-	if err := os.WriteFile(absPath, data, 0o644); err != nil {
+	if err := os.WriteFile(absPath, secrets.RedactBytes(data), 0o644); err != nil {
 		return "", err
 	}
 
 	return filepath.Join("assets", w.flow.ID, filename), nil
 }
 
+// SaveAttempt keeps the detail of a failed attempt before the flow is
+// retried, as flows/<id>-attempt-<n>.json, and returns its relative path.
+func SaveAttempt(outputDir string, detail *FlowDetail, attempt int) (string, error) {
+	rel := filepath.Join("flows", fmt.Sprintf("%s-attempt-%d.json", detail.ID, attempt))
+	if err := atomicWriteJSON(filepath.Join(outputDir, rel), detail); err != nil {
+		return "", err
+	}
+	return rel, nil
+}
+
 // GetFlowDetail returns the current flow detail (for reading).
 func (w *FlowWriter) GetFlowDetail() *FlowDetail {
 	return w.flow
@@ -222,7 +244,7 @@ func (w *FlowWriter) commandSummary() CommandSummary {
 			s.Passed++
 		case StatusFailed:
 			s.Failed++
-		case StatusSkipped:
+		case StatusSkipped, StatusConditionSkipped:
 			s.Skipped++
 		case StatusRunning:
 			s.Running++
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/secrets"
 )
 
 func createTestFlowWriter(t *testing.T) (*FlowWriter, *IndexWriter, string) {
@@ -166,6 +168,47 @@ func TestFlowWriter_CommandEnd_WithError(t *testing.T) {
 	}
 }
 
+func TestFlowWriter_RedactsSecretValuesOnly(t *testing.T) {
+	defer secrets.Reset()
+	secrets.Add("passed") // Also a status, and part of the "passed" summary key
+
+	fw, iw, tmpDir := createTestFlowWriter(t)
+	defer iw.Close()
+	fw.flow.Commands[1].YAML = `tapOn: "passed"`
+
+	fw.Start()
+	fw.CommandStart(0)
+	fw.CommandEnd(0, StatusPassed, &Element{Found: true, Text: "passed"}, nil, CommandArtifacts{})
+	fw.CommandStart(1)
+	fw.CommandEnd(1, StatusFailed, nil, &Error{Type: "assertion", Message: "no element passed"}, CommandArtifacts{})
+	fw.End(StatusFailed)
+
+	detail, err := ReadFlowDetail(filepath.Join(tmpDir, "flows", "flow-000.json"))
+	if err != nil {
+		t.Fatalf("ReadFlowDetail() error = %v", err)
+	}
+	if detail.Commands[0].Status != StatusPassed || detail.Commands[1].Status != StatusFailed {
+		t.Errorf("statuses = %q, %q", detail.Commands[0].Status, detail.Commands[1].Status)
+	}
+	if got := detail.Commands[0].Element.Text; got != secrets.Placeholder {
+		t.Errorf("element text = %q", got)
+	}
+	if got := detail.Commands[1].YAML; got != `tapOn: "***"` {
+		t.Errorf("YAML = %q", got)
+	}
+	if got := detail.Commands[1].Error.Message; got != "no element ***" {
+		t.Errorf("error = %q", got)
+	}
+
+	index, err := ReadIndex(filepath.Join(tmpDir, "report.json"))
+	if err != nil {
+		t.Fatalf("ReadIndex() error = %v", err)
+	}
+	if f := index.Flows[0]; f.Commands.Passed != 1 || f.Error == nil || *f.Error != "no element ***" {
+		t.Errorf("index entry = %+v", f)
+	}
+}
+
 func TestFlowWriter_End(t *testing.T) {
 	fw, iw, _ := createTestFlowWriter(t)
 	defer iw.Close()
@@ -0,0 +1,57 @@
+package report
+
+import (
+	"path/filepath"
+	"sort"
+)
+
+// HistoryRuns is how many past runs ExpectedDurations averages over.
+const HistoryRuns = 10
+
+// FlowKey identifies a flow across runs by its source file and display name.
+func FlowKey(sourceFile, name string) string {
+	return filepath.Clean(sourceFile) + "#" + name
+}
+
+// ExpectedDurations returns the average duration in milliseconds of each flow
+// over the most recent runs reports in baseDir: baseDir/report.json and
+// baseDir/*/report.json (the timestamped run folders). Only flows that ran
+// to completion (passed or failed) count. Keys are FlowKey values.
+func ExpectedDurations(baseDir string, runs int) map[string]int64 {
+	paths := []string{filepath.Join(baseDir, "report.json")}
+	if matches, err := filepath.Glob(filepath.Join(baseDir, "*", "report.json")); err == nil {
+		paths = append(paths, matches...)
+	}
+
+	var indexes []*Index
+	for _, p := range paths {
+		if index, err := ReadIndex(p); err == nil {
+			indexes = append(indexes, index)
+		}
+	}
+	sort.Slice(indexes, func(i, j int) bool {
+		return indexes[i].StartTime.After(indexes[j].StartTime)
+	})
+	if len(indexes) > runs {
+		indexes = indexes[:runs]
+	}
+
+	totals := make(map[string]int64)
+	counts := make(map[string]int64)
+	for _, index := range indexes {
+		for _, f := range index.Flows {
+			if f.Duration == nil || (f.Status != StatusPassed && f.Status != StatusFailed) {
+				continue
+			}
+			key := FlowKey(f.SourceFile, f.Name)
+			totals[key] += *f.Duration
+			counts[key]++
+		}
+	}
+
+	durations := make(map[string]int64, len(totals))
+	for key, total := range totals {
+		durations[key] = total / counts[key]
+	}
+	return durations
+}
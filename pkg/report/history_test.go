@@ -0,0 +1,54 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeHistoryRun(t *testing.T, dir string, start time.Time, flows ...FlowEntry) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	index := &Index{Version: Version, StartTime: start, Flows: flows}
+	if err := atomicWriteJSON(filepath.Join(dir, "report.json"), index); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func historyEntry(file, name string, status Status, ms int64) FlowEntry {
+	return FlowEntry{Name: name, SourceFile: file, Status: status, Duration: &ms}
+}
+
+func TestExpectedDurations(t *testing.T) {
+	base := t.TempDir()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	writeHistoryRun(t, filepath.Join(base, "old"), start,
+		historyEntry("flows/login.yaml", "Login", StatusPassed, 90000))
+	writeHistoryRun(t, filepath.Join(base, "run1"), start.Add(time.Hour),
+		historyEntry("flows/login.yaml", "Login", StatusPassed, 1000),
+		historyEntry("flows/checkout.yaml", "Checkout", StatusSkipped, 50000))
+	writeHistoryRun(t, filepath.Join(base, "run2"), start.Add(2*time.Hour),
+		historyEntry("flows/login.yaml", "Login", StatusFailed, 3000),
+		historyEntry("flows/checkout.yaml", "Checkout", StatusPassed, 5000))
+
+	got := ExpectedDurations(base, 2)
+
+	if d := got[FlowKey("flows/login.yaml", "Login")]; d != 2000 {
+		t.Errorf("login = %d, want 2000 (oldest run excluded)", d)
+	}
+	if d := got[FlowKey("./flows/checkout.yaml", "Checkout")]; d != 5000 {
+		t.Errorf("checkout = %d, want 5000 (skipped run excluded)", d)
+	}
+	if len(got) != 2 {
+		t.Errorf("got %d keys, want 2", len(got))
+	}
+}
+
+func TestExpectedDurations_NoHistory(t *testing.T) {
+	if got := ExpectedDurations(filepath.Join(t.TempDir(), "missing"), HistoryRuns); len(got) != 0 {
+		t.Errorf("expected no durations, got %v", got)
+	}
+}
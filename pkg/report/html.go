@@ -91,11 +91,12 @@ type CommandHTMLData struct {
 
 func buildHTMLData(index *Index, flows []FlowDetail, cfg HTMLConfig) HTMLData {
 	statusClass := map[Status]string{
-		StatusPassed:  "passed",
-		StatusFailed:  "failed",
-		StatusSkipped: "skipped",
-		StatusRunning: "running",
-		StatusPending: "pending",
+		StatusPassed:           "passed",
+		StatusFailed:           "failed",
+		StatusSkipped:          "skipped",
+		StatusConditionSkipped: "conditionSkipped",
+		StatusRunning:          "running",
+		StatusPending:          "pending",
 	}
 
 	// Find max duration for percentage bars
@@ -837,6 +838,7 @@ const htmlTemplate = `<!DOCTYPE html>
         .command-status.passed { background: var(--passed); }
         .command-status.failed { background: var(--failed); }
         .command-status.skipped { background: var(--skipped); }
+        .command-status.conditionSkipped { background: transparent; border: 1px solid var(--skipped); }
         .command-status.running {
             background: transparent;
             border: 2px solid var(--running);
@@ -1657,7 +1659,8 @@ const htmlTemplate = `<!DOCTYPE html>
                 html += '<div class="command-details">';
 
                 if (cmd.yaml && !hasSubCommands) {
-                    html += '<div class="command-yaml">' + escapeHtml(cmd.yaml) + '</div>';
+                    const yaml = cmd.location ? '# ' + cmd.location + '\n' + cmd.yaml : cmd.yaml;
+                    html += '<div class="command-yaml">' + escapeHtml(yaml) + '</div>';
                 }
 
                 if (cmd.error) {
@@ -1707,6 +1710,10 @@ const htmlTemplate = `<!DOCTYPE html>
                 if (cmd.params.direction) {
                     return cmd.params.direction;
                 }
+                if (cmd.params.arguments) {
+                    return Object.keys(cmd.params.arguments).sort()
+                        .map(k => k + '=' + cmd.params.arguments[k]).join(', ');
+                }
             }
             // Fallback: try to extract from label or return empty
             if (cmd.label && cmd.label !== cmd.type) {
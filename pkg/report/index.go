@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/devicelab-dev/maestro-runner/pkg/logger"
+	"github.com/devicelab-dev/maestro-runner/pkg/secrets"
 )
 
 // IndexWriter provides thread-safe updates to the report index.
@@ -180,7 +181,7 @@ func (w *IndexWriter) applyUpdate(flowID string, update *FlowUpdate) {
 			}
 			f.Commands = update.Commands
 			if update.Error != nil {
-				f.Error = update.Error
+				f.Error = redactString(update.Error)
 			}
 			if update.Device != nil {
 				f.Device = update.Device
@@ -251,7 +252,7 @@ func (w *IndexWriter) RecordAttempt(flowID string, attempt int, status Status, d
 				DataFile: dataFile,
 				Status:   status,
 				Duration: duration,
-				Error:    errMsg,
+				Error:    secrets.Redact(errMsg),
 			})
 			break
 		}
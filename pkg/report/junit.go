@@ -155,12 +155,16 @@ func resolveFailure(entry *FlowEntry, detail *FlowDetail) (failureType, body str
 
 	failureType = mapCommandTypeToFailure(cmd.Type)
 
-	// Use the command's label or type as the failure body (step description)
+	// Use the command's label or type as the failure body (step description),
+	// prefixed with where the step is in the flow file
 	if cmd.Label != "" {
 		body = cmd.Label
 	} else {
 		body = cmd.Type
 	}
+	if cmd.Location != "" {
+		body = cmd.Location + ": " + body
+	}
 
 	return failureType, body
 }
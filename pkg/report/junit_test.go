@@ -592,6 +592,17 @@ func TestFindFailedCommand(t *testing.T) {
 	}
 }
 
+func TestResolveFailureLocation(t *testing.T) {
+	detail := &FlowDetail{Commands: []Command{
+		{ID: "cmd-0", Type: "launchApp", Status: StatusPassed},
+		{ID: "cmd-1", Type: "tapOn", Status: StatusFailed, Location: "login.yaml:42:5"},
+	}}
+	failureType, body := resolveFailure(&FlowEntry{}, detail)
+	if failureType != "ElementInteractionError" || body != "login.yaml:42:5: tapOn" {
+		t.Errorf("resolveFailure() = %q, %q", failureType, body)
+	}
+}
+
 func TestGenerateJUnitReadError(t *testing.T) {
 	tmpDir := t.TempDir()
 	err := GenerateJUnit(tmpDir)
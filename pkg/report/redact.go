@@ -0,0 +1,55 @@
+package report
+
+import "github.com/devicelab-dev/maestro-runner/pkg/secrets"
+
+// redactCommand replaces secret values in the strings of cmd and its
+// sub-commands that come from the flow or the device: label, YAML,
+// parameters, element and error. IDs, types, statuses and artifact paths are
+// left alone, so the report keeps its schema whatever the secret is.
+func redactCommand(cmd *Command) {
+	cmd.Label = secrets.Redact(cmd.Label)
+	cmd.YAML = secrets.Redact(cmd.YAML)
+	if p := cmd.Params; p != nil {
+		params := *p // Params may be shared with the report skeleton
+		params.Text = secrets.Redact(params.Text)
+		if params.Selector != nil {
+			sel := *params.Selector
+			sel.Value = secrets.Redact(sel.Value)
+			params.Selector = &sel
+		}
+		if params.Arguments != nil {
+			args := make(map[string]string, len(params.Arguments))
+			for k, v := range params.Arguments {
+				args[k] = secrets.Redact(v)
+			}
+			params.Arguments = args
+		}
+		cmd.Params = &params
+	}
+	if e := cmd.Element; e != nil {
+		e.ID = secrets.Redact(e.ID)
+		e.Text = secrets.Redact(e.Text)
+	}
+	if e := cmd.Error; e != nil {
+		e.Message = secrets.Redact(e.Message)
+		e.Details = secrets.Redact(e.Details)
+		e.Suggestion = secrets.Redact(e.Suggestion)
+	}
+	redactCommands(cmd.SubCommands)
+}
+
+// redactCommands is redactCommand for each command.
+func redactCommands(cmds []Command) {
+	for i := range cmds {
+		redactCommand(&cmds[i])
+	}
+}
+
+// redactString returns s with secret values replaced, or nil for nil.
+func redactString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	redacted := secrets.Redact(*s)
+	return &redacted
+}
@@ -24,11 +24,15 @@ const (
 	StatusPassed  Status = "passed"
 	StatusFailed  Status = "failed"
 	StatusSkipped Status = "skipped"
+
+	// StatusConditionSkipped marks a command that did not run because its
+	// when condition was not met, as opposed to one skipped after a failure.
+	StatusConditionSkipped Status = "conditionSkipped"
 )
 
 // IsTerminal returns true if the status is a final state.
 func (s Status) IsTerminal() bool {
-	return s == StatusPassed || s == StatusFailed || s == StatusSkipped
+	return s == StatusPassed || s == StatusFailed || s == StatusSkipped || s == StatusConditionSkipped
 }
 
 // ============================================================================
@@ -48,6 +52,7 @@ type Index struct {
 	App           App         `json:"app"`
 	CI            *CI         `json:"ci,omitempty"`
 	MaestroRunner RunnerInfo  `json:"maestroRunner"`
+	Seed          int64       `json:"seed"` // Random data seed; rerun with --seed to reproduce generated values
 	Summary       Summary     `json:"summary"`
 	Flows         []FlowEntry `json:"flows"`
 }
@@ -97,14 +102,15 @@ type Summary struct {
 
 // FlowEntry is the index entry for a flow (minimal info).
 type FlowEntry struct {
-	Index          int            `json:"index"`            // Original position
-	ID             string         `json:"id"`               // Unique flow ID
-	Name           string         `json:"name"`             // Display name
-	SourceFile     string         `json:"sourceFile"`       // Path to YAML file
-	Tags           []string       `json:"tags,omitempty"`   // Tags for filtering
-	Device         *Device        `json:"device,omitempty"` // Device that ran this flow (for multi-device runs)
-	DataFile       string         `json:"dataFile"`         // Path to flow detail JSON
-	AssetsDir      string         `json:"assetsDir"`        // Path to assets directory
+	Index          int            `json:"index"`             // Original position
+	ID             string         `json:"id"`                // Unique flow ID
+	Name           string         `json:"name"`              // Display name
+	SourceFile     string         `json:"sourceFile"`        // Path to YAML file
+	Tags           []string       `json:"tags,omitempty"`    // Tags for filtering
+	DataKey        string         `json:"dataKey,omitempty"` // Dataset row of a data-driven flow instance
+	Device         *Device        `json:"device,omitempty"`  // Device that ran this flow (for multi-device runs)
+	DataFile       string         `json:"dataFile"`          // Path to flow detail JSON
+	AssetsDir      string         `json:"assetsDir"`         // Path to assets directory
 	Status         Status         `json:"status"`
 	UpdateSeq      uint64         `json:"updateSeq"`
 	StartTime      *time.Time     `json:"startTime,omitempty"`
@@ -153,6 +159,9 @@ type FlowDetail struct {
 	Duration   *int64        `json:"duration,omitempty"` // milliseconds
 	Commands   []Command     `json:"commands"`
 	Artifacts  FlowArtifacts `json:"artifacts"`
+
+	OnFlowFailure  []Command `json:"onFlowFailure,omitempty"`  // Steps of the onFlowFailure hook, if it ran
+	OnFlowComplete []Command `json:"onFlowComplete,omitempty"` // Steps of the onFlowComplete hook
 }
 
 // Command represents a single command execution.
@@ -162,6 +171,7 @@ type Command struct {
 	Type        string           `json:"type"`
 	Label       string           `json:"label,omitempty"` // Human-readable description from YAML label field
 	YAML        string           `json:"yaml,omitempty"`
+	Location    string           `json:"location,omitempty"` // file:line:col of the step in its flow file
 	Status      Status           `json:"status"`
 	StartTime   *time.Time       `json:"startTime,omitempty"`
 	EndTime     *time.Time       `json:"endTime,omitempty"`
@@ -179,6 +189,8 @@ type CommandParams struct {
 	Text      string    `json:"text,omitempty"`
 	Direction string    `json:"direction,omitempty"`
 	Timeout   int       `json:"timeout,omitempty"`
+
+	Arguments map[string]string `json:"arguments,omitempty"` // Custom command parameters
 }
 
 // Selector represents an element selector.
@@ -0,0 +1,156 @@
+// Package secrets redacts the values of secret variables from everything the
+// runner writes out: reports, logs and console output.
+//
+// Values are registered once they are known (Add) and replaced by Placeholder
+// wherever output passes through Redact or a Writer. Execution never sees the
+// redacted form.
+package secrets
+
+import (
+	"encoding/json"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Placeholder replaces secret values in output.
+const Placeholder = "***"
+
+// MinLength is the shortest value that is redacted. Shorter values such as
+// "pass" or "1234" turn up in unrelated output too often to replace blindly,
+// so Add refuses them.
+const MinLength = 6
+
+// DefaultPatterns mark variables as secret by name.
+var DefaultPatterns = []string{"*PASSWORD*", "*SECRET*", "*TOKEN*", "*API_KEY*"}
+
+var (
+	mu       sync.RWMutex
+	values   = map[string]bool{}
+	replacer *strings.Replacer
+)
+
+// Add registers value to be redacted. It returns false, and redacts nothing,
+// for values shorter than MinLength.
+func Add(value string) bool {
+	if len(value) < MinLength {
+		return false
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if values[value] {
+		return true
+	}
+	values[value] = true
+
+	// The JSON-escaped form is what ends up in reports
+	forms := map[string]bool{}
+	for v := range values {
+		forms[v] = true
+		if quoted, err := json.Marshal(v); err == nil {
+			forms[string(quoted[1:len(quoted)-1])] = true
+		}
+	}
+	// Longest first, so a secret containing another is replaced whole
+	sorted := make([]string, 0, len(forms))
+	for f := range forms {
+		sorted = append(sorted, f)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i]) != len(sorted[j]) {
+			return len(sorted[i]) > len(sorted[j])
+		}
+		return sorted[i] < sorted[j]
+	})
+	pairs := make([]string, 0, 2*len(sorted))
+	for _, f := range sorted {
+		pairs = append(pairs, f, Placeholder)
+	}
+	replacer = strings.NewReplacer(pairs...)
+	return true
+}
+
+// Redact returns s with every registered value replaced by Placeholder.
+func Redact(s string) string {
+	mu.RLock()
+	r := replacer
+	mu.RUnlock()
+
+	if r == nil || s == "" {
+		return s
+	}
+	return r.Replace(s)
+}
+
+// RedactBytes is Redact for byte slices. It returns b itself when there is
+// nothing to redact.
+func RedactBytes(b []byte) []byte {
+	mu.RLock()
+	r := replacer
+	mu.RUnlock()
+
+	if r == nil || len(b) == 0 {
+		return b
+	}
+	return []byte(r.Replace(string(b)))
+}
+
+// Reset forgets all registered values.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	values = map[string]bool{}
+	replacer = nil
+}
+
+// Matcher decides from its name whether a variable is secret.
+type Matcher struct {
+	patterns []string
+}
+
+// NewMatcher returns a Matcher for variable names or glob patterns such as
+// "*_PASSWORD", compared case-insensitively.
+func NewMatcher(patterns ...string) *Matcher {
+	m := &Matcher{}
+	for _, p := range patterns {
+		if p = strings.TrimSpace(p); p != "" {
+			m.patterns = append(m.patterns, strings.ToUpper(p))
+		}
+	}
+	return m
+}
+
+// IsSecret reports whether the variable name matches one of the patterns.
+func (m *Matcher) IsSecret(name string) bool {
+	if m == nil {
+		return false
+	}
+	name = strings.ToUpper(name)
+	for _, p := range m.patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Writer returns a writer that redacts what is written before passing it on
+// to w. Each Write is redacted on its own, so a value split across two writes
+// is not caught; line-oriented writers such as log.Logger never do that.
+func Writer(w io.Writer) io.Writer {
+	return &writer{w: w}
+}
+
+type writer struct {
+	w io.Writer
+}
+
+func (r *writer) Write(p []byte) (int, error) {
+	if _, err := r.w.Write(RedactBytes(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,84 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	if got := Redact("password hunter2"); got != "password hunter2" {
+		t.Errorf("Redact() with nothing registered = %q", got)
+	}
+
+	Add("hunter2")
+	Add("hunter2-admin")
+	Add(`pa"ss<word>`)
+	if Add("pass") {
+		t.Error("Add() accepted a value shorter than MinLength")
+	}
+
+	tests := []struct {
+		in, want string
+	}{
+		{`inputText: "hunter2"`, `inputText: "***"`},
+		{"login hunter2-admin", "login ***"},
+		{"pass word", "pass word"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Redact(tt.in); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	// Values are caught in their JSON-escaped form too
+	data, _ := json.Marshal(map[string]string{"text": `pa"ss<word>`})
+	if got := string(RedactBytes(data)); got != `{"text":"***"}` {
+		t.Errorf("RedactBytes(%s) = %s", data, got)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	Reset()
+	defer Reset()
+	Add("s3cr3t")
+
+	var buf bytes.Buffer
+	n, err := Writer(&buf).Write([]byte("token=s3cr3t\n"))
+	if err != nil || n != len("token=s3cr3t\n") {
+		t.Fatalf("Write() = %d, %v", n, err)
+	}
+	if buf.String() != "token=***\n" {
+		t.Errorf("written %q", buf.String())
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	m := NewMatcher(append([]string{"PIN", "*_KEY"}, DefaultPatterns...)...)
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"PASSWORD", true},
+		{"admin_password", true},
+		{"GITHUB_TOKEN", true},
+		{"pin", true},
+		{"STRIPE_KEY", true},
+		{"USERNAME", false},
+		{"PINCODE", false},
+	}
+	for _, tt := range tests {
+		if got := m.IsSecret(tt.name); got != tt.want {
+			t.Errorf("IsSecret(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	var none *Matcher
+	if none.IsSecret("PASSWORD") {
+		t.Error("nil Matcher matched")
+	}
+}
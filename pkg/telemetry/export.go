@@ -0,0 +1,118 @@
+package telemetry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/report"
+)
+
+// Config configures trace export. Both destinations may be set.
+type Config struct {
+	// Endpoint is an OTLP/HTTP collector URL. "/v1/traces" is appended
+	// unless the URL already ends with it (same rule as OTEL_EXPORTER_OTLP_ENDPOINT).
+	Endpoint string
+	// Headers are sent with every export request (e.g. API keys).
+	Headers map[string]string
+	// File is a path to write the trace as OTLP-JSON.
+	File string
+}
+
+// Enabled returns true if any export destination is configured.
+func (c Config) Enabled() bool {
+	return c.Endpoint != "" || c.File != ""
+}
+
+// exportTimeout bounds how long an export request may take.
+const exportTimeout = 10 * time.Second
+
+// ExportTraces builds a trace from the report directory and sends it to the
+// configured destinations. The driver HTTP calls of the run, if any, are
+// included.
+func ExportTraces(reportDir string, cfg Config, calls []HTTPCall) error {
+	index, flows, err := report.ReadReport(reportDir)
+	if err != nil {
+		return fmt.Errorf("read report: %w", err)
+	}
+
+	data, err := json.Marshal(BuildTraces(index, flows, calls))
+	if err != nil {
+		return fmt.Errorf("encode traces: %w", err)
+	}
+
+	if cfg.File != "" {
+		if dir := filepath.Dir(cfg.File); dir != "" {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return fmt.Errorf("create trace dir: %w", err)
+			}
+		}
+		if err := os.WriteFile(cfg.File, data, 0o644); err != nil {
+			return fmt.Errorf("write trace file: %w", err)
+		}
+	}
+
+	if cfg.Endpoint != "" {
+		if err := postTraces(tracesURL(cfg.Endpoint), cfg.Headers, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tracesURL returns the OTLP/HTTP traces URL for an endpoint.
+func tracesURL(endpoint string) string {
+	endpoint = strings.TrimRight(endpoint, "/")
+	if strings.HasSuffix(endpoint, "/v1/traces") {
+		return endpoint
+	}
+	return endpoint + "/v1/traces"
+}
+
+// postTraces sends an OTLP-JSON payload to a collector.
+func postTraces(url string, headers map[string]string, data []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("create export request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	client := &http.Client{Timeout: exportTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send traces: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("collector returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// ParseHeaders parses "key=value,key2=value2" (the OTEL_EXPORTER_OTLP_HEADERS format).
+func ParseHeaders(s string) map[string]string {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		if key != "" {
+			headers[key] = strings.TrimSpace(parts[1])
+		}
+	}
+	return headers
+}
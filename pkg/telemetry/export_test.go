@@ -0,0 +1,136 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestReport writes testReport() to dir in the on-disk report layout.
+func writeTestReport(t *testing.T, dir string) {
+	t.Helper()
+	index, flows := testReport(time.Now())
+
+	if err := os.MkdirAll(filepath.Join(dir, "flows"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for i := range index.Flows {
+		index.Flows[i].DataFile = filepath.Join("flows", index.Flows[i].ID+".json")
+		data, _ := json.Marshal(flows[i])
+		if err := os.WriteFile(filepath.Join(dir, index.Flows[i].DataFile), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, _ := json.Marshal(index)
+	if err := os.WriteFile(filepath.Join(dir, "report.json"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExportTraces_File(t *testing.T) {
+	dir := t.TempDir()
+	writeTestReport(t, dir)
+
+	out := filepath.Join(dir, "otel", "trace.json")
+	if err := ExportTraces(dir, Config{File: out}, nil); err != nil {
+		t.Fatalf("ExportTraces failed: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("trace file not written: %v", err)
+	}
+	var traces TracesData
+	if err := json.Unmarshal(data, &traces); err != nil {
+		t.Fatalf("invalid trace JSON: %v", err)
+	}
+	if len(traces.ResourceSpans) != 1 || len(traces.ResourceSpans[0].ScopeSpans[0].Spans) == 0 {
+		t.Error("trace file has no spans")
+	}
+}
+
+func TestExportTraces_Endpoint(t *testing.T) {
+	dir := t.TempDir()
+	writeTestReport(t, dir)
+
+	var gotPath, gotAuth, gotType string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		gotType = r.Header.Get("Content-Type")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	cfg := Config{Endpoint: srv.URL, Headers: map[string]string{"Authorization": "Bearer abc"}}
+	if err := ExportTraces(dir, cfg, nil); err != nil {
+		t.Fatalf("ExportTraces failed: %v", err)
+	}
+
+	if gotPath != "/v1/traces" {
+		t.Errorf("path = %q, want /v1/traces", gotPath)
+	}
+	if gotAuth != "Bearer abc" {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if gotType != "application/json" {
+		t.Errorf("Content-Type = %q", gotType)
+	}
+	var traces TracesData
+	if err := json.Unmarshal(gotBody, &traces); err != nil {
+		t.Errorf("invalid body: %v", err)
+	}
+}
+
+func TestExportTraces_EndpointError(t *testing.T) {
+	dir := t.TempDir()
+	writeTestReport(t, dir)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad payload", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	if err := ExportTraces(dir, Config{Endpoint: srv.URL}, nil); err == nil {
+		t.Error("expected error for non-2xx response")
+	}
+}
+
+func TestExportTraces_MissingReport(t *testing.T) {
+	if err := ExportTraces(t.TempDir(), Config{File: "trace.json"}, nil); err == nil {
+		t.Error("expected error for missing report")
+	}
+}
+
+func TestTracesURL(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+	}{
+		{"http://localhost:4318", "http://localhost:4318/v1/traces"},
+		{"http://localhost:4318/", "http://localhost:4318/v1/traces"},
+		{"http://localhost:4318/v1/traces", "http://localhost:4318/v1/traces"},
+	}
+	for _, tt := range tests {
+		if got := tracesURL(tt.endpoint); got != tt.want {
+			t.Errorf("tracesURL(%q) = %q, want %q", tt.endpoint, got, tt.want)
+		}
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	got := ParseHeaders("api-key=secret, x-team = mobile,invalid,")
+	if len(got) != 2 || got["api-key"] != "secret" || got["x-team"] != "mobile" {
+		t.Errorf("unexpected headers: %v", got)
+	}
+	if len(ParseHeaders("")) != 0 {
+		t.Error("empty string should produce no headers")
+	}
+}
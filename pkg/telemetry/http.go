@@ -0,0 +1,89 @@
+// Package telemetry exports run timings to external observability systems.
+//
+// Traces are built after a run from the JSON report (report.json + flow
+// detail files), so the exporter never slows down execution. Driver HTTP
+// calls are the one exception: they are not part of the report, so drivers
+// record them in the run's Recorder while the run is in progress.
+package telemetry
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HTTPCall is a single driver HTTP request recorded during a run.
+type HTTPCall struct {
+	Device     string // ID of the device the driver server runs on, if known
+	Target     string // Base URL or socket path of the driver server
+	Method     string
+	Path       string
+	Start      time.Time
+	Duration   time.Duration
+	StatusCode int    // 0 if the request failed before a response
+	Error      string // Transport or server error, if any
+}
+
+// End returns the time the call completed.
+func (c HTTPCall) End() time.Time {
+	return c.Start.Add(c.Duration)
+}
+
+// Recorder collects the driver HTTP calls of one run. It is safe for
+// concurrent use. A nil *Recorder records nothing, so runs without an
+// exporter pay nothing.
+type Recorder struct {
+	mu    sync.Mutex
+	calls []HTTPCall
+}
+
+// NewRecorder returns an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Record records a driver HTTP call.
+func (r *Recorder) Record(call HTTPCall) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+// RecordResponse records a driver HTTP call that started at start and has
+// just returned resp or err.
+func (r *Recorder) RecordResponse(device, target, method, path string, start time.Time, resp *http.Response, err error) {
+	if r == nil {
+		return
+	}
+	call := HTTPCall{
+		Device:   device,
+		Target:   target,
+		Method:   method,
+		Path:     path,
+		Start:    start,
+		Duration: time.Since(start),
+	}
+	if resp != nil {
+		call.StatusCode = resp.StatusCode
+	}
+	if err != nil {
+		call.Error = err.Error()
+	}
+	r.Record(call)
+}
+
+// Calls returns a copy of the recorded calls.
+func (r *Recorder) Calls() []HTTPCall {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	calls := make([]HTTPCall, len(r.calls))
+	copy(calls, r.calls)
+	return calls
+}
@@ -0,0 +1,376 @@
+package telemetry
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/report"
+)
+
+// OTLP/JSON encoding of the OpenTelemetry trace data model.
+// See https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+
+// TracesData is the top-level OTLP trace payload.
+type TracesData struct {
+	ResourceSpans []ResourceSpans `json:"resourceSpans"`
+}
+
+// ResourceSpans groups spans produced by one resource.
+type ResourceSpans struct {
+	Resource   Resource     `json:"resource"`
+	ScopeSpans []ScopeSpans `json:"scopeSpans"`
+}
+
+// Resource describes the entity producing telemetry.
+type Resource struct {
+	Attributes []KeyValue `json:"attributes"`
+}
+
+// ScopeSpans groups spans produced by one instrumentation scope.
+type ScopeSpans struct {
+	Scope Scope  `json:"scope"`
+	Spans []Span `json:"spans"`
+}
+
+// Scope identifies the instrumentation library.
+type Scope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// Span is a single timed operation.
+type Span struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []KeyValue `json:"attributes,omitempty"`
+	Events            []Event    `json:"events,omitempty"`
+	Status            SpanStatus `json:"status"`
+}
+
+// Event is a timestamped annotation on a span.
+type Event struct {
+	TimeUnixNano string     `json:"timeUnixNano"`
+	Name         string     `json:"name"`
+	Attributes   []KeyValue `json:"attributes,omitempty"`
+}
+
+// SpanStatus is the outcome of a span.
+type SpanStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// KeyValue is a span or resource attribute.
+type KeyValue struct {
+	Key   string   `json:"key"`
+	Value AnyValue `json:"value"`
+}
+
+// AnyValue holds one attribute value. Only one field is set.
+type AnyValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"` // int64 is encoded as a string in OTLP/JSON
+	BoolValue   *bool   `json:"boolValue,omitempty"`
+}
+
+// Span kinds and status codes (from the OTLP protobuf enums).
+const (
+	SpanKindInternal = 1
+	SpanKindClient   = 3
+
+	StatusUnset = 0
+	StatusOK    = 1
+	StatusError = 2
+)
+
+// instrumentationName is the scope name reported on every span.
+const instrumentationName = "maestro-runner"
+
+func stringAttr(key, value string) KeyValue {
+	return KeyValue{Key: key, Value: AnyValue{StringValue: &value}}
+}
+
+func intAttr(key string, value int64) KeyValue {
+	s := strconv.FormatInt(value, 10)
+	return KeyValue{Key: key, Value: AnyValue{IntValue: &s}}
+}
+
+func boolAttr(key string, value bool) KeyValue {
+	return KeyValue{Key: key, Value: AnyValue{BoolValue: &value}}
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// newID returns n random bytes hex-encoded (16 for trace IDs, 8 for span IDs).
+func newID(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b) // crypto/rand does not fail on supported platforms
+	return hex.EncodeToString(b)
+}
+
+// BuildTraces converts a finished report into an OTLP trace.
+// The run is the root span, each flow is a child of the run, each command is
+// a child of its flow (sub-commands nest under their parent command), and
+// driver HTTP calls become client spans under the innermost command that was
+// running on the same device when the call was made.
+func BuildTraces(index *report.Index, flows []report.FlowDetail, calls []HTTPCall) *TracesData {
+	b := &traceBuilder{
+		traceID: newID(16),
+		calls:   calls,
+	}
+
+	runEnd := index.LastUpdated
+	if index.EndTime != nil {
+		runEnd = *index.EndTime
+	}
+	runSpan := Span{
+		TraceID:           b.traceID,
+		SpanID:            newID(8),
+		Name:              "run",
+		Kind:              SpanKindInternal,
+		StartTimeUnixNano: unixNano(index.StartTime),
+		EndTimeUnixNano:   unixNano(runEnd),
+		Attributes: []KeyValue{
+			stringAttr("maestro.driver", index.MaestroRunner.Driver),
+			intAttr("maestro.flows.total", int64(index.Summary.Total)),
+			intAttr("maestro.flows.passed", int64(index.Summary.Passed)),
+			intAttr("maestro.flows.failed", int64(index.Summary.Failed)),
+			intAttr("maestro.flows.skipped", int64(index.Summary.Skipped)),
+		},
+		Status: spanStatus(index.Status, ""),
+	}
+	if index.App.ID != "" {
+		runSpan.Attributes = append(runSpan.Attributes, stringAttr("maestro.app.id", index.App.ID))
+	}
+	b.spans = append(b.spans, runSpan)
+
+	for i := range index.Flows {
+		var detail *report.FlowDetail
+		if i < len(flows) {
+			detail = &flows[i]
+		}
+		b.addFlow(runSpan.SpanID, &index.Flows[i], detail, index)
+	}
+
+	return &TracesData{
+		ResourceSpans: []ResourceSpans{{
+			Resource: Resource{Attributes: []KeyValue{
+				stringAttr("service.name", instrumentationName),
+				stringAttr("service.version", index.MaestroRunner.Version),
+			}},
+			ScopeSpans: []ScopeSpans{{
+				Scope: Scope{Name: instrumentationName, Version: index.MaestroRunner.Version},
+				Spans: b.spans,
+			}},
+		}},
+	}
+}
+
+// traceBuilder accumulates spans for a single trace.
+type traceBuilder struct {
+	traceID string
+	spans   []Span
+	calls   []HTTPCall
+	claimed map[int]bool // HTTP calls already attached to a deeper command
+}
+
+// addFlow adds a flow span and its command spans.
+func (b *traceBuilder) addFlow(parentID string, entry *report.FlowEntry, detail *report.FlowDetail, index *report.Index) {
+	if entry.StartTime == nil {
+		return // never started (pending or skipped before execution)
+	}
+
+	start := *entry.StartTime
+	end := start
+	if entry.EndTime != nil {
+		end = *entry.EndTime
+	}
+
+	device := entry.Device
+	if device == nil {
+		device = &index.Device
+	}
+
+	errMsg := ""
+	if entry.Error != nil {
+		errMsg = *entry.Error
+	}
+
+	span := Span{
+		TraceID:           b.traceID,
+		SpanID:            newID(8),
+		ParentSpanID:      parentID,
+		Name:              "flow: " + entry.Name,
+		Kind:              SpanKindInternal,
+		StartTimeUnixNano: unixNano(start),
+		EndTimeUnixNano:   unixNano(end),
+		Attributes: []KeyValue{
+			stringAttr("maestro.flow.id", entry.ID),
+			stringAttr("maestro.flow.name", entry.Name),
+			stringAttr("maestro.flow.file", filepath.ToSlash(entry.SourceFile)),
+			stringAttr("maestro.flow.status", string(entry.Status)),
+			stringAttr("device.id", device.ID),
+			stringAttr("device.name", device.Name),
+			stringAttr("os.type", device.Platform),
+			stringAttr("os.version", device.OSVersion),
+			boolAttr("device.simulator", device.IsSimulator),
+		},
+		Status: spanStatus(entry.Status, errMsg),
+	}
+	if len(entry.Tags) > 0 {
+		span.Attributes = append(span.Attributes, stringAttr("maestro.flow.tags", strings.Join(entry.Tags, ",")))
+	}
+	b.spans = append(b.spans, span)
+
+	if detail != nil {
+		calls := b.callsForDevice(device.ID)
+		b.addCommands(span.SpanID, detail.Commands, calls)
+	}
+}
+
+// addCommands adds a span for each executed command, recursing into sub-commands.
+// calls holds the indices of HTTP calls that may be attached beneath these commands.
+func (b *traceBuilder) addCommands(parentID string, commands []report.Command, calls []int) {
+	for i := range commands {
+		cmd := &commands[i]
+		if cmd.StartTime == nil {
+			continue // pending or skipped
+		}
+
+		start := *cmd.StartTime
+		end := start
+		if cmd.EndTime != nil {
+			end = *cmd.EndTime
+		}
+
+		span := Span{
+			TraceID:           b.traceID,
+			SpanID:            newID(8),
+			ParentSpanID:      parentID,
+			Name:              cmd.Type,
+			Kind:              SpanKindInternal,
+			StartTimeUnixNano: unixNano(start),
+			EndTimeUnixNano:   unixNano(end),
+			Attributes: []KeyValue{
+				stringAttr("maestro.step.type", cmd.Type),
+				stringAttr("maestro.step.status", string(cmd.Status)),
+				intAttr("maestro.step.index", int64(cmd.Index)),
+			},
+		}
+		if cmd.YAML != "" {
+			span.Attributes = append(span.Attributes, stringAttr("maestro.step.description", cmd.YAML))
+		}
+		if cmd.Label != "" {
+			span.Attributes = append(span.Attributes, stringAttr("maestro.step.label", cmd.Label))
+		}
+		if cmd.Params != nil && cmd.Params.Selector != nil {
+			span.Attributes = append(span.Attributes,
+				stringAttr("maestro.selector.type", cmd.Params.Selector.Type),
+				stringAttr("maestro.selector.value", cmd.Params.Selector.Value))
+		}
+
+		errMsg := ""
+		if cmd.Error != nil {
+			errMsg = cmd.Error.Message
+			span.Events = append(span.Events, Event{
+				TimeUnixNano: unixNano(end),
+				Name:         "exception",
+				Attributes: []KeyValue{
+					stringAttr("exception.type", cmd.Error.Type),
+					stringAttr("exception.message", cmd.Error.Message),
+				},
+			})
+		}
+		span.Status = spanStatus(cmd.Status, errMsg)
+
+		// Calls during this command's window belong to it (or to a sub-command)
+		var inside []int
+		for _, ci := range calls {
+			c := b.calls[ci]
+			if !c.Start.Before(start) && !c.End().After(end) {
+				inside = append(inside, ci)
+			}
+		}
+
+		b.spans = append(b.spans, span)
+
+		// Sub-commands claim their calls first so each call has exactly one parent
+		if len(cmd.SubCommands) > 0 {
+			b.addCommands(span.SpanID, cmd.SubCommands, inside)
+		}
+		for _, ci := range inside {
+			if b.claimed[ci] {
+				continue
+			}
+			b.addHTTPCall(span.SpanID, ci)
+		}
+	}
+}
+
+// addHTTPCall adds a client span for a driver HTTP call.
+func (b *traceBuilder) addHTTPCall(parentID string, ci int) {
+	if b.claimed == nil {
+		b.claimed = make(map[int]bool)
+	}
+	b.claimed[ci] = true
+
+	c := b.calls[ci]
+	span := Span{
+		TraceID:           b.traceID,
+		SpanID:            newID(8),
+		ParentSpanID:      parentID,
+		Name:              c.Method + " " + c.Path,
+		Kind:              SpanKindClient,
+		StartTimeUnixNano: unixNano(c.Start),
+		EndTimeUnixNano:   unixNano(c.End()),
+		Attributes: []KeyValue{
+			stringAttr("http.request.method", c.Method),
+			stringAttr("url.path", c.Path),
+			stringAttr("server.address", c.Target),
+		},
+		Status: SpanStatus{Code: StatusUnset},
+	}
+	if c.StatusCode > 0 {
+		span.Attributes = append(span.Attributes, intAttr("http.response.status_code", int64(c.StatusCode)))
+	}
+	if c.Error != "" {
+		span.Status = SpanStatus{Code: StatusError, Message: c.Error}
+	}
+	b.spans = append(b.spans, span)
+}
+
+// callsForDevice returns the indices of calls that may belong to a device.
+// Calls made before a client knew its device, such as Appium's session
+// creation, can't be attributed, so they are matched by time alone.
+func (b *traceBuilder) callsForDevice(deviceID string) []int {
+	var result []int
+	for i, c := range b.calls {
+		if deviceID == "" || c.Device == "" || c.Device == deviceID {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// spanStatus maps a report status to an OTLP span status.
+func spanStatus(status report.Status, message string) SpanStatus {
+	switch status {
+	case report.StatusPassed:
+		return SpanStatus{Code: StatusOK}
+	case report.StatusFailed:
+		return SpanStatus{Code: StatusError, Message: message}
+	default:
+		return SpanStatus{Code: StatusUnset}
+	}
+}
@@ -0,0 +1,247 @@
+package telemetry
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/report"
+)
+
+// testReport builds a two-flow report: a passing flow with a repeat step and a
+// failing flow whose second command errors. The third flow never started.
+func testReport(now time.Time) (*report.Index, []report.FlowDetail) {
+	at := func(ms int) *time.Time {
+		t := now.Add(time.Duration(ms) * time.Millisecond)
+		return &t
+	}
+
+	device := &report.Device{ID: "emulator-5554", Name: "Pixel 7", Platform: "android", OSVersion: "14"}
+
+	index := &report.Index{
+		Status:        report.StatusFailed,
+		StartTime:     now,
+		EndTime:       at(3000),
+		LastUpdated:   *at(3000),
+		App:           report.App{ID: "com.example.app"},
+		MaestroRunner: report.RunnerInfo{Version: "1.0.0", Driver: "uiautomator2"},
+		Summary:       report.Summary{Total: 3, Passed: 1, Failed: 1, Pending: 1},
+		Flows: []report.FlowEntry{
+			{ID: "flow-000", Name: "Login", SourceFile: "login.yaml", Tags: []string{"smoke"}, Device: device,
+				Status: report.StatusPassed, StartTime: at(0), EndTime: at(1000)},
+			{ID: "flow-001", Name: "Checkout", SourceFile: "checkout.yaml", Device: device,
+				Status: report.StatusFailed, StartTime: at(1000), EndTime: at(2000)},
+			{ID: "flow-002", Name: "Never", SourceFile: "never.yaml", Status: report.StatusPending},
+		},
+	}
+
+	flows := []report.FlowDetail{
+		{
+			ID: "flow-000", Name: "Login", StartTime: now, Device: device,
+			Commands: []report.Command{
+				{Type: "tapOn", Status: report.StatusPassed, StartTime: at(0), EndTime: at(400),
+					Params: &report.CommandParams{Selector: &report.Selector{Type: "text", Value: "Login"}}},
+				{Type: "repeat", Status: report.StatusPassed, StartTime: at(400), EndTime: at(1000),
+					SubCommands: []report.Command{
+						{Type: "swipe", Status: report.StatusPassed, StartTime: at(400), EndTime: at(700)},
+						{Type: "swipe", Status: report.StatusPassed, StartTime: at(700), EndTime: at(1000)},
+					}},
+			},
+		},
+		{
+			ID: "flow-001", Name: "Checkout", StartTime: *at(1000), Device: device,
+			Commands: []report.Command{
+				{Type: "launchApp", Status: report.StatusPassed, StartTime: at(1000), EndTime: at(1500)},
+				{Type: "assertVisible", Status: report.StatusFailed, StartTime: at(1500), EndTime: at(2000),
+					Error: &report.Error{Type: "element_not_found", Message: "Element not found: Pay"}},
+				{Type: "tapOn", Status: report.StatusSkipped},
+			},
+		},
+		{ID: "flow-002", Name: "Never"},
+	}
+
+	return index, flows
+}
+
+func spansByName(data *TracesData) map[string][]Span {
+	result := make(map[string][]Span)
+	for _, s := range data.ResourceSpans[0].ScopeSpans[0].Spans {
+		result[s.Name] = append(result[s.Name], s)
+	}
+	return result
+}
+
+func attr(span Span, key string) *AnyValue {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return &kv.Value
+		}
+	}
+	return nil
+}
+
+func TestBuildTraces_Hierarchy(t *testing.T) {
+	index, flows := testReport(time.Now())
+	data := BuildTraces(index, flows, nil)
+
+	spans := data.ResourceSpans[0].ScopeSpans[0].Spans
+	// run + 2 flows + (tapOn, repeat, 2 swipes) + (launchApp, assertVisible)
+	if len(spans) != 9 {
+		t.Fatalf("expected 9 spans, got %d", len(spans))
+	}
+
+	byName := spansByName(data)
+	run := byName["run"][0]
+	if run.ParentSpanID != "" {
+		t.Errorf("run span should be root, got parent %q", run.ParentSpanID)
+	}
+	if run.Status.Code != StatusError {
+		t.Errorf("run status = %d, want error", run.Status.Code)
+	}
+	for _, s := range spans {
+		if s.TraceID != run.TraceID {
+			t.Errorf("span %q has trace %q, want %q", s.Name, s.TraceID, run.TraceID)
+		}
+	}
+
+	login := byName["flow: Login"][0]
+	if login.ParentSpanID != run.SpanID {
+		t.Errorf("flow parent = %q, want run span", login.ParentSpanID)
+	}
+	if v := attr(login, "device.id"); v == nil || v.StringValue == nil || *v.StringValue != "emulator-5554" {
+		t.Errorf("flow missing device.id attribute")
+	}
+	if _, ok := byName["flow: Never"]; ok {
+		t.Error("flow that never started should not have a span")
+	}
+
+	repeat := byName["repeat"][0]
+	if repeat.ParentSpanID != login.SpanID {
+		t.Errorf("repeat parent = %q, want login flow", repeat.ParentSpanID)
+	}
+	for _, s := range byName["swipe"] {
+		if s.ParentSpanID != repeat.SpanID {
+			t.Errorf("swipe parent = %q, want repeat", s.ParentSpanID)
+		}
+	}
+
+	tap := byName["tapOn"][0]
+	if v := attr(tap, "maestro.selector.value"); v == nil || *v.StringValue != "Login" {
+		t.Errorf("tapOn missing selector attribute")
+	}
+}
+
+func TestBuildTraces_ErrorEvent(t *testing.T) {
+	index, flows := testReport(time.Now())
+	byName := spansByName(BuildTraces(index, flows, nil))
+
+	assert := byName["assertVisible"][0]
+	if assert.Status.Code != StatusError || assert.Status.Message != "Element not found: Pay" {
+		t.Errorf("unexpected status: %+v", assert.Status)
+	}
+	if len(assert.Events) != 1 || assert.Events[0].Name != "exception" {
+		t.Fatalf("expected one exception event, got %+v", assert.Events)
+	}
+
+	launch := byName["launchApp"][0]
+	if launch.Status.Code != StatusOK {
+		t.Errorf("launchApp status = %d, want OK", launch.Status.Code)
+	}
+	if len(launch.Events) != 0 {
+		t.Errorf("passing command should have no events")
+	}
+}
+
+func TestBuildTraces_HTTPCalls(t *testing.T) {
+	now := time.Now()
+	index, flows := testReport(now)
+
+	calls := []HTTPCall{
+		// During first swipe (inside repeat) - belongs to the swipe, not the repeat
+		{Device: "emulator-5554", Target: "http://localhost:8100", Method: "POST", Path: "/session/1/actions",
+			Start: now.Add(450 * time.Millisecond), Duration: 100 * time.Millisecond, StatusCode: 200},
+		// During assertVisible, failed
+		{Device: "emulator-5554", Target: "http://localhost:8100", Method: "POST", Path: "/session/1/element",
+			Start: now.Add(1600 * time.Millisecond), Duration: 50 * time.Millisecond, StatusCode: 404,
+			Error: "no such element"},
+		// Another device on the same target - must not be attached
+		{Device: "emulator-5556", Target: "http://localhost:8100", Method: "GET", Path: "/source",
+			Start: now.Add(100 * time.Millisecond), Duration: 50 * time.Millisecond, StatusCode: 200},
+		// Unknown device - matched by time alone
+		{Target: "http://localhost:4723", Method: "GET", Path: "/window/rect",
+			Start: now.Add(100 * time.Millisecond), Duration: 50 * time.Millisecond, StatusCode: 200},
+	}
+
+	byName := spansByName(BuildTraces(index, flows, calls))
+
+	actions := byName["POST /session/1/actions"]
+	if len(actions) != 1 {
+		t.Fatalf("expected 1 actions span, got %d", len(actions))
+	}
+	if actions[0].ParentSpanID != byName["swipe"][0].SpanID {
+		t.Error("HTTP call should be attached to the innermost command")
+	}
+	if actions[0].Kind != SpanKindClient {
+		t.Errorf("kind = %d, want client", actions[0].Kind)
+	}
+
+	element := byName["POST /session/1/element"]
+	if len(element) != 1 || element[0].ParentSpanID != byName["assertVisible"][0].SpanID {
+		t.Fatal("element call should be attached to assertVisible")
+	}
+	if element[0].Status.Code != StatusError {
+		t.Errorf("failed call status = %d, want error", element[0].Status.Code)
+	}
+	if v := attr(element[0], "http.response.status_code"); v == nil || *v.IntValue != "404" {
+		t.Error("missing status code attribute")
+	}
+
+	if _, ok := byName["GET /source"]; ok {
+		t.Error("call from another device should not be attached")
+	}
+	if rect := byName["GET /window/rect"]; len(rect) != 1 || rect[0].ParentSpanID != byName["tapOn"][0].SpanID {
+		t.Error("call from an unknown device should be attached by time")
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	var off *Recorder
+	off.Record(HTTPCall{Method: "GET", Path: "/status"})
+	if len(off.Calls()) != 0 {
+		t.Fatal("a nil recorder should record nothing")
+	}
+
+	r := NewRecorder()
+	r.Record(HTTPCall{Method: "GET", Path: "/status"})
+	calls := r.Calls()
+	if len(calls) != 1 || calls[0].Path != "/status" {
+		t.Fatalf("unexpected calls: %+v", calls)
+	}
+
+	calls[0].Path = "/changed"
+	if r.Calls()[0].Path != "/status" {
+		t.Error("Calls should return a copy")
+	}
+	if len(NewRecorder().Calls()) != 0 {
+		t.Error("a new recorder should start empty")
+	}
+}
+
+func TestRecordResponse(t *testing.T) {
+	r := NewRecorder()
+	start := time.Now()
+	r.RecordResponse("emulator-5554", "http://localhost:8100", "GET", "/status", start, &http.Response{StatusCode: 200}, nil)
+	r.RecordResponse("emulator-5554", "http://localhost:8100", "POST", "/session", start, nil, errors.New("connection refused"))
+
+	calls := r.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 calls, got %d", len(calls))
+	}
+	if c := calls[0]; c.Device != "emulator-5554" || c.Target != "http://localhost:8100" || c.StatusCode != 200 || c.Error != "" {
+		t.Errorf("unexpected call: %+v", c)
+	}
+	if c := calls[1]; c.StatusCode != 0 || c.Error != "connection refused" || c.Start != start {
+		t.Errorf("unexpected call: %+v", c)
+	}
+}
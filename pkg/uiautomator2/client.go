@@ -11,6 +11,9 @@ import (
 	"net/http"
 	"os"
 	"time"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/secrets"
+	"github.com/devicelab-dev/maestro-runner/pkg/telemetry"
 )
 
 // Client communicates with UIAutomator2 server.
@@ -20,6 +23,8 @@ type Client struct {
 	sessionID  string
 	socketPath string
 	logger     *log.Logger
+	deviceID   string              // Recorded with telemetry HTTP calls
+	recorder   *telemetry.Recorder // Records HTTP calls for trace export (nil = off)
 }
 
 // NewClient creates a client using Unix socket (Linux/Mac).
@@ -57,13 +62,14 @@ func createLogger() *log.Logger {
 	return createLoggerWithPath("/tmp/maestro-client.log")
 }
 
-// createLoggerWithPath creates a logger that writes to the specified path
+// createLoggerWithPath creates a logger that writes to the specified path,
+// with secret values redacted
 func createLoggerWithPath(path string) *log.Logger {
 	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
 	if err != nil {
 		return log.New(io.Discard, "", 0)
 	}
-	return log.New(f, "", log.Ltime|log.Lmicroseconds)
+	return log.New(secrets.Writer(f), "", log.Ltime|log.Lmicroseconds)
 }
 
 // SetLogPath sets the log file path for HTTP request timing
@@ -71,6 +77,18 @@ func (c *Client) SetLogPath(path string) {
 	c.logger = createLoggerWithPath(path)
 }
 
+// SetDeviceID sets the serial of the device the server runs on, so its HTTP
+// calls can be told apart from those of other devices in a parallel run.
+func (c *Client) SetDeviceID(id string) {
+	c.deviceID = id
+}
+
+// SetRecorder records the client's HTTP calls in r, the run's call log for
+// trace export.
+func (c *Client) SetRecorder(r *telemetry.Recorder) {
+	c.recorder = r
+}
+
 // SessionID returns the current session ID.
 func (c *Client) SessionID() string {
 	return c.sessionID
@@ -93,7 +111,7 @@ func (c *Client) request(method, path string, body interface{}) ([]byte, error)
 			return nil, fmt.Errorf("marshal request: %w", err)
 		}
 		reqBody = bytes.NewReader(data)
-		bodyStr = string(data)
+		bodyStr = secrets.Redact(string(data)) // Before truncating, which could cut a secret short
 		if len(bodyStr) > 100 {
 			bodyStr = bodyStr[:100] + "..."
 		}
@@ -107,6 +125,11 @@ func (c *Client) request(method, path string, body interface{}) ([]byte, error)
 
 	resp, err := c.http.Do(req)
 	elapsed := time.Since(start)
+	target := c.baseURL
+	if c.socketPath != "" {
+		target = c.socketPath
+	}
+	c.recorder.RecordResponse(c.deviceID, target, method, path, start, resp, err)
 	if err != nil {
 		c.logger.Printf("%s %s [%v] ERROR: %v", method, path, elapsed, err)
 		return nil, fmt.Errorf("send request: %w", err)
@@ -4,7 +4,13 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/secrets"
+	"github.com/devicelab-dev/maestro-runner/pkg/telemetry"
 )
 
 func newTestClient(handler http.HandlerFunc) (*Client, *httptest.Server) {
@@ -461,3 +467,45 @@ func TestRequestInvalidURL(t *testing.T) {
 		t.Error("expected error for invalid URL")
 	}
 }
+
+func TestRequestLogRedactsSecrets(t *testing.T) {
+	defer secrets.Reset()
+	secret := "hunter2-" + strings.Repeat("x", 100) // Longer than the logged body
+	secrets.Add(secret)
+
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"value": null}`))
+	})
+	defer server.Close()
+	logPath := filepath.Join(t.TempDir(), "client.log")
+	client.SetLogPath(logPath)
+
+	if _, err := client.request("POST", "/element/1/value", InputTextRequest{Text: secret}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "hunter2") || !strings.Contains(string(data), secrets.Placeholder) {
+		t.Errorf("log not redacted: %s", data)
+	}
+}
+
+func TestRequestRecordsDeviceID(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"value": null}`))
+	})
+	defer server.Close()
+	client.SetDeviceID("emulator-5554")
+	recorder := telemetry.NewRecorder()
+	client.SetRecorder(recorder)
+
+	if _, err := client.request("GET", "/status", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	calls := recorder.Calls()
+	if len(calls) != 1 || calls[0].Device != "emulator-5554" || calls[0].Target != server.URL || calls[0].StatusCode != 200 {
+		t.Errorf("recorded calls = %+v", calls)
+	}
+}
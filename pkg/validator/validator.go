@@ -3,6 +3,7 @@
 package validator
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -13,21 +14,63 @@ import (
 )
 
 // ValidationError represents a validation error with context.
+// Line and Column are set when the error points at a step (1-based).
 type ValidationError struct {
 	File    string
+	Line    int
+	Column  int
 	Message string
 }
 
 func (e *ValidationError) Error() string {
+	if e.Line > 0 {
+		pos := flow.Position{File: e.File, Line: e.Line, Column: e.Column}
+		return fmt.Sprintf("%s: %s", pos, e.Message)
+	}
 	return fmt.Sprintf("%s: %s", e.File, e.Message)
 }
 
+// stepError returns a validation error located at step.
+func stepError(file string, step flow.Step, message string) *ValidationError {
+	pos := step.Position()
+	return &ValidationError{File: file, Line: pos.Line, Column: pos.Column, Message: message}
+}
+
 // Result contains the validation result.
 type Result struct {
 	// TestCases is the list of top-level test case file paths.
 	TestCases []string
 	// Errors contains all validation errors found.
 	Errors []error
+	// Files lists every file the flows depend on: test cases, runFlow and
+	// runScript dependencies, and the directory's config.yaml if present.
+	Files []string
+	// Dependencies maps each flow file to the runFlow, retry and custom
+	// command files it references, in order of appearance.
+	Dependencies map[string][]string
+}
+
+// addFile records a dependency once.
+func (r *Result) addFile(path string) {
+	for _, f := range r.Files {
+		if f == path {
+			return
+		}
+	}
+	r.Files = append(r.Files, path)
+}
+
+// addDependency records that parent references dep.
+func (r *Result) addDependency(parent, dep string) {
+	if r.Dependencies == nil {
+		r.Dependencies = make(map[string][]string)
+	}
+	for _, d := range r.Dependencies[parent] {
+		if d == dep {
+			return
+		}
+	}
+	r.Dependencies[parent] = append(r.Dependencies[parent], dep)
 }
 
 // IsValid returns true if there are no validation errors.
@@ -39,6 +82,8 @@ func (r *Result) IsValid() bool {
 type Validator struct {
 	includeTags []string
 	excludeTags []string
+	strict      bool
+	config      *config.Config // Workspace config; nil = config.yaml of each directory
 }
 
 // New creates a new Validator.
@@ -49,6 +94,18 @@ func New(includeTags, excludeTags []string) *Validator {
 	}
 }
 
+// SetStrict enables schema checks: unknown keys, wrong value types and
+// conflicting keys are reported as errors (see flow.Check).
+func (v *Validator) SetStrict(strict bool) {
+	v.strict = strict
+}
+
+// SetConfig sets the workspace config that selects flows in directories,
+// instead of the config.yaml found in each directory.
+func (v *Validator) SetConfig(cfg *config.Config) {
+	v.config = cfg
+}
+
 // Validate validates a file or directory.
 // It parses all flows, resolves runFlow references, and returns validation results.
 func (v *Validator) Validate(path string) *Result {
@@ -65,6 +122,12 @@ func (v *Validator) Validate(path string) *Result {
 
 	var testCases []string
 	if info.IsDir() {
+		for _, name := range []string{"config.yaml", "config.yml"} {
+			if configPath := filepath.Join(path, name); fileExists(configPath) {
+				result.addFile(configPath)
+				break
+			}
+		}
 		testCases, err = v.collectTestCases(path)
 		if err != nil {
 			result.Errors = append(result.Errors, &ValidationError{
@@ -89,8 +152,11 @@ func (v *Validator) Validate(path string) *Result {
 
 // collectTestCases finds test case files based on config.yaml or top-level files.
 func (v *Validator) collectTestCases(dir string) ([]string, error) {
-	// Try to load config.yaml (may not exist)
-	cfg, _ := config.LoadFromDir(dir)
+	// Use the workspace config, or load config.yaml (may not exist)
+	cfg := v.config
+	if cfg == nil {
+		cfg, _ = config.LoadFromDir(dir)
+	}
 
 	// Determine flow patterns
 	patterns := []string{"*"} // Default: top-level files only
@@ -138,7 +204,7 @@ func (v *Validator) matchPattern(dir, pattern string) ([]string, error) {
 	var files []string
 
 	// Handle "**" for recursive matching
-	if pattern == "**" || strings.HasPrefix(pattern, "**/") {
+	if strings.Contains(pattern, "**") {
 		return v.collectRecursive(dir, pattern)
 	}
 
@@ -177,42 +243,66 @@ func (v *Validator) matchPattern(dir, pattern string) ([]string, error) {
 	return files, nil
 }
 
-// collectRecursive collects all flow files recursively.
+// collectRecursive collects flow files at any depth whose path relative to
+// dir matches pattern. "**" matches any number of directories (including
+// none), so "**" matches every flow and "**/login*.yaml" matches login flows
+// in any directory.
 func (v *Validator) collectRecursive(dir, pattern string) ([]string, error) {
 	var files []string
-
-	// Get the suffix after **/ if any
-	suffix := ""
-	if strings.HasPrefix(pattern, "**/") {
-		suffix = pattern[3:]
-	}
+	patternParts := strings.Split(filepath.ToSlash(pattern), "/")
 
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 		if info.IsDir() {
+			if path != dir && info.Name() == flow.CommandsDir {
+				return filepath.SkipDir // Custom commands are not test cases
+			}
 			return nil
 		}
 		if !isFlowFile(path) {
 			return nil
 		}
 
-		// If there's a suffix pattern, check if filename matches
-		if suffix != "" {
-			matched, _ := filepath.Match(suffix, filepath.Base(path))
-			if !matched {
-				return nil
-			}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if matchSegments(patternParts, strings.Split(filepath.ToSlash(rel), "/")) {
+			files = append(files, path)
 		}
-
-		files = append(files, path)
 		return nil
 	})
 
 	return files, err
 }
 
+// matchSegments matches path segments against pattern segments, where a
+// "**" segment matches zero or more path segments (at least one at the end
+// of the pattern) and "**" within a segment acts like "*".
+func matchSegments(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+	if pattern[0] == "**" {
+		if len(pattern) == 1 {
+			return len(path) > 0
+		}
+		for i := 0; i <= len(path); i++ {
+			if matchSegments(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 {
+		return false
+	}
+	matched, err := filepath.Match(strings.ReplaceAll(pattern[0], "**", "*"), path[0])
+	return err == nil && matched && matchSegments(pattern[1:], path[1:])
+}
+
 // getTopLevelFlows gets flow files directly in a directory (not recursive).
 func (v *Validator) getTopLevelFlows(dir string) ([]string, error) {
 	var files []string
@@ -267,8 +357,35 @@ func (v *Validator) validateFile(filePath string, result *Result, validated map[
 	var f *flow.Flow
 	var err error
 	if !validated[filePath] {
-		f, err = flow.ParseFile(filePath)
+		result.addFile(filePath)
+		if v.strict {
+			f, err = flow.ParseFileStrict(filePath)
+		} else {
+			f, err = flow.ParseFile(filePath)
+		}
 		if err != nil {
+			var schemaErr *flow.SchemaError
+			if errors.As(err, &schemaErr) {
+				for _, p := range schemaErr.Problems {
+					result.Errors = append(result.Errors, &ValidationError{
+						File:    filePath,
+						Line:    p.Line,
+						Column:  p.Column,
+						Message: p.Message,
+					})
+				}
+				return
+			}
+			var parseErr *flow.ParseError
+			if errors.As(err, &parseErr) {
+				result.Errors = append(result.Errors, &ValidationError{
+					File:    filePath,
+					Line:    parseErr.Line,
+					Column:  parseErr.Column,
+					Message: fmt.Sprintf("parse error: %s", parseErr.Message),
+				})
+				return
+			}
 			result.Errors = append(result.Errors, &ValidationError{
 				File:    filePath,
 				Message: fmt.Sprintf("parse error: %v", err),
@@ -277,6 +394,19 @@ func (v *Validator) validateFile(filePath string, result *Result, validated map[
 		}
 		validated[filePath] = true
 
+		if ds := f.Config.Dataset; ds != nil {
+			if path := ds.Path(filePath); path != "" {
+				result.addFile(path)
+			}
+			if _, _, err := ds.Load(filePath); err != nil {
+				result.Errors = append(result.Errors, &ValidationError{File: filePath, Message: err.Error()})
+			}
+		}
+
+		if err := f.Config.Device.Validate(); err != nil {
+			result.Errors = append(result.Errors, &ValidationError{File: filePath, Message: err.Error()})
+		}
+
 		// Recursively validate runFlow dependencies (not test cases)
 		newChain := append(chain, filePath)
 		v.validateRunFlowSteps(f.Steps, filePath, result, validated, testCasesAdded, newChain)
@@ -284,6 +414,7 @@ func (v *Validator) validateFile(filePath string, result *Result, validated map[
 		// Also validate lifecycle hooks
 		v.validateRunFlowSteps(f.Config.OnFlowStart, filePath, result, validated, testCasesAdded, newChain)
 		v.validateRunFlowSteps(f.Config.OnFlowComplete, filePath, result, validated, testCasesAdded, newChain)
+		v.validateRunFlowSteps(f.Config.OnFlowFailure, filePath, result, validated, testCasesAdded, newChain)
 	}
 
 	// Add to TestCases if it's a top-level test case and not already added
@@ -309,12 +440,20 @@ func (v *Validator) validateRunFlowSteps(steps []flow.Step, parentFile string, r
 	parentDir := filepath.Dir(parentFile)
 
 	for _, step := range steps {
+		v.validateRunFlowSteps(step.Recovery(), parentFile, result, validated, testCasesAdded, chain)
+
 		switch s := step.(type) {
 		case *flow.RunFlowStep:
 			if s.File != "" {
 				refPath := resolveFilePath(parentDir, s.File)
-				// Dependencies are validated but NOT added as test cases
-				v.validateFile(refPath, result, validated, testCasesAdded, chain, false)
+				result.addDependency(parentFile, refPath)
+				if !fileExists(refPath) {
+					result.addFile(refPath)
+					result.Errors = append(result.Errors, stepError(parentFile, s, fmt.Sprintf("runFlow: file not found: %s", s.File)))
+				} else {
+					// Dependencies are validated but NOT added as test cases
+					v.validateFile(refPath, result, validated, testCasesAdded, chain, false)
+				}
 			}
 			// Also check inline commands
 			v.validateRunFlowSteps(s.Steps, parentFile, result, validated, testCasesAdded, chain)
@@ -322,16 +461,44 @@ func (v *Validator) validateRunFlowSteps(steps []flow.Step, parentFile string, r
 		case *flow.RepeatStep:
 			v.validateRunFlowSteps(s.Steps, parentFile, result, validated, testCasesAdded, chain)
 
+		case *flow.ForEachStep:
+			v.validateRunFlowSteps(s.Steps, parentFile, result, validated, testCasesAdded, chain)
+
+		case *flow.IfStep:
+			v.validateRunFlowSteps(s.Then, parentFile, result, validated, testCasesAdded, chain)
+			v.validateRunFlowSteps(s.Else, parentFile, result, validated, testCasesAdded, chain)
+
 		case *flow.RetryStep:
 			if s.File != "" {
 				refPath := resolveFilePath(parentDir, s.File)
-				v.validateFile(refPath, result, validated, testCasesAdded, chain, false)
+				result.addDependency(parentFile, refPath)
+				if !fileExists(refPath) {
+					result.addFile(refPath)
+					result.Errors = append(result.Errors, stepError(parentFile, s, fmt.Sprintf("retry: file not found: %s", s.File)))
+				} else {
+					v.validateFile(refPath, result, validated, testCasesAdded, chain, false)
+				}
 			}
 			v.validateRunFlowSteps(s.Steps, parentFile, result, validated, testCasesAdded, chain)
+
+		case *flow.CustomCommandStep:
+			// The parser found the definition, so it exists
+			result.addDependency(parentFile, s.File)
+			v.validateFile(s.File, result, validated, testCasesAdded, chain, false)
+
+		case *flow.RunScriptStep:
+			if script := s.ScriptPath(); strings.HasSuffix(script, ".js") {
+				result.addFile(resolveFilePath(parentDir, script))
+			}
 		}
 	}
 }
 
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
 // resolveFilePath resolves a file path relative to a base directory.
 func resolveFilePath(baseDir, filePath string) string {
 	if filepath.IsAbs(filePath) {
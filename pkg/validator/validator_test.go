@@ -3,8 +3,11 @@ package validator
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/devicelab-dev/maestro-runner/pkg/config"
 )
 
 func TestValidate_SingleFile(t *testing.T) {
@@ -185,7 +188,47 @@ func TestValidate_MissingRunFlowFile(t *testing.T) {
 	result := v.Validate(filepath.Join(dir, "main.yaml"))
 
 	if result.IsValid() {
-		t.Error("expected error for missing runFlow file")
+		t.Fatal("expected error for missing runFlow file")
+	}
+	want := filepath.Join(dir, "main.yaml") + ":1:3: runFlow: file not found: nonexistent.yaml"
+	if got := result.Errors[0].Error(); got != want {
+		t.Errorf("error = %q, want %q", got, want)
+	}
+}
+
+func TestValidate_Strict(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "main.yaml")
+	if err := os.WriteFile(main, []byte("- tapOn: {txt: Login}\n- runFlow: sub.yaml\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub.yaml"), []byte("- back\n- inputText: {text: hi, lable: x}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unknown keys are ignored by default
+	if result := New(nil, nil).Validate(main); !result.IsValid() {
+		t.Fatalf("non-strict validation failed: %v", result.Errors)
+	}
+
+	v := New(nil, nil)
+	v.SetStrict(true)
+	result := v.Validate(main)
+	if len(result.Errors) != 1 {
+		t.Fatalf("errors = %v, want 1 for main.yaml", result.Errors)
+	}
+	want := main + `:1:11: unknown key "txt" in tapOn (did you mean "text"?)`
+	if got := result.Errors[0].Error(); got != want {
+		t.Errorf("error = %q, want %q", got, want)
+	}
+
+	// runFlow dependencies are checked too
+	if err := os.WriteFile(main, []byte("- tapOn: {text: Login}\n- runFlow: sub.yaml\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	result = v.Validate(main)
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Error(), "sub.yaml:2:25:") {
+		t.Errorf("errors = %v, want the unknown key in sub.yaml", result.Errors)
 	}
 }
 
@@ -525,6 +568,15 @@ func TestValidationError_Error(t *testing.T) {
 	}
 }
 
+func TestValidationError_ErrorWithPosition(t *testing.T) {
+	err := &ValidationError{File: "test.yaml", Line: 4, Column: 3, Message: "something went wrong"}
+
+	expected := "test.yaml:4:3: something went wrong"
+	if err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+}
+
 func TestValidate_RecursivePatternWithSuffix(t *testing.T) {
 	dir := t.TempDir()
 	subdir := filepath.Join(dir, "auth")
@@ -1218,3 +1270,259 @@ func TestValidate_GetTopLevelFlowsWithMixedContent(t *testing.T) {
 		t.Errorf("expected 3 test cases, got %d: %v", len(result.TestCases), result.TestCases)
 	}
 }
+
+func TestValidate_Files(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.yaml":    "flows:\n  - main.yaml\n",
+		"main.yaml":      "- runFlow: sub/login.yaml\n- runScript: setup.js\n",
+		"sub/login.yaml": "- runScript:\n    file: creds.js\n- tapOn: Login\n",
+		"unrelated.yaml": "- back\n",
+		"sub/creds.js":   "output.user = 'a'",
+		"setup.js":       "",
+		"inline.yaml":    "- runFlow:\n    commands:\n      - runScript: inline.js\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result := New(nil, nil).Validate(dir)
+	if !result.IsValid() {
+		t.Fatalf("expected valid result, got errors: %v", result.Errors)
+	}
+	want := []string{
+		filepath.Join(dir, "config.yaml"),
+		filepath.Join(dir, "main.yaml"),
+		filepath.Join(dir, "sub/login.yaml"),
+		filepath.Join(dir, "sub/creds.js"),
+		filepath.Join(dir, "setup.js"),
+	}
+	if len(result.Files) != len(want) {
+		t.Fatalf("Files = %v, want %v", result.Files, want)
+	}
+	for i := range want {
+		if result.Files[i] != want[i] {
+			t.Errorf("Files[%d] = %s, want %s", i, result.Files[i], want[i])
+		}
+	}
+
+	result = New(nil, nil).Validate(filepath.Join(dir, "inline.yaml"))
+	if len(result.Files) != 2 || result.Files[1] != filepath.Join(dir, "inline.js") {
+		t.Errorf("inline runScript not tracked: %v", result.Files)
+	}
+}
+
+func TestValidate_Dependencies(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.yaml":       "- runFlow: sub/login.yaml\n- retry:\n    file: sub/flaky.yaml\n- runFlow: sub/login.yaml\n",
+		"sub/login.yaml":  "- runFlow: common.yaml\n",
+		"sub/flaky.yaml":  "- back\n",
+		"sub/common.yaml": "- back\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result := New(nil, nil).Validate(filepath.Join(dir, "main.yaml"))
+	if !result.IsValid() {
+		t.Fatalf("expected valid result, got errors: %v", result.Errors)
+	}
+
+	main := result.Dependencies[filepath.Join(dir, "main.yaml")]
+	want := []string{filepath.Join(dir, "sub/login.yaml"), filepath.Join(dir, "sub/flaky.yaml")}
+	if len(main) != len(want) || main[0] != want[0] || main[1] != want[1] {
+		t.Errorf("main dependencies = %v, want %v", main, want)
+	}
+	login := result.Dependencies[filepath.Join(dir, "sub/login.yaml")]
+	if len(login) != 1 || login[0] != filepath.Join(dir, "sub/common.yaml") {
+		t.Errorf("login dependencies = %v", login)
+	}
+	if deps, ok := result.Dependencies[filepath.Join(dir, "sub/common.yaml")]; ok {
+		t.Errorf("leaf flow should have no dependencies, got %v", deps)
+	}
+}
+
+func TestValidate_Dataset(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"login.yaml":  "appId: x\ndataset: users.csv\n---\n- back\n",
+		"users.csv":   "USER\nalice\n",
+		"broken.yaml": "appId: x\ndataset: missing.csv\n---\n- back\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result := New(nil, nil).Validate(filepath.Join(dir, "login.yaml"))
+	if !result.IsValid() {
+		t.Fatalf("expected valid result, got errors: %v", result.Errors)
+	}
+	if len(result.Files) != 2 || result.Files[1] != filepath.Join(dir, "users.csv") {
+		t.Errorf("dataset not tracked in Files: %v", result.Files)
+	}
+
+	result = New(nil, nil).Validate(filepath.Join(dir, "broken.yaml"))
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Error(), "dataset missing.csv") {
+		t.Errorf("expected dataset error, got %v", result.Errors)
+	}
+}
+
+func TestValidate_DeviceRequirements(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"tablet.yaml": "appId: x\ndevice:\n  platform: android\n  minOSVersion: \"14\"\n  formFactor: tablet\n---\n- back\n",
+		"broken.yaml": "appId: x\ndevice:\n  formFactor: watch\n---\n- back\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result := New(nil, nil).Validate(filepath.Join(dir, "tablet.yaml"))
+	if !result.IsValid() {
+		t.Fatalf("expected valid result, got errors: %v", result.Errors)
+	}
+
+	result = New(nil, nil).Validate(filepath.Join(dir, "broken.yaml"))
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Error(), "device.formFactor") {
+		t.Errorf("expected formFactor error, got %v", result.Errors)
+	}
+}
+
+func TestValidate_CustomCommands(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.yaml":           "flows: ['**']\n",
+		"login.yaml":            "appId: x\n---\n- login: {user: alice}\n",
+		"loop.yaml":             "appId: x\n---\n- ping\n",
+		"commands/login.yaml":   "params:\n  user: string\n---\n- inputText: ${user}\n- logout\n",
+		"commands/logout.yaml":  "- tapOn: Logout\n",
+		"commands/ping.yaml":    "- pong\n",
+		"commands/pong.yaml":    "- ping\n",
+		"commands/unused.yaml":  "- back\n",
+		"nested/commands/x.yml": "- back\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result := New(nil, nil).Validate(filepath.Join(dir, "login.yaml"))
+	if !result.IsValid() {
+		t.Fatalf("expected valid result, got errors: %v", result.Errors)
+	}
+	login := filepath.Join(dir, "commands/login.yaml")
+	logout := filepath.Join(dir, "commands/logout.yaml")
+	if deps := result.Dependencies[filepath.Join(dir, "login.yaml")]; len(deps) != 1 || deps[0] != login {
+		t.Errorf("flow dependencies = %v", deps)
+	}
+	if deps := result.Dependencies[login]; len(deps) != 1 || deps[0] != logout {
+		t.Errorf("command dependencies = %v", deps)
+	}
+
+	result = New(nil, nil).Validate(filepath.Join(dir, "loop.yaml"))
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Error(), "circular dependency") {
+		t.Errorf("expected circular dependency error, got %v", result.Errors)
+	}
+
+	// Command definitions are not collected as test cases
+	result = New(nil, nil).Validate(dir)
+	for _, tc := range result.TestCases {
+		if strings.Contains(tc, string(filepath.Separator)+"commands"+string(filepath.Separator)) {
+			t.Errorf("command collected as test case: %s", tc)
+		}
+	}
+	if len(result.TestCases) != 2 {
+		t.Errorf("TestCases = %v, want login.yaml and loop.yaml", result.TestCases)
+	}
+}
+
+func TestValidate_DoubleStarInsidePattern(t *testing.T) {
+	dir := t.TempDir()
+	for _, rel := range []string{"auth/login.yaml", "auth/ios/login.yaml", "auth/ios/commands/helper.yaml", "home.yaml", "other/auth/x.yaml"} {
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(`- tapOn: "Login"`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("flows:\n  - \"auth/**\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := New(nil, nil).Validate(dir)
+
+	if !result.IsValid() {
+		t.Fatalf("expected valid result, got errors: %v", result.Errors)
+	}
+	want := []string{filepath.Join(dir, "auth/ios/login.yaml"), filepath.Join(dir, "auth/login.yaml")}
+	if !reflect.DeepEqual(result.TestCases, want) {
+		t.Errorf("TestCases = %v, want %v", result.TestCases, want)
+	}
+}
+
+func TestMatchSegments(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"**", "a/b/c.yaml", true},
+		{"**/c.yaml", "c.yaml", true},
+		{"a/**/c.yaml", "a/c.yaml", true},
+		{"a/**/c.yaml", "a/x/y/c.yaml", true},
+		{"a/**/c.yaml", "b/x/c.yaml", false},
+		{"a/**", "a", false},
+		{"a/*.yaml", "a/b/c.yaml", false},
+		{"**/smoke_**.yaml", "x/smoke_login.yaml", true},
+	}
+	for _, tt := range tests {
+		if got := matchSegments(strings.Split(tt.pattern, "/"), strings.Split(tt.path, "/")); got != tt.want {
+			t.Errorf("matchSegments(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestValidate_SetConfig(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"config.yaml": "flows:\n  - \"*\"\n",
+		"a.yaml":      `- tapOn: "A"`,
+		"b.yaml":      `- tapOn: "B"`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The workspace config replaces the directory's config.yaml
+	v := New(nil, nil)
+	v.SetConfig(&config.Config{Flows: []string{"b.yaml"}})
+	result := v.Validate(dir)
+
+	if want := []string{filepath.Join(dir, "b.yaml")}; !reflect.DeepEqual(result.TestCases, want) {
+		t.Errorf("TestCases = %v, want %v", result.TestCases, want)
+	}
+}
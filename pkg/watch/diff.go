@@ -0,0 +1,85 @@
+package watch
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FirstChangedStep compares two versions of a flow file and returns the
+// index of the first top-level step that differs. A changed config header
+// returns 0, since it affects the whole flow. If the steps are identical,
+// or only steps were removed from the end, it returns the new step count.
+// Formatting and comment changes are ignored.
+func FirstChangedStep(oldData, newData []byte) (int, error) {
+	oldHeader, oldSteps, err := splitFlow(oldData)
+	if err != nil {
+		return 0, err
+	}
+	newHeader, newSteps, err := splitFlow(newData)
+	if err != nil {
+		return 0, err
+	}
+
+	if !bytes.Equal(oldHeader, newHeader) {
+		return 0, nil
+	}
+	for i, step := range newSteps {
+		if i >= len(oldSteps) || !bytes.Equal(step, oldSteps[i]) {
+			return i, nil
+		}
+	}
+	return len(newSteps), nil
+}
+
+// splitFlow returns the normalized config header and each top-level step of
+// a flow file. Steps are the sequence in the last YAML document.
+func splitFlow(data []byte) (header []byte, steps [][]byte, err error) {
+	var docs []*yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, nil, err
+		}
+		docs = append(docs, &doc)
+	}
+
+	var seq *yaml.Node
+	if n := len(docs); n > 0 && len(docs[n-1].Content) > 0 && docs[n-1].Content[0].Kind == yaml.SequenceNode {
+		seq = docs[n-1].Content[0]
+		docs = docs[:n-1]
+	}
+
+	for _, doc := range docs {
+		out, err := normalize(doc)
+		if err != nil {
+			return nil, nil, err
+		}
+		header = append(header, out...)
+	}
+	if seq != nil {
+		for _, item := range seq.Content {
+			out, err := normalize(item)
+			if err != nil {
+				return nil, nil, err
+			}
+			steps = append(steps, out)
+		}
+	}
+	return header, steps, nil
+}
+
+// normalize re-encodes a node without comments so only content is compared.
+func normalize(n *yaml.Node) ([]byte, error) {
+	var v interface{}
+	if err := n.Decode(&v); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(v)
+}
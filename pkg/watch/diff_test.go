@@ -0,0 +1,80 @@
+package watch
+
+import "testing"
+
+const baseFlow = `appId: com.example
+---
+- launchApp
+- tapOn: "Login"   # the button
+- inputText: "hello"
+- back
+`
+
+func TestFirstChangedStep(t *testing.T) {
+	tests := []struct {
+		name    string
+		newFlow string
+		want    int
+	}{
+		{"identical", baseFlow, 4},
+		{"comment and formatting only", `appId: com.example
+---
+- launchApp
+- tapOn: Login
+- inputText:   "hello"
+- back
+`, 4},
+		{"changed step", `appId: com.example
+---
+- launchApp
+- tapOn: "Login"
+- inputText: "hello world"
+- back
+`, 2},
+		{"step inserted", `appId: com.example
+---
+- launchApp
+- tapOn: "Login"
+- tapOn: "Email"
+- inputText: "hello"
+- back
+`, 2},
+		{"step appended", baseFlow + "- pressKey: Enter\n", 4},
+		{"steps removed from end", `appId: com.example
+---
+- launchApp
+- tapOn: "Login"
+`, 2},
+		{"header changed", `appId: com.example.other
+---
+- launchApp
+- tapOn: "Login"
+- inputText: "hello"
+- back
+`, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FirstChangedStep([]byte(baseFlow), []byte(tt.newFlow))
+			if err != nil {
+				t.Fatalf("FirstChangedStep failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirstChangedStep_NoHeader(t *testing.T) {
+	got, err := FirstChangedStep([]byte("- back\n- back\n"), []byte("- back\n- launchApp\n"))
+	if err != nil || got != 1 {
+		t.Errorf("got %d, %v; want 1", got, err)
+	}
+}
+
+func TestFirstChangedStep_InvalidYAML(t *testing.T) {
+	if _, err := FirstChangedStep([]byte(baseFlow), []byte("- tapOn: [\n")); err == nil {
+		t.Error("expected error for invalid YAML")
+	}
+}
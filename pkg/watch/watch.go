@@ -0,0 +1,93 @@
+// Package watch detects changes to flow files for continuous mode.
+package watch
+
+import (
+	"context"
+	"os"
+	"sort"
+	"time"
+)
+
+// DefaultInterval is how often files are polled.
+const DefaultInterval = 300 * time.Millisecond
+
+// fileState is what a change is detected from. A missing file has a zero
+// state, so deleting and re-creating a file both count as changes.
+type fileState struct {
+	modTime time.Time
+	size    int64
+	exists  bool
+}
+
+func stat(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size(), exists: true}
+}
+
+// Watcher polls a set of files and directories for changes. A directory
+// changes when entries are added, removed or renamed.
+type Watcher struct {
+	interval time.Duration
+	files    map[string]fileState
+}
+
+// New creates a watcher for paths, recording their current state.
+func New(paths []string, interval time.Duration) *Watcher {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	w := &Watcher{interval: interval}
+	w.Set(paths)
+	return w
+}
+
+// Set replaces the watched paths and records their current state.
+func (w *Watcher) Set(paths []string) {
+	w.files = make(map[string]fileState, len(paths))
+	for _, p := range paths {
+		w.files[p] = stat(p)
+	}
+}
+
+// Len returns the number of watched paths.
+func (w *Watcher) Len() int {
+	return len(w.files)
+}
+
+// Wait blocks until at least one watched path changes and returns the
+// changed paths, sorted. Changes are collected until the files have been
+// stable for one interval, so an editor's save (often several writes)
+// is reported once.
+func (w *Watcher) Wait(ctx context.Context) ([]string, error) {
+	changed := make(map[string]bool)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		settled := true
+		for path, old := range w.files {
+			if cur := stat(path); cur != old {
+				w.files[path] = cur
+				changed[path] = true
+				settled = false
+			}
+		}
+		if settled && len(changed) > 0 {
+			paths := make([]string, 0, len(changed))
+			for p := range changed {
+				paths = append(paths, p)
+			}
+			sort.Strings(paths)
+			return paths, nil
+		}
+	}
+}
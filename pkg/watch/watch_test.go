@@ -0,0 +1,73 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher_ReportsChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.yaml")
+	b := filepath.Join(dir, "b.yaml")
+	missing := filepath.Join(dir, "missing.js")
+	for _, p := range []string{a, b} {
+		if err := os.WriteFile(p, []byte("- back\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := New([]string{a, b, missing}, 10*time.Millisecond)
+	if w.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", w.Len())
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_ = os.WriteFile(b, []byte("- back\n- back\n"), 0o644)
+		_ = os.WriteFile(missing, []byte("output.x = 1"), 0o644)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	changed, err := w.Wait(ctx)
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if len(changed) != 2 || changed[0] != b || changed[1] != missing {
+		t.Errorf("changed = %v, want [%s %s]", changed, b, missing)
+	}
+}
+
+func TestWatcher_DirectoryEntries(t *testing.T) {
+	dir := t.TempDir()
+	w := New([]string{dir}, 10*time.Millisecond)
+
+	// Directory mtime granularity can be coarse; make sure it moves
+	time.Sleep(20 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(dir, "new.yaml"), []byte("- back\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Second)
+	if err := os.Chtimes(dir, future, future); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	changed, err := w.Wait(ctx)
+	if err != nil || len(changed) != 1 || changed[0] != dir {
+		t.Errorf("changed = %v, %v", changed, err)
+	}
+}
+
+func TestWatcher_Cancel(t *testing.T) {
+	w := New(nil, 10*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := w.Wait(ctx); err == nil {
+		t.Error("expected error after cancel")
+	}
+}